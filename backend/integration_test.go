@@ -32,10 +32,11 @@ func setupTestRouter() *gin.Engine {
 	// Create mock config and handlers
 	mockDB := &database.MockFirestoreClient{}
 	cfg := &config.Config{
-		AdminPassword:   "test-password",
-		SubcollectionID: "test-collection",
-		CORSOrigin:      "http://localhost:5173",
-		Port:            "8080",
+		AdminPassword:    "test-password",
+		AdminTokenSecret: "test-token-secret",
+		SubcollectionID:  "test-collection",
+		CORSOrigin:       "http://localhost:5173",
+		Port:             "8080",
 	}
 	h := handlers.New(mockDB, cfg)
 
@@ -51,7 +52,7 @@ func setupTestRouter() *gin.Engine {
 	// Admin routes
 	admin := r.Group("/api/admin")
 	admin.POST("/login", h.AdminLogin)
-	admin.Use(middleware.AuthMiddleware(cfg.AdminPassword))
+	admin.Use(middleware.AuthMiddleware(cfg.AdminTokenSecret, ""))
 	{
 		admin.GET("/attendees", h.GetAttendees)
 		admin.GET("/speakers", h.GetSpeakers)
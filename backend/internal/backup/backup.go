@@ -0,0 +1,284 @@
+// Package backup snapshots a workshop's registrations, speakers and sessions into a
+// versioned JSON archive and restores such archives, keeping document IDs so that
+// references between documents (such as session speaker IDs) stay valid.
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"appdirect-workshop-backend/internal/database"
+	"appdirect-workshop-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// FormatVersion is written to every archive; Restore rejects newer versions
+const FormatVersion = 1
+
+// batchSize keeps each restore transaction well under Firestore's 500 writes
+const batchSize = 250
+
+// Conflict modes decide what Restore does with documents whose ID already exists
+const (
+	ConflictFail      = "fail"
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+)
+
+// ErrConflict is returned in ConflictFail mode when any archived ID already exists
+var ErrConflict = errors.New("archive conflicts with existing documents")
+
+// idPattern restricts workshop and document IDs to characters that are safe in Firestore paths
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+type Archive struct {
+	Version       int              `json:"version"`
+	WorkshopID    string           `json:"workshopId"`
+	CreatedAt     time.Time        `json:"createdAt"`
+	Registrations []Registration   `json:"registrations"`
+	Speakers      []models.Speaker `json:"speakers"`
+	Sessions      []models.Session `json:"sessions"`
+}
+
+// Registration is an archived registration. It keeps the token hash, which the API
+// never returns, so attendees' tokens still work after a restore.
+type Registration struct {
+	models.Registration
+	TokenHash string `json:"tokenHash,omitempty"`
+}
+
+// Counts tallies restored documents for one collection
+type Counts struct {
+	Created     int `json:"created"`
+	Overwritten int `json:"overwritten"`
+	Skipped     int `json:"skipped"`
+}
+
+type Result struct {
+	WorkshopID  string            `json:"workshopId"`
+	Collections map[string]Counts `json:"collections"`
+	// Conflicts lists "collection/id" for every archived document that already exists
+	Conflicts []string `json:"conflicts,omitempty"`
+}
+
+// ValidWorkshopID reports whether id is safe to use as a workshop document ID
+func ValidWorkshopID(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+// ValidConflictMode reports whether mode is one of the Conflict constants
+func ValidConflictMode(mode string) bool {
+	return mode == ConflictFail || mode == ConflictSkip || mode == ConflictOverwrite
+}
+
+// Export reads every registration, speaker and session of the workshop
+func Export(db database.DatabaseInterface, workshopID string) (*Archive, error) {
+	archive := &Archive{
+		Version:    FormatVersion,
+		WorkshopID: workshopID,
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	err := readAll(db, "registrations", func(doc *firestore.DocumentSnapshot) error {
+		var reg models.Registration
+		if err := doc.DataTo(&reg); err != nil {
+			return err
+		}
+		reg.ID = doc.Ref.ID
+		archive.Registrations = append(archive.Registrations, Registration{Registration: reg, TokenHash: reg.TokenHash})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readAll(db, "speakers", func(doc *firestore.DocumentSnapshot) error {
+		var speaker models.Speaker
+		if err := doc.DataTo(&speaker); err != nil {
+			return err
+		}
+		speaker.ID = doc.Ref.ID
+		archive.Speakers = append(archive.Speakers, speaker)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readAll(db, "sessions", func(doc *firestore.DocumentSnapshot) error {
+		var session models.Session
+		if err := doc.DataTo(&session); err != nil {
+			return err
+		}
+		session.ID = doc.Ref.ID
+		archive.Sessions = append(archive.Sessions, session)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+// Validate checks an archive before it is restored
+func Validate(archive *Archive) error {
+	if archive.Version < 1 || archive.Version > FormatVersion {
+		return fmt.Errorf("unsupported archive version %d (supported: 1 to %d)", archive.Version, FormatVersion)
+	}
+
+	seen := map[string]bool{}
+	for _, doc := range archive.documents() {
+		if doc.id == "" {
+			continue
+		}
+		if !idPattern.MatchString(doc.id) {
+			return fmt.Errorf("invalid document ID %q in %s", doc.id, doc.collection)
+		}
+		key := doc.collection + "/" + doc.id
+		if seen[key] {
+			return fmt.Errorf("duplicate document ID %q in %s", doc.id, doc.collection)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// Restore writes the archive into db, keeping document IDs. Documents without an ID
+// get a new one. In ConflictFail mode nothing is written if any ID already exists.
+func Restore(db database.DatabaseInterface, workshopID string, archive *Archive, mode string) (*Result, error) {
+	if err := Validate(archive); err != nil {
+		return nil, err
+	}
+
+	existing := map[string]map[string]bool{}
+	for _, collection := range []string{"registrations", "speakers", "sessions"} {
+		ids, err := documentIDs(db, collection)
+		if err != nil {
+			return nil, err
+		}
+		existing[collection] = ids
+	}
+
+	result, writes := plan(archive, existing, mode)
+	result.WorkshopID = workshopID
+	if len(result.Conflicts) > 0 {
+		return result, ErrConflict
+	}
+
+	for start := 0; start < len(writes); start += batchSize {
+		end := start + batchSize
+		if end > len(writes) {
+			end = len(writes)
+		}
+		batch := writes[start:end]
+		err := db.RunTransaction(func(ctx context.Context, tx *firestore.Transaction) error {
+			for _, doc := range batch {
+				ref := db.Collection(doc.collection).NewDoc()
+				if doc.id != "" {
+					ref = db.Collection(doc.collection).Doc(doc.id)
+				}
+				if err := tx.Set(ref, doc.data); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return result, fmt.Errorf("restored %d of %d documents before failing: %w", start, len(writes), err)
+		}
+	}
+
+	return result, nil
+}
+
+// plan decides which archived documents to write given the IDs that already exist
+func plan(archive *Archive, existing map[string]map[string]bool, mode string) (*Result, []document) {
+	result := &Result{Collections: map[string]Counts{
+		"registrations": {},
+		"speakers":      {},
+		"sessions":      {},
+	}}
+
+	var writes []document
+	for _, doc := range archive.documents() {
+		counts := result.Collections[doc.collection]
+		switch {
+		case doc.id == "" || !existing[doc.collection][doc.id]:
+			counts.Created++
+			writes = append(writes, doc)
+		case mode == ConflictOverwrite:
+			counts.Overwritten++
+			writes = append(writes, doc)
+		case mode == ConflictSkip:
+			counts.Skipped++
+		default:
+			result.Conflicts = append(result.Conflicts, doc.collection+"/"+doc.id)
+		}
+		result.Collections[doc.collection] = counts
+	}
+	return result, writes
+}
+
+// document is one archived record bound for a collection
+type document struct {
+	collection string
+	id         string
+	data       interface{}
+}
+
+// documents lists speakers before sessions so a partially failed restore never leaves
+// sessions pointing at speakers that were not written yet
+func (a *Archive) documents() []document {
+	var docs []document
+	for _, archived := range a.Registrations {
+		reg := archived.Registration
+		reg.TokenHash = archived.TokenHash
+		docs = append(docs, document{"registrations", reg.ID, reg})
+	}
+	for _, speaker := range a.Speakers {
+		docs = append(docs, document{"speakers", speaker.ID, speaker})
+	}
+	for _, session := range a.Sessions {
+		docs = append(docs, document{"sessions", session.ID, session})
+	}
+	return docs
+}
+
+func readAll(db database.DatabaseInterface, collection string, fn func(*firestore.DocumentSnapshot) error) error {
+	iter := db.Collection(collection).Documents(db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", collection, err)
+		}
+		if err := fn(doc); err != nil {
+			return fmt.Errorf("failed to decode %s/%s: %w", collection, doc.Ref.ID, err)
+		}
+	}
+}
+
+// documentIDs lists existing IDs without reading document contents
+func documentIDs(db database.DatabaseInterface, collection string) (map[string]bool, error) {
+	ids := map[string]bool{}
+	iter := db.Collection(collection).DocumentRefs(db.Context())
+	for {
+		ref, err := iter.Next()
+		if err == iterator.Done {
+			return ids, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", collection, err)
+		}
+		ids[ref.ID] = true
+	}
+}
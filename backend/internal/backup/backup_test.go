@@ -0,0 +1,126 @@
+package backup
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"appdirect-workshop-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testArchive() *Archive {
+	return &Archive{
+		Version:    FormatVersion,
+		WorkshopID: "workshop-2024",
+		Registrations: []Registration{
+			{
+				Registration: models.Registration{ID: "reg1", Name: "Jane", Email: "jane@example.com", Designation: "Engineer", CreatedAt: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)},
+				TokenHash:    "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8",
+			},
+			{Registration: models.Registration{ID: "reg2", Name: "John", Email: "john@example.com", Designation: "Manager"}},
+		},
+		Speakers: []models.Speaker{{ID: "spk1", Name: "Ada", Bio: "Bio"}},
+		Sessions: []models.Session{{ID: "ses1", Title: "Keynote", SpeakerIDs: []string{"spk1"}}},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		modify        func(a *Archive)
+		expectedError string
+	}{
+		{name: "valid", modify: func(a *Archive) {}},
+		{name: "missing ID is allowed", modify: func(a *Archive) { a.Speakers[0].ID = "" }},
+		{name: "missing version", modify: func(a *Archive) { a.Version = 0 }, expectedError: "unsupported archive version 0"},
+		{name: "newer version", modify: func(a *Archive) { a.Version = FormatVersion + 1 }, expectedError: "unsupported archive version"},
+		{name: "path in ID", modify: func(a *Archive) { a.Sessions[0].ID = "../admin" }, expectedError: "invalid document ID"},
+		{
+			name:          "duplicate ID",
+			modify:        func(a *Archive) { a.Registrations[1].ID = "reg1" },
+			expectedError: `duplicate document ID "reg1" in registrations`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := testArchive()
+			tt.modify(archive)
+			err := Validate(archive)
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	existing := map[string]map[string]bool{
+		"registrations": {"reg1": true},
+		"speakers":      {},
+		"sessions":      {"ses1": true},
+	}
+
+	tests := []struct {
+		mode              string
+		expectedWrites    int
+		expectedConflicts []string
+		expectedRegs      Counts
+		expectedSessions  Counts
+	}{
+		{
+			mode:              ConflictFail,
+			expectedWrites:    2,
+			expectedConflicts: []string{"registrations/reg1", "sessions/ses1"},
+			expectedRegs:      Counts{Created: 1},
+		},
+		{
+			mode:             ConflictSkip,
+			expectedWrites:   2,
+			expectedRegs:     Counts{Created: 1, Skipped: 1},
+			expectedSessions: Counts{Skipped: 1},
+		},
+		{
+			mode:             ConflictOverwrite,
+			expectedWrites:   4,
+			expectedRegs:     Counts{Created: 1, Overwritten: 1},
+			expectedSessions: Counts{Overwritten: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			result, writes := plan(testArchive(), existing, tt.mode)
+			assert.Len(t, writes, tt.expectedWrites)
+			assert.Equal(t, tt.expectedConflicts, result.Conflicts)
+			assert.Equal(t, tt.expectedRegs, result.Collections["registrations"])
+			assert.Equal(t, Counts{Created: 1}, result.Collections["speakers"])
+			assert.Equal(t, tt.expectedSessions, result.Collections["sessions"])
+		})
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	archive := testArchive()
+	data, err := json.Marshal(archive)
+	assert.NoError(t, err)
+
+	var decoded Archive
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, archive.Registrations[0].CreatedAt, decoded.Registrations[0].CreatedAt)
+	assert.Equal(t, "reg1", decoded.Registrations[0].ID)
+	assert.Equal(t, archive.Registrations[0].TokenHash, decoded.Registrations[0].TokenHash)
+	assert.Contains(t, string(data), `"tokenHash"`)
+	assert.Equal(t, []string{"spk1"}, decoded.Sessions[0].SpeakerIDs)
+}
+
+func TestValidWorkshopID(t *testing.T) {
+	assert.True(t, ValidWorkshopID("workshop-2024"))
+	assert.False(t, ValidWorkshopID(""))
+	assert.False(t, ValidWorkshopID("a/b"))
+	assert.False(t, ValidWorkshopID(".."))
+}
@@ -0,0 +1,120 @@
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"appdirect-workshop-backend/internal/config"
+	"appdirect-workshop-backend/internal/database"
+)
+
+// RunCommand runs the "backup" or "restore" command line tools:
+//
+//	app backup [-workshop id] [-out file]
+//	app restore [-workshop id] [-conflict fail|skip|overwrite] -in file
+//
+// The workshop defaults to SUBCOLLECTION_ID; "-" or no file means stdout/stdin.
+func RunCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: app backup|restore [flags]")
+	}
+
+	switch args[0] {
+	case "backup":
+		flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+		workshop := flags.String("workshop", cfg.SubcollectionID, "workshop ID to back up")
+		out := flags.String("out", "-", "archive file to write")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		db, err := openWorkshop(cfg, *workshop)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		archive, err := Export(db, *workshop)
+		if err != nil {
+			return err
+		}
+
+		w := io.Writer(os.Stdout)
+		if *out != "-" {
+			f, err := os.Create(*out)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(archive); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Backed up %d registrations, %d speakers and %d sessions from %s\n",
+			len(archive.Registrations), len(archive.Speakers), len(archive.Sessions), *workshop)
+		return nil
+
+	case "restore":
+		flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+		workshop := flags.String("workshop", cfg.SubcollectionID, "workshop ID to restore into")
+		in := flags.String("in", "-", "archive file to read")
+		conflict := flags.String("conflict", ConflictFail, "what to do with existing IDs: fail, skip or overwrite")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		if !ValidConflictMode(*conflict) {
+			return fmt.Errorf("invalid -conflict %q", *conflict)
+		}
+
+		r := io.Reader(os.Stdin)
+		if *in != "-" {
+			f, err := os.Open(*in)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+		var archive Archive
+		if err := json.NewDecoder(r).Decode(&archive); err != nil {
+			return fmt.Errorf("failed to parse archive: %w", err)
+		}
+
+		db, err := openWorkshop(cfg, *workshop)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		result, err := Restore(db, *workshop, &archive, *conflict)
+		if errors.Is(err, ErrConflict) {
+			return fmt.Errorf("%w: %v (use -conflict skip or overwrite)", err, result.Conflicts)
+		}
+		if err != nil {
+			return err
+		}
+		for collection, counts := range result.Collections {
+			fmt.Fprintf(os.Stderr, "%s: %d created, %d overwritten, %d skipped\n",
+				collection, counts.Created, counts.Overwritten, counts.Skipped)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown command %q (expected backup or restore)", args[0])
+}
+
+func openWorkshop(cfg *config.Config, workshopID string) (*database.FirestoreClient, error) {
+	if !ValidWorkshopID(workshopID) {
+		return nil, fmt.Errorf("invalid workshop ID %q", workshopID)
+	}
+	workshopCfg := *cfg
+	workshopCfg.SubcollectionID = workshopID
+	return database.NewFirestoreClient(&workshopCfg)
+}
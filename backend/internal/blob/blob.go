@@ -0,0 +1,47 @@
+// Package blob stores uploaded files, such as speaker images, in a local directory or
+// a Cloud Storage bucket.
+package blob
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrNotFound is returned for keys with no object
+var ErrNotFound = errors.New("blob: object not found")
+
+// ErrInvalidKey is returned for keys ValidKey rejects
+var ErrInvalidKey = errors.New("blob: invalid key")
+
+// Object is a stored file and its media type
+type Object struct {
+	Data        []byte
+	ContentType string
+}
+
+// Store keeps objects under slash-separated keys
+type Store interface {
+	Put(ctx context.Context, key string, obj Object) error
+	Get(ctx context.Context, key string) (Object, error)
+}
+
+// ValidKey reports whether key is safe both as an object name and as a relative file
+// path: slash-separated segments of letters, digits, '.', '-' and '_', none of them
+// empty or starting with a dot
+func ValidKey(key string) bool {
+	if key == "" || len(key) > 512 {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment[0] == '.' {
+			return false
+		}
+		for _, r := range segment {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package blob
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidKey(t *testing.T) {
+	valid := []string{"a.png", "default-workshop/speakers/sp1/3f2a-large.webp", "A_b-c/d.E"}
+	invalid := []string{"", "/abs.png", "a//b.png", "a/../b.png", "../b.png", ".hidden", "a/.b", "a b.png", `a\b.png`, "a/b/"}
+
+	for _, key := range valid {
+		assert.True(t, ValidKey(key), key)
+	}
+	for _, key := range invalid {
+		assert.False(t, ValidKey(key), key)
+	}
+}
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := NewLocal(dir)
+
+	_, err := store.Get(ctx, "w/speakers/sp1/a-large.webp")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Put(ctx, "w/speakers/sp1/a-large.webp", Object{Data: []byte("RIFF"), ContentType: "image/webp"}))
+	obj, err := store.Get(ctx, "w/speakers/sp1/a-large.webp")
+	require.NoError(t, err)
+	assert.Equal(t, Object{Data: []byte("RIFF"), ContentType: "image/webp"}, obj)
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(filepath.Join(dir, "w", "speakers", "sp1"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// Putting a key again replaces the object
+	require.NoError(t, store.Put(ctx, "w/speakers/sp1/a-large.webp", Object{Data: []byte("RIFF2"), ContentType: "image/webp"}))
+	obj, err = store.Get(ctx, "w/speakers/sp1/a-large.webp")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("RIFF2"), obj.Data)
+
+	assert.ErrorIs(t, store.Put(ctx, "../escape.png", Object{}), ErrInvalidKey)
+	_, err = store.Get(ctx, "w/../../etc/passwd")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
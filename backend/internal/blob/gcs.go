@@ -0,0 +1,83 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+)
+
+// GCS stores objects in a Cloud Storage bucket. The client is created on first use,
+// so a missing or misconfigured bucket shows up as errors from the calls rather than
+// at startup.
+type GCS struct {
+	bucket string
+	opts   []option.ClientOption
+
+	once   sync.Once
+	handle *storage.BucketHandle
+	err    error
+}
+
+func NewGCS(bucket string, opts ...option.ClientOption) *GCS {
+	return &GCS{bucket: bucket, opts: opts}
+}
+
+func (g *GCS) bucketHandle() (*storage.BucketHandle, error) {
+	g.once.Do(func() {
+		client, err := storage.NewClient(context.Background(), g.opts...)
+		if err != nil {
+			g.err = err
+			return
+		}
+		g.handle = client.Bucket(g.bucket)
+	})
+	return g.handle, g.err
+}
+
+func (g *GCS) Put(ctx context.Context, key string, obj Object) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+	bucket, err := g.bucketHandle()
+	if err != nil {
+		return err
+	}
+
+	w := bucket.Object(key).NewWriter(ctx)
+	w.ContentType = obj.ContentType
+	// Keys are never reused for different content
+	w.CacheControl = "public, max-age=31536000, immutable"
+	if _, err := w.Write(obj.Data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (g *GCS) Get(ctx context.Context, key string) (Object, error) {
+	if !ValidKey(key) {
+		return Object{}, ErrInvalidKey
+	}
+	bucket, err := g.bucketHandle()
+	if err != nil {
+		return Object{}, err
+	}
+
+	r, err := bucket.Object(key).NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return Object{}, ErrNotFound
+	}
+	if err != nil {
+		return Object{}, err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Object{}, err
+	}
+	return Object{Data: data, ContentType: r.Attrs.ContentType}, nil
+}
@@ -0,0 +1,68 @@
+package challenge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Captcha verifies response tokens with a siteverify-style endpoint, as used by
+// reCAPTCHA, hCaptcha and Cloudflare Turnstile
+type Captcha struct {
+	VerifyURL  string
+	Secret     string
+	HTTPClient *http.Client
+}
+
+func NewCaptcha(verifyURL, secret string) *Captcha {
+	return &Captcha{
+		VerifyURL:  verifyURL,
+		Secret:     secret,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Verify implements Verifier
+func (v *Captcha) Verify(ctx context.Context, proof Proof) error {
+	if proof.Solution == "" {
+		return fmt.Errorf("%w: missing CAPTCHA response", ErrFailed)
+	}
+
+	form := url.Values{}
+	form.Set("secret", v.Secret)
+	form.Set("response", proof.Solution)
+	if proof.RemoteIP != "" {
+		form.Set("remoteip", proof.RemoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.VerifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("CAPTCHA verification request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("CAPTCHA provider returned %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to parse CAPTCHA response: %v", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("%w: CAPTCHA rejected", ErrFailed)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package challenge
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrFailed is returned when a submission does not pass the challenge
+var ErrFailed = errors.New("challenge verification failed")
+
+// Proof is what a client submits alongside a registration
+type Proof struct {
+	// Challenge is the token issued by the server (proof-of-work only)
+	Challenge string
+	// Solution is the proof-of-work nonce or the CAPTCHA response token
+	Solution string
+	// RemoteIP is the client address, forwarded to CAPTCHA providers
+	RemoteIP string
+}
+
+// Verifier checks a registration's proof that it comes from a human
+type Verifier interface {
+	Verify(ctx context.Context, proof Proof) error
+}
@@ -0,0 +1,91 @@
+package challenge
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProofOfWork(t *testing.T) {
+	pow := NewProofOfWork([]byte("secret"), 8, time.Minute)
+	ctx := context.Background()
+
+	c, err := pow.Issue()
+	assert.NoError(t, err)
+	assert.Equal(t, 8, c.Difficulty)
+
+	nonce := Solve(c.Challenge, c.Difficulty)
+	assert.NoError(t, pow.Verify(ctx, Proof{Challenge: c.Challenge, Solution: nonce}))
+
+	// A solved challenge cannot be replayed
+	err = pow.Verify(ctx, Proof{Challenge: c.Challenge, Solution: nonce})
+	assert.True(t, errors.Is(err, ErrFailed))
+}
+
+func TestProofOfWorkRejects(t *testing.T) {
+	pow := NewProofOfWork([]byte("secret"), 8, time.Minute)
+	ctx := context.Background()
+	c, _ := pow.Issue()
+
+	// Find a nonce that does not meet the difficulty
+	bad := ""
+	for i := 0; bad == "" || leadingZeroBits(Hash(c.Challenge, bad)) >= 8; i++ {
+		bad = strconv.Itoa(i)
+	}
+
+	tests := []struct {
+		name  string
+		proof Proof
+	}{
+		{name: "missing solution", proof: Proof{Challenge: c.Challenge}},
+		{name: "insufficient work", proof: Proof{Challenge: c.Challenge, Solution: bad}},
+		{name: "tampered challenge", proof: Proof{Challenge: c.Challenge + "x", Solution: Solve(c.Challenge+"x", 8)}},
+		{name: "garbage", proof: Proof{Challenge: "not-a-challenge", Solution: "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := pow.Verify(ctx, tt.proof)
+			assert.True(t, errors.Is(err, ErrFailed))
+		})
+	}
+
+	// A challenge signed with another secret is rejected
+	other := NewProofOfWork([]byte("other"), 8, time.Minute)
+	foreign, _ := other.Issue()
+	err := pow.Verify(ctx, Proof{Challenge: foreign.Challenge, Solution: Solve(foreign.Challenge, 8)})
+	assert.True(t, errors.Is(err, ErrFailed))
+
+	// Expired challenges are rejected
+	expired, _ := pow.Issue()
+	pow.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	err = pow.Verify(ctx, Proof{Challenge: expired.Challenge, Solution: Solve(expired.Challenge, 8)})
+	assert.True(t, errors.Is(err, ErrFailed))
+}
+
+func TestCaptcha(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		assert.Equal(t, "captcha-secret", r.Form.Get("secret"))
+		assert.Equal(t, "203.0.113.7", r.Form.Get("remoteip"))
+		if r.Form.Get("response") == "human" {
+			w.Write([]byte(`{"success": true}`))
+			return
+		}
+		w.Write([]byte(`{"success": false, "error-codes": ["invalid-input-response"]}`))
+	}))
+	defer server.Close()
+
+	v := NewCaptcha(server.URL, "captcha-secret")
+	ctx := context.Background()
+
+	assert.NoError(t, v.Verify(ctx, Proof{Solution: "human", RemoteIP: "203.0.113.7"}))
+	assert.True(t, errors.Is(v.Verify(ctx, Proof{Solution: "bot", RemoteIP: "203.0.113.7"}), ErrFailed))
+	assert.True(t, errors.Is(v.Verify(ctx, Proof{RemoteIP: "203.0.113.7"}), ErrFailed))
+}
@@ -0,0 +1,154 @@
+package challenge
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/bits"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProofOfWork is a hashcash-style challenge: the client must find a nonce such that
+// sha256(challenge + ":" + nonce) starts with Difficulty zero bits. Challenges are
+// HMAC-signed and stateless; solved ones are remembered until expiry to stop reuse.
+type ProofOfWork struct {
+	Difficulty int
+	TTL        time.Duration
+
+	secret []byte
+	now    func() time.Time
+
+	mu   sync.Mutex
+	used map[string]time.Time
+}
+
+// Challenge is returned to clients by the challenge endpoint
+type Challenge struct {
+	Challenge  string    `json:"challenge"`
+	Difficulty int       `json:"difficulty"`
+	Algorithm  string    `json:"algorithm"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+type powPayload struct {
+	Salt       string `json:"s"`
+	Difficulty int    `json:"d"`
+	ExpiresAt  int64  `json:"e"`
+}
+
+func NewProofOfWork(secret []byte, difficulty int, ttl time.Duration) *ProofOfWork {
+	return &ProofOfWork{
+		Difficulty: difficulty,
+		TTL:        ttl,
+		secret:     secret,
+		now:        time.Now,
+		used:       make(map[string]time.Time),
+	}
+}
+
+// Issue creates a new signed challenge
+func (p *ProofOfWork) Issue() (*Challenge, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	expiresAt := p.now().Add(p.TTL)
+	payload, err := json.Marshal(powPayload{
+		Salt:       base64.RawURLEncoding.EncodeToString(salt),
+		Difficulty: p.Difficulty,
+		ExpiresAt:  expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return &Challenge{
+		Challenge:  encoded + "." + p.sign(encoded),
+		Difficulty: p.Difficulty,
+		Algorithm:  "sha256",
+		ExpiresAt:  time.Unix(expiresAt.Unix(), 0),
+	}, nil
+}
+
+// Verify implements Verifier
+func (p *ProofOfWork) Verify(ctx context.Context, proof Proof) error {
+	encoded, signature, ok := strings.Cut(proof.Challenge, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(p.sign(encoded))) {
+		return fmt.Errorf("%w: invalid challenge", ErrFailed)
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("%w: invalid challenge", ErrFailed)
+	}
+	var payload powPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return fmt.Errorf("%w: invalid challenge", ErrFailed)
+	}
+
+	now := p.now()
+	expiresAt := time.Unix(payload.ExpiresAt, 0)
+	if now.After(expiresAt) {
+		return fmt.Errorf("%w: challenge expired", ErrFailed)
+	}
+
+	if proof.Solution == "" || leadingZeroBits(Hash(proof.Challenge, proof.Solution)) < payload.Difficulty {
+		return fmt.Errorf("%w: insufficient proof of work", ErrFailed)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, exp := range p.used {
+		if now.After(exp) {
+			delete(p.used, key)
+		}
+	}
+	if _, seen := p.used[proof.Challenge]; seen {
+		return fmt.Errorf("%w: challenge already used", ErrFailed)
+	}
+	p.used[proof.Challenge] = expiresAt
+
+	return nil
+}
+
+// Hash is the digest clients must compute for a candidate nonce
+func Hash(challenge, nonce string) []byte {
+	sum := sha256.Sum256([]byte(challenge + ":" + nonce))
+	return sum[:]
+}
+
+// Solve brute-forces a nonce; intended for tests and non-browser clients
+func Solve(challenge string, difficulty int) string {
+	for i := 0; ; i++ {
+		nonce := fmt.Sprintf("%x", i)
+		if leadingZeroBits(Hash(challenge, nonce)) >= difficulty {
+			return nonce
+		}
+	}
+}
+
+func (p *ProofOfWork) sign(encoded string) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func leadingZeroBits(sum []byte) int {
+	n := 0
+	for _, b := range sum {
+		if b == 0 {
+			n += 8
+			continue
+		}
+		return n + bits.LeadingZeros8(b)
+	}
+	return n
+}
@@ -4,15 +4,73 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net/mail"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// RateLimit is a token bucket budget: Requests tokens refilled evenly over Period
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// defaultFreeMailDomains are bucketed together in organization analytics unless
+// FREE_MAIL_DOMAINS replaces them
+var defaultFreeMailDomains = []string{
+	"gmail.com", "googlemail.com", "yahoo.com", "yahoo.co.in", "outlook.com", "hotmail.com",
+	"live.com", "msn.com", "icloud.com", "me.com", "aol.com", "proton.me", "protonmail.com",
+	"gmx.com", "zoho.com", "yandex.com", "mail.com", "rediffmail.com",
+}
+
+// defaultRateLimits are the per-route budgets used unless overridden by RATE_LIMITS
+var defaultRateLimits = map[string]RateLimit{
+	"register": {Requests: 5, Period: time.Minute},
+	"proposal": {Requests: 5, Period: time.Minute},
+	"count":    {Requests: 60, Period: time.Minute},
+	"login":    {Requests: 10, Period: time.Minute},
+	"default":  {Requests: 120, Period: time.Minute},
+	// Keyed by attendee rather than IP, since a venue often shares one address
+	"question": {Requests: 3, Period: time.Minute},
+	"vote":     {Requests: 30, Period: time.Minute},
+}
+
 type Config struct {
 	FirebaseServiceAccount map[string]interface{}
 	SubcollectionID        string
 	AdminPassword          string
+	AdminTokenSecret       string
 	Port                   string
-	CORSOrigin            string
+	CORSOrigin             string
+	MFAEnabled             bool
+	TOTPIssuer             string
+	OIDCIssuer             string
+	OIDCClientID           string
+	OIDCClientSecret       string
+	OIDCRedirectURL        string
+	OIDCAllowedDomains     []string
+	OIDCRoleClaim          string
+	OIDCRoleMapping        map[string]string
+	OIDCPostLoginURL       string
+	OIDCTokenSecret        string
+	RateLimitEnabled       bool
+	RateLimits             map[string]RateLimit
+	TrustedProxyHops       int
+	ChallengeMode          string
+	PoWDifficulty          int
+	CaptchaVerifyURL       string
+	CaptchaSecret          string
+	SuperAdmins            []string
+	FreeMailDomains        []string
+	SMTPHost               string
+	SMTPPort               int
+	SMTPUsername           string
+	SMTPPassword           string
+	MailFrom               string
+	BlobBucket             string
+	BlobDir                string
 }
 
 func Load() (*Config, error) {
@@ -61,6 +119,13 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("ADMIN_PASSWORD environment variable is required")
 	}
 
+	// Admin tokens are signed with a key only the server knows; signing them with the
+	// shared password would let anyone who knows it mint a token and skip two-factor
+	cfg.AdminTokenSecret = os.Getenv("ADMIN_TOKEN_SECRET")
+	if cfg.AdminTokenSecret == "" || cfg.AdminTokenSecret == cfg.AdminPassword {
+		return nil, fmt.Errorf("ADMIN_TOKEN_SECRET is required and must differ from ADMIN_PASSWORD")
+	}
+
 	// Port
 	cfg.Port = os.Getenv("PORT")
 	if cfg.Port == "" {
@@ -73,6 +138,198 @@ func Load() (*Config, error) {
 		cfg.CORSOrigin = "http://localhost:5173"
 	}
 
+	// Admin two-factor authentication (enabled unless explicitly turned off)
+	cfg.MFAEnabled = os.Getenv("ADMIN_MFA_ENABLED") != "false"
+
+	// Issuer shown in authenticator apps for admin two-factor enrollment
+	cfg.TOTPIssuer = os.Getenv("TOTP_ISSUER")
+	if cfg.TOTPIssuer == "" {
+		cfg.TOTPIssuer = "AppDirect Workshop"
+	}
+
+	// OpenID Connect single sign-on (optional)
+	cfg.OIDCIssuer = os.Getenv("OIDC_ISSUER")
+	if cfg.OIDCIssuer != "" {
+		cfg.OIDCClientID = os.Getenv("OIDC_CLIENT_ID")
+		cfg.OIDCClientSecret = os.Getenv("OIDC_CLIENT_SECRET")
+		cfg.OIDCRedirectURL = os.Getenv("OIDC_REDIRECT_URL")
+		if cfg.OIDCClientID == "" || cfg.OIDCRedirectURL == "" {
+			return nil, fmt.Errorf("OIDC_CLIENT_ID and OIDC_REDIRECT_URL are required when OIDC_ISSUER is set")
+		}
+
+		cfg.OIDCAllowedDomains = splitList(os.Getenv("OIDC_ALLOWED_DOMAINS"))
+		if len(cfg.OIDCAllowedDomains) == 0 {
+			return nil, fmt.Errorf("OIDC_ALLOWED_DOMAINS is required when OIDC_ISSUER is set")
+		}
+
+		cfg.OIDCRoleClaim = os.Getenv("OIDC_ROLE_CLAIM")
+		if cfg.OIDCRoleClaim == "" {
+			cfg.OIDCRoleClaim = "groups"
+		}
+
+		// Format: "claim-value=role,claim-value=role"
+		mapping, err := parseMapping(os.Getenv("OIDC_ROLE_MAPPING"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid OIDC_ROLE_MAPPING: %v", err)
+		}
+		for _, role := range mapping {
+			if role != "admin" && role != "viewer" {
+				return nil, fmt.Errorf("invalid OIDC_ROLE_MAPPING: unknown role %q", role)
+			}
+		}
+		cfg.OIDCRoleMapping = mapping
+
+		cfg.OIDCPostLoginURL = os.Getenv("OIDC_POST_LOGIN_URL")
+		if cfg.OIDCPostLoginURL == "" {
+			cfg.OIDCPostLoginURL = "/admin"
+		}
+
+		// SSO tokens carry a verified per-person identity, so they are signed with a
+		// key that holders of the shared admin password don't know
+		cfg.OIDCTokenSecret = os.Getenv("OIDC_TOKEN_SECRET")
+		if cfg.OIDCTokenSecret == "" || cfg.OIDCTokenSecret == cfg.AdminPassword {
+			return nil, fmt.Errorf("OIDC_TOKEN_SECRET is required when OIDC_ISSUER is set and must differ from ADMIN_PASSWORD")
+		}
+	}
+
+	// Rate limiting of public endpoints
+	cfg.RateLimitEnabled = os.Getenv("RATE_LIMIT_ENABLED") != "false"
+	cfg.RateLimits = make(map[string]RateLimit)
+	for route, limit := range defaultRateLimits {
+		cfg.RateLimits[route] = limit
+	}
+	// Format: "route=requests/period,...", e.g. "register=5/1m,count=60/1m"
+	overrides, err := parseMapping(os.Getenv("RATE_LIMITS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMITS: %v", err)
+	}
+	for route, value := range overrides {
+		if _, ok := defaultRateLimits[route]; !ok {
+			return nil, fmt.Errorf("invalid RATE_LIMITS: unknown route %q", route)
+		}
+		limit, err := parseRateLimit(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RATE_LIMITS entry for %s: %v", route, err)
+		}
+		cfg.RateLimits[route] = limit
+	}
+
+	// Number of proxies that append to X-Forwarded-For in front of the server.
+	// Cloud Run's front end adds exactly one entry.
+	if hops := os.Getenv("TRUSTED_PROXY_HOPS"); hops != "" {
+		n, err := strconv.Atoi(hops)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("TRUSTED_PROXY_HOPS must be a non-negative integer")
+		}
+		cfg.TrustedProxyHops = n
+	} else if os.Getenv("K_SERVICE") != "" {
+		cfg.TrustedProxyHops = 1
+	}
+
+	// Bot protection on registration: "none", "pow" (proof-of-work) or "captcha"
+	cfg.ChallengeMode = os.Getenv("CHALLENGE_MODE")
+	if cfg.ChallengeMode == "" {
+		cfg.ChallengeMode = "none"
+	}
+	switch cfg.ChallengeMode {
+	case "none":
+	case "pow":
+		cfg.PoWDifficulty = 18
+		if difficulty := os.Getenv("POW_DIFFICULTY"); difficulty != "" {
+			n, err := strconv.Atoi(difficulty)
+			if err != nil || n < 1 || n > 32 {
+				return nil, fmt.Errorf("POW_DIFFICULTY must be between 1 and 32")
+			}
+			cfg.PoWDifficulty = n
+		}
+	case "captcha":
+		cfg.CaptchaVerifyURL = os.Getenv("CAPTCHA_VERIFY_URL")
+		cfg.CaptchaSecret = os.Getenv("CAPTCHA_SECRET")
+		if cfg.CaptchaVerifyURL == "" || cfg.CaptchaSecret == "" {
+			return nil, fmt.Errorf("CAPTCHA_VERIFY_URL and CAPTCHA_SECRET are required when CHALLENGE_MODE is captcha")
+		}
+	default:
+		return nil, fmt.Errorf("CHALLENGE_MODE must be none, pow or captcha")
+	}
+
+	// Admins who can manage every workshop, including creating them and granting access
+	cfg.SuperAdmins = splitList(os.Getenv("SUPER_ADMINS"))
+	if len(cfg.SuperAdmins) == 0 {
+		cfg.SuperAdmins = []string{"admin"}
+	}
+
+	cfg.FreeMailDomains = defaultFreeMailDomains
+	if domains := splitList(strings.ToLower(os.Getenv("FREE_MAIL_DOMAINS"))); len(domains) > 0 {
+		cfg.FreeMailDomains = domains
+	}
+
+	// Email for announcements; without SMTP_HOST announcements can't be emailed
+	cfg.SMTPHost = os.Getenv("SMTP_HOST")
+	if cfg.SMTPHost != "" {
+		cfg.SMTPPort = 587
+		if port := os.Getenv("SMTP_PORT"); port != "" {
+			n, err := strconv.Atoi(port)
+			if err != nil || n < 1 || n > 65535 {
+				return nil, fmt.Errorf("SMTP_PORT must be a port number")
+			}
+			cfg.SMTPPort = n
+		}
+		cfg.SMTPUsername = os.Getenv("SMTP_USERNAME")
+		cfg.SMTPPassword = os.Getenv("SMTP_PASSWORD")
+		cfg.MailFrom = os.Getenv("MAIL_FROM")
+		if _, err := mail.ParseAddress(cfg.MailFrom); err != nil {
+			return nil, fmt.Errorf("MAIL_FROM must be an email address when SMTP_HOST is set")
+		}
+	}
+
+	// Uploaded images go to a Cloud Storage bucket, or to a local directory without one
+	cfg.BlobBucket = os.Getenv("BLOB_BUCKET")
+	cfg.BlobDir = os.Getenv("BLOB_DIR")
+	if cfg.BlobDir == "" {
+		cfg.BlobDir = "uploads"
+	}
+
 	return cfg, nil
 }
 
+// splitList parses a comma-separated list, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseMapping parses comma-separated key=value pairs
+func parseMapping(value string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	for _, pair := range splitList(value) {
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" || strings.TrimSpace(val) == "" {
+			return nil, fmt.Errorf("expected key=value, got %q", pair)
+		}
+		mapping[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+	return mapping, nil
+}
+
+// parseRateLimit parses "requests/period", e.g. "5/1m" or "100/1h"
+func parseRateLimit(value string) (RateLimit, error) {
+	count, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("expected requests/period, got %q", value)
+	}
+	requests, err := strconv.Atoi(count)
+	if err != nil || requests < 1 {
+		return RateLimit{}, fmt.Errorf("invalid request count %q", count)
+	}
+	duration, err := time.ParseDuration(period)
+	if err != nil || duration <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid period %q", period)
+	}
+	return RateLimit{Requests: requests, Period: duration}, nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -16,8 +17,28 @@ func TestLoadConfig(t *testing.T) {
 		"FIREBASE_SERVICE_ACCOUNT",
 		"SUBSCOLLECTION_ID",
 		"ADMIN_PASSWORD",
+		"ADMIN_TOKEN_SECRET",
 		"PORT",
 		"CORS_ORIGIN",
+		"ADMIN_MFA_ENABLED",
+		"TOTP_ISSUER",
+		"OIDC_ISSUER",
+		"OIDC_CLIENT_ID",
+		"OIDC_REDIRECT_URL",
+		"OIDC_ALLOWED_DOMAINS",
+		"OIDC_ROLE_MAPPING",
+		"OIDC_TOKEN_SECRET",
+		"RATE_LIMITS",
+		"TRUSTED_PROXY_HOPS",
+		"CHALLENGE_MODE",
+		"POW_DIFFICULTY",
+		"CAPTCHA_VERIFY_URL",
+		"CAPTCHA_SECRET",
+		"SUPER_ADMINS",
+		"FREE_MAIL_DOMAINS",
+		"SMTP_HOST",
+		"SMTP_PORT",
+		"MAIL_FROM",
 	}
 	for _, key := range envVars {
 		originalEnv[key] = os.Getenv(key)
@@ -50,6 +71,7 @@ func TestLoadConfig(t *testing.T) {
 				os.Setenv("FIREBASE_SERVICE_ACCOUNT", "base64:"+base64Data)
 				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
 				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
 			},
 			expectedError: false,
 		},
@@ -58,6 +80,7 @@ func TestLoadConfig(t *testing.T) {
 			setupEnv: func() {
 				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
 				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
 				// Simulate Cloud Run environment
 				os.Setenv("K_SERVICE", "test-service")
 			},
@@ -71,6 +94,7 @@ func TestLoadConfig(t *testing.T) {
 				base64Data := base64.StdEncoding.EncodeToString(jsonData)
 				os.Setenv("FIREBASE_SERVICE_ACCOUNT", "base64:"+base64Data)
 				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
 			},
 			expectedError: true,
 		},
@@ -85,6 +109,25 @@ func TestLoadConfig(t *testing.T) {
 			},
 			expectedError: true,
 		},
+		{
+			name: "missing ADMIN_TOKEN_SECRET",
+			setupEnv: func() {
+				os.Setenv("K_SERVICE", "test-service")
+				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
+				os.Setenv("ADMIN_PASSWORD", "test-password")
+			},
+			expectedError: true,
+		},
+		{
+			name: "ADMIN_TOKEN_SECRET same as ADMIN_PASSWORD",
+			setupEnv: func() {
+				os.Setenv("K_SERVICE", "test-service")
+				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
+				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-password")
+			},
+			expectedError: true,
+		},
 		{
 			name: "default PORT",
 			setupEnv: func() {
@@ -94,6 +137,7 @@ func TestLoadConfig(t *testing.T) {
 				os.Setenv("FIREBASE_SERVICE_ACCOUNT", "base64:"+base64Data)
 				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
 				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
 				os.Unsetenv("PORT")
 			},
 			expectedError: false,
@@ -107,10 +151,220 @@ func TestLoadConfig(t *testing.T) {
 				os.Setenv("FIREBASE_SERVICE_ACCOUNT", "base64:"+base64Data)
 				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
 				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
 				os.Unsetenv("CORS_ORIGIN")
 			},
 			expectedError: false,
 		},
+		{
+			name: "valid OIDC config",
+			setupEnv: func() {
+				os.Setenv("K_SERVICE", "test-service")
+				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
+				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
+				os.Setenv("OIDC_ISSUER", "https://accounts.example.com")
+				os.Setenv("OIDC_CLIENT_ID", "client-id")
+				os.Setenv("OIDC_REDIRECT_URL", "https://workshop.example.com/api/admin/sso/callback")
+				os.Setenv("OIDC_ALLOWED_DOMAINS", "example.com, example.org")
+				os.Setenv("OIDC_ROLE_MAPPING", "organizers=admin,volunteers=viewer")
+				os.Setenv("OIDC_TOKEN_SECRET", "sso-signing-secret")
+			},
+			expectedError: false,
+		},
+		{
+			name: "OIDC without token secret",
+			setupEnv: func() {
+				os.Setenv("K_SERVICE", "test-service")
+				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
+				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
+				os.Setenv("OIDC_ISSUER", "https://accounts.example.com")
+				os.Setenv("OIDC_CLIENT_ID", "client-id")
+				os.Setenv("OIDC_REDIRECT_URL", "https://workshop.example.com/api/admin/sso/callback")
+				os.Setenv("OIDC_ALLOWED_DOMAINS", "example.com")
+			},
+			expectedError: true,
+		},
+		{
+			name: "OIDC token secret reusing the admin password",
+			setupEnv: func() {
+				os.Setenv("K_SERVICE", "test-service")
+				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
+				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
+				os.Setenv("OIDC_ISSUER", "https://accounts.example.com")
+				os.Setenv("OIDC_CLIENT_ID", "client-id")
+				os.Setenv("OIDC_REDIRECT_URL", "https://workshop.example.com/api/admin/sso/callback")
+				os.Setenv("OIDC_ALLOWED_DOMAINS", "example.com")
+				os.Setenv("OIDC_TOKEN_SECRET", "test-password")
+			},
+			expectedError: true,
+		},
+		{
+			name: "OIDC without allowed domains",
+			setupEnv: func() {
+				os.Setenv("K_SERVICE", "test-service")
+				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
+				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
+				os.Setenv("OIDC_ISSUER", "https://accounts.example.com")
+				os.Setenv("OIDC_CLIENT_ID", "client-id")
+				os.Setenv("OIDC_REDIRECT_URL", "https://workshop.example.com/api/admin/sso/callback")
+			},
+			expectedError: true,
+		},
+		{
+			name: "OIDC with unknown role",
+			setupEnv: func() {
+				os.Setenv("K_SERVICE", "test-service")
+				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
+				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
+				os.Setenv("OIDC_ISSUER", "https://accounts.example.com")
+				os.Setenv("OIDC_CLIENT_ID", "client-id")
+				os.Setenv("OIDC_REDIRECT_URL", "https://workshop.example.com/api/admin/sso/callback")
+				os.Setenv("OIDC_ALLOWED_DOMAINS", "example.com")
+				os.Setenv("OIDC_ROLE_MAPPING", "organizers=superuser")
+			},
+			expectedError: true,
+		},
+		{
+			name: "custom rate limits",
+			setupEnv: func() {
+				os.Setenv("K_SERVICE", "test-service")
+				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
+				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
+				os.Setenv("RATE_LIMITS", "register=3/30s")
+			},
+			expectedError: false,
+		},
+		{
+			name: "invalid rate limit",
+			setupEnv: func() {
+				os.Setenv("K_SERVICE", "test-service")
+				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
+				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
+				os.Setenv("RATE_LIMITS", "register=fast")
+			},
+			expectedError: true,
+		},
+		{
+			name: "unknown rate limit route",
+			setupEnv: func() {
+				os.Setenv("K_SERVICE", "test-service")
+				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
+				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
+				os.Setenv("RATE_LIMITS", "regster=3/30s")
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid trusted proxy hops",
+			setupEnv: func() {
+				os.Setenv("K_SERVICE", "test-service")
+				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
+				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
+				os.Setenv("TRUSTED_PROXY_HOPS", "-1")
+			},
+			expectedError: true,
+		},
+		{
+			name: "proof-of-work challenge",
+			setupEnv: func() {
+				os.Setenv("K_SERVICE", "test-service")
+				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
+				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
+				os.Setenv("CHALLENGE_MODE", "pow")
+			},
+			expectedError: false,
+		},
+		{
+			name: "captcha challenge without secret",
+			setupEnv: func() {
+				os.Setenv("K_SERVICE", "test-service")
+				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
+				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
+				os.Setenv("CHALLENGE_MODE", "captcha")
+				os.Setenv("CAPTCHA_VERIFY_URL", "https://challenges.cloudflare.com/turnstile/v0/siteverify")
+			},
+			expectedError: true,
+		},
+		{
+			name: "unknown challenge mode",
+			setupEnv: func() {
+				os.Setenv("K_SERVICE", "test-service")
+				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
+				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
+				os.Setenv("CHALLENGE_MODE", "quiz")
+			},
+			expectedError: true,
+		},
+		{
+			name: "super admins",
+			setupEnv: func() {
+				os.Setenv("K_SERVICE", "test-service")
+				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
+				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
+				os.Setenv("SUPER_ADMINS", "admin, ops@example.com")
+			},
+			expectedError: false,
+		},
+		{
+			name: "free mail domains",
+			setupEnv: func() {
+				os.Setenv("K_SERVICE", "test-service")
+				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
+				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
+				os.Setenv("FREE_MAIL_DOMAINS", "Gmail.com, example-mail.org")
+			},
+			expectedError: false,
+		},
+		{
+			name: "SMTP",
+			setupEnv: func() {
+				os.Setenv("K_SERVICE", "test-service")
+				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
+				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
+				os.Setenv("SMTP_HOST", "smtp.example.com")
+				os.Setenv("MAIL_FROM", "AI Workshop <noreply@example.com>")
+			},
+			expectedError: false,
+		},
+		{
+			name: "SMTP without sender",
+			setupEnv: func() {
+				os.Setenv("K_SERVICE", "test-service")
+				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
+				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
+				os.Setenv("SMTP_HOST", "smtp.example.com")
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid SMTP port",
+			setupEnv: func() {
+				os.Setenv("K_SERVICE", "test-service")
+				os.Setenv("SUBSCOLLECTION_ID", "test-collection")
+				os.Setenv("ADMIN_PASSWORD", "test-password")
+				os.Setenv("ADMIN_TOKEN_SECRET", "test-token-secret")
+				os.Setenv("SMTP_HOST", "smtp.example.com")
+				os.Setenv("SMTP_PORT", "smtp")
+				os.Setenv("MAIL_FROM", "noreply@example.com")
+			},
+			expectedError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -131,12 +385,44 @@ func TestLoadConfig(t *testing.T) {
 				if cfg != nil {
 					assert.NotEmpty(t, cfg.SubcollectionID)
 					assert.NotEmpty(t, cfg.AdminPassword)
+					assert.NotEqual(t, cfg.AdminPassword, cfg.AdminTokenSecret)
 					if os.Getenv("PORT") == "" {
 						assert.Equal(t, "8080", cfg.Port)
 					}
 					if os.Getenv("CORS_ORIGIN") == "" {
 						assert.Equal(t, "http://localhost:5173", cfg.CORSOrigin)
 					}
+					if os.Getenv("ADMIN_MFA_ENABLED") == "" {
+						assert.True(t, cfg.MFAEnabled)
+					}
+					if os.Getenv("SUPER_ADMINS") != "" {
+						assert.Equal(t, []string{"admin", "ops@example.com"}, cfg.SuperAdmins)
+					} else {
+						assert.Equal(t, []string{"admin"}, cfg.SuperAdmins)
+					}
+					if os.Getenv("FREE_MAIL_DOMAINS") != "" {
+						assert.Equal(t, []string{"gmail.com", "example-mail.org"}, cfg.FreeMailDomains)
+					} else {
+						assert.Contains(t, cfg.FreeMailDomains, "gmail.com")
+					}
+					if os.Getenv("SMTP_HOST") != "" {
+						assert.Equal(t, 587, cfg.SMTPPort)
+					}
+					if os.Getenv("CHALLENGE_MODE") == "pow" {
+						assert.Equal(t, 18, cfg.PoWDifficulty)
+					}
+					if os.Getenv("RATE_LIMITS") != "" {
+						assert.Equal(t, RateLimit{Requests: 3, Period: 30 * time.Second}, cfg.RateLimits["register"])
+						assert.Equal(t, 60, cfg.RateLimits["count"].Requests)
+					}
+					if os.Getenv("K_SERVICE") != "" && os.Getenv("TRUSTED_PROXY_HOPS") == "" {
+						assert.Equal(t, 1, cfg.TrustedProxyHops)
+					}
+					if os.Getenv("OIDC_ISSUER") != "" {
+						assert.Equal(t, []string{"example.com", "example.org"}, cfg.OIDCAllowedDomains)
+						assert.Equal(t, "viewer", cfg.OIDCRoleMapping["volunteers"])
+						assert.Equal(t, "groups", cfg.OIDCRoleClaim)
+					}
 				}
 			}
 		})
@@ -80,3 +80,26 @@ func (f *FirestoreClient) Collection(name string) *firestore.CollectionRef {
 	return docRef.Collection(name)
 }
 
+// RunTransaction runs f atomically, retrying it on contention
+func (f *FirestoreClient) RunTransaction(fn func(ctx context.Context, tx *firestore.Transaction) error) error {
+	return f.client.RunTransaction(f.ctx, fn)
+}
+
+// ForWorkshop returns a client sharing this connection whose collections live under
+// another workshop document
+func (f *FirestoreClient) ForWorkshop(id string) DatabaseInterface {
+	cfg := *f.cfg
+	cfg.SubcollectionID = id
+	return &FirestoreClient{client: f.client, ctx: f.ctx, cfg: &cfg}
+}
+
+// Workshops returns the top-level collection whose documents hold each workshop's data
+func (f *FirestoreClient) Workshops() *firestore.CollectionRef {
+	return f.client.Collection("workshops")
+}
+
+// WrapClient serves a workshop's collections from an existing client, such as one
+// connected to the in-memory server in firestoretest
+func WrapClient(client *firestore.Client, cfg *config.Config) *FirestoreClient {
+	return &FirestoreClient{client: client, ctx: context.Background(), cfg: cfg}
+}
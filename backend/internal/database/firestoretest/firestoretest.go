@@ -0,0 +1,648 @@
+// Package firestoretest runs an in-memory Firestore server for tests. It implements the
+// parts of the API the handlers use: document reads and writes with preconditions and
+// merges, transactions, and queries with filters, ordering, cursors, limits and counts.
+// Transactions are not isolated; each request runs under one lock.
+package firestoretest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"appdirect-workshop-backend/internal/config"
+	"appdirect-workshop-backend/internal/database"
+
+	"cloud.google.com/go/firestore"
+	pb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const projectID = "test-project"
+
+// NewDatabase starts a server and returns a client for the workshop's collections. The
+// server stops when the test ends.
+func NewDatabase(t testing.TB, workshopID string) database.DatabaseInterface {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	pb.RegisterFirestoreServer(server, &fake{docs: map[string]*pb.Document{}})
+	go server.Serve(listener)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("firestoretest: dial: %v", err)
+	}
+	client, err := firestore.NewClient(context.Background(), projectID, option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatalf("firestoretest: client: %v", err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		server.Stop()
+	})
+
+	return database.WrapClient(client, &config.Config{SubcollectionID: workshopID})
+}
+
+type fake struct {
+	pb.UnimplementedFirestoreServer
+
+	mu    sync.Mutex
+	docs  map[string]*pb.Document
+	clock time.Time
+	txn   int
+}
+
+// now returns strictly increasing times so update-time preconditions can tell writes apart
+func (f *fake) now() *timestamppb.Timestamp {
+	t := time.Now().UTC()
+	if !t.After(f.clock) {
+		t = f.clock.Add(time.Microsecond)
+	}
+	f.clock = t
+	return timestamppb.New(t)
+}
+
+func (f *fake) BeginTransaction(ctx context.Context, req *pb.BeginTransactionRequest) (*pb.BeginTransactionResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.txn++
+	return &pb.BeginTransactionResponse{Transaction: []byte(fmt.Sprint(f.txn))}, nil
+}
+
+func (f *fake) Rollback(ctx context.Context, req *pb.RollbackRequest) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, nil
+}
+
+func (f *fake) BatchGetDocuments(req *pb.BatchGetDocumentsRequest, stream pb.Firestore_BatchGetDocumentsServer) error {
+	f.mu.Lock()
+	readTime := f.now()
+	var responses []*pb.BatchGetDocumentsResponse
+	for _, name := range req.Documents {
+		resp := &pb.BatchGetDocumentsResponse{ReadTime: readTime}
+		if doc, ok := f.docs[name]; ok {
+			resp.Result = &pb.BatchGetDocumentsResponse_Found{Found: proto.Clone(doc).(*pb.Document)}
+		} else {
+			resp.Result = &pb.BatchGetDocumentsResponse_Missing{Missing: name}
+		}
+		responses = append(responses, resp)
+	}
+	f.mu.Unlock()
+
+	for _, resp := range responses {
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fake) Commit(ctx context.Context, req *pb.CommitRequest) (*pb.CommitResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Apply to a copy so a failed precondition leaves nothing half written
+	docs := make(map[string]*pb.Document, len(f.docs))
+	for name, doc := range f.docs {
+		docs[name] = doc
+	}
+	commitTime := f.now()
+	results := make([]*pb.WriteResult, 0, len(req.Writes))
+	for _, w := range req.Writes {
+		if err := applyWrite(docs, w, commitTime); err != nil {
+			return nil, err
+		}
+		results = append(results, &pb.WriteResult{UpdateTime: commitTime})
+	}
+	f.docs = docs
+	return &pb.CommitResponse{WriteResults: results, CommitTime: commitTime}, nil
+}
+
+func applyWrite(docs map[string]*pb.Document, w *pb.Write, now *timestamppb.Timestamp) error {
+	var name string
+	switch op := w.Operation.(type) {
+	case *pb.Write_Update:
+		name = op.Update.Name
+	case *pb.Write_Delete:
+		name = op.Delete
+	case *pb.Write_Transform:
+		name = op.Transform.Document
+	}
+	current, exists := docs[name]
+
+	if pre := w.CurrentDocument; pre != nil {
+		switch c := pre.ConditionType.(type) {
+		case *pb.Precondition_Exists:
+			if c.Exists && !exists {
+				return status.Errorf(codes.NotFound, "no entity to update: %s", name)
+			}
+			if !c.Exists && exists {
+				return status.Errorf(codes.AlreadyExists, "document already exists: %s", name)
+			}
+		case *pb.Precondition_UpdateTime:
+			if !exists || !proto.Equal(current.UpdateTime, c.UpdateTime) {
+				return status.Errorf(codes.FailedPrecondition, "document was updated: %s", name)
+			}
+		}
+	}
+
+	if _, ok := w.Operation.(*pb.Write_Delete); ok {
+		delete(docs, name)
+		return nil
+	}
+
+	doc := &pb.Document{Name: name, Fields: map[string]*pb.Value{}, CreateTime: now}
+	if exists {
+		doc = proto.Clone(current).(*pb.Document)
+	}
+	if op, ok := w.Operation.(*pb.Write_Update); ok {
+		if w.UpdateMask == nil {
+			doc.Fields = op.Update.Fields
+		} else {
+			for _, path := range w.UpdateMask.FieldPaths {
+				setField(doc.Fields, splitPath(path), lookup(op.Update.Fields, splitPath(path)))
+			}
+		}
+	}
+	transforms := w.UpdateTransforms
+	if op, ok := w.Operation.(*pb.Write_Transform); ok {
+		transforms = op.Transform.FieldTransforms
+	}
+	for _, t := range transforms {
+		path := splitPath(t.FieldPath)
+		switch tt := t.TransformType.(type) {
+		case *pb.DocumentTransform_FieldTransform_Increment:
+			setField(doc.Fields, path, increment(lookup(doc.Fields, path), tt.Increment))
+		case *pb.DocumentTransform_FieldTransform_SetToServerValue:
+			setField(doc.Fields, path, &pb.Value{ValueType: &pb.Value_TimestampValue{TimestampValue: now}})
+		default:
+			return status.Errorf(codes.Unimplemented, "firestoretest: transform %T", tt)
+		}
+	}
+	if doc.Fields == nil {
+		doc.Fields = map[string]*pb.Value{}
+	}
+	doc.UpdateTime = now
+	docs[name] = doc
+	return nil
+}
+
+func increment(current, by *pb.Value) *pb.Value {
+	ci, cIsInt := current.GetValueType().(*pb.Value_IntegerValue)
+	bi, bIsInt := by.ValueType.(*pb.Value_IntegerValue)
+	if cIsInt && bIsInt {
+		return &pb.Value{ValueType: &pb.Value_IntegerValue{IntegerValue: ci.IntegerValue + bi.IntegerValue}}
+	}
+	if current == nil {
+		return by
+	}
+	c, _ := number(current)
+	b, _ := number(by)
+	return &pb.Value{ValueType: &pb.Value_DoubleValue{DoubleValue: c + b}}
+}
+
+func (f *fake) ListDocuments(ctx context.Context, req *pb.ListDocumentsRequest) (*pb.ListDocumentsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &pb.ListDocumentsResponse{}
+	for _, doc := range f.collection(req.Parent, req.CollectionId, false) {
+		resp.Documents = append(resp.Documents, &pb.Document{Name: doc.Name, CreateTime: doc.CreateTime, UpdateTime: doc.UpdateTime})
+	}
+	return resp, nil
+}
+
+func (f *fake) RunQuery(req *pb.RunQueryRequest, stream pb.Firestore_RunQueryServer) error {
+	f.mu.Lock()
+	docs, err := f.query(req.Parent, req.GetStructuredQuery())
+	readTime := f.now()
+	f.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if len(docs) == 0 {
+		return stream.Send(&pb.RunQueryResponse{ReadTime: readTime})
+	}
+	for _, doc := range docs {
+		if err := stream.Send(&pb.RunQueryResponse{Document: doc, ReadTime: readTime}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fake) RunAggregationQuery(req *pb.RunAggregationQueryRequest, stream pb.Firestore_RunAggregationQueryServer) error {
+	agg := req.GetStructuredAggregationQuery()
+	f.mu.Lock()
+	docs, err := f.query(req.Parent, agg.GetStructuredQuery())
+	readTime := f.now()
+	f.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	fields := map[string]*pb.Value{}
+	for _, a := range agg.Aggregations {
+		if a.GetCount() == nil {
+			return status.Error(codes.Unimplemented, "firestoretest: only count aggregations are supported")
+		}
+		fields[a.Alias] = &pb.Value{ValueType: &pb.Value_IntegerValue{IntegerValue: int64(len(docs))}}
+	}
+	return stream.Send(&pb.RunAggregationQueryResponse{
+		Result:   &pb.AggregationResult{AggregateFields: fields},
+		ReadTime: readTime,
+	})
+}
+
+// collection returns the documents directly in parent's collection (or, for
+// allDescendants, any collection with that ID below parent), sorted by name
+func (f *fake) collection(parent, collectionID string, allDescendants bool) []*pb.Document {
+	var docs []*pb.Document
+	for name, doc := range f.docs {
+		rest, ok := strings.CutPrefix(name, parent+"/")
+		if !ok {
+			continue
+		}
+		segments := strings.Split(rest, "/")
+		n := len(segments)
+		if segments[n-2] != collectionID || (!allDescendants && n != 2) {
+			continue
+		}
+		docs = append(docs, doc)
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Name < docs[j].Name })
+	return docs
+}
+
+func (f *fake) query(parent string, q *pb.StructuredQuery) ([]*pb.Document, error) {
+	if len(q.From) != 1 {
+		return nil, status.Error(codes.InvalidArgument, "firestoretest: queries need exactly one collection")
+	}
+
+	var docs []*pb.Document
+	for _, doc := range f.collection(parent, q.From[0].CollectionId, q.From[0].AllDescendants) {
+		ok, err := matches(doc, q.Where)
+		if err != nil {
+			return nil, err
+		}
+		// Like Firestore, ordering by a field leaves out documents without it
+		for _, o := range q.OrderBy {
+			if fieldValue(doc, o.Field.FieldPath) == nil {
+				ok = false
+			}
+		}
+		if ok {
+			docs = append(docs, doc)
+		}
+	}
+
+	orders := append([]*pb.StructuredQuery_Order(nil), q.OrderBy...)
+	if len(orders) == 0 || orders[len(orders)-1].Field.FieldPath != "__name__" {
+		dir := pb.StructuredQuery_ASCENDING
+		if len(orders) > 0 {
+			dir = orders[len(orders)-1].Direction
+		}
+		orders = append(orders, &pb.StructuredQuery_Order{Field: &pb.StructuredQuery_FieldReference{FieldPath: "__name__"}, Direction: dir})
+	}
+	sort.SliceStable(docs, func(i, j int) bool { return compareDocs(docs[i], docs[j], orders) < 0 })
+
+	if c := q.StartAt; c != nil {
+		for len(docs) > 0 {
+			cmp := compareCursor(docs[0], c.Values, orders)
+			if cmp > 0 || (cmp == 0 && c.Before) {
+				break
+			}
+			docs = docs[1:]
+		}
+	}
+	if c := q.EndAt; c != nil {
+		for i, doc := range docs {
+			cmp := compareCursor(doc, c.Values, orders)
+			if cmp > 0 || (cmp == 0 && c.Before) {
+				docs = docs[:i]
+				break
+			}
+		}
+	}
+
+	if q.Offset > 0 {
+		if int(q.Offset) >= len(docs) {
+			docs = nil
+		} else {
+			docs = docs[q.Offset:]
+		}
+	}
+	if q.Limit != nil && int(q.Limit.Value) < len(docs) {
+		docs = docs[:q.Limit.Value]
+	}
+
+	out := make([]*pb.Document, len(docs))
+	for i, doc := range docs {
+		out[i] = proto.Clone(doc).(*pb.Document)
+	}
+	return out, nil
+}
+
+func compareDocs(a, b *pb.Document, orders []*pb.StructuredQuery_Order) int {
+	for _, o := range orders {
+		cmp := compareValues(fieldValue(a, o.Field.FieldPath), fieldValue(b, o.Field.FieldPath))
+		if o.Direction == pb.StructuredQuery_DESCENDING {
+			cmp = -cmp
+		}
+		if cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+// compareCursor compares a document with cursor values for the leading orders
+func compareCursor(doc *pb.Document, values []*pb.Value, orders []*pb.StructuredQuery_Order) int {
+	for i, v := range values {
+		if i >= len(orders) {
+			break
+		}
+		cmp := compareValues(fieldValue(doc, orders[i].Field.FieldPath), v)
+		if orders[i].Direction == pb.StructuredQuery_DESCENDING {
+			cmp = -cmp
+		}
+		if cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+func matches(doc *pb.Document, filter *pb.StructuredQuery_Filter) (bool, error) {
+	if filter == nil {
+		return true, nil
+	}
+	switch ft := filter.FilterType.(type) {
+	case *pb.StructuredQuery_Filter_CompositeFilter:
+		and := ft.CompositeFilter.Op != pb.StructuredQuery_CompositeFilter_OR
+		for _, sub := range ft.CompositeFilter.Filters {
+			ok, err := matches(doc, sub)
+			if err != nil {
+				return false, err
+			}
+			if ok != and {
+				return ok, nil
+			}
+		}
+		return and, nil
+	case *pb.StructuredQuery_Filter_UnaryFilter:
+		v := fieldValue(doc, ft.UnaryFilter.GetField().FieldPath)
+		_, isNull := v.GetValueType().(*pb.Value_NullValue)
+		switch ft.UnaryFilter.Op {
+		case pb.StructuredQuery_UnaryFilter_IS_NULL:
+			return isNull, nil
+		case pb.StructuredQuery_UnaryFilter_IS_NOT_NULL:
+			return v != nil && !isNull, nil
+		}
+		return false, status.Errorf(codes.Unimplemented, "firestoretest: unary filter %v", ft.UnaryFilter.Op)
+	case *pb.StructuredQuery_Filter_FieldFilter:
+		return matchField(doc, ft.FieldFilter)
+	}
+	return false, status.Errorf(codes.Unimplemented, "firestoretest: filter %T", filter.FilterType)
+}
+
+func matchField(doc *pb.Document, f *pb.StructuredQuery_FieldFilter) (bool, error) {
+	v := fieldValue(doc, f.Field.FieldPath)
+	if v == nil {
+		return false, nil
+	}
+	cmp := compareValues(v, f.Value)
+	comparable := typeOrder(v) == typeOrder(f.Value)
+	switch f.Op {
+	case pb.StructuredQuery_FieldFilter_EQUAL:
+		return cmp == 0, nil
+	case pb.StructuredQuery_FieldFilter_NOT_EQUAL:
+		return cmp != 0, nil
+	case pb.StructuredQuery_FieldFilter_LESS_THAN:
+		return comparable && cmp < 0, nil
+	case pb.StructuredQuery_FieldFilter_LESS_THAN_OR_EQUAL:
+		return comparable && cmp <= 0, nil
+	case pb.StructuredQuery_FieldFilter_GREATER_THAN:
+		return comparable && cmp > 0, nil
+	case pb.StructuredQuery_FieldFilter_GREATER_THAN_OR_EQUAL:
+		return comparable && cmp >= 0, nil
+	case pb.StructuredQuery_FieldFilter_ARRAY_CONTAINS:
+		return contains(v.GetArrayValue().GetValues(), f.Value), nil
+	case pb.StructuredQuery_FieldFilter_IN:
+		return contains(f.Value.GetArrayValue().GetValues(), v), nil
+	case pb.StructuredQuery_FieldFilter_NOT_IN:
+		return !contains(f.Value.GetArrayValue().GetValues(), v), nil
+	case pb.StructuredQuery_FieldFilter_ARRAY_CONTAINS_ANY:
+		for _, want := range f.Value.GetArrayValue().GetValues() {
+			if contains(v.GetArrayValue().GetValues(), want) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, status.Errorf(codes.Unimplemented, "firestoretest: operator %v", f.Op)
+}
+
+func contains(values []*pb.Value, want *pb.Value) bool {
+	for _, v := range values {
+		if compareValues(v, want) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldValue(doc *pb.Document, path string) *pb.Value {
+	if path == "__name__" {
+		return &pb.Value{ValueType: &pb.Value_ReferenceValue{ReferenceValue: doc.Name}}
+	}
+	return lookup(doc.Fields, splitPath(path))
+}
+
+func lookup(fields map[string]*pb.Value, path []string) *pb.Value {
+	v := fields[path[0]]
+	for _, key := range path[1:] {
+		m := v.GetMapValue()
+		if m == nil {
+			return nil
+		}
+		v = m.Fields[key]
+	}
+	return v
+}
+
+// setField sets the value at path, or removes it when value is nil
+func setField(fields map[string]*pb.Value, path []string, value *pb.Value) {
+	for _, key := range path[:len(path)-1] {
+		m := fields[key].GetMapValue()
+		if m == nil {
+			if value == nil {
+				return
+			}
+			m = &pb.MapValue{Fields: map[string]*pb.Value{}}
+		} else {
+			m = proto.Clone(m).(*pb.MapValue)
+		}
+		fields[key] = &pb.Value{ValueType: &pb.Value_MapValue{MapValue: m}}
+		if m.Fields == nil {
+			m.Fields = map[string]*pb.Value{}
+		}
+		fields = m.Fields
+	}
+	last := path[len(path)-1]
+	if value == nil {
+		delete(fields, last)
+	} else {
+		fields[last] = value
+	}
+}
+
+// splitPath splits a field path, unquoting backquoted segments
+func splitPath(path string) []string {
+	var parts []string
+	var b strings.Builder
+	quoted := false
+	for i := 0; i < len(path); i++ {
+		switch ch := path[i]; {
+		case ch == '`':
+			quoted = !quoted
+		case ch == '\\' && quoted && i+1 < len(path):
+			i++
+			b.WriteByte(path[i])
+		case ch == '.' && !quoted:
+			parts = append(parts, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return append(parts, b.String())
+}
+
+// typeOrder ranks value types the way Firestore orders mixed types
+func typeOrder(v *pb.Value) int {
+	switch v.GetValueType().(type) {
+	case nil, *pb.Value_NullValue:
+		return 0
+	case *pb.Value_BooleanValue:
+		return 1
+	case *pb.Value_IntegerValue, *pb.Value_DoubleValue:
+		return 2
+	case *pb.Value_TimestampValue:
+		return 3
+	case *pb.Value_StringValue:
+		return 4
+	case *pb.Value_BytesValue:
+		return 5
+	case *pb.Value_ReferenceValue:
+		return 6
+	case *pb.Value_GeoPointValue:
+		return 7
+	case *pb.Value_ArrayValue:
+		return 8
+	default:
+		return 9
+	}
+}
+
+func number(v *pb.Value) (float64, bool) {
+	switch n := v.GetValueType().(type) {
+	case *pb.Value_IntegerValue:
+		return float64(n.IntegerValue), true
+	case *pb.Value_DoubleValue:
+		return n.DoubleValue, true
+	}
+	return 0, false
+}
+
+func compareValues(a, b *pb.Value) int {
+	if ta, tb := typeOrder(a), typeOrder(b); ta != tb {
+		return ta - tb
+	}
+	switch av := a.GetValueType().(type) {
+	case *pb.Value_BooleanValue:
+		return compareBools(av.BooleanValue, b.GetBooleanValue())
+	case *pb.Value_IntegerValue, *pb.Value_DoubleValue:
+		x, _ := number(a)
+		y, _ := number(b)
+		return compareOrdered(x, y)
+	case *pb.Value_TimestampValue:
+		return av.TimestampValue.AsTime().Compare(b.GetTimestampValue().AsTime())
+	case *pb.Value_StringValue:
+		return strings.Compare(av.StringValue, b.GetStringValue())
+	case *pb.Value_BytesValue:
+		return strings.Compare(string(av.BytesValue), string(b.GetBytesValue()))
+	case *pb.Value_ReferenceValue:
+		return strings.Compare(av.ReferenceValue, b.GetReferenceValue())
+	case *pb.Value_GeoPointValue:
+		if cmp := compareOrdered(av.GeoPointValue.Latitude, b.GetGeoPointValue().Latitude); cmp != 0 {
+			return cmp
+		}
+		return compareOrdered(av.GeoPointValue.Longitude, b.GetGeoPointValue().Longitude)
+	case *pb.Value_ArrayValue:
+		x, y := av.ArrayValue.GetValues(), b.GetArrayValue().GetValues()
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if cmp := compareValues(x[i], y[i]); cmp != 0 {
+				return cmp
+			}
+		}
+		return len(x) - len(y)
+	case *pb.Value_MapValue:
+		x, y := av.MapValue.GetFields(), b.GetMapValue().GetFields()
+		keys := map[string]bool{}
+		for k := range x {
+			keys[k] = true
+		}
+		for k := range y {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			if cmp := compareValues(x[k], y[k]); cmp != 0 {
+				return cmp
+			}
+		}
+	}
+	return 0
+}
+
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case !a:
+		return -1
+	default:
+		return 1
+	}
+}
+
+func compareOrdered(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,126 @@
+package firestoretest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	pb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type item struct {
+	Name      string    `firestore:"name"`
+	Count     int       `firestore:"count"`
+	Tags      []string  `firestore:"tags,omitempty"`
+	CreatedAt time.Time `firestore:"createdAt,omitempty"`
+}
+
+func names(t *testing.T, iter *firestore.DocumentIterator) []string {
+	t.Helper()
+	defer iter.Stop()
+	var out []string
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return out
+		}
+		require.NoError(t, err)
+		out = append(out, doc.Ref.ID)
+	}
+}
+
+func TestDocuments(t *testing.T) {
+	db := NewDatabase(t, "w1")
+	ctx := context.Background()
+	ref := db.Collection("items").Doc("a")
+
+	_, err := ref.Get(ctx)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = ref.Create(ctx, item{Name: "A", Count: 1})
+	require.NoError(t, err)
+	_, err = ref.Create(ctx, item{Name: "A"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	_, err = ref.Update(ctx, []firestore.Update{{Path: "count", Value: firestore.Increment(2)}})
+	require.NoError(t, err)
+	_, err = ref.Set(ctx, map[string]interface{}{"tags": []string{"x"}}, firestore.Merge([]string{"tags"}))
+	require.NoError(t, err)
+
+	doc, err := ref.Get(ctx)
+	require.NoError(t, err)
+	var got item
+	require.NoError(t, doc.DataTo(&got))
+	assert.Equal(t, item{Name: "A", Count: 3, Tags: []string{"x"}}, got)
+
+	_, err = ref.Update(ctx, []firestore.Update{{Path: "count", Value: 0}}, firestore.LastUpdateTime(doc.UpdateTime.Add(-time.Second)))
+	assert.Error(t, err)
+
+	_, err = db.Collection("items").Doc("missing").Update(ctx, []firestore.Update{{Path: "count", Value: 1}})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Other workshops don't see the document
+	_, err = db.ForWorkshop("w2").Collection("items").Doc("a").Get(ctx)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestQueries(t *testing.T) {
+	db := NewDatabase(t, "w1")
+	ctx := context.Background()
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	items := db.Collection("items")
+	for id, it := range map[string]item{
+		"a": {Name: "A", Count: 3, CreatedAt: base.Add(2 * time.Hour), Tags: []string{"x"}},
+		"b": {Name: "B", Count: 1, CreatedAt: base},
+		"c": {Name: "C", Count: 2, CreatedAt: base.Add(time.Hour), Tags: []string{"x", "y"}},
+		"d": {Name: "D", Count: 2},
+	} {
+		_, err := items.Doc(id).Set(ctx, it)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"a", "b", "c", "d"}, names(t, items.Documents(ctx)))
+	// Ordering by a field leaves out documents without it
+	assert.Equal(t, []string{"b", "c", "a"}, names(t, items.OrderBy("createdAt", firestore.Asc).Documents(ctx)))
+	assert.Equal(t, []string{"a", "d"}, names(t, items.OrderBy("count", firestore.Desc).Limit(2).Documents(ctx)))
+	assert.Equal(t, []string{"c", "d"}, names(t, items.Where("count", "==", 2).Documents(ctx)))
+	assert.Equal(t, []string{"a", "c"}, names(t, items.Where("tags", "array-contains", "x").Documents(ctx)))
+	assert.Equal(t, []string{"c", "a"}, names(t, items.Where("createdAt", ">=", base.Add(time.Hour)).OrderBy("createdAt", firestore.Asc).Documents(ctx)))
+	assert.Equal(t, []string{"c", "a"}, names(t, items.OrderBy("createdAt", firestore.Asc).StartAfter(base).Documents(ctx)))
+
+	twos := items.Where("count", "==", 2)
+	result, err := twos.NewAggregationQuery().WithCount("n").Get(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, result["n"].(*pb.Value).GetIntegerValue())
+}
+
+func TestTransactions(t *testing.T) {
+	db := NewDatabase(t, "w1")
+	ctx := context.Background()
+	ref := db.Collection("items").Doc("a")
+
+	err := db.RunTransaction(func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := tx.Get(ref); status.Code(err) != codes.NotFound {
+			return err
+		}
+		return tx.Create(ref, item{Name: "A"})
+	})
+	require.NoError(t, err)
+
+	// A failed write leaves the transaction's other writes unapplied
+	err = db.RunTransaction(func(ctx context.Context, tx *firestore.Transaction) error {
+		if err := tx.Set(db.Collection("items").Doc("b"), item{Name: "B"}); err != nil {
+			return err
+		}
+		return tx.Create(ref, item{Name: "A again"})
+	})
+	assert.Error(t, err)
+	_, err = db.Collection("items").Doc("b").Get(ctx)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
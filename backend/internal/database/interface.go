@@ -11,6 +11,9 @@ import (
 type DatabaseInterface interface {
 	Context() context.Context
 	Collection(name string) *firestore.CollectionRef
+	RunTransaction(f func(ctx context.Context, tx *firestore.Transaction) error) error
+	ForWorkshop(id string) DatabaseInterface
+	Workshops() *firestore.CollectionRef
 	Close() error
 }
 
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"errors"
 
 	"cloud.google.com/go/firestore"
 )
@@ -11,6 +12,10 @@ type MockFirestoreClient struct {
 	ContextFunc    func() context.Context
 	CollectionFunc func(name string) *firestore.CollectionRef
 	CloseFunc      func() error
+
+	RunTransactionFunc func(f func(ctx context.Context, tx *firestore.Transaction) error) error
+	ForWorkshopFunc    func(id string) DatabaseInterface
+	WorkshopsFunc      func() *firestore.CollectionRef
 }
 
 func (m *MockFirestoreClient) Context() context.Context {
@@ -27,6 +32,27 @@ func (m *MockFirestoreClient) Collection(name string) *firestore.CollectionRef {
 	return nil
 }
 
+func (m *MockFirestoreClient) RunTransaction(f func(ctx context.Context, tx *firestore.Transaction) error) error {
+	if m.RunTransactionFunc != nil {
+		return m.RunTransactionFunc(f)
+	}
+	return errors.New("mock: RunTransaction is not configured")
+}
+
+func (m *MockFirestoreClient) ForWorkshop(id string) DatabaseInterface {
+	if m.ForWorkshopFunc != nil {
+		return m.ForWorkshopFunc(id)
+	}
+	return m
+}
+
+func (m *MockFirestoreClient) Workshops() *firestore.CollectionRef {
+	if m.WorkshopsFunc != nil {
+		return m.WorkshopsFunc()
+	}
+	return nil
+}
+
 func (m *MockFirestoreClient) Close() error {
 	if m.CloseFunc != nil {
 		return m.CloseFunc()
@@ -0,0 +1,96 @@
+// Package designation maps the free-text designations people register with onto the
+// canonical designations of an admin-managed taxonomy.
+package designation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"appdirect-workshop-backend/internal/models"
+)
+
+// Unmapped labels the analytics bucket for designations no taxonomy entry matches
+const Unmapped = "Unmapped"
+
+// maxPatternLength bounds admin-supplied regular expressions
+const maxPatternLength = 200
+
+// Normalizer matches designations against a taxonomy. Exact matches on a name or synonym
+// win over patterns, and patterns are tried in taxonomy order.
+type Normalizer struct {
+	exact    map[string]string
+	patterns []pattern
+}
+
+type pattern struct {
+	re        *regexp.Regexp
+	canonical string
+}
+
+// New builds a Normalizer, failing on invalid patterns or on a name or synonym that
+// belongs to more than one entry
+func New(taxonomy []models.Designation) (*Normalizer, error) {
+	n := &Normalizer{exact: make(map[string]string)}
+	for _, entry := range taxonomy {
+		for _, alias := range append([]string{entry.Name}, entry.Synonyms...) {
+			key := Fold(alias)
+			if key == "" {
+				continue
+			}
+			if existing, ok := n.exact[key]; ok && existing != entry.Name {
+				return nil, fmt.Errorf("%q is used by both %q and %q", alias, existing, entry.Name)
+			}
+			n.exact[key] = entry.Name
+		}
+		for _, expr := range entry.Patterns {
+			re, err := CompilePattern(expr)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", entry.Name, err)
+			}
+			n.patterns = append(n.patterns, pattern{re: re, canonical: entry.Name})
+		}
+	}
+	return n, nil
+}
+
+// Canonical returns the canonical designation for raw, or false if nothing matches
+func (n *Normalizer) Canonical(raw string) (string, bool) {
+	key := Fold(raw)
+	if key == "" {
+		return "", false
+	}
+	if canonical, ok := n.exact[key]; ok {
+		return canonical, true
+	}
+	for _, p := range n.patterns {
+		if p.re.MatchString(key) {
+			return p.canonical, true
+		}
+	}
+	return "", false
+}
+
+// Empty reports whether the taxonomy has no entries
+func (n *Normalizer) Empty() bool {
+	return len(n.exact) == 0 && len(n.patterns) == 0
+}
+
+// Fold lowercases s and collapses runs of whitespace, so "  Software  Engineer " and
+// "software engineer" compare equal
+func Fold(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// CompilePattern compiles a case-insensitive pattern. Patterns are matched against the
+// folded designation, so they only need to handle lowercase and single spaces.
+func CompilePattern(expr string) (*regexp.Regexp, error) {
+	if len(expr) > maxPatternLength {
+		return nil, fmt.Errorf("pattern %q is longer than %d characters", expr, maxPatternLength)
+	}
+	re, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", expr, err)
+	}
+	return re, nil
+}
@@ -0,0 +1,88 @@
+package designation
+
+import (
+	"testing"
+
+	"appdirect-workshop-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonical(t *testing.T) {
+	normalizer, err := New([]models.Designation{
+		{Name: "Software Engineer", Synonyms: []string{"SDE", "Developer"}, Patterns: []string{`^(senior |sr\.? |staff )?(software|backend|frontend) (engineer|developer)$`}},
+		{Name: "Product Manager", Synonyms: []string{"PM"}},
+		{Name: "Engineering Manager", Patterns: []string{`engineer`}},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		raw       string
+		canonical string
+		mapped    bool
+	}{
+		{raw: "Software Engineer", canonical: "Software Engineer", mapped: true},
+		{raw: "  software   engineer ", canonical: "Software Engineer", mapped: true},
+		{raw: "SDE", canonical: "Software Engineer", mapped: true},
+		{raw: "sde", canonical: "Software Engineer", mapped: true},
+		{raw: "Sr. Backend Developer", canonical: "Software Engineer", mapped: true},
+		{raw: "pm", canonical: "Product Manager", mapped: true},
+		// Patterns are tried in taxonomy order after exact matches
+		{raw: "Data Engineer", canonical: "Engineering Manager", mapped: true},
+		{raw: "Student", mapped: false},
+		{raw: "   ", mapped: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			canonical, ok := normalizer.Canonical(tt.raw)
+			assert.Equal(t, tt.mapped, ok)
+			assert.Equal(t, tt.canonical, canonical)
+		})
+	}
+}
+
+func TestNewRejectsConflictsAndBadPatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		taxonomy []models.Designation
+	}{
+		{
+			name: "synonym shared by two entries",
+			taxonomy: []models.Designation{
+				{Name: "Software Engineer", Synonyms: []string{"Engineer"}},
+				{Name: "Data Engineer", Synonyms: []string{"engineer"}},
+			},
+		},
+		{
+			name: "synonym equal to another name",
+			taxonomy: []models.Designation{
+				{Name: "Designer"},
+				{Name: "UX Designer", Synonyms: []string{"designer"}},
+			},
+		},
+		{name: "invalid regex", taxonomy: []models.Designation{{Name: "Designer", Patterns: []string{"(ux"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.taxonomy)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	empty, err := New(nil)
+	require.NoError(t, err)
+	assert.True(t, empty.Empty())
+
+	_, ok := empty.Canonical("Software Engineer")
+	assert.False(t, ok)
+}
+
+func TestFold(t *testing.T) {
+	assert.Equal(t, "software engineer", Fold("  Software \t Engineer\n"))
+	assert.Equal(t, "", Fold("   "))
+}
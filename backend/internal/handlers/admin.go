@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"appdirect-workshop-backend/internal/middleware"
 	"appdirect-workshop-backend/internal/models"
 
+	"cloud.google.com/go/firestore"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"google.golang.org/api/iterator"
@@ -18,7 +22,9 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token       string `json:"token,omitempty"`
+	MFARequired bool   `json:"mfaRequired,omitempty"`
+	MFAToken    string `json:"mfaToken,omitempty"`
 }
 
 func (h *Handlers) AdminLogin(c *gin.Context) {
@@ -33,13 +39,29 @@ func (h *Handlers) AdminLogin(c *gin.Context) {
 		return
 	}
 
-	// Generate JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"admin": true,
-		"exp":   time.Now().Add(time.Hour * 24).Unix(),
-	})
+	// The shared password proves nothing about who is logging in, so every password
+	// login is the same admin and its two-factor enrollment always applies
+	adminID := defaultAdminID
+
+	// Admins with two-factor enabled get a short-lived token for the second step instead
+	if h.cfg.MFAEnabled {
+		mfa, err := h.getAdminMFA(adminID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check two-factor status"})
+			return
+		}
+		if mfa != nil && mfa.Enabled {
+			mfaToken, err := h.issueMFAToken(adminID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+				return
+			}
+			c.JSON(http.StatusOK, LoginResponse{MFARequired: true, MFAToken: mfaToken})
+			return
+		}
+	}
 
-	tokenString, err := token.SignedString([]byte(h.cfg.AdminPassword))
+	tokenString, err := h.issueAdminToken(adminID, middleware.RoleAdmin)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
@@ -48,17 +70,96 @@ func (h *Handlers) AdminLogin(c *gin.Context) {
 	c.JSON(http.StatusOK, LoginResponse{Token: tokenString})
 }
 
-func (h *Handlers) GetAttendees(c *gin.Context) {
-	var attendees []models.Registration
-	iter := h.db.Collection("registrations").Documents(h.db.Context())
+// issueAdminToken signs the JWT accepted by AuthMiddleware for a shared-password
+// session, which AuthMiddleware always treats as defaultAdminID
+func (h *Handlers) issueAdminToken(adminID, role string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"admin": true,
+		"sub":   adminID,
+		"role":  role,
+		"exp":   time.Now().Add(time.Hour * 24).Unix(),
+	})
+	return token.SignedString([]byte(h.cfg.AdminTokenSecret))
+}
+
+// attendeeFilter narrows the attendee list and export
+type attendeeFilter struct {
+	designation string
+	search      string
+	from, to    *time.Time
+}
+
+// parseAttendeeFilter reads designation, search (name or email substring) and a
+// from/to registration time range (RFC 3339) from the query string
+func parseAttendeeFilter(c *gin.Context) (attendeeFilter, error) {
+	f := attendeeFilter{
+		designation: strings.TrimSpace(c.Query("designation")),
+		search:      strings.ToLower(strings.TrimSpace(c.Query("search"))),
+	}
+	for param, target := range map[string]**time.Time{"from": &f.from, "to": &f.to} {
+		if raw := c.Query(param); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return f, fmt.Errorf("%s must be an RFC 3339 timestamp", param)
+			}
+			*target = &t
+		}
+	}
+	return f, nil
+}
+
+// matches applies the filters Firestore can't express without composite indexes
+func (f attendeeFilter) matches(reg models.Registration) bool {
+	if f.designation != "" && !strings.EqualFold(reg.Designation, f.designation) &&
+		!strings.EqualFold(reg.CanonicalDesignation, f.designation) {
+		return false
+	}
+	if f.search != "" &&
+		!strings.Contains(strings.ToLower(reg.Name), f.search) &&
+		!strings.Contains(strings.ToLower(reg.Email), f.search) {
+		return false
+	}
+	return true
+}
+
+// forEachAttendee calls fn for every matching registration, oldest first, streaming
+// them from Firestore. Ordering by createdAt leaves out registrations without it, so
+// those are found by a scan first. A from/to range only matches registrations with a time.
+func (h *Handlers) forEachAttendee(f attendeeFilter, fn func(models.Registration) error) error {
+	registrations := h.db.Collection("registrations")
+	if f.from == nil && f.to == nil {
+		if err := h.streamAttendees(registrations.Query, f, true, fn); err != nil {
+			return err
+		}
+	}
+
+	query := registrations.OrderBy("createdAt", firestore.Asc)
+	if f.from != nil {
+		query = query.Where("createdAt", ">=", *f.from)
+	}
+	if f.to != nil {
+		query = query.Where("createdAt", "<=", *f.to)
+	}
+	return h.streamAttendees(query, f, false, fn)
+}
+
+// streamAttendees calls fn for the query's registrations that match f. With
+// withoutCreatedAt it only passes on those that have no createdAt field.
+func (h *Handlers) streamAttendees(query firestore.Query, f attendeeFilter, withoutCreatedAt bool, fn func(models.Registration) error) error {
+	iter := query.Documents(h.db.Context())
+	defer iter.Stop()
 	for {
 		doc, err := iter.Next()
 		if err == iterator.Done {
-			break
+			return nil
 		}
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch attendees"})
-			return
+			return err
+		}
+		if withoutCreatedAt {
+			if _, ok := doc.Data()["createdAt"]; ok {
+				continue
+			}
 		}
 
 		var reg models.Registration
@@ -66,7 +167,30 @@ func (h *Handlers) GetAttendees(c *gin.Context) {
 			continue
 		}
 		reg.ID = doc.Ref.ID
+		if !f.matches(reg) {
+			continue
+		}
+		if err := fn(reg); err != nil {
+			return err
+		}
+	}
+}
+
+func (h *Handlers) GetAttendees(c *gin.Context) {
+	filter, err := parseAttendeeFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var attendees []models.Registration
+	err = h.forEachAttendee(filter, func(reg models.Registration) error {
 		attendees = append(attendees, reg)
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch attendees"})
+		return
 	}
 
 	c.JSON(http.StatusOK, attendees)
@@ -95,7 +219,13 @@ func (h *Handlers) GetAttendee(c *gin.Context) {
 }
 
 func (h *Handlers) GetDesignationBreakdown(c *gin.Context) {
-	designationCount := make(map[string]int)
+	normalizer, err := h.designationNormalizer()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load designation taxonomy"})
+		return
+	}
+
+	var designations []string
 	iter := h.db.Collection("registrations").Documents(h.db.Context())
 	for {
 		doc, err := iter.Next()
@@ -111,17 +241,9 @@ func (h *Handlers) GetDesignationBreakdown(c *gin.Context) {
 		if err := doc.DataTo(&reg); err != nil {
 			continue
 		}
-		designationCount[reg.Designation]++
-	}
-
-	var breakdown []models.DesignationBreakdown
-	for designation, count := range designationCount {
-		breakdown = append(breakdown, models.DesignationBreakdown{
-			Designation: designation,
-			Count:       count,
-		})
+		designations = append(designations, reg.Designation)
 	}
 
-	c.JSON(http.StatusOK, breakdown)
+	c.JSON(http.StatusOK, designationBreakdown(normalizer, designations))
 }
 
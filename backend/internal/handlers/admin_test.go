@@ -2,15 +2,20 @@ package handlers
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"appdirect-workshop-backend/internal/config"
+	"appdirect-workshop-backend/internal/database/firestoretest"
+	"appdirect-workshop-backend/internal/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAdminLogin(t *testing.T) {
@@ -46,8 +51,9 @@ func TestAdminLogin(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockDB := createMockDB()
 			cfg := &config.Config{
-				AdminPassword: "test-password",
-				SubcollectionID: "test-collection",
+				AdminPassword:    "test-password",
+				AdminTokenSecret: "test-token-secret",
+				SubcollectionID:  "test-collection",
 			}
 			h := New(mockDB, cfg)
 
@@ -75,13 +81,44 @@ func TestAdminLogin(t *testing.T) {
 	}
 }
 
+func TestAdminLoginUsernameCannotSkipMFA(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := firestoretest.NewDatabase(t, "test-collection")
+	_, err := db.Collection("admin_mfa").Doc(defaultAdminID).Set(db.Context(), models.AdminMFA{Enabled: true, Secret: "JBSWY3DPEHPK3PXP"})
+	require.NoError(t, err)
+	h := New(db, &config.Config{AdminPassword: "test-password", AdminTokenSecret: "test-token-secret", SubcollectionID: "test-collection", MFAEnabled: true})
+
+	router := gin.New()
+	router.POST("/api/admin/login", h.AdminLogin)
+
+	for _, username := range []string{"", "admin", "x", "someone@example.com"} {
+		body, _ := json.Marshal(map[string]string{"username": username, "password": "test-password"})
+		req, _ := http.NewRequest("POST", "/api/admin/login", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code, username)
+		var resp LoginResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.True(t, resp.MFARequired, username)
+		assert.Empty(t, resp.Token, username)
+
+		adminID, err := h.parseMFAToken(resp.MFAToken)
+		require.NoError(t, err)
+		assert.Equal(t, defaultAdminID, adminID)
+	}
+}
+
 func TestGetAttendees(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockDB := createMockDB()
 	cfg := &config.Config{
-		AdminPassword: "test-password",
-		SubcollectionID: "test-collection",
+		AdminPassword:    "test-password",
+		AdminTokenSecret: "test-token-secret",
+		SubcollectionID:  "test-collection",
 	}
 	h := New(mockDB, cfg)
 
@@ -97,13 +134,69 @@ func TestGetAttendees(t *testing.T) {
 	assert.True(t, w.Code == http.StatusOK || w.Code == http.StatusInternalServerError)
 }
 
+func TestGetAttendeesWithoutCreatedAt(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := firestoretest.NewDatabase(t, "test-collection")
+	_, err := db.Collection("registrations").Doc("new").Set(db.Context(), models.Registration{
+		Name:        "Jane",
+		Email:       "jane@example.com",
+		Designation: "Engineer",
+		CreatedAt:   time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	// Written before registrations recorded their time
+	_, err = db.Collection("registrations").Doc("legacy").Set(db.Context(), map[string]interface{}{
+		"name":        "John",
+		"email":       "john@example.com",
+		"designation": "Manager",
+	})
+	require.NoError(t, err)
+	h := New(db, &config.Config{AdminPassword: "test-password", AdminTokenSecret: "test-token-secret", SubcollectionID: "test-collection"})
+
+	router := gin.New()
+	router.GET("/api/admin/attendees", h.GetAttendees)
+	router.GET("/api/admin/attendees/export", h.ExportAttendees)
+
+	list := func(query string) []string {
+		req, _ := http.NewRequest("GET", "/api/admin/attendees"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var attendees []models.Registration
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &attendees))
+		ids := []string{}
+		for _, reg := range attendees {
+			ids = append(ids, reg.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"legacy", "new"}, list(""))
+	assert.Equal(t, []string{"legacy"}, list("?search=john"))
+	assert.Equal(t, []string{"new"}, list("?from=2026-03-01T00:00:00Z"))
+
+	req, _ := http.NewRequest("GET", "/api/admin/attendees/export?format=csv&columns=email,createdAt", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Email", "Registered At (UTC)"},
+		{"john@example.com", ""},
+		{"jane@example.com", "2026-03-01 09:00:00"},
+	}, rows)
+}
+
 func TestGetDesignationBreakdown(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockDB := createMockDB()
 	cfg := &config.Config{
-		AdminPassword: "test-password",
-		SubcollectionID: "test-collection",
+		AdminPassword:    "test-password",
+		AdminTokenSecret: "test-token-secret",
+		SubcollectionID:  "test-collection",
 	}
 	h := New(mockDB, cfg)
 
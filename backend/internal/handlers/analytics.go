@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"appdirect-workshop-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxTimeSeriesBuckets keeps hourly series over long ranges from producing huge responses
+const maxTimeSeriesBuckets = 5000
+
+// Time series bucket sizes
+const (
+	IntervalHour = "hour"
+	IntervalDay  = "day"
+	IntervalWeek = "week"
+)
+
+type TimeSeriesBucket struct {
+	Start      time.Time `json:"start"`
+	Count      int       `json:"count"`
+	Cumulative int       `json:"cumulative"`
+}
+
+type RegistrationTimeSeries struct {
+	Interval string `json:"interval"`
+	TimeZone string `json:"timeZone"`
+	// Total counts every matching registration, including those before the first bucket
+	Total   int                `json:"total"`
+	Buckets []TimeSeriesBucket `json:"buckets"`
+}
+
+// GetRegistrationTimeSeries counts registrations per hour, day or week (weeks start on
+// Monday) in the requested time zone. Buckets without registrations are included so the
+// series can be charted directly, and cumulative totals include registrations before from.
+func (h *Handlers) GetRegistrationTimeSeries(c *gin.Context) {
+	interval := c.DefaultQuery("interval", IntervalDay)
+	if interval != IntervalHour && interval != IntervalDay && interval != IntervalWeek {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "interval must be hour, day or week"})
+		return
+	}
+	loc, err := time.LoadLocation(c.DefaultQuery("tz", "UTC"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tz must be an IANA time zone such as Asia/Kolkata"})
+		return
+	}
+	filter, err := parseAttendeeFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.from != nil && filter.to != nil && filter.to.Before(*filter.from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return
+	}
+
+	// Registrations before from still count towards the cumulative totals
+	from := filter.from
+	filter.from = nil
+
+	var times []time.Time
+	err = h.forEachAttendee(filter, func(reg models.Registration) error {
+		// Registrations without a time can't be placed in a bucket
+		if !reg.CreatedAt.IsZero() {
+			times = append(times, reg.CreatedAt)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch registrations"})
+		return
+	}
+
+	series, err := bucketRegistrations(times, interval, loc, from, filter.to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, series)
+}
+
+// bucketRegistrations builds the series from registration times sorted oldest first.
+// Without from and to the series spans the first to the last registration; times after
+// the last bucket only count towards Total.
+func bucketRegistrations(times []time.Time, interval string, loc *time.Location, from, to *time.Time) (RegistrationTimeSeries, error) {
+	series := RegistrationTimeSeries{
+		Interval: interval,
+		TimeZone: loc.String(),
+		Total:    len(times),
+		Buckets:  []TimeSeriesBucket{},
+	}
+
+	var first, last time.Time
+	switch {
+	case from != nil:
+		first = *from
+	case len(times) > 0:
+		first = times[0]
+	default:
+		return series, nil
+	}
+	switch {
+	case to != nil:
+		last = *to
+	case len(times) > 0:
+		last = times[len(times)-1]
+	default:
+		last = first
+	}
+
+	start := bucketStart(first, interval, loc)
+	end := bucketStart(last, interval, loc)
+	var starts []time.Time
+	for t := start; !t.After(end); t = nextBucket(t, interval) {
+		if len(starts) == maxTimeSeriesBuckets {
+			return series, fmt.Errorf("range has more than %d %s buckets, use a larger interval", maxTimeSeriesBuckets, interval)
+		}
+		starts = append(starts, t)
+	}
+
+	cumulative := 0
+	i := 0
+	// Everything before the first bucket is carried into the running total
+	for i < len(times) && times[i].Before(start) {
+		cumulative++
+		i++
+	}
+	for _, bucketFrom := range starts {
+		bucketEnd := nextBucket(bucketFrom, interval)
+		count := 0
+		for i < len(times) && times[i].Before(bucketEnd) {
+			count++
+			i++
+		}
+		cumulative += count
+		series.Buckets = append(series.Buckets, TimeSeriesBucket{
+			Start:      bucketFrom,
+			Count:      count,
+			Cumulative: cumulative,
+		})
+	}
+	return series, nil
+}
+
+// bucketStart truncates t to the start of its hour, day or Monday-based week in loc
+func bucketStart(t time.Time, interval string, loc *time.Location) time.Time {
+	t = t.In(loc)
+	switch interval {
+	case IntervalHour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+	case IntervalWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		daysSinceMonday := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -daysSinceMonday)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+}
+
+// nextBucket steps days and weeks in calendar terms so they stay aligned across DST
+// changes, and hours in elapsed time so a repeated hour gets its own bucket
+func nextBucket(t time.Time, interval string) time.Time {
+	switch interval {
+	case IntervalHour:
+		return t.Add(time.Hour)
+	case IntervalWeek:
+		return t.AddDate(0, 0, 7)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketRegistrations(t *testing.T) {
+	utc := func(s string) time.Time {
+		ts, err := time.Parse(time.RFC3339, s)
+		require.NoError(t, err)
+		return ts
+	}
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+
+	times := []time.Time{
+		utc("2025-03-03T10:15:00Z"), // Monday
+		utc("2025-03-03T10:45:00Z"),
+		utc("2025-03-03T20:00:00Z"), // 01:30 on Tuesday in Kolkata
+		utc("2025-03-05T08:00:00Z"),
+		utc("2025-03-11T09:00:00Z"), // the following Tuesday
+	}
+
+	type bucket struct {
+		start      string
+		count      int
+		cumulative int
+	}
+	tests := []struct {
+		name     string
+		interval string
+		loc      *time.Location
+		from     *time.Time
+		expected []bucket
+	}{
+		{
+			name:     "daily in UTC with empty days filled",
+			interval: IntervalDay,
+			loc:      time.UTC,
+			expected: []bucket{
+				{"2025-03-03T00:00:00Z", 3, 3},
+				{"2025-03-04T00:00:00Z", 0, 3},
+				{"2025-03-05T00:00:00Z", 1, 4},
+				{"2025-03-06T00:00:00Z", 0, 4},
+				{"2025-03-07T00:00:00Z", 0, 4},
+				{"2025-03-08T00:00:00Z", 0, 4},
+				{"2025-03-09T00:00:00Z", 0, 4},
+				{"2025-03-10T00:00:00Z", 0, 4},
+				{"2025-03-11T00:00:00Z", 1, 5},
+			},
+		},
+		{
+			name:     "weekly in UTC starts on Monday",
+			interval: IntervalWeek,
+			loc:      time.UTC,
+			expected: []bucket{
+				{"2025-03-03T00:00:00Z", 4, 4},
+				{"2025-03-10T00:00:00Z", 1, 5},
+			},
+		},
+		{
+			name:     "daily in Kolkata moves the late registration to Tuesday",
+			interval: IntervalDay,
+			loc:      kolkata,
+			from:     timePtr(utc("2025-03-02T20:00:00Z")),
+			expected: []bucket{
+				{"2025-03-03T00:00:00+05:30", 2, 2},
+				{"2025-03-04T00:00:00+05:30", 1, 3},
+				{"2025-03-05T00:00:00+05:30", 1, 4},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series, err := bucketRegistrations(times, tt.interval, tt.loc, tt.from, nil)
+			require.NoError(t, err)
+			assert.Equal(t, len(times), series.Total)
+
+			// Only the leading buckets are listed for the Kolkata case
+			buckets := series.Buckets
+			if len(buckets) > len(tt.expected) {
+				buckets = buckets[:len(tt.expected)]
+			}
+			require.Len(t, buckets, len(tt.expected))
+			for i, want := range tt.expected {
+				assert.Equal(t, want.start, buckets[i].Start.Format(time.RFC3339), "bucket %d start", i)
+				assert.Equal(t, want.count, buckets[i].Count, "bucket %d count", i)
+				assert.Equal(t, want.cumulative, buckets[i].Cumulative, "bucket %d cumulative", i)
+			}
+		})
+	}
+}
+
+func TestBucketRegistrationsCarriesEarlierTotals(t *testing.T) {
+	from := time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 6, 23, 0, 0, 0, time.UTC)
+	times := []time.Time{
+		time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2025, 3, 2, 9, 0, 0, 0, time.UTC),
+		time.Date(2025, 3, 6, 9, 0, 0, 0, time.UTC),
+	}
+
+	series, err := bucketRegistrations(times, IntervalDay, time.UTC, &from, &to)
+	require.NoError(t, err)
+	require.Len(t, series.Buckets, 2)
+	assert.Equal(t, 0, series.Buckets[0].Count)
+	assert.Equal(t, 2, series.Buckets[0].Cumulative)
+	assert.Equal(t, 1, series.Buckets[1].Count)
+	assert.Equal(t, 3, series.Buckets[1].Cumulative)
+}
+
+func TestBucketRegistrationsEmpty(t *testing.T) {
+	series, err := bucketRegistrations(nil, IntervalHour, time.UTC, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, series.Total)
+	assert.Empty(t, series.Buckets)
+	assert.NotNil(t, series.Buckets)
+}
+
+func TestBucketRegistrationsTooManyBuckets(t *testing.T) {
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := bucketRegistrations(nil, IntervalHour, time.UTC, &from, &to)
+	assert.Error(t, err)
+}
+
+func TestGetRegistrationTimeSeriesValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "unknown interval", query: "?interval=month"},
+		{name: "unknown time zone", query: "?tz=Mars/Olympus"},
+		{name: "invalid from", query: "?from=yesterday"},
+		{name: "to before from", query: "?from=2025-03-02T00:00:00Z&to=2025-03-01T00:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkshopTestHandlers()
+			router := gin.New()
+			router.GET("/api/admin/analytics/registrations", h.GetRegistrationTimeSeries)
+
+			req, _ := http.NewRequest("GET", "/api/admin/analytics/registrations"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
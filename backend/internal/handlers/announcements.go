@@ -0,0 +1,451 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"appdirect-workshop-backend/internal/mail"
+	"appdirect-workshop-backend/internal/middleware"
+	"appdirect-workshop-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Announcement severities, most urgent last
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Events on the workshop's public stream. Updates carry the whole announcement, including
+// scheduled ones, so clients can show it once its publish time comes.
+const (
+	EventAnnouncementUpdated = "announcement.updated"
+	EventAnnouncementDeleted = "announcement.deleted"
+)
+
+// Announcement email job states
+const (
+	EmailJobRunning   = "running"
+	EmailJobCompleted = "completed"
+	EmailJobFailed    = "failed"
+)
+
+const (
+	// emailProgressInterval is how many sends pass between progress writes
+	emailProgressInterval = 25
+	maxEmailErrors        = 20
+	// emailJobStaleAfter is how long a running job can go without progress before it is
+	// assumed to have died with its server and another may be started
+	emailJobStaleAfter = 10 * time.Minute
+)
+
+var severityRank = map[string]int{SeverityInfo: 0, SeverityWarning: 1, SeverityCritical: 2}
+
+type AnnouncementRequest struct {
+	Title     string     `json:"title" binding:"required,max=200"`
+	Body      string     `json:"body" binding:"required,max=5000"`
+	Severity  string     `json:"severity" binding:"omitempty,oneof=info warning critical"`
+	PublishAt *time.Time `json:"publishAt"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// GetAnnouncements lists the announcements currently published, most severe first
+func (h *Handlers) GetAnnouncements(c *gin.Context) {
+	announcements, err := h.allAnnouncements()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch announcements"})
+		return
+	}
+	c.JSON(http.StatusOK, activeAnnouncements(announcements, time.Now()))
+}
+
+// GetAllAnnouncements lists every announcement, including scheduled and expired ones,
+// newest first
+func (h *Handlers) GetAllAnnouncements(c *gin.Context) {
+	announcements, err := h.allAnnouncements()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch announcements"})
+		return
+	}
+	c.JSON(http.StatusOK, announcements)
+}
+
+func (h *Handlers) CreateAnnouncement(c *gin.Context) {
+	var req AnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	now := time.Now()
+	announcement, err := req.announcement(now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	announcement.CreatedAt = now
+
+	docRef, _, err := h.db.Collection("announcements").Add(h.db.Context(), announcement)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create announcement"})
+		return
+	}
+
+	announcement.ID = docRef.ID
+	h.publishPublic(EventAnnouncementUpdated, announcement)
+	h.recordAudit(c, "announcement.create", "announcement", announcement.ID, nil, announcement)
+	c.JSON(http.StatusCreated, announcement)
+}
+
+func (h *Handlers) UpdateAnnouncement(c *gin.Context) {
+	id := c.Param("id")
+	var req AnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	announcement, err := req.announcement(time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	before, err := h.getAnnouncement(id)
+	if status.Code(err) == codes.NotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Announcement not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch announcement"})
+		return
+	}
+	announcement.ID = id
+	announcement.CreatedAt = before.CreatedAt
+
+	if _, err := h.db.Collection("announcements").Doc(id).Set(h.db.Context(), announcement); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update announcement"})
+		return
+	}
+
+	h.publishPublic(EventAnnouncementUpdated, announcement)
+	h.recordAudit(c, "announcement.update", "announcement", id, before, announcement)
+	c.JSON(http.StatusOK, announcement)
+}
+
+func (h *Handlers) DeleteAnnouncement(c *gin.Context) {
+	id := c.Param("id")
+	before := h.getSnapshot("announcements", id)
+	if before == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Announcement not found"})
+		return
+	}
+
+	if _, err := h.db.Collection("announcements").Doc(id).Delete(h.db.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete announcement"})
+		return
+	}
+
+	h.publishPublic(EventAnnouncementDeleted, gin.H{"id": id})
+	h.recordAudit(c, "announcement.delete", "announcement", id, before, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Announcement deleted successfully"})
+}
+
+// EmailAnnouncement starts a background job emailing the announcement to every
+// registrant and returns it with 202; its progress is read from GetAnnouncementEmails.
+// Every registration counts as confirmed, and addresses registered more than once get
+// one email.
+func (h *Handlers) EmailAnnouncement(c *gin.Context) {
+	if h.mailer == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Email is not configured"})
+		return
+	}
+
+	id := c.Param("id")
+	announcement, err := h.getAnnouncement(id)
+	if status.Code(err) == codes.NotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Announcement not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch announcement"})
+		return
+	}
+	now := time.Now()
+	if announcement.ExpiresAt != nil && !now.Before(*announcement.ExpiresAt) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Announcement has expired"})
+		return
+	}
+
+	jobs, err := h.announcementEmails(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch email jobs"})
+		return
+	}
+	for _, job := range jobs {
+		if job.Status == EmailJobRunning && now.Sub(job.UpdatedAt) < emailJobStaleAfter {
+			c.JSON(http.StatusConflict, gin.H{"error": "This announcement is already being emailed", "job": job})
+			return
+		}
+	}
+
+	recipients, err := h.registrantEmails()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch registrations"})
+		return
+	}
+
+	job := models.AnnouncementEmail{
+		AnnouncementID: id,
+		Status:         EmailJobRunning,
+		Total:          len(recipients),
+		RequestedBy:    c.GetString(middleware.AdminIDKey),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	jobRef, _, err := h.db.Collection("announcement_emails").Add(h.db.Context(), job)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start email job"})
+		return
+	}
+	job.ID = jobRef.ID
+
+	subject, body := announcementEmail(announcement, h.workshopTitle(c))
+	go h.runAnnouncementEmail(jobRef, job, recipients, subject, body)
+
+	h.recordAudit(c, "announcement.email", "announcement", id, nil, gin.H{"job": job.ID, "recipients": job.Total})
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetAnnouncementEmails lists an announcement's email jobs with their progress, newest
+// first
+func (h *Handlers) GetAnnouncementEmails(c *gin.Context) {
+	jobs, err := h.announcementEmails(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch email jobs"})
+		return
+	}
+	c.JSON(http.StatusOK, jobs)
+}
+
+// runAnnouncementEmail sends the emails and records the job's progress. It outlives the
+// request, so it uses the database's own context.
+func (h *Handlers) runAnnouncementEmail(jobRef *firestore.DocumentRef, job models.AnnouncementEmail, recipients []string, subject, body string) {
+	save := func(job models.AnnouncementEmail) error {
+		_, err := jobRef.Set(h.db.Context(), job)
+		return err
+	}
+	job = sendAnnouncementEmails(h.db.Context(), h.mailer, job, recipients, subject, body, save, time.Now)
+	log.Printf("Announcement %s emailed: %d sent, %d failed", job.AnnouncementID, job.Sent, job.Failed)
+}
+
+// sendAnnouncementEmails sends one email per recipient, saving progress every
+// emailProgressInterval sends and once more when done. The job fails only if no email
+// could be sent; individual failures are counted and the first few kept.
+func sendAnnouncementEmails(ctx context.Context, sender mail.Sender, job models.AnnouncementEmail, recipients []string, subject, body string, save func(models.AnnouncementEmail) error, now func() time.Time) models.AnnouncementEmail {
+	for i, to := range recipients {
+		err := sender.Send(ctx, mail.Message{To: to, Subject: subject, Body: body})
+		if err != nil {
+			job.Failed++
+			if len(job.Errors) < maxEmailErrors {
+				job.Errors = append(job.Errors, fmt.Sprintf("%s: %v", to, err))
+			}
+		} else {
+			job.Sent++
+		}
+
+		if (i+1)%emailProgressInterval == 0 && i+1 < len(recipients) {
+			job.UpdatedAt = now()
+			if err := save(job); err != nil {
+				log.Printf("Failed to save progress of email job %s: %v", job.ID, err)
+			}
+		}
+	}
+
+	finished := now()
+	job.Status = EmailJobCompleted
+	if job.Sent == 0 && job.Failed > 0 {
+		job.Status = EmailJobFailed
+	}
+	job.UpdatedAt, job.FinishedAt = finished, &finished
+	if err := save(job); err != nil {
+		log.Printf("Failed to save email job %s: %v", job.ID, err)
+	}
+	return job
+}
+
+// announcementEmail renders the plain-text email for an announcement
+func announcementEmail(announcement models.Announcement, workshopTitle string) (string, string) {
+	subject := announcement.Title
+	if workshopTitle != "" {
+		subject = workshopTitle + ": " + announcement.Title
+	}
+	body := announcement.Title + "\n\n" + announcement.Body + "\n"
+	if workshopTitle != "" {
+		body += "\n--\nYou are receiving this because you registered for " + workshopTitle + ".\n"
+	}
+	return subject, body
+}
+
+// workshopTitle names the workshop in emails, falling back to its name
+func (h *Handlers) workshopTitle(c *gin.Context) string {
+	value, ok := c.Get(WorkshopKey)
+	if !ok {
+		return ""
+	}
+	return workshopDisplayName(value.(*models.Workshop))
+}
+
+func workshopDisplayName(workshop *models.Workshop) string {
+	if workshop.Settings.Title != "" {
+		return workshop.Settings.Title
+	}
+	return workshop.Name
+}
+
+// announcement validates the request beyond its binding rules. The publish time
+// defaults to now.
+func (req *AnnouncementRequest) announcement(now time.Time) (models.Announcement, error) {
+	announcement := models.Announcement{
+		Title:     strings.TrimSpace(req.Title),
+		Body:      strings.TrimSpace(req.Body),
+		Severity:  req.Severity,
+		PublishAt: now,
+		ExpiresAt: req.ExpiresAt,
+		UpdatedAt: now,
+	}
+	if announcement.Severity == "" {
+		announcement.Severity = SeverityInfo
+	}
+	if req.PublishAt != nil {
+		announcement.PublishAt = *req.PublishAt
+	}
+
+	if announcement.Title == "" {
+		return announcement, errors.New("title is required")
+	}
+	if strings.ContainsAny(announcement.Title, "\r\n") {
+		return announcement, errors.New("title must be a single line")
+	}
+	if announcement.Body == "" {
+		return announcement, errors.New("body is required")
+	}
+	if announcement.ExpiresAt != nil && !announcement.ExpiresAt.After(announcement.PublishAt) {
+		return announcement, errors.New("expiresAt must be after publishAt")
+	}
+	return announcement, nil
+}
+
+// activeAnnouncements keeps the announcements published at now, most severe first and
+// then newest first
+func activeAnnouncements(announcements []models.Announcement, now time.Time) []models.Announcement {
+	active := make([]models.Announcement, 0, len(announcements))
+	for _, a := range announcements {
+		if !now.Before(a.PublishAt) && (a.ExpiresAt == nil || now.Before(*a.ExpiresAt)) {
+			active = append(active, a)
+		}
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		if severityRank[active[i].Severity] != severityRank[active[j].Severity] {
+			return severityRank[active[i].Severity] > severityRank[active[j].Severity]
+		}
+		return active[i].PublishAt.After(active[j].PublishAt)
+	})
+	return active
+}
+
+// allAnnouncements lists announcements by publish time, newest first
+func (h *Handlers) allAnnouncements() ([]models.Announcement, error) {
+	announcements := make([]models.Announcement, 0)
+	iter := h.db.Collection("announcements").OrderBy("publishAt", firestore.Desc).Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return announcements, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var announcement models.Announcement
+		if err := doc.DataTo(&announcement); err != nil {
+			continue
+		}
+		announcement.ID = doc.Ref.ID
+		announcements = append(announcements, announcement)
+	}
+}
+
+func (h *Handlers) getAnnouncement(id string) (models.Announcement, error) {
+	var announcement models.Announcement
+	doc, err := h.db.Collection("announcements").Doc(id).Get(h.db.Context())
+	if err != nil {
+		return announcement, err
+	}
+	if err := doc.DataTo(&announcement); err != nil {
+		return announcement, err
+	}
+	announcement.ID = doc.Ref.ID
+	return announcement, nil
+}
+
+// announcementEmails lists an announcement's email jobs, newest first. Sorting here
+// avoids needing a composite index.
+func (h *Handlers) announcementEmails(announcementID string) ([]models.AnnouncementEmail, error) {
+	jobs := make([]models.AnnouncementEmail, 0)
+	iter := h.db.Collection("announcement_emails").Where("announcementId", "==", announcementID).Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var job models.AnnouncementEmail
+		if err := doc.DataTo(&job); err != nil {
+			continue
+		}
+		job.ID = doc.Ref.ID
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.After(jobs[j].CreatedAt) })
+	return jobs, nil
+}
+
+// registrantEmails lists each registered address once, ignoring case
+func (h *Handlers) registrantEmails() ([]string, error) {
+	var emails []string
+	seen := map[string]bool{}
+	iter := h.db.Collection("registrations").Select("email").Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return emails, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		email, _ := doc.Data()["email"].(string)
+		email = strings.TrimSpace(email)
+		if key := strings.ToLower(email); email != "" && !seen[key] {
+			seen[key] = true
+			emails = append(emails, email)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"appdirect-workshop-backend/internal/mail"
+	"appdirect-workshop-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnouncementRequest(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+	earlier := now.Add(-time.Hour)
+
+	req := AnnouncementRequest{Title: "  Room change ", Body: " Keynote moves to Hall B "}
+	announcement, err := req.announcement(now)
+	require.NoError(t, err)
+	assert.Equal(t, "Room change", announcement.Title)
+	assert.Equal(t, "Keynote moves to Hall B", announcement.Body)
+	assert.Equal(t, SeverityInfo, announcement.Severity)
+	assert.Equal(t, now, announcement.PublishAt)
+
+	invalid := []AnnouncementRequest{
+		{Title: " ", Body: "Body"},
+		{Title: "Title", Body: " "},
+		{Title: "Room\r\nBcc: eve@example.com", Body: "Body"},
+		{Title: "Title", Body: "Body", ExpiresAt: &earlier},
+		{Title: "Title", Body: "Body", PublishAt: &later, ExpiresAt: &later},
+	}
+	for _, req := range invalid {
+		_, err := req.announcement(now)
+		assert.Error(t, err)
+	}
+}
+
+func TestActiveAnnouncements(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	expired := now.Add(-time.Minute)
+	expires := now.Add(time.Hour)
+
+	announcements := []models.Announcement{
+		{ID: "scheduled", Severity: SeverityCritical, PublishAt: now.Add(time.Minute)},
+		{ID: "newer-info", Severity: SeverityInfo, PublishAt: now.Add(-time.Minute)},
+		{ID: "expired", Severity: SeverityCritical, PublishAt: now.Add(-time.Hour), ExpiresAt: &expired},
+		{ID: "older-info", Severity: SeverityInfo, PublishAt: now.Add(-time.Hour)},
+		{ID: "warning", Severity: SeverityWarning, PublishAt: now.Add(-2 * time.Hour), ExpiresAt: &expires},
+		{ID: "critical-now", Severity: SeverityCritical, PublishAt: now},
+	}
+
+	var ids []string
+	for _, a := range activeAnnouncements(announcements, now) {
+		ids = append(ids, a.ID)
+	}
+	assert.Equal(t, []string{"critical-now", "warning", "newer-info", "older-info"}, ids)
+}
+
+type fakeSender struct {
+	sent []mail.Message
+	fail map[string]bool
+}
+
+func (s *fakeSender) Send(ctx context.Context, msg mail.Message) error {
+	if s.fail[msg.To] {
+		return errors.New("mailbox unavailable")
+	}
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func TestSendAnnouncementEmails(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	var recipients []string
+	for i := 0; i < emailProgressInterval*2+5; i++ {
+		recipients = append(recipients, "attendee"+strings.Repeat("x", i)+"@example.com")
+	}
+	sender := &fakeSender{fail: map[string]bool{recipients[3]: true}}
+
+	var saved []models.AnnouncementEmail
+	save := func(job models.AnnouncementEmail) error {
+		saved = append(saved, job)
+		return nil
+	}
+
+	job := models.AnnouncementEmail{ID: "job1", Status: EmailJobRunning, Total: len(recipients)}
+	job = sendAnnouncementEmails(context.Background(), sender, job, recipients, "Subject", "Body", save, clock)
+
+	assert.Equal(t, EmailJobCompleted, job.Status)
+	assert.Equal(t, len(recipients)-1, job.Sent)
+	assert.Equal(t, 1, job.Failed)
+	assert.Equal(t, []string{recipients[3] + ": mailbox unavailable"}, job.Errors)
+	require.NotNil(t, job.FinishedAt)
+	assert.Len(t, sender.sent, len(recipients)-1)
+
+	// Two progress writes and the final one
+	require.Len(t, saved, 3)
+	assert.Equal(t, EmailJobRunning, saved[0].Status)
+	assert.Equal(t, emailProgressInterval, saved[0].Sent+saved[0].Failed)
+	assert.Equal(t, job, saved[2])
+}
+
+func TestSendAnnouncementEmailsAllFailed(t *testing.T) {
+	recipients := []string{"a@example.com", "b@example.com"}
+	sender := &fakeSender{fail: map[string]bool{"a@example.com": true, "b@example.com": true}}
+	save := func(models.AnnouncementEmail) error { return nil }
+
+	job := sendAnnouncementEmails(context.Background(), sender, models.AnnouncementEmail{Status: EmailJobRunning}, recipients, "S", "B", save, time.Now)
+	assert.Equal(t, EmailJobFailed, job.Status)
+	assert.Equal(t, 2, job.Failed)
+}
+
+func TestAnnouncementEmail(t *testing.T) {
+	announcement := models.Announcement{Title: "Room change", Body: "Keynote moves to Hall B."}
+
+	subject, body := announcementEmail(announcement, "AI Workshop")
+	assert.Equal(t, "AI Workshop: Room change", subject)
+	assert.Equal(t, "Room change\n\nKeynote moves to Hall B.\n\n--\nYou are receiving this because you registered for AI Workshop.\n", body)
+
+	subject, body = announcementEmail(announcement, "")
+	assert.Equal(t, "Room change", subject)
+	assert.Equal(t, "Room change\n\nKeynote moves to Hall B.\n", body)
+}
+
+func TestAnnouncementRequestValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{name: "create without body", method: "POST", path: "/api/admin/announcements", body: `{"title":"Room change"}`, status: http.StatusBadRequest},
+		{name: "unknown severity", method: "POST", path: "/api/admin/announcements", body: `{"title":"T","body":"B","severity":"urgent"}`, status: http.StatusBadRequest},
+		{name: "update expiring before publish", method: "PUT", path: "/api/admin/announcements/a1", body: `{"title":"T","body":"B","publishAt":"2026-03-01T10:00:00Z","expiresAt":"2026-03-01T09:00:00Z"}`, status: http.StatusBadRequest},
+		{name: "email without SMTP", method: "POST", path: "/api/admin/announcements/a1/emails", status: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkshopTestHandlers()
+			router := gin.New()
+			router.POST("/api/admin/announcements", h.CreateAnnouncement)
+			router.PUT("/api/admin/announcements/:id", h.UpdateAnnouncement)
+			router.POST("/api/admin/announcements/:id/emails", h.EmailAnnouncement)
+
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"appdirect-workshop-backend/internal/middleware"
+	"appdirect-workshop-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	apiKeyPrefix = "wsk_"
+	// lastUsedInterval limits how often a busy key's lastUsedAt is written back
+	lastUsedInterval = time.Minute
+)
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type CreateAPIKeyResponse struct {
+	models.APIKey
+	Key string `json:"key"`
+}
+
+func (h *Handlers) GetAPIKeys(c *gin.Context) {
+	keys := make([]models.APIKey, 0)
+	iter := h.db.Collection("api_keys").OrderBy("createdAt", firestore.Desc).Documents(h.db.Context())
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API keys"})
+			return
+		}
+
+		var key models.APIKey
+		if err := doc.DataTo(&key); err != nil {
+			continue
+		}
+		key.ID = doc.Ref.ID
+		keys = append(keys, key)
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// CreateAPIKey returns the plaintext key once; only its hash is stored
+func (h *Handlers) CreateAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, scope := range req.Scopes {
+		if !middleware.ValidScope(scope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid scope %q", scope)})
+			return
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expiresAt must be in the future"})
+		return
+	}
+
+	rawKey, err := generateToken(apiKeyPrefix)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate API key"})
+		return
+	}
+
+	key := models.APIKey{
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    rawKey[:len(apiKeyPrefix)+6],
+		KeyHash:   hashToken(rawKey),
+		Scopes:    req.Scopes,
+		CreatedBy: c.GetString(middleware.AdminIDKey),
+		CreatedAt: time.Now(),
+		ExpiresAt: req.ExpiresAt,
+	}
+
+	docRef, _, err := h.db.Collection("api_keys").Add(h.db.Context(), key)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	key.ID = docRef.ID
+	h.recordAudit(c, "api_key.create", "api_key", key.ID, nil, key)
+	c.JSON(http.StatusCreated, CreateAPIKeyResponse{APIKey: key, Key: rawKey})
+}
+
+// RevokeAPIKey keeps the record for auditing but stops the key from authenticating
+func (h *Handlers) RevokeAPIKey(c *gin.Context) {
+	id := c.Param("id")
+	now := time.Now()
+	_, err := h.db.Collection("api_keys").Doc(id).Update(h.db.Context(), []firestore.Update{
+		{Path: "revokedAt", Value: now},
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+		return
+	}
+
+	h.recordAudit(c, "api_key.revoke", "api_key", id, nil, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked successfully"})
+}
+
+// ValidateAPIKey implements middleware.APIKeyValidator. Keys belong to a workshop, so
+// they are looked up in the workshop the request is scoped to.
+func (h *Handlers) ValidateAPIKey(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	if scoped := h.scopedFrom(ctx); scoped != h {
+		return scoped.ValidateAPIKey(ctx, rawKey)
+	}
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, fmt.Errorf("malformed API key")
+	}
+
+	iter := h.db.Collection("api_keys").Where("keyHash", "==", hashToken(rawKey)).Limit(1).Documents(ctx)
+	doc, err := iter.Next()
+	if err == iterator.Done {
+		return nil, fmt.Errorf("unknown API key")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var key models.APIKey
+	if err := doc.DataTo(&key); err != nil {
+		return nil, err
+	}
+	key.ID = doc.Ref.ID
+
+	now := time.Now()
+	if key.RevokedAt != nil {
+		return nil, fmt.Errorf("API key revoked")
+	}
+	if key.ExpiresAt != nil && now.After(*key.ExpiresAt) {
+		return nil, fmt.Errorf("API key expired")
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > lastUsedInterval {
+		// Usage tracking is best effort and must not fail the request
+		doc.Ref.Update(ctx, []firestore.Update{{Path: "lastUsedAt", Value: now}})
+		key.LastUsedAt = &now
+	}
+
+	return &key, nil
+}
+
+// generateToken returns a random bearer credential such as an API key or registration token
+func generateToken(prefix string) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return prefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"appdirect-workshop-backend/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAPIKeyValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	past := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name        string
+		requestBody map[string]interface{}
+	}{
+		{
+			name:        "missing name",
+			requestBody: map[string]interface{}{"scopes": []string{"attendees:read"}},
+		},
+		{
+			name:        "missing scopes",
+			requestBody: map[string]interface{}{"name": "CRM sync", "scopes": []string{}},
+		},
+		{
+			name:        "unknown scope",
+			requestBody: map[string]interface{}{"name": "CRM sync", "scopes": []string{"api-keys:write"}},
+		},
+		{
+			name:        "expiry in the past",
+			requestBody: map[string]interface{}{"name": "CRM sync", "scopes": []string{"attendees:read"}, "expiresAt": past},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				AdminPassword:   "test-password",
+				SubcollectionID: "test-collection",
+			}
+			h := New(createMockDB(), cfg)
+
+			router := gin.New()
+			router.POST("/api/admin/api-keys", h.CreateAPIKey)
+
+			body, _ := json.Marshal(tt.requestBody)
+			req, _ := http.NewRequest("POST", "/api/admin/api-keys", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, err := generateToken(apiKeyPrefix)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, apiKeyPrefix))
+
+	other, _ := generateToken(apiKeyPrefix)
+	assert.NotEqual(t, key, other)
+	assert.NotEqual(t, hashToken(key), hashToken(other))
+	assert.Equal(t, hashToken(key), hashToken(key))
+}
+
+func TestValidateAPIKeyMalformed(t *testing.T) {
+	h := New(createMockDB(), &config.Config{AdminPassword: "test-password"})
+
+	_, err := h.ValidateAPIKey(context.Background(), "not-a-key")
+	assert.Error(t, err)
+}
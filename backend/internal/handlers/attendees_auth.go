@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"appdirect-workshop-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	// RegistrationTokenHeader carries the token an attendee received when registering
+	RegistrationTokenHeader = "X-Registration-Token"
+	// AttendeeKey holds the authenticated *models.Registration in the gin context
+	AttendeeKey = "attendee"
+
+	registrationTokenPrefix = "wrt_"
+)
+
+var errUnknownRegistrationToken = errors.New("unknown registration token")
+
+// RequireAttendee authenticates attendee routes by registration token. It runs after
+// ResolveWorkshop, since tokens are looked up in the resolved workshop.
+func (h *Handlers) RequireAttendee() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader(RegistrationTokenHeader)
+		if !strings.HasPrefix(token, registrationTokenPrefix) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Registration token required"})
+			c.Abort()
+			return
+		}
+
+		reg, err := h.scopedFrom(c.Request.Context()).registrationByToken(token)
+		if errors.Is(err, errUnknownRegistrationToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid registration token"})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify registration token"})
+			c.Abort()
+			return
+		}
+
+		c.Set(AttendeeKey, reg)
+		c.Next()
+	}
+}
+
+// AttendeeID returns the registration ID set by RequireAttendee, for keying rate limits
+func AttendeeID(c *gin.Context) string {
+	if reg, ok := c.Get(AttendeeKey); ok {
+		return reg.(*models.Registration).ID
+	}
+	return ""
+}
+
+func (h *Handlers) registrationByToken(token string) (*models.Registration, error) {
+	iter := h.db.Collection("registrations").Where("tokenHash", "==", hashToken(token)).Limit(1).Documents(h.db.Context())
+	defer iter.Stop()
+	doc, err := iter.Next()
+	if err == iterator.Done {
+		return nil, errUnknownRegistrationToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var reg models.Registration
+	if err := doc.DataTo(&reg); err != nil {
+		return nil, err
+	}
+	reg.ID = doc.Ref.ID
+	return &reg, nil
+}
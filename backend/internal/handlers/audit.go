@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"appdirect-workshop-backend/internal/middleware"
+	"appdirect-workshop-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 200
+)
+
+type AuditPage struct {
+	Entries    []models.AuditEntry `json:"entries"`
+	NextCursor string              `json:"nextCursor,omitempty"`
+}
+
+// recordAudit writes an audit entry for a successful change. before and after are
+// the target's state around the change (nil for creates and deletes respectively).
+// A failed write is logged rather than failing a change that has already happened.
+func (h *Handlers) recordAudit(c *gin.Context, action, targetType, targetID string, before, after interface{}) {
+	// Speakers editing their own profile are recorded as speaker:<id>
+	actor := c.GetString(middleware.AdminIDKey)
+	if id := SpeakerID(c); actor == "" && id != "" {
+		actor = "speaker:" + id
+	}
+	entry := models.AuditEntry{
+		Actor:      actor,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Changes:    diffFields(before, after),
+		IP:         middleware.ClientIP(c),
+		CreatedAt:  time.Now(),
+	}
+
+	if _, _, err := h.db.Collection("audit_log").Add(h.db.Context(), entry); err != nil {
+		log.Printf("Failed to write audit entry for %s %s/%s: %v", action, targetType, targetID, err)
+	}
+}
+
+// getSnapshot loads a document as a generic map for auditing, or nil if it does not exist
+func (h *Handlers) getSnapshot(collection, id string) map[string]interface{} {
+	doc, err := h.db.Collection(collection).Doc(id).Get(h.db.Context())
+	if err != nil {
+		return nil
+	}
+	return doc.Data()
+}
+
+// diffFields returns the top-level fields that differ between before and after
+func diffFields(before, after interface{}) map[string]models.AuditChange {
+	b := toFieldMap(before)
+	a := toFieldMap(after)
+
+	changes := make(map[string]models.AuditChange)
+	for key, bv := range b {
+		if av, ok := a[key]; !ok || !reflect.DeepEqual(bv, av) {
+			changes[key] = models.AuditChange{Before: bv, After: a[key]}
+		}
+	}
+	for key, av := range a {
+		if _, ok := b[key]; !ok {
+			changes[key] = models.AuditChange{Before: nil, After: av}
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+// toFieldMap normalizes a model or Firestore map into JSON-shaped fields, dropping IDs
+func toFieldMap(v interface{}) map[string]interface{} {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Map && reflect.ValueOf(v).IsNil()) {
+		return map[string]interface{}{}
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return map[string]interface{}{}
+	}
+	fields := map[string]interface{}{}
+	json.Unmarshal(data, &fields)
+	delete(fields, "id")
+	return fields
+}
+
+// GetAuditLog lists audit entries newest first, filtered by actor, action, targetType,
+// targetId and a from/to time range (RFC 3339), paginated with limit and cursor
+func (h *Handlers) GetAuditLog(c *gin.Context) {
+	limit := defaultAuditPageSize
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		if n > maxAuditPageSize {
+			n = maxAuditPageSize
+		}
+		limit = n
+	}
+
+	timeRange := map[string]time.Time{}
+	for _, param := range []string{"from", "to"} {
+		if raw := c.Query(param); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be an RFC 3339 timestamp"})
+				return
+			}
+			timeRange[param] = t
+		}
+	}
+
+	query := h.db.Collection("audit_log").Query
+	for _, field := range []string{"actor", "action", "targetType", "targetId"} {
+		if value := c.Query(field); value != "" {
+			query = query.Where(field, "==", value)
+		}
+	}
+	if from, ok := timeRange["from"]; ok {
+		query = query.Where("createdAt", ">=", from)
+	}
+	if to, ok := timeRange["to"]; ok {
+		query = query.Where("createdAt", "<=", to)
+	}
+
+	query = query.OrderBy("createdAt", firestore.Desc)
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		doc, err := h.db.Collection("audit_log").Doc(cursor).Get(h.db.Context())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+		query = query.StartAfter(doc)
+	}
+
+	// Fetch one extra entry to know whether another page exists
+	entries := make([]models.AuditEntry, 0, limit)
+	iter := query.Limit(limit + 1).Documents(h.db.Context())
+	hasMore := false
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log"})
+			return
+		}
+		if len(entries) == limit {
+			hasMore = true
+			break
+		}
+
+		var entry models.AuditEntry
+		if err := doc.DataTo(&entry); err != nil {
+			continue
+		}
+		entry.ID = doc.Ref.ID
+		entries = append(entries, entry)
+	}
+
+	page := AuditPage{Entries: entries}
+	if hasMore && len(entries) > 0 {
+		page.NextCursor = entries[len(entries)-1].ID
+	}
+	c.JSON(http.StatusOK, page)
+}
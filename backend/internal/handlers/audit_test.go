@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"appdirect-workshop-backend/internal/config"
+	"appdirect-workshop-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffFields(t *testing.T) {
+	before := map[string]interface{}{
+		"name":       "Jane Doe",
+		"bio":        "Old bio",
+		"twitterUrl": "https://twitter.com/jane",
+	}
+	after := models.Speaker{
+		ID:          "speaker-1",
+		Name:        "Jane Doe",
+		Bio:         "New bio",
+		LinkedInURL: "https://linkedin.com/in/jane",
+	}
+
+	changes := diffFields(before, after)
+	assert.Len(t, changes, 3)
+	assert.Equal(t, models.AuditChange{Before: "Old bio", After: "New bio"}, changes["bio"])
+	assert.Equal(t, models.AuditChange{Before: "https://twitter.com/jane", After: nil}, changes["twitterUrl"])
+	assert.Equal(t, models.AuditChange{Before: nil, After: "https://linkedin.com/in/jane"}, changes["linkedinUrl"])
+	assert.NotContains(t, changes, "id")
+
+	// Creates record every field, deletes record every removed field
+	assert.Len(t, diffFields(nil, after), 3)
+	assert.Len(t, diffFields(before, nil), 3)
+	assert.Nil(t, diffFields(before, before))
+}
+
+func TestGetAuditLogValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "invalid limit", query: "?limit=abc"},
+		{name: "zero limit", query: "?limit=0"},
+		{name: "invalid from", query: "?from=yesterday"},
+		{name: "invalid to", query: "?to=2024-13-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				AdminPassword:   "test-password",
+				SubcollectionID: "test-collection",
+			}
+			h := New(createMockDB(), cfg)
+
+			router := gin.New()
+			router.GET("/api/admin/audit", h.GetAuditLog)
+
+			req, _ := http.NewRequest("GET", "/api/admin/audit"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
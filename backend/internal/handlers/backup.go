@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"appdirect-workshop-backend/internal/backup"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRestoreBytes bounds uploaded archives
+const maxRestoreBytes = 50 << 20
+
+// GetBackup downloads the workshop's registrations, speakers and sessions as a JSON archive
+func (h *Handlers) GetBackup(c *gin.Context) {
+	archive, err := backup.Export(h.db, h.cfg.SubcollectionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create backup"})
+		return
+	}
+
+	filename := fmt.Sprintf("%s-backup-%s.json", h.cfg.SubcollectionID, time.Now().UTC().Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, archive)
+}
+
+// RestoreBackup imports an archive into this workshop, or into the workshop named by
+// the workshop query parameter. conflict decides what happens to IDs that already
+// exist: fail (default, nothing is written), skip or overwrite.
+func (h *Handlers) RestoreBackup(c *gin.Context) {
+	conflict := c.DefaultQuery("conflict", backup.ConflictFail)
+	if !backup.ValidConflictMode(conflict) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "conflict must be fail, skip or overwrite"})
+		return
+	}
+
+	workshopID := c.DefaultQuery("workshop", h.cfg.SubcollectionID)
+	if !backup.ValidWorkshopID(workshopID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workshop ID"})
+		return
+	}
+
+	if workshopID != h.cfg.SubcollectionID && !h.isSuperAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only super admins can restore into another workshop"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRestoreBytes)
+	var archive backup.Archive
+	if err := c.ShouldBindJSON(&archive); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid archive: " + err.Error()})
+		return
+	}
+	if err := backup.Validate(&archive); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := h.db
+	if workshopID != h.cfg.SubcollectionID {
+		db = h.db.ForWorkshop(workshopID)
+	}
+
+	result, err := backup.Restore(db, workshopID, &archive, conflict)
+	if errors.Is(err, backup.ErrConflict) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     "Archive contains IDs that already exist; retry with conflict=skip or conflict=overwrite",
+			"conflicts": result.Conflicts,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore backup", "result": result})
+		return
+	}
+
+	h.recordAudit(c, "workshop.restore", "workshop", workshopID, nil, gin.H{
+		"sourceWorkshopId": archive.WorkshopID,
+		"conflict":         conflict,
+		"collections":      result.Collections,
+	})
+	c.JSON(http.StatusOK, result)
+}
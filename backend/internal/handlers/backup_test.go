@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"appdirect-workshop-backend/internal/config"
+	"appdirect-workshop-backend/internal/database/firestoretest"
+	"appdirect-workshop-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreBackupValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name  string
+		query string
+		body  string
+	}{
+		{name: "invalid conflict mode", query: "?conflict=merge", body: `{"version":1}`},
+		{name: "invalid workshop", query: "?workshop=a/b", body: `{"version":1}`},
+		{name: "malformed JSON", body: `{"version":`},
+		{name: "unsupported version", body: `{"version":99}`},
+		{name: "duplicate IDs", body: `{"version":1,"speakers":[{"id":"s1"},{"id":"s1"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				AdminPassword:   "test-password",
+				SubcollectionID: "test-collection",
+			}
+			h := New(createMockDB(), cfg)
+
+			router := gin.New()
+			router.POST("/api/admin/restore", h.RestoreBackup)
+
+			req, _ := http.NewRequest("POST", "/api/admin/restore"+tt.query, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestBackupRestoreKeepsRegistrationTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := firestoretest.NewDatabase(t, "test-collection")
+	h := New(db, &config.Config{AdminPassword: "test-password", SubcollectionID: "test-collection"})
+	token, err := generateToken(registrationTokenPrefix)
+	require.NoError(t, err)
+	ref := db.Collection("registrations").Doc("reg1")
+	_, err = ref.Set(db.Context(), models.Registration{
+		Name:        "Jane",
+		Email:       "jane@example.com",
+		Designation: "Engineer",
+		CreatedAt:   time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+		TokenHash:   hashToken(token),
+	})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/api/admin/backup", h.GetBackup)
+	router.POST("/api/admin/restore", h.RestoreBackup)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/admin/backup", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	archive := w.Body.Bytes()
+
+	// Overwriting replaces the whole document, so the hash must come from the archive
+	_, err = ref.Set(db.Context(), models.Registration{Name: "Jane", Email: "jane@example.com"})
+	require.NoError(t, err)
+	_, err = h.registrationByToken(token)
+	require.ErrorIs(t, err, errUnknownRegistrationToken)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/admin/restore?conflict=overwrite", bytes.NewReader(archive))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	reg, err := h.registrationByToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "reg1", reg.ID)
+	assert.Equal(t, "Engineer", reg.Designation)
+}
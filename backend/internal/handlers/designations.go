@@ -0,0 +1,403 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"appdirect-workshop-backend/internal/designation"
+	"appdirect-workshop-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	// applyBatchSize keeps each retroactive update well under Firestore's 500 writes
+	applyBatchSize = 250
+	// designationCacheTTL bounds how long another instance's taxonomy edits take to
+	// reach registrations
+	designationCacheTTL = 30 * time.Second
+)
+
+type DesignationRequest struct {
+	Name     string   `json:"name" binding:"required,max=100"`
+	Synonyms []string `json:"synonyms" binding:"max=100,dive,max=100"`
+	Patterns []string `json:"patterns" binding:"max=20"`
+}
+
+type ApplyDesignationsResult struct {
+	Total    int `json:"total"`
+	Updated  int `json:"updated"`
+	Unmapped int `json:"unmapped"`
+}
+
+// designationCache keeps each workshop's normalizer so registering doesn't read the
+// whole taxonomy. Edits on this instance invalidate it; other instances' edits show
+// up within designationCacheTTL.
+type designationCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedNormalizer
+}
+
+type cachedNormalizer struct {
+	normalizer *designation.Normalizer
+	expires    time.Time
+}
+
+func newDesignationCache() *designationCache {
+	return &designationCache{entries: make(map[string]cachedNormalizer)}
+}
+
+func (dc *designationCache) get(workshopID string) (*designation.Normalizer, bool) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	entry, ok := dc.entries[workshopID]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.normalizer, true
+}
+
+func (dc *designationCache) put(workshopID string, normalizer *designation.Normalizer) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.entries[workshopID] = cachedNormalizer{normalizer: normalizer, expires: time.Now().Add(designationCacheTTL)}
+}
+
+func (dc *designationCache) invalidate(workshopID string) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	delete(dc.entries, workshopID)
+}
+
+// designationTaxonomy lists the taxonomy in name order
+func (h *Handlers) designationTaxonomy() ([]models.Designation, error) {
+	taxonomy := make([]models.Designation, 0)
+	iter := h.db.Collection("designations").OrderBy("name", firestore.Asc).Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return taxonomy, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var entry models.Designation
+		if err := doc.DataTo(&entry); err != nil {
+			continue
+		}
+		entry.ID = doc.Ref.ID
+		taxonomy = append(taxonomy, entry)
+	}
+}
+
+func (h *Handlers) designationNormalizer() (*designation.Normalizer, error) {
+	taxonomy, err := h.designationTaxonomy()
+	if err != nil {
+		return nil, err
+	}
+	return designation.New(taxonomy)
+}
+
+// cachedDesignationNormalizer is designationNormalizer through the designation cache
+func (h *Handlers) cachedDesignationNormalizer() (*designation.Normalizer, error) {
+	if normalizer, ok := h.designations.get(h.cfg.SubcollectionID); ok {
+		return normalizer, nil
+	}
+	normalizer, err := h.designationNormalizer()
+	if err != nil {
+		return nil, err
+	}
+	h.designations.put(h.cfg.SubcollectionID, normalizer)
+	return normalizer, nil
+}
+
+// canonicalDesignation maps a new registration's designation. If the taxonomy can't be
+// read, registration goes ahead with the designation as typed; applying the taxonomy
+// later corrects it.
+func (h *Handlers) canonicalDesignation(raw string) string {
+	normalizer, err := h.cachedDesignationNormalizer()
+	if err != nil {
+		log.Printf("Failed to load designation taxonomy: %v", err)
+		return strings.Join(strings.Fields(raw), " ")
+	}
+	canonical, _ := normalizer.Canonical(raw)
+	return canonical
+}
+
+func (h *Handlers) GetDesignations(c *gin.Context) {
+	taxonomy, err := h.designationTaxonomy()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch designations"})
+		return
+	}
+	c.JSON(http.StatusOK, taxonomy)
+}
+
+func (h *Handlers) CreateDesignation(c *gin.Context) {
+	var req DesignationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	entry, ok := req.designation(c)
+	if !ok {
+		return
+	}
+
+	taxonomy, err := h.designationTaxonomy()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch designations"})
+		return
+	}
+	if _, err := designation.New(append(taxonomy, entry)); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	docRef, _, err := h.db.Collection("designations").Add(h.db.Context(), entry)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create designation"})
+		return
+	}
+
+	entry.ID = docRef.ID
+	h.designations.invalidate(h.cfg.SubcollectionID)
+	h.recordAudit(c, "designation.create", "designation", entry.ID, nil, entry)
+	c.JSON(http.StatusCreated, entry)
+}
+
+func (h *Handlers) UpdateDesignation(c *gin.Context) {
+	id := c.Param("id")
+
+	var req DesignationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	entry, ok := req.designation(c)
+	if !ok {
+		return
+	}
+	entry.ID = id
+
+	taxonomy, err := h.designationTaxonomy()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch designations"})
+		return
+	}
+	var before *models.Designation
+	for i := range taxonomy {
+		if taxonomy[i].ID == id {
+			previous := taxonomy[i]
+			before = &previous
+			taxonomy[i] = entry
+		}
+	}
+	if before == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Designation not found"})
+		return
+	}
+	if _, err := designation.New(taxonomy); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.db.Collection("designations").Doc(id).Set(h.db.Context(), entry); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update designation"})
+		return
+	}
+
+	h.designations.invalidate(h.cfg.SubcollectionID)
+	h.recordAudit(c, "designation.update", "designation", id, before, entry)
+	c.JSON(http.StatusOK, entry)
+}
+
+func (h *Handlers) DeleteDesignation(c *gin.Context) {
+	id := c.Param("id")
+	before := h.getSnapshot("designations", id)
+	if before == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Designation not found"})
+		return
+	}
+
+	if _, err := h.db.Collection("designations").Doc(id).Delete(h.db.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete designation"})
+		return
+	}
+
+	h.designations.invalidate(h.cfg.SubcollectionID)
+	h.recordAudit(c, "designation.delete", "designation", id, before, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Designation deleted successfully"})
+}
+
+// ApplyDesignations maps every existing registration through the current taxonomy and
+// stores the result as its canonical designation
+func (h *Handlers) ApplyDesignations(c *gin.Context) {
+	normalizer, err := h.designationNormalizer()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load designation taxonomy"})
+		return
+	}
+	// New registrations map the same way as the ones updated here
+	h.designations.put(h.cfg.SubcollectionID, normalizer)
+
+	var result ApplyDesignationsResult
+	var pending []firestore.Update
+	var pendingRefs []*firestore.DocumentRef
+	flush := func() error {
+		if len(pendingRefs) == 0 {
+			return nil
+		}
+		err := h.db.RunTransaction(func(ctx context.Context, tx *firestore.Transaction) error {
+			for i, ref := range pendingRefs {
+				if err := tx.Update(ref, []firestore.Update{pending[i]}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		result.Updated += len(pendingRefs)
+		pending, pendingRefs = pending[:0], pendingRefs[:0]
+		return nil
+	}
+
+	iter := h.db.Collection("registrations").Select("designation", "canonicalDesignation").Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch registrations"})
+			return
+		}
+
+		raw, _ := doc.Data()["designation"].(string)
+		stored, _ := doc.Data()["canonicalDesignation"].(string)
+		result.Total++
+
+		canonical, ok := normalizer.Canonical(raw)
+		if !ok {
+			result.Unmapped++
+		}
+		if canonical == stored {
+			continue
+		}
+
+		update := firestore.Update{Path: "canonicalDesignation", Value: canonical}
+		if canonical == "" {
+			update.Value = firestore.Delete
+		}
+		pending = append(pending, update)
+		pendingRefs = append(pendingRefs, doc.Ref)
+		if len(pendingRefs) == applyBatchSize {
+			if err := flush(); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update registrations", "updated": result.Updated})
+				return
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update registrations", "updated": result.Updated})
+		return
+	}
+
+	if result.Updated > 0 {
+		h.recordAudit(c, "designation.apply", "registration", "", nil, result)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// designation trims the request and drops empty or repeated aliases. Patterns are
+// compiled here so bad ones are reported as validation errors.
+func (req *DesignationRequest) designation(c *gin.Context) (models.Designation, bool) {
+	entry := models.Designation{
+		Name:     strings.Join(strings.Fields(req.Name), " "),
+		Synonyms: make([]string, 0, len(req.Synonyms)),
+		Patterns: make([]string, 0, len(req.Patterns)),
+	}
+	if entry.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return entry, false
+	}
+
+	seen := map[string]bool{designation.Fold(entry.Name): true}
+	for _, synonym := range req.Synonyms {
+		synonym = strings.Join(strings.Fields(synonym), " ")
+		if key := designation.Fold(synonym); key != "" && !seen[key] {
+			seen[key] = true
+			entry.Synonyms = append(entry.Synonyms, synonym)
+		}
+	}
+	for _, expr := range req.Patterns {
+		if strings.TrimSpace(expr) == "" {
+			continue
+		}
+		if _, err := designation.CompilePattern(expr); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return entry, false
+		}
+		entry.Patterns = append(entry.Patterns, expr)
+	}
+	return entry, true
+}
+
+// designationBreakdown groups registrations by canonical designation, with the ones no
+// entry matches in an Unmapped bucket. Without a taxonomy it groups by the designation
+// as typed, ignoring case and extra spaces. Largest groups come first.
+func designationBreakdown(normalizer *designation.Normalizer, designations []string) []models.DesignationBreakdown {
+	counts := map[string]int{}
+	labels := map[string]string{}
+	unmapped := models.DesignationBreakdown{Designation: designation.Unmapped, Unmapped: true}
+	unmappedValues := map[string]bool{}
+
+	for _, raw := range designations {
+		if normalizer.Empty() {
+			key := designation.Fold(raw)
+			if _, ok := labels[key]; !ok {
+				labels[key] = strings.Join(strings.Fields(raw), " ")
+			}
+			counts[key]++
+			continue
+		}
+		if canonical, ok := normalizer.Canonical(raw); ok {
+			labels[canonical] = canonical
+			counts[canonical]++
+			continue
+		}
+		unmapped.Count++
+		if value := strings.Join(strings.Fields(raw), " "); !unmappedValues[value] {
+			unmappedValues[value] = true
+			unmapped.Values = append(unmapped.Values, value)
+		}
+	}
+
+	breakdown := make([]models.DesignationBreakdown, 0, len(counts)+1)
+	for key, count := range counts {
+		breakdown = append(breakdown, models.DesignationBreakdown{Designation: labels[key], Count: count})
+	}
+	sort.Slice(breakdown, func(i, j int) bool {
+		if breakdown[i].Count != breakdown[j].Count {
+			return breakdown[i].Count > breakdown[j].Count
+		}
+		return breakdown[i].Designation < breakdown[j].Designation
+	})
+	if unmapped.Count > 0 {
+		sort.Strings(unmapped.Values)
+		breakdown = append(breakdown, unmapped)
+	}
+	return breakdown
+}
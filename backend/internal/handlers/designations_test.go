@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"appdirect-workshop-backend/internal/config"
+	"appdirect-workshop-backend/internal/database/firestoretest"
+	"appdirect-workshop-backend/internal/designation"
+	"appdirect-workshop-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDesignationBreakdown(t *testing.T) {
+	registrations := []string{"SDE", "Software Engineer", "software engineer ", "PM", "Student", "student", "Astronaut"}
+
+	t.Run("grouped by canonical designation", func(t *testing.T) {
+		normalizer, err := designation.New([]models.Designation{
+			{Name: "Software Engineer", Synonyms: []string{"SDE"}},
+			{Name: "Product Manager", Synonyms: []string{"PM"}},
+		})
+		require.NoError(t, err)
+
+		breakdown := designationBreakdown(normalizer, registrations)
+		assert.Equal(t, []models.DesignationBreakdown{
+			{Designation: "Software Engineer", Count: 3},
+			{Designation: "Product Manager", Count: 1},
+			{Designation: designation.Unmapped, Count: 3, Unmapped: true, Values: []string{"Astronaut", "Student", "student"}},
+		}, breakdown)
+	})
+
+	t.Run("case-folded without a taxonomy", func(t *testing.T) {
+		normalizer, err := designation.New(nil)
+		require.NoError(t, err)
+
+		breakdown := designationBreakdown(normalizer, registrations)
+		assert.Equal(t, []models.DesignationBreakdown{
+			{Designation: "Software Engineer", Count: 2},
+			{Designation: "Student", Count: 2},
+			{Designation: "Astronaut", Count: 1},
+			{Designation: "PM", Count: 1},
+			{Designation: "SDE", Count: 1},
+		}, breakdown)
+	})
+}
+
+func TestDesignationRequestValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "missing name", body: `{"synonyms":["SDE"]}`},
+		{name: "blank name", body: `{"name":"  "}`},
+		{name: "invalid pattern", body: `{"name":"Software Engineer","patterns":["(dev"]}`},
+		{name: "pattern too long", body: `{"name":"Software Engineer","patterns":["` + string(bytes.Repeat([]byte("a"), 201)) + `"]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkshopTestHandlers()
+			router := gin.New()
+			router.POST("/api/admin/designations", h.CreateDesignation)
+
+			req, _ := http.NewRequest("POST", "/api/admin/designations", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestDesignationRequestCleansAliases(t *testing.T) {
+	req := DesignationRequest{
+		Name:     "  Software   Engineer ",
+		Synonyms: []string{"SDE", " sde ", "", "software engineer", "Developer"},
+		Patterns: []string{"", `backend (engineer|developer)`},
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	entry, ok := req.designation(c)
+	require.True(t, ok)
+	assert.Equal(t, "Software Engineer", entry.Name)
+	assert.Equal(t, []string{"SDE", "Developer"}, entry.Synonyms)
+	assert.Equal(t, []string{`backend (engineer|developer)`}, entry.Patterns)
+}
+
+func TestCanonicalDesignationCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := firestoretest.NewDatabase(t, "test-collection")
+	ref := db.Collection("designations").Doc("swe")
+	_, err := ref.Set(db.Context(), models.Designation{Name: "Software Engineer", Synonyms: []string{"SDE"}})
+	require.NoError(t, err)
+	h := New(db, &config.Config{AdminPassword: "test-password", SubcollectionID: "test-collection"})
+
+	assert.Equal(t, "Software Engineer", h.canonicalDesignation("sde"))
+
+	// Registering reads the cached taxonomy rather than the collection
+	_, err = ref.Delete(db.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "Software Engineer", h.canonicalDesignation("sde"))
+
+	// Admin edits invalidate it
+	router := gin.New()
+	router.POST("/api/admin/designations", h.CreateDesignation)
+	req, _ := http.NewRequest("POST", "/api/admin/designations", bytes.NewBufferString(`{"name":"Product Manager","synonyms":["PM"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	assert.Equal(t, "", h.canonicalDesignation("sde"))
+	assert.Equal(t, "Product Manager", h.canonicalDesignation("pm"))
+
+	// A taxonomy that can't be read leaves the designation as typed
+	h.designations.invalidate("test-collection")
+	require.NoError(t, db.Close())
+	assert.Equal(t, "Staff Engineer", h.canonicalDesignation(" Staff  Engineer "))
+}
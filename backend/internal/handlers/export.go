@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"appdirect-workshop-backend/internal/models"
+	"appdirect-workshop-backend/internal/xlsx"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportDateLayout is used for createdAt in CSV exports; spreadsheets parse it as a date
+const exportDateLayout = "2006-01-02 15:04:05"
+
+type exportColumn struct {
+	header string
+	value  func(reg models.Registration, loc *time.Location) interface{}
+}
+
+var exportColumns = map[string]exportColumn{
+	"id":                   {"ID", func(reg models.Registration, _ *time.Location) interface{} { return reg.ID }},
+	"name":                 {"Name", func(reg models.Registration, _ *time.Location) interface{} { return reg.Name }},
+	"email":                {"Email", func(reg models.Registration, _ *time.Location) interface{} { return reg.Email }},
+	"designation":          {"Designation", func(reg models.Registration, _ *time.Location) interface{} { return reg.Designation }},
+	"canonicalDesignation": {"Canonical Designation", func(reg models.Registration, _ *time.Location) interface{} { return reg.CanonicalDesignation }},
+	"createdAt":            {"Registered At", func(reg models.Registration, loc *time.Location) interface{} { return reg.CreatedAt.In(loc) }},
+}
+
+var defaultExportColumns = []string{"name", "email", "designation", "createdAt"}
+
+// ExportAttendees streams attendees as CSV or XLSX. Query parameters: format (csv or
+// xlsx), columns (comma-separated, in order), tz (IANA zone for createdAt, default UTC),
+// plus the same filters as GetAttendees.
+func (h *Handlers) ExportAttendees(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or xlsx"})
+		return
+	}
+
+	columns := defaultExportColumns
+	if raw := c.Query("columns"); raw != "" {
+		columns = splitColumns(raw)
+		if len(columns) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "columns must list at least one column"})
+			return
+		}
+		for _, col := range columns {
+			if _, ok := exportColumns[col]; !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown column %q", col)})
+				return
+			}
+		}
+	}
+
+	loc, err := time.LoadLocation(c.DefaultQuery("tz", "UTC"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tz must be an IANA time zone such as Europe/Berlin"})
+		return
+	}
+
+	filter, err := parseAttendeeFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	headers := make([]string, len(columns))
+	for i, col := range columns {
+		headers[i] = exportColumns[col].header
+		if col == "createdAt" {
+			headers[i] += " (" + loc.String() + ")"
+		}
+	}
+
+	filename := fmt.Sprintf("attendees-%s.%s", time.Now().In(loc).Format("20060102"), format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+
+	if format == "xlsx" {
+		err = h.exportXLSX(c, filter, columns, headers, loc)
+	} else {
+		err = h.exportCSV(c, filter, columns, headers, loc)
+	}
+	if err != nil {
+		// Rows may already be on the wire, so the status can't change; cut the download short
+		log.Printf("Attendee export failed: %v", err)
+		c.Abort()
+	}
+}
+
+func (h *Handlers) exportCSV(c *gin.Context, filter attendeeFilter, columns, headers []string, loc *time.Location) error {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	if err := w.Write(headers); err != nil {
+		return err
+	}
+	err := h.forEachAttendee(filter, func(reg models.Registration) error {
+		record := make([]string, len(columns))
+		for i, col := range columns {
+			switch v := exportColumns[col].value(reg, loc).(type) {
+			case time.Time:
+				// Left empty for registrations without a time, as in XLSX
+				if !v.IsZero() {
+					record[i] = v.Format(exportDateLayout)
+				}
+			case string:
+				record[i] = escapeCSVCell(v)
+			}
+		}
+		return w.Write(record)
+	})
+	w.Flush()
+	if err != nil {
+		return err
+	}
+	return w.Error()
+}
+
+func (h *Handlers) exportXLSX(c *gin.Context, filter attendeeFilter, columns, headers []string, loc *time.Location) error {
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Status(http.StatusOK)
+
+	w, err := xlsx.NewWriter(c.Writer, "Attendees")
+	if err != nil {
+		return err
+	}
+	headerRow := make([]interface{}, len(headers))
+	for i, header := range headers {
+		headerRow[i] = header
+	}
+	if err := w.WriteRow(headerRow...); err != nil {
+		return err
+	}
+
+	// Inline strings are never evaluated as formulas, so cells need no escaping here
+	err = h.forEachAttendee(filter, func(reg models.Registration) error {
+		row := make([]interface{}, len(columns))
+		for i, col := range columns {
+			row[i] = exportColumns[col].value(reg, loc)
+		}
+		return w.WriteRow(row...)
+	})
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// escapeCSVCell defuses values a spreadsheet would otherwise run as a formula
+// (CSV injection) by prefixing them with a single quote
+func escapeCSVCell(value string) string {
+	if value == "" {
+		return value
+	}
+	switch value[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + value
+	}
+	return value
+}
+
+func splitColumns(raw string) []string {
+	var columns []string
+	for _, col := range strings.Split(raw, ",") {
+		if col = strings.TrimSpace(col); col != "" {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"appdirect-workshop-backend/internal/config"
+	"appdirect-workshop-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapeCSVCell(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"Jane Doe", "Jane Doe"},
+		{"", ""},
+		{"=HYPERLINK(\"http://evil\")", "'=HYPERLINK(\"http://evil\")"},
+		{"+1234", "'+1234"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tcmd", "'\tcmd"},
+		{"a=b", "a=b"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, escapeCSVCell(tt.value))
+	}
+}
+
+func TestAttendeeFilterMatches(t *testing.T) {
+	reg := models.Registration{Name: "Jane Doe", Email: "jane@example.com", Designation: "Engineer"}
+
+	tests := []struct {
+		name     string
+		filter   attendeeFilter
+		expected bool
+	}{
+		{name: "no filter", filter: attendeeFilter{}, expected: true},
+		{name: "designation ignores case", filter: attendeeFilter{designation: "engineer"}, expected: true},
+		{name: "other designation", filter: attendeeFilter{designation: "Manager"}, expected: false},
+		{name: "search by name", filter: attendeeFilter{search: "doe"}, expected: true},
+		{name: "search by email", filter: attendeeFilter{search: "example.com"}, expected: true},
+		{name: "search miss", filter: attendeeFilter{search: "smith"}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.filter.matches(reg))
+		})
+	}
+}
+
+func TestExportAttendeesValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "unknown format", query: "?format=pdf"},
+		{name: "unknown column", query: "?columns=name,password"},
+		{name: "unknown time zone", query: "?tz=Mars/Olympus"},
+		{name: "invalid from", query: "?from=last-week"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				AdminPassword:   "test-password",
+				SubcollectionID: "test-collection",
+			}
+			h := New(createMockDB(), cfg)
+
+			router := gin.New()
+			router.GET("/api/admin/attendees/export", h.ExportAttendees)
+
+			req, _ := http.NewRequest("GET", "/api/admin/attendees/export"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"appdirect-workshop-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type FeedbackRequest struct {
+	Rating  int    `json:"rating" binding:"required,min=1,max=5"`
+	Comment string `json:"comment" binding:"max=2000"`
+}
+
+type FeedbackSummary struct {
+	SessionID    string  `json:"sessionId"`
+	SessionTitle string  `json:"sessionTitle"`
+	Count        int     `json:"count"`
+	Average      float64 `json:"average"`
+	// Histogram[i] counts ratings of i+1
+	Histogram [5]int `json:"histogram"`
+}
+
+type SessionFeedbackReport struct {
+	FeedbackSummary
+	// Comments lists feedback with a comment, newest first
+	Comments []models.SessionFeedback `json:"comments"`
+}
+
+// SubmitSessionFeedback records the authenticated attendee's rating of a session
+func (h *Handlers) SubmitSessionFeedback(c *gin.Context) {
+	attendee := c.MustGet(AttendeeKey).(*models.Registration)
+	sessionID := c.Param("id")
+
+	var req FeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !h.sessionExists(c, sessionID) {
+		return
+	}
+
+	feedback := models.SessionFeedback{
+		ID:             feedbackID(sessionID, attendee.ID),
+		SessionID:      sessionID,
+		RegistrationID: attendee.ID,
+		Rating:         req.Rating,
+		Comment:        strings.TrimSpace(req.Comment),
+		CreatedAt:      time.Now(),
+	}
+
+	// Create fails if the document exists, so concurrent submissions can't both succeed
+	if _, err := h.db.Collection("session_feedback").Doc(feedback.ID).Create(h.db.Context(), feedback); err != nil {
+		if status.Code(err) == codes.AlreadyExists {
+			c.JSON(http.StatusConflict, gin.H{"error": "You have already rated this session"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save feedback"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, feedback)
+}
+
+// GetMyFeedback lists the authenticated attendee's feedback, so clients know which
+// sessions they have rated
+func (h *Handlers) GetMyFeedback(c *gin.Context) {
+	attendee := c.MustGet(AttendeeKey).(*models.Registration)
+
+	feedback, err := h.sessionFeedback(h.db.Collection("session_feedback").Where("registrationId", "==", attendee.ID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feedback"})
+		return
+	}
+	c.JSON(http.StatusOK, feedback)
+}
+
+// GetFeedbackSummaries returns rating summaries for every session
+func (h *Handlers) GetFeedbackSummaries(c *gin.Context) {
+	sessions, err := h.allSessions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+	feedback, err := h.sessionFeedback(h.db.Collection("session_feedback").Query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feedback"})
+		return
+	}
+
+	bySession := map[string][]models.SessionFeedback{}
+	for _, f := range feedback {
+		bySession[f.SessionID] = append(bySession[f.SessionID], f)
+	}
+	summaries := make([]FeedbackSummary, 0, len(sessions))
+	for _, session := range sessions {
+		summaries = append(summaries, summarizeFeedback(session, bySession[session.ID]))
+	}
+	c.JSON(http.StatusOK, summaries)
+}
+
+// GetSessionFeedback returns one session's rating summary and comments
+func (h *Handlers) GetSessionFeedback(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	doc, err := h.db.Collection("sessions").Doc(sessionID).Get(h.db.Context())
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch session"})
+		return
+	}
+	var session models.Session
+	if err := doc.DataTo(&session); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch session"})
+		return
+	}
+	session.ID = doc.Ref.ID
+
+	feedback, err := h.sessionFeedback(h.db.Collection("session_feedback").Where("sessionId", "==", sessionID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feedback"})
+		return
+	}
+
+	report := SessionFeedbackReport{
+		FeedbackSummary: summarizeFeedback(session, feedback),
+		Comments:        []models.SessionFeedback{},
+	}
+	for _, f := range feedback {
+		if f.Comment != "" {
+			report.Comments = append(report.Comments, f)
+		}
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// ExportSessionFeedback streams all feedback as CSV. tz sets the zone for submission
+// times (default UTC).
+func (h *Handlers) ExportSessionFeedback(c *gin.Context) {
+	loc, err := time.LoadLocation(c.DefaultQuery("tz", "UTC"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tz must be an IANA time zone such as Europe/Berlin"})
+		return
+	}
+
+	sessions, err := h.allSessions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+	titles := make(map[string]string, len(sessions))
+	for _, session := range sessions {
+		titles[session.ID] = session.Title
+	}
+	feedback, err := h.sessionFeedback(h.db.Collection("session_feedback").Query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feedback"})
+		return
+	}
+
+	filename := fmt.Sprintf("session-feedback-%s.csv", time.Now().In(loc).Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"Session ID", "Session", "Registration ID", "Rating", "Comment", "Submitted At (" + loc.String() + ")"})
+	for _, f := range feedback {
+		w.Write([]string{
+			f.SessionID,
+			escapeCSVCell(titles[f.SessionID]),
+			f.RegistrationID,
+			strconv.Itoa(f.Rating),
+			escapeCSVCell(f.Comment),
+			f.CreatedAt.In(loc).Format(exportDateLayout),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Printf("Feedback export failed: %v", err)
+		c.Abort()
+	}
+}
+
+// sessionFeedback runs a feedback query, returning the results newest first
+func (h *Handlers) sessionFeedback(query firestore.Query) ([]models.SessionFeedback, error) {
+	feedback := make([]models.SessionFeedback, 0)
+	iter := query.Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var f models.SessionFeedback
+		if err := doc.DataTo(&f); err != nil {
+			continue
+		}
+		f.ID = doc.Ref.ID
+		feedback = append(feedback, f)
+	}
+	sort.SliceStable(feedback, func(i, j int) bool { return feedback[i].CreatedAt.After(feedback[j].CreatedAt) })
+	return feedback, nil
+}
+
+func (h *Handlers) allSessions() ([]models.Session, error) {
+	sessions := make([]models.Session, 0)
+	iter := h.db.Collection("sessions").Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return sessions, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var session models.Session
+		if err := doc.DataTo(&session); err != nil {
+			continue
+		}
+		session.ID = doc.Ref.ID
+		sessions = append(sessions, session)
+	}
+}
+
+// summarizeFeedback computes the count, histogram and average rounded to two decimals
+func summarizeFeedback(session models.Session, feedback []models.SessionFeedback) FeedbackSummary {
+	summary := FeedbackSummary{SessionID: session.ID, SessionTitle: session.Title}
+	total := 0
+	for _, f := range feedback {
+		if f.Rating < 1 || f.Rating > 5 {
+			continue
+		}
+		summary.Count++
+		summary.Histogram[f.Rating-1]++
+		total += f.Rating
+	}
+	if summary.Count > 0 {
+		summary.Average = math.Round(float64(total)/float64(summary.Count)*100) / 100
+	}
+	return summary
+}
+
+func feedbackID(sessionID, registrationID string) string {
+	return sessionID + "_" + registrationID
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"appdirect-workshop-backend/internal/config"
+	"appdirect-workshop-backend/internal/database"
+	"appdirect-workshop-backend/internal/database/firestoretest"
+	"appdirect-workshop-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeFeedback(t *testing.T) {
+	session := models.Session{ID: "s1", Title: "Keynote"}
+
+	tests := []struct {
+		name    string
+		ratings []int
+		want    FeedbackSummary
+	}{
+		{
+			name: "no feedback",
+			want: FeedbackSummary{SessionID: "s1", SessionTitle: "Keynote"},
+		},
+		{
+			name:    "average rounded to two decimals",
+			ratings: []int{5, 4, 4},
+			want:    FeedbackSummary{SessionID: "s1", SessionTitle: "Keynote", Count: 3, Average: 4.33, Histogram: [5]int{0, 0, 0, 2, 1}},
+		},
+		{
+			name:    "out of range ratings ignored",
+			ratings: []int{1, 0, 6, 3},
+			want:    FeedbackSummary{SessionID: "s1", SessionTitle: "Keynote", Count: 2, Average: 2, Histogram: [5]int{1, 0, 1, 0, 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var feedback []models.SessionFeedback
+			for _, rating := range tt.ratings {
+				feedback = append(feedback, models.SessionFeedback{SessionID: "s1", Rating: rating})
+			}
+			assert.Equal(t, tt.want, summarizeFeedback(session, feedback))
+		})
+	}
+}
+
+func TestSubmitSessionFeedbackValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "missing rating", body: `{"comment":"Great"}`},
+		{name: "rating too low", body: `{"rating":0}`},
+		{name: "rating too high", body: `{"rating":6}`},
+		{name: "comment too long", body: `{"rating":4,"comment":"` + string(bytes.Repeat([]byte("a"), 2001)) + `"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkshopTestHandlers()
+			router := gin.New()
+			router.POST("/api/sessions/:id/feedback", func(c *gin.Context) {
+				c.Set(AttendeeKey, &models.Registration{ID: "reg1"})
+			}, h.SubmitSessionFeedback)
+
+			req, _ := http.NewRequest("POST", "/api/sessions/s1/feedback", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestRequireAttendeeRejectsMissingToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, token := range []string{"", "wsk_not-a-registration-token"} {
+		h := newWorkshopTestHandlers()
+		router := gin.New()
+		router.GET("/api/me/feedback", h.RequireAttendee(), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		req, _ := http.NewRequest("GET", "/api/me/feedback", nil)
+		req.Header.Set(RegistrationTokenHeader, token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestFeedbackIDIsPerAttendeeAndSession(t *testing.T) {
+	assert.Equal(t, feedbackID("s1", "reg1"), feedbackID("s1", "reg1"))
+	assert.NotEqual(t, feedbackID("s1", "reg1"), feedbackID("s1", "reg2"))
+	assert.NotEqual(t, feedbackID("s1", "reg1"), feedbackID("s2", "reg1"))
+}
+
+// seedAttendee stores a registration and returns its attendee token
+func seedAttendee(t *testing.T, db database.DatabaseInterface, id string) string {
+	t.Helper()
+	token, err := generateToken(registrationTokenPrefix)
+	require.NoError(t, err)
+	_, err = db.Collection("registrations").Doc(id).Set(db.Context(), models.Registration{
+		Name:      id,
+		Email:     id + "@example.com",
+		CreatedAt: time.Now(),
+		TokenHash: hashToken(token),
+	})
+	require.NoError(t, err)
+	return token
+}
+
+func TestSessionFeedback(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := firestoretest.NewDatabase(t, "test-collection")
+	for id, title := range map[string]string{"s1": "Keynote", "s2": "Workshop"} {
+		_, err := db.Collection("sessions").Doc(id).Set(db.Context(), models.Session{Title: title})
+		require.NoError(t, err)
+	}
+	tokens := map[string]string{}
+	for _, id := range []string{"reg1", "reg2", "reg3"} {
+		tokens[id] = seedAttendee(t, db, id)
+	}
+	h := New(db, &config.Config{SubcollectionID: "test-collection"})
+
+	router := gin.New()
+	router.POST("/api/sessions/:id/feedback", h.RequireAttendee(), h.SubmitSessionFeedback)
+	router.GET("/api/me/feedback", h.RequireAttendee(), h.GetMyFeedback)
+	router.GET("/api/admin/sessions/feedback", h.GetFeedbackSummaries)
+	router.GET("/api/admin/sessions/feedback/export", h.ExportSessionFeedback)
+	router.GET("/api/admin/sessions/:id/feedback", h.GetSessionFeedback)
+	serve := func(method, path, registrationID, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if registrationID != "" {
+			req.Header.Set(RegistrationTokenHeader, tokens[registrationID])
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("POST", "/api/sessions/s1/feedback", "reg1", `{"rating":5,"comment":"  Great talk "}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created models.SessionFeedback
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, models.SessionFeedback{ID: "s1_reg1", SessionID: "s1", RegistrationID: "reg1", Rating: 5, Comment: "Great talk", CreatedAt: created.CreatedAt}, created)
+
+	require.Equal(t, http.StatusCreated, serve("POST", "/api/sessions/s1/feedback", "reg2", `{"rating":4}`).Code)
+	require.Equal(t, http.StatusCreated, serve("POST", "/api/sessions/s1/feedback", "reg3", `{"rating":4,"comment":"=HYPERLINK(\"x\")"}`).Code)
+	require.Equal(t, http.StatusCreated, serve("POST", "/api/sessions/s2/feedback", "reg1", `{"rating":2}`).Code)
+
+	assert.Equal(t, http.StatusConflict, serve("POST", "/api/sessions/s1/feedback", "reg1", `{"rating":1}`).Code)
+	assert.Equal(t, http.StatusNotFound, serve("POST", "/api/sessions/s9/feedback", "reg1", `{"rating":3}`).Code)
+
+	w = serve("GET", "/api/me/feedback", "reg1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var mine []models.SessionFeedback
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &mine))
+	require.Len(t, mine, 2)
+	// The duplicate didn't replace the first rating
+	assert.Equal(t, "s2", mine[0].SessionID)
+	assert.Equal(t, 5, mine[1].Rating)
+
+	w = serve("GET", "/api/admin/sessions/feedback", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var summaries []FeedbackSummary
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &summaries))
+	assert.ElementsMatch(t, []FeedbackSummary{
+		{SessionID: "s1", SessionTitle: "Keynote", Count: 3, Average: 4.33, Histogram: [5]int{0, 0, 0, 2, 1}},
+		{SessionID: "s2", SessionTitle: "Workshop", Count: 1, Average: 2, Histogram: [5]int{0, 1, 0, 0, 0}},
+	}, summaries)
+
+	w = serve("GET", "/api/admin/sessions/s1/feedback", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var report SessionFeedbackReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, 3, report.Count)
+	require.Len(t, report.Comments, 2)
+	assert.Equal(t, "reg3", report.Comments[0].RegistrationID)
+	assert.Equal(t, "Great talk", report.Comments[1].Comment)
+	assert.Equal(t, http.StatusNotFound, serve("GET", "/api/admin/sessions/s9/feedback", "", "").Code)
+
+	w = serve("GET", "/api/admin/sessions/feedback/export", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 5)
+	assert.Equal(t, []string{"Session ID", "Session", "Registration ID", "Rating", "Comment", "Submitted At (UTC)"}, rows[0])
+	byRegistration := map[string][]string{}
+	for _, row := range rows[1:] {
+		byRegistration[row[0]+"/"+row[2]] = row[:5]
+	}
+	assert.Equal(t, []string{"s1", "Keynote", "reg1", "5", "Great talk"}, byRegistration["s1/reg1"])
+	assert.Equal(t, []string{"s1", "Keynote", "reg3", "4", `'=HYPERLINK("x")`}, byRegistration["s1/reg3"])
+	assert.Equal(t, []string{"s2", "Workshop", "reg1", "2", ""}, byRegistration["s2/reg1"])
+}
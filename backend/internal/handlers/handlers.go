@@ -1,19 +1,90 @@
 package handlers
 
 import (
+	"crypto/sha256"
+	"encoding/json"
+	"time"
+
+	"appdirect-workshop-backend/internal/blob"
+	"appdirect-workshop-backend/internal/challenge"
 	"appdirect-workshop-backend/internal/config"
 	"appdirect-workshop-backend/internal/database"
+	"appdirect-workshop-backend/internal/live"
+	"appdirect-workshop-backend/internal/mail"
+	"appdirect-workshop-backend/internal/oidc"
+	"appdirect-workshop-backend/internal/webhook"
+
+	"google.golang.org/api/option"
 )
 
 type Handlers struct {
-	db  database.DatabaseInterface
-	cfg *config.Config
+	db   database.DatabaseInterface
+	cfg  *config.Config
+	oidc *oidc.Provider
+
+	// verifier checks registrations for bots; nil disables the check
+	verifier challenge.Verifier
+	pow      *challenge.ProofOfWork
+
+	// workshops caches workshop documents; shared by every workshop's handlers
+	workshops *workshopCache
+	// designations caches each workshop's designation taxonomy; shared like workshops
+	designations *designationCache
+	// hub pushes live updates to connected clients; shared like workshops
+	hub *live.Hub
+	// mailer sends announcement emails; nil when SMTP isn't configured
+	mailer mail.Sender
+	// webhooks delivers outbound webhooks with retries
+	webhooks *webhook.Dispatcher
+	// dispatch queues outbox events and webhook deliveries for the workers started by
+	// RunOutbox; shared like workshops
+	dispatch chan func()
+	// blobs holds uploaded images
+	blobs blob.Store
 }
 
 func New(db database.DatabaseInterface, cfg *config.Config) *Handlers {
-	return &Handlers{
-		db:  db,
-		cfg: cfg,
+	h := &Handlers{
+		db:           db,
+		cfg:          cfg,
+		workshops:    newWorkshopCache(),
+		designations: newDesignationCache(),
+		hub:          live.NewHub(),
+		webhooks:     webhook.NewDispatcher(),
+		dispatch:     make(chan func(), outboxQueueSize),
+	}
+	if cfg.SMTPHost != "" {
+		h.mailer = mail.NewSMTP(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	}
+	h.blobs = blob.NewLocal(cfg.BlobDir)
+	if cfg.BlobBucket != "" {
+		h.blobs = blob.NewGCS(cfg.BlobBucket, googleClientOptions(cfg)...)
 	}
+	if cfg.OIDCIssuer != "" {
+		h.oidc = oidc.NewProvider(cfg.OIDCIssuer, cfg.OIDCClientID, cfg.OIDCClientSecret, cfg.OIDCRedirectURL)
+	}
+
+	switch cfg.ChallengeMode {
+	case "pow":
+		// Derive a separate key so challenges can never be confused with admin tokens
+		key := sha256.Sum256([]byte("registration-challenge:" + cfg.AdminPassword))
+		h.pow = challenge.NewProofOfWork(key[:], cfg.PoWDifficulty, 10*time.Minute)
+		h.verifier = h.pow
+	case "captcha":
+		h.verifier = challenge.NewCaptcha(cfg.CaptchaVerifyURL, cfg.CaptchaSecret)
+	}
+	return h
 }
 
+// googleClientOptions authenticates Google Cloud clients with the Firebase service
+// account when one is configured, and with Application Default Credentials otherwise
+func googleClientOptions(cfg *config.Config) []option.ClientOption {
+	if len(cfg.FirebaseServiceAccount) == 0 {
+		return nil
+	}
+	serviceAccountJSON, err := json.Marshal(cfg.FirebaseServiceAccount)
+	if err != nil {
+		return nil
+	}
+	return []option.ClientOption{option.WithCredentialsJSON(serviceAccountJSON)}
+}
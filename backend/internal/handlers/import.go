@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"appdirect-workshop-backend/internal/designation"
+	"appdirect-workshop-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	maxImportBytes = 5 << 20
+	maxImportRows  = 5000
+	// importBatchSize keeps each commit, two writes per row (the registration and its
+	// outbox event), under Firestore's 500 writes per transaction
+	importBatchSize = 200
+)
+
+// Import row statuses
+const (
+	ImportCreated   = "created"
+	ImportValid     = "valid"
+	ImportInvalid   = "invalid"
+	ImportDuplicate = "duplicate"
+	ImportFailed    = "failed"
+)
+
+type ImportRowResult struct {
+	Row    int      `json:"row"`
+	Email  string   `json:"email,omitempty"`
+	Status string   `json:"status"`
+	Errors []string `json:"errors,omitempty"`
+	ID     string   `json:"id,omitempty"`
+	// Token is the created registration's attendee token. Like the one returned by
+	// Register it is only shown here, so admins pass it on to the attendee.
+	Token string `json:"token,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun     bool              `json:"dryRun"`
+	Total      int               `json:"total"`
+	Created    int               `json:"created"`
+	Valid      int               `json:"valid"`
+	Invalid    int               `json:"invalid"`
+	Duplicates int               `json:"duplicates"`
+	Failed     int               `json:"failed"`
+	Rows       []ImportRowResult `json:"rows"`
+}
+
+// importRow is a parsed CSV line; line is the 1-based line number in the file
+type importRow struct {
+	line int
+	req  RegisterRequest
+}
+
+// ImportAttendees creates registrations from a CSV upload (multipart field "file", or a
+// text/csv body) with name, email and designation columns. Rows are validated like
+// public registrations and skipped when the email is already registered or repeated in
+// the file. With dryRun=true nothing is written and the report shows what would happen.
+func (h *Handlers) ImportAttendees(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dryRun", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dryRun must be true or false"})
+		return
+	}
+
+	var body io.Reader
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file is required in the \"file\" field"})
+			return
+		}
+		if file.Size > maxImportBytes {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "CSV file is too large"})
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read CSV file"})
+			return
+		}
+		defer f.Close()
+		body = f
+	} else {
+		body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+	}
+
+	rows, err := parseImportCSV(body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "CSV file is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	existing, err := h.registeredEmails()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check existing registrations"})
+		return
+	}
+
+	normalizer, err := h.designationNormalizer()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load designation taxonomy"})
+		return
+	}
+
+	report := ImportReport{DryRun: dryRun, Total: len(rows)}
+	report.Rows = validateImportRows(rows, existing)
+
+	if !dryRun {
+		h.commitImport(rows, report.Rows, normalizer)
+	}
+
+	for _, row := range report.Rows {
+		switch row.Status {
+		case ImportCreated:
+			report.Created++
+		case ImportValid:
+			report.Valid++
+		case ImportInvalid:
+			report.Invalid++
+		case ImportDuplicate:
+			report.Duplicates++
+		case ImportFailed:
+			report.Failed++
+		}
+	}
+
+	if report.Created > 0 {
+		h.publishRegistrationCount()
+		h.recordAudit(c, "attendee.import", "registration", "", nil, gin.H{
+			"created":    report.Created,
+			"invalid":    report.Invalid,
+			"duplicates": report.Duplicates,
+			"failed":     report.Failed,
+		})
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// parseImportCSV reads the header row to locate columns, so their order doesn't matter
+// and extra columns are ignored
+func parseImportCSV(r io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("CSV file is empty")
+	}
+	if err != nil {
+		return nil, importParseError(err)
+	}
+
+	index := map[string]int{}
+	for i, name := range header {
+		// Spreadsheet tools often prepend a byte order mark
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		index[name] = i
+	}
+	for _, required := range []string{"name", "email", "designation"} {
+		if _, ok := index[required]; !ok {
+			return nil, fmt.Errorf("CSV header is missing the %q column", required)
+		}
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, importParseError(err)
+		}
+		line, _ := reader.FieldPos(0)
+		if isBlankRecord(record) {
+			continue
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("CSV file has more than %d rows", maxImportRows)
+		}
+
+		field := func(name string) string {
+			if i := index[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		rows = append(rows, importRow{
+			line: line,
+			req: RegisterRequest{
+				Name:        field("name"),
+				Email:       field("email"),
+				Designation: field("designation"),
+			},
+		})
+	}
+	return rows, nil
+}
+
+// validateImportRows applies the RegisterRequest binding rules and flags emails that are
+// already registered or appear earlier in the file. existing holds lowercased emails.
+func validateImportRows(rows []importRow, existing map[string]bool) []ImportRowResult {
+	seen := map[string]int{}
+	results := make([]ImportRowResult, len(rows))
+	for i, row := range rows {
+		result := ImportRowResult{Row: row.line, Email: row.req.Email, Status: ImportValid}
+		email := strings.ToLower(row.req.Email)
+
+		if err := binding.Validator.ValidateStruct(&row.req); err != nil {
+			result.Status = ImportInvalid
+			result.Errors = describeValidation(err)
+		} else if existing[email] {
+			result.Status = ImportDuplicate
+			result.Errors = []string{"email is already registered"}
+		} else if first, ok := seen[email]; ok {
+			result.Status = ImportDuplicate
+			result.Errors = []string{fmt.Sprintf("email already appears on row %d", first)}
+		} else {
+			seen[email] = row.line
+		}
+		results[i] = result
+	}
+	return results
+}
+
+// commitImport writes valid rows in batches, each in its own transaction along with a
+// registration.created outbox event per row, and marks them created, with their
+// attendee token, or failed in place
+func (h *Handlers) commitImport(rows []importRow, results []ImportRowResult, normalizer *designation.Normalizer) {
+	var pending []int
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		now := time.Now()
+		refs := make([]*firestore.DocumentRef, len(pending))
+		tokens := make([]string, len(pending))
+		err := h.transactWithEvents(func(tx *firestore.Transaction) ([]outboxEntry, error) {
+			entries := make([]outboxEntry, 0, len(pending))
+			for j, i := range pending {
+				token, err := generateToken(registrationTokenPrefix)
+				if err != nil {
+					return nil, err
+				}
+				tokens[j] = token
+				refs[j] = h.db.Collection("registrations").NewDoc()
+				reg := models.Registration{
+					ID:          refs[j].ID,
+					Name:        rows[i].req.Name,
+					Email:       rows[i].req.Email,
+					Designation: rows[i].req.Designation,
+					CreatedAt:   now,
+					TokenHash:   hashToken(token),
+				}
+				reg.CanonicalDesignation, _ = normalizer.Canonical(reg.Designation)
+				if err := tx.Create(refs[j], reg); err != nil {
+					return nil, err
+				}
+				entries = append(entries, outboxEntry{WebhookRegistrationCreated, reg})
+			}
+			return entries, nil
+		})
+		for j, i := range pending {
+			if err != nil {
+				results[i].Status = ImportFailed
+				results[i].Errors = []string{"Failed to save registration"}
+				continue
+			}
+			results[i].Status = ImportCreated
+			results[i].ID = refs[j].ID
+			results[i].Token = tokens[j]
+		}
+		pending = pending[:0]
+	}
+
+	for i := range results {
+		if results[i].Status != ImportValid {
+			continue
+		}
+		pending = append(pending, i)
+		if len(pending) == importBatchSize {
+			flush()
+		}
+	}
+	flush()
+}
+
+// registeredEmails returns the lowercased emails of all existing registrations
+func (h *Handlers) registeredEmails() (map[string]bool, error) {
+	emails := map[string]bool{}
+	iter := h.db.Collection("registrations").Select("email").Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return emails, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if email, ok := doc.Data()["email"].(string); ok {
+			emails[strings.ToLower(email)] = true
+		}
+	}
+}
+
+// describeValidation turns binding errors into messages keyed by the JSON field name
+func describeValidation(err error) []string {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return []string{err.Error()}
+	}
+	messages := make([]string, 0, len(errs))
+	for _, fe := range errs {
+		field := strings.ToLower(fe.Field())
+		switch fe.Tag() {
+		case "required":
+			messages = append(messages, field+" is required")
+		case "email":
+			messages = append(messages, field+" must be a valid email address")
+		default:
+			messages = append(messages, fmt.Sprintf("%s failed %s validation", field, fe.Tag()))
+		}
+	}
+	return messages
+}
+
+func importParseError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return fmt.Errorf("Invalid CSV on line %d: %v", parseErr.Line, parseErr.Err)
+	}
+	return err
+}
+
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
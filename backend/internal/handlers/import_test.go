@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"appdirect-workshop-backend/internal/config"
+	"appdirect-workshop-backend/internal/database"
+	"appdirect-workshop-backend/internal/database/firestoretest"
+	"appdirect-workshop-backend/internal/designation"
+	"appdirect-workshop-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImportCSV(t *testing.T) {
+	tests := []struct {
+		name          string
+		csv           string
+		expectedRows  int
+		expectedError string
+	}{
+		{
+			name:         "columns in any order with extras",
+			csv:          "\ufeffEmail,Designation,Phone,Name\njane@example.com,Engineer,123,Jane Doe\n\n,,,\njohn@example.com,Manager,,John\n",
+			expectedRows: 2,
+		},
+		{
+			name:          "missing column",
+			csv:           "name,email\nJane,jane@example.com\n",
+			expectedError: `missing the "designation" column`,
+		},
+		{
+			name:          "empty file",
+			csv:           "",
+			expectedError: "CSV file is empty",
+		},
+		{
+			name:          "malformed quotes",
+			csv:           "name,email,designation\n\"Jane,jane@example.com,Engineer\n",
+			expectedError: "Invalid CSV on line",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parseImportCSV(strings.NewReader(tt.csv))
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, rows, tt.expectedRows)
+		})
+	}
+
+	rows, _ := parseImportCSV(strings.NewReader("email,designation,name\njane@example.com,Engineer, Jane Doe \n\njohn@example.com,Manager,John\n"))
+	assert.Equal(t, RegisterRequest{Name: "Jane Doe", Email: "jane@example.com", Designation: "Engineer"}, rows[0].req)
+	assert.Equal(t, 2, rows[0].line)
+	assert.Equal(t, 4, rows[1].line)
+}
+
+func TestValidateImportRows(t *testing.T) {
+	rows := []importRow{
+		{line: 2, req: RegisterRequest{Name: "Jane", Email: "jane@example.com", Designation: "Engineer"}},
+		{line: 3, req: RegisterRequest{Name: "", Email: "not-an-email", Designation: "Engineer"}},
+		{line: 4, req: RegisterRequest{Name: "Jane Again", Email: "JANE@example.com", Designation: "Engineer"}},
+		{line: 5, req: RegisterRequest{Name: "Existing", Email: "existing@example.com", Designation: "Manager"}},
+	}
+	existing := map[string]bool{"existing@example.com": true}
+
+	results := validateImportRows(rows, existing)
+	assert.Equal(t, ImportValid, results[0].Status)
+	assert.Equal(t, ImportInvalid, results[1].Status)
+	assert.ElementsMatch(t, []string{"name is required", "email must be a valid email address"}, results[1].Errors)
+	assert.Equal(t, ImportDuplicate, results[2].Status)
+	assert.Equal(t, []string{"email already appears on row 2"}, results[2].Errors)
+	assert.Equal(t, ImportDuplicate, results[3].Status)
+	assert.Equal(t, []string{"email is already registered"}, results[3].Errors)
+}
+
+func TestCommitImportMarksFailedBatches(t *testing.T) {
+	mockDB := &database.MockFirestoreClient{
+		RunTransactionFunc: func(f func(ctx context.Context, tx *firestore.Transaction) error) error {
+			return errors.New("unavailable")
+		},
+	}
+	h := New(mockDB, &config.Config{AdminPassword: "test-password"})
+
+	rows := []importRow{{line: 2}, {line: 3}}
+	results := []ImportRowResult{{Row: 2, Status: ImportValid}, {Row: 3, Status: ImportInvalid}}
+	normalizer, err := designation.New(nil)
+	require.NoError(t, err)
+	h.commitImport(rows, results, normalizer)
+
+	assert.Equal(t, ImportFailed, results[0].Status)
+	assert.Equal(t, ImportInvalid, results[1].Status)
+}
+
+func TestImportAttendeesIssuesTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := firestoretest.NewDatabase(t, "test-collection")
+	h := New(db, &config.Config{AdminPassword: "test-password", SubcollectionID: "test-collection"})
+	router := gin.New()
+	router.POST("/api/admin/attendees/import", h.ImportAttendees)
+
+	csv := "name,email,designation\nJane,jane@example.com,Engineer\nJohn,not-an-email,Manager\n"
+	req, _ := http.NewRequest("POST", "/api/admin/attendees/import", bytes.NewBufferString(csv))
+	req.Header.Set("Content-Type", "text/csv")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var report ImportReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	require.Equal(t, 1, report.Created)
+	assert.Empty(t, report.Rows[1].Token)
+
+	created := report.Rows[0]
+	require.True(t, strings.HasPrefix(created.Token, registrationTokenPrefix))
+	reg, err := h.registrationByToken(created.Token)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, reg.ID)
+	assert.Equal(t, "jane@example.com", reg.Email)
+
+	// Imports notify webhooks and send confirmations like public registrations
+	events, err := db.Collection(outboxCollection).Where("type", "==", WebhookRegistrationCreated).Documents(db.Context()).GetAll()
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	var event models.OutboxEvent
+	require.NoError(t, events[0].DataTo(&event))
+	assert.Contains(t, event.Data, `"id":"`+created.ID+`"`)
+}
+
+func TestImportAttendeesValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	multipartBody := func(field string) (*bytes.Buffer, string) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile(field, "attendees.csv")
+		part.Write([]byte("name,email,designation\n"))
+		writer.Close()
+		return body, writer.FormDataContentType()
+	}
+
+	wrongField, wrongFieldType := multipartBody("upload")
+
+	tests := []struct {
+		name           string
+		query          string
+		body           *bytes.Buffer
+		contentType    string
+		expectedStatus int
+	}{
+		{
+			name:           "invalid dryRun",
+			query:          "?dryRun=maybe",
+			body:           bytes.NewBufferString("name,email,designation\n"),
+			contentType:    "text/csv",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing file field",
+			body:           wrongField,
+			contentType:    wrongFieldType,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing columns",
+			body:           bytes.NewBufferString("name,email\n"),
+			contentType:    "text/csv",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "body too large",
+			body:           bytes.NewBufferString("name,email,designation\n" + strings.Repeat("a", maxImportBytes)),
+			contentType:    "text/csv",
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				AdminPassword:   "test-password",
+				SubcollectionID: "test-collection",
+			}
+			h := New(createMockDB(), cfg)
+
+			router := gin.New()
+			router.POST("/api/admin/attendees/import", h.ImportAttendees)
+
+			req, _ := http.NewRequest("POST", "/api/admin/attendees/import"+tt.query, tt.body)
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
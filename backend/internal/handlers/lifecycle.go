@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"appdirect-workshop-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+)
+
+// Workshop lifecycle states
+const (
+	StateDraft      = "draft"
+	StateOpen       = "open"
+	StateClosed     = "closed"
+	StateInProgress = "in-progress"
+	StateFinished   = "finished"
+)
+
+// Error codes returned when registration is refused, so clients can explain why
+const (
+	CodeRegistrationNotOpen = "REGISTRATION_NOT_OPEN"
+	CodeRegistrationClosed  = "REGISTRATION_CLOSED"
+	CodeEventInProgress     = "EVENT_IN_PROGRESS"
+	CodeEventFinished       = "EVENT_FINISHED"
+)
+
+type UpdateLifecycleRequest struct {
+	Status               string     `json:"status" binding:"required,oneof=draft open closed in-progress finished"`
+	RegistrationOpensAt  *time.Time `json:"registrationOpensAt"`
+	RegistrationClosesAt *time.Time `json:"registrationClosesAt"`
+}
+
+// Lifecycle is a workshop's effective state at a point in time. Code explains why
+// registration is refused and is empty while it is open.
+type Lifecycle struct {
+	State                string     `json:"state"`
+	RegistrationOpen     bool       `json:"registrationOpen"`
+	RegistrationOpensAt  *time.Time `json:"registrationOpensAt,omitempty"`
+	RegistrationClosesAt *time.Time `json:"registrationClosesAt,omitempty"`
+	Code                 string     `json:"code,omitempty"`
+}
+
+// workshopLifecycle combines the organizer's status with the registration window and the
+// event dates. Draft, closed, in-progress and finished are manual overrides. An open
+// workshop accepts registrations between its open and close times (until the event ends
+// when no close time is set) and moves to in-progress and finished with the event dates.
+func workshopLifecycle(workshop *models.Workshop, now time.Time) Lifecycle {
+	lifecycle := Lifecycle{
+		RegistrationOpensAt:  workshop.RegistrationOpensAt,
+		RegistrationClosesAt: workshop.RegistrationClosesAt,
+	}
+	startsAt, endsAt := workshop.Settings.StartsAt, workshop.Settings.EndsAt
+
+	switch workshop.Status {
+	case StateDraft:
+		lifecycle.State, lifecycle.Code = StateDraft, CodeRegistrationNotOpen
+		return lifecycle
+	case StateClosed:
+		lifecycle.State, lifecycle.Code = StateClosed, CodeRegistrationClosed
+		return lifecycle
+	case StateInProgress:
+		lifecycle.State, lifecycle.Code = StateInProgress, CodeEventInProgress
+		return lifecycle
+	case StateFinished:
+		lifecycle.State, lifecycle.Code = StateFinished, CodeEventFinished
+		return lifecycle
+	}
+
+	closesAt := workshop.RegistrationClosesAt
+	if closesAt == nil {
+		closesAt = endsAt
+	}
+	switch {
+	case workshop.RegistrationOpensAt != nil && now.Before(*workshop.RegistrationOpensAt):
+		lifecycle.Code = CodeRegistrationNotOpen
+	case closesAt != nil && !now.Before(*closesAt):
+		lifecycle.Code = CodeRegistrationClosed
+	default:
+		lifecycle.RegistrationOpen = true
+	}
+
+	switch {
+	case endsAt != nil && !now.Before(*endsAt):
+		lifecycle.State, lifecycle.Code = StateFinished, CodeEventFinished
+		lifecycle.RegistrationOpen = false
+	case startsAt != nil && !now.Before(*startsAt):
+		lifecycle.State = StateInProgress
+		if !lifecycle.RegistrationOpen {
+			lifecycle.Code = CodeEventInProgress
+		}
+	case lifecycle.RegistrationOpen:
+		lifecycle.State = StateOpen
+	default:
+		lifecycle.State = StateClosed
+	}
+	return lifecycle
+}
+
+// registrationRefusal describes a refused registration for the public API
+func registrationRefusal(lifecycle Lifecycle) gin.H {
+	messages := map[string]string{
+		CodeRegistrationNotOpen: "Registration is not open yet",
+		CodeRegistrationClosed:  "Registration is closed",
+		CodeEventInProgress:     "Registration is closed because the event has started",
+		CodeEventFinished:       "This event has finished",
+	}
+	body := gin.H{"error": messages[lifecycle.Code], "code": lifecycle.Code, "state": lifecycle.State}
+	if lifecycle.Code == CodeRegistrationNotOpen && lifecycle.RegistrationOpensAt != nil {
+		body["opensAt"] = lifecycle.RegistrationOpensAt
+	}
+	return body
+}
+
+// UpdateWorkshopLifecycle sets the workshop's status and registration window
+func (h *Handlers) UpdateWorkshopLifecycle(c *gin.Context) {
+	workshop := c.MustGet(WorkshopKey).(*models.Workshop)
+
+	var req UpdateLifecycleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := map[string]interface{}{
+		"name":   workshop.Name,
+		"status": req.Status,
+	}
+	// Unset window bounds are deleted so a previous schedule doesn't linger
+	for field, value := range map[string]*time.Time{
+		"registrationOpensAt":  req.RegistrationOpensAt,
+		"registrationClosesAt": req.RegistrationClosesAt,
+	} {
+		if value == nil {
+			updates[field] = firestore.Delete
+		} else {
+			updates[field] = *value
+		}
+	}
+
+	// Set rather than update so the default workshop gets a document on first use
+	_, err := h.db.Workshops().Doc(workshop.Slug).Set(h.db.Context(), updates,
+		firestore.Merge([]string{"name"}, []string{"status"}, []string{"registrationOpensAt"}, []string{"registrationClosesAt"}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update workshop lifecycle"})
+		return
+	}
+	h.workshops.invalidate(workshop.Slug)
+
+	before := gin.H{
+		"status":               workshop.Status,
+		"registrationOpensAt":  workshop.RegistrationOpensAt,
+		"registrationClosesAt": workshop.RegistrationClosesAt,
+	}
+	after := gin.H{
+		"status":               req.Status,
+		"registrationOpensAt":  req.RegistrationOpensAt,
+		"registrationClosesAt": req.RegistrationClosesAt,
+	}
+	h.recordAudit(c, "workshop.lifecycle.update", "workshop", workshop.Slug, before, after)
+
+	updated := *workshop
+	updated.Status = req.Status
+	updated.RegistrationOpensAt = req.RegistrationOpensAt
+	updated.RegistrationClosesAt = req.RegistrationClosesAt
+	c.JSON(http.StatusOK, workshopLifecycle(&updated, time.Now()))
+}
+
+func (req *UpdateLifecycleRequest) validate() error {
+	opens, closes := req.RegistrationOpensAt, req.RegistrationClosesAt
+	if opens != nil && closes != nil && !closes.After(*opens) {
+		return errors.New("registrationClosesAt must be after registrationOpensAt")
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"appdirect-workshop-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkshopLifecycle(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		ts := now.Add(d)
+		return &ts
+	}
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name          string
+		workshop      models.Workshop
+		expectedOpen  bool
+		expectedState string
+		expectedCode  string
+	}{
+		{name: "legacy workshop without status", workshop: models.Workshop{}, expectedOpen: true, expectedState: StateOpen},
+		{name: "draft", workshop: models.Workshop{Status: StateDraft}, expectedState: StateDraft, expectedCode: CodeRegistrationNotOpen},
+		{name: "manually closed", workshop: models.Workshop{Status: StateClosed}, expectedState: StateClosed, expectedCode: CodeRegistrationClosed},
+		{name: "manually finished", workshop: models.Workshop{Status: StateFinished}, expectedState: StateFinished, expectedCode: CodeEventFinished},
+		{
+			name:          "before the window opens",
+			workshop:      models.Workshop{Status: StateOpen, RegistrationOpensAt: at(day)},
+			expectedState: StateClosed,
+			expectedCode:  CodeRegistrationNotOpen,
+		},
+		{
+			name:          "inside the window",
+			workshop:      models.Workshop{Status: StateOpen, RegistrationOpensAt: at(-day), RegistrationClosesAt: at(day)},
+			expectedOpen:  true,
+			expectedState: StateOpen,
+		},
+		{
+			name:          "after the window closes",
+			workshop:      models.Workshop{Status: StateOpen, RegistrationClosesAt: at(-time.Hour)},
+			expectedState: StateClosed,
+			expectedCode:  CodeRegistrationClosed,
+		},
+		{
+			name: "event started without a close time",
+			workshop: models.Workshop{
+				Status:   StateOpen,
+				Settings: models.WorkshopSettings{StartsAt: at(-time.Hour), EndsAt: at(time.Hour)},
+			},
+			expectedOpen:  true,
+			expectedState: StateInProgress,
+		},
+		{
+			name: "event started after registration closed",
+			workshop: models.Workshop{
+				Status:               StateOpen,
+				RegistrationClosesAt: at(-2 * time.Hour),
+				Settings:             models.WorkshopSettings{StartsAt: at(-time.Hour), EndsAt: at(time.Hour)},
+			},
+			expectedState: StateInProgress,
+			expectedCode:  CodeEventInProgress,
+		},
+		{
+			name: "event over even with a later close time",
+			workshop: models.Workshop{
+				Status:               StateOpen,
+				RegistrationClosesAt: at(day),
+				Settings:             models.WorkshopSettings{StartsAt: at(-2 * time.Hour), EndsAt: at(-time.Hour)},
+			},
+			expectedState: StateFinished,
+			expectedCode:  CodeEventFinished,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lifecycle := workshopLifecycle(&tt.workshop, now)
+			assert.Equal(t, tt.expectedOpen, lifecycle.RegistrationOpen)
+			assert.Equal(t, tt.expectedState, lifecycle.State)
+			assert.Equal(t, tt.expectedCode, lifecycle.Code)
+		})
+	}
+}
+
+func TestRegisterRefusedOutsideLifecycle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	opensAt := time.Now().Add(time.Hour)
+	tests := []struct {
+		name         string
+		workshop     models.Workshop
+		expectedCode string
+	}{
+		{name: "draft", workshop: models.Workshop{Slug: "devfest", Status: StateDraft}, expectedCode: CodeRegistrationNotOpen},
+		{name: "not open yet", workshop: models.Workshop{Slug: "devfest", Status: StateOpen, RegistrationOpensAt: &opensAt}, expectedCode: CodeRegistrationNotOpen},
+		{name: "closed", workshop: models.Workshop{Slug: "devfest", Status: StateClosed}, expectedCode: CodeRegistrationClosed},
+		{name: "finished", workshop: models.Workshop{Slug: "devfest", Status: StateFinished}, expectedCode: CodeEventFinished},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkshopTestHandlers()
+			workshop := tt.workshop
+
+			router := gin.New()
+			router.POST("/api/register", func(c *gin.Context) { c.Set(WorkshopKey, &workshop) }, h.Register)
+
+			body := `{"name":"Ada Lovelace","email":"ada@example.com","designation":"Software Engineer"}`
+			req, _ := http.NewRequest("POST", "/api/register", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusForbidden, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedCode, response["code"])
+			assert.NotEmpty(t, response["error"])
+		})
+	}
+}
+
+func TestUpdateLifecycleValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "missing status", body: `{}`},
+		{name: "unknown status", body: `{"status":"paused"}`},
+		{name: "window closes before it opens", body: `{"status":"open","registrationOpensAt":"2025-03-02T00:00:00Z","registrationClosesAt":"2025-03-01T00:00:00Z"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkshopTestHandlers()
+			workshop := models.Workshop{Slug: "devfest"}
+
+			router := gin.New()
+			router.PUT("/api/admin/workshop/lifecycle", func(c *gin.Context) { c.Set(WorkshopKey, &workshop) }, h.UpdateWorkshopLifecycle)
+
+			req, _ := http.NewRequest("PUT", "/api/admin/workshop/lifecycle", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
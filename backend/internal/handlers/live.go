@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"appdirect-workshop-backend/internal/live"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Events on the workshop's public stream
+const (
+	EventRegistrationCount = "registrations.count"
+	EventSessionUpdated    = "session.updated"
+	EventSessionDeleted    = "session.deleted"
+	EventSpeakerUpdated    = "speaker.updated"
+	EventSpeakerDeleted    = "speaker.deleted"
+	// EventResync tells a resuming client that events were missed and it should reload
+	EventResync = "resync"
+)
+
+// heartbeatInterval keeps idle streams from being closed by proxies and load balancers,
+// which commonly time out connections after 30 to 60 seconds of silence
+var heartbeatInterval = 25 * time.Second
+
+// topic namespaces a hub topic by workshop, so streams never see another workshop's events
+func (h *Handlers) topic(name string) string {
+	return h.cfg.SubcollectionID + "/" + name
+}
+
+func (h *Handlers) publicTopic() string {
+	return h.topic("public")
+}
+
+// StreamEvents streams the workshop's registration count, session and speaker changes
+// and announcements
+func (h *Handlers) StreamEvents(c *gin.Context) {
+	h.stream(c, h.publicTopic())
+}
+
+// publishPublic publishes on the public stream
+func (h *Handlers) publishPublic(eventType string, data interface{}) {
+	h.hub.Publish(h.publicTopic(), eventType, data)
+}
+
+// publishRegistrationCount publishes the current count in the background, so registering
+// doesn't wait on the count. Nothing is counted while no one is listening.
+func (h *Handlers) publishRegistrationCount() {
+	if h.hub.Subscribers(h.publicTopic()) == 0 {
+		return
+	}
+	go func() {
+		count, err := h.registrationCount()
+		if err != nil {
+			log.Printf("Failed to count registrations: %v", err)
+			return
+		}
+		h.publishPublic(EventRegistrationCount, gin.H{"count": count})
+	}()
+}
+
+// stream subscribes the request to a topic and streams its events. A reconnecting
+// EventSource sends the ID of the last event it received, and the events it missed are
+// replayed; if they can't be, it is sent a resync event instead.
+func (h *Handlers) stream(c *gin.Context, topic string) {
+	events, unsubscribe, complete := h.hub.Resume(topic, c.GetHeader("Last-Event-ID"))
+	defer unsubscribe()
+	streamEvents(c, events, !complete)
+}
+
+// streamEvents writes events as Server-Sent Events until the client disconnects or the
+// subscription is dropped, with a comment line as a heartbeat while idle. EventSource
+// clients reconnect on their own either way.
+func streamEvents(c *gin.Context, events <-chan live.Event, resync bool) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-store")
+	// Stops proxies such as nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if resync {
+		// The empty ID clears the client's last event ID, so its next reconnect doesn't
+		// ask for the same unavailable events again
+		if err := writeEvent(c.Writer, live.Event{Type: EventResync, Data: gin.H{}}); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	ctx := c.Request.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Writer, ": keepalive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeEvent(c.Writer, event); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// writeEvent encodes one event with its JSON data on a single line
+func writeEvent(w io.Writer, event live.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"appdirect-workshop-backend/internal/live"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamEventsResync(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	hub := live.NewHub()
+	events, unsubscribe := hub.Subscribe("default-workshop/public")
+	count := hub.Publish("default-workshop/public", EventRegistrationCount, gin.H{"count": 42})
+	unsubscribe()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/api/events", nil)
+	streamEvents(c, events, true)
+
+	assert.Equal(t, "id: \nevent: resync\ndata: {}\n\n"+
+		"id: "+count.ID+"\nevent: registrations.count\ndata: {\"count\":42}\n\n", w.Body.String())
+}
+
+func TestStreamEventsHeartbeat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	interval := heartbeatInterval
+	heartbeatInterval = 10 * time.Millisecond
+	defer func() { heartbeatInterval = interval }()
+
+	events, unsubscribe := live.NewHub().Subscribe("default-workshop/public")
+	defer unsubscribe()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 35*time.Millisecond)
+	defer cancel()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequestWithContext(ctx, "GET", "/api/events", nil)
+	streamEvents(c, events, false)
+
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.Contains(t, w.Body.String(), ": keepalive\n\n")
+}
+
+func TestStreamResumesFromLastEventID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := newWorkshopTestHandlers()
+	first := h.hub.Publish(h.publicTopic(), EventSessionUpdated, gin.H{"id": "s1"})
+	h.hub.Publish(h.publicTopic(), EventSessionDeleted, gin.H{"id": "s2"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequestWithContext(ctx, "GET", "/api/events", nil)
+	c.Request.Header.Set("Last-Event-ID", first.ID)
+	h.StreamEvents(c)
+
+	assert.NotContains(t, w.Body.String(), "session.updated")
+	assert.Contains(t, w.Body.String(), "event: session.deleted\ndata: {\"id\":\"s2\"}")
+	assert.NotContains(t, w.Body.String(), EventResync)
+}
@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"appdirect-workshop-backend/internal/middleware"
+	"appdirect-workshop-backend/internal/models"
+	"appdirect-workshop-backend/internal/totp"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultAdminID     = middleware.DefaultAdminID
+	mfaTokenTTL        = 5 * time.Minute
+	mfaSkew            = 1
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+)
+
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfaToken" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type MFAEnrollResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+type MFAStatusResponse struct {
+	Enabled                bool      `json:"enabled"`
+	EnrolledAt             time.Time `json:"enrolledAt,omitempty"`
+	RecoveryCodesRemaining int       `json:"recoveryCodesRemaining"`
+}
+
+// VerifyMFA completes a two-step login by checking a TOTP or recovery code
+func (h *Handlers) VerifyMFA(c *gin.Context) {
+	if !h.cfg.MFAEnabled {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Two-factor authentication is disabled"})
+		return
+	}
+
+	var req MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "MFA token and code are required"})
+		return
+	}
+
+	adminID, err := h.parseMFAToken(req.MFAToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired MFA token"})
+		return
+	}
+
+	ref := h.db.Collection("admin_mfa").Doc(adminID)
+	doc, err := ref.Get(h.db.Context())
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Two-factor authentication is not enabled"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch two-factor settings"})
+		return
+	}
+
+	var mfa models.AdminMFA
+	if err := doc.DataTo(&mfa); err != nil || !mfa.Enabled {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+
+	var updates []firestore.Update
+	if step, ok := totp.Validate(mfa.Secret, req.Code, time.Now(), mfaSkew); ok {
+		// Each code may only be used once
+		if step <= mfa.LastUsedStep {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Code has already been used"})
+			return
+		}
+		updates = []firestore.Update{{Path: "lastUsedStep", Value: step}}
+	} else if remaining, ok := consumeRecoveryCode(mfa.RecoveryCodes, req.Code); ok {
+		updates = []firestore.Update{{Path: "recoveryCodes", Value: remaining}}
+	} else {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	// The precondition rejects a concurrent login that consumed the same code first
+	if _, err := ref.Update(h.db.Context(), updates, firestore.LastUpdateTime(doc.UpdateTime)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Code has already been used"})
+		return
+	}
+
+	tokenString, err := h.issueAdminToken(adminID, middleware.RoleAdmin)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, LoginResponse{Token: tokenString})
+}
+
+func (h *Handlers) GetMFAStatus(c *gin.Context) {
+	mfa, err := h.getAdminMFA(c.GetString(middleware.AdminIDKey))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch two-factor settings"})
+		return
+	}
+
+	resp := MFAStatusResponse{}
+	if mfa != nil && mfa.Enabled {
+		resp.Enabled = true
+		resp.EnrolledAt = mfa.EnrolledAt
+		resp.RecoveryCodesRemaining = len(mfa.RecoveryCodes)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// EnrollMFA starts enrollment by generating a secret; it is not enforced until confirmed
+func (h *Handlers) EnrollMFA(c *gin.Context) {
+	if !h.cfg.MFAEnabled {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Two-factor authentication is disabled"})
+		return
+	}
+
+	adminID := c.GetString(middleware.AdminIDKey)
+	existing, err := h.getAdminMFA(adminID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch two-factor settings"})
+		return
+	}
+	if existing != nil && existing.Enabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+
+	mfa := models.AdminMFA{Secret: secret, Enabled: false}
+	if _, err := h.db.Collection("admin_mfa").Doc(adminID).Set(h.db.Context(), mfa); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save two-factor settings"})
+		return
+	}
+
+	h.recordAudit(c, "mfa.enroll", "admin", adminID, nil, nil)
+	c.JSON(http.StatusOK, MFAEnrollResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(h.cfg.TOTPIssuer, adminID, secret),
+	})
+}
+
+// ConfirmMFA enables two-factor once the admin proves their authenticator works
+// and returns the one-time recovery codes
+func (h *Handlers) ConfirmMFA(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Code is required"})
+		return
+	}
+
+	adminID := c.GetString(middleware.AdminIDKey)
+	mfa, err := h.getAdminMFA(adminID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch two-factor settings"})
+		return
+	}
+	if mfa == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Two-factor enrollment not started"})
+		return
+	}
+	if mfa.Enabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	step, ok := totp.Validate(mfa.Secret, req.Code, time.Now(), mfaSkew)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	recoveryCodes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	mfa.Enabled = true
+	mfa.RecoveryCodes = hashes
+	mfa.LastUsedStep = step
+	mfa.EnrolledAt = time.Now()
+	if _, err := h.db.Collection("admin_mfa").Doc(adminID).Set(h.db.Context(), mfa); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save two-factor settings"})
+		return
+	}
+
+	h.recordAudit(c, "mfa.enable", "admin", adminID, nil, nil)
+	c.JSON(http.StatusOK, gin.H{"recoveryCodes": recoveryCodes})
+}
+
+// DisableMFA removes the enrollment after checking a current code
+func (h *Handlers) DisableMFA(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Code is required"})
+		return
+	}
+
+	adminID := c.GetString(middleware.AdminIDKey)
+	ref := h.db.Collection("admin_mfa").Doc(adminID)
+	doc, err := ref.Get(h.db.Context())
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Two-factor authentication is not enabled"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch two-factor settings"})
+		return
+	}
+
+	var mfa models.AdminMFA
+	if err := doc.DataTo(&mfa); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch two-factor settings"})
+		return
+	}
+
+	if step, ok := totp.Validate(mfa.Secret, req.Code, time.Now(), mfaSkew); ok {
+		// A code already used to log in can't be replayed to turn MFA off
+		if step <= mfa.LastUsedStep {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Code has already been used"})
+			return
+		}
+	} else if _, ok := consumeRecoveryCode(mfa.RecoveryCodes, req.Code); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	// The precondition rejects the delete if a login consumed the same code first
+	if _, err := ref.Delete(h.db.Context(), firestore.LastUpdateTime(doc.UpdateTime)); err != nil {
+		if status.Code(err) == codes.FailedPrecondition {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Code has already been used"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+
+	h.recordAudit(c, "mfa.disable", "admin", adminID, nil, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// getAdminMFA returns nil without error when the admin has never enrolled
+func (h *Handlers) getAdminMFA(adminID string) (*models.AdminMFA, error) {
+	doc, err := h.db.Collection("admin_mfa").Doc(adminID).Get(h.db.Context())
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var mfa models.AdminMFA
+	if err := doc.DataTo(&mfa); err != nil {
+		return nil, err
+	}
+	return &mfa, nil
+}
+
+// issueMFAToken signs a short-lived token that only VerifyMFA accepts
+func (h *Handlers) issueMFAToken(adminID string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": adminID,
+		"mfa": "pending",
+		"exp": time.Now().Add(mfaTokenTTL).Unix(),
+	})
+	return token.SignedString([]byte(h.cfg.AdminTokenSecret))
+}
+
+func (h *Handlers) parseMFAToken(tokenString string) (string, error) {
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(h.cfg.AdminTokenSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid {
+		return "", fmt.Errorf("invalid token")
+	}
+	if pending, _ := claims["mfa"].(string); pending != "pending" {
+		return "", fmt.Errorf("not an MFA token")
+	}
+	adminID, err := claims.GetSubject()
+	if err != nil || adminID == "" {
+		return "", fmt.Errorf("missing subject")
+	}
+	return adminID, nil
+}
+
+// generateRecoveryCodes returns the plain codes to show once and their stored hashes
+func generateRecoveryCodes() ([]string, []string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		buf := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(encoding.EncodeToString(buf)[:recoveryCodeLength])
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// consumeRecoveryCode reports whether code matches a stored hash and returns the remaining hashes
+func consumeRecoveryCode(hashes []string, code string) ([]string, bool) {
+	hashed := hashRecoveryCode(code)
+	for i, h := range hashes {
+		if h == hashed {
+			remaining := make([]string, 0, len(hashes)-1)
+			remaining = append(remaining, hashes[:i]...)
+			remaining = append(remaining, hashes[i+1:]...)
+			return remaining, true
+		}
+	}
+	return hashes, false
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"appdirect-workshop-backend/internal/config"
+	"appdirect-workshop-backend/internal/database/firestoretest"
+	"appdirect-workshop-backend/internal/middleware"
+	"appdirect-workshop-backend/internal/models"
+	"appdirect-workshop-backend/internal/totp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyMFA(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		AdminPassword:    "test-password",
+		AdminTokenSecret: "test-token-secret",
+		SubcollectionID:  "test-collection",
+		MFAEnabled:       true,
+	}
+	h := New(createMockDB(), cfg)
+
+	adminToken, _ := h.issueAdminToken("admin", "admin")
+
+	tests := []struct {
+		name           string
+		requestBody    map[string]string
+		expectedStatus int
+	}{
+		{
+			name:           "missing code",
+			requestBody:    map[string]string{"mfaToken": "token"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid mfa token",
+			requestBody:    map[string]string{"mfaToken": "invalid", "code": "123456"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "admin token is not an mfa token",
+			requestBody:    map[string]string{"mfaToken": adminToken, "code": "123456"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.POST("/api/admin/login/verify", h.VerifyMFA)
+
+			body, _ := json.Marshal(tt.requestBody)
+			req, _ := http.NewRequest("POST", "/api/admin/login/verify", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestVerifyMFADisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		AdminPassword:    "test-password",
+		AdminTokenSecret: "test-token-secret",
+		SubcollectionID:  "test-collection",
+	}
+	h := New(createMockDB(), cfg)
+
+	router := gin.New()
+	router.POST("/api/admin/login/verify", h.VerifyMFA)
+
+	body, _ := json.Marshal(map[string]string{"mfaToken": "token", "code": "123456"})
+	req, _ := http.NewRequest("POST", "/api/admin/login/verify", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestMFAToken(t *testing.T) {
+	h := New(createMockDB(), &config.Config{AdminPassword: "test-password", AdminTokenSecret: "test-token-secret"})
+
+	token, err := h.issueMFAToken("alice")
+	assert.NoError(t, err)
+
+	adminID, err := h.parseMFAToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", adminID)
+
+	other := New(createMockDB(), &config.Config{AdminPassword: "test-password", AdminTokenSecret: "other-token-secret"})
+	_, err = other.parseMFAToken(token)
+	assert.Error(t, err)
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := generateRecoveryCodes()
+	assert.NoError(t, err)
+	assert.Len(t, codes, recoveryCodeCount)
+	assert.Len(t, hashes, recoveryCodeCount)
+
+	remaining, ok := consumeRecoveryCode(hashes, codes[3])
+	assert.True(t, ok)
+	assert.Len(t, remaining, recoveryCodeCount-1)
+
+	// Codes are single use and tolerate formatting differences
+	_, ok = consumeRecoveryCode(remaining, codes[3])
+	assert.False(t, ok)
+	_, ok = consumeRecoveryCode(remaining, " "+codes[4][:5]+codes[4][6:]+" ")
+	assert.True(t, ok)
+}
+
+func TestDisableMFARejectsUsedCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const secret = "JBSWY3DPEHPK3PXP"
+	step := totp.Step(time.Now())
+	db := firestoretest.NewDatabase(t, "test-collection")
+	ref := db.Collection("admin_mfa").Doc(defaultAdminID)
+	_, err := ref.Set(db.Context(), models.AdminMFA{Enabled: true, Secret: secret, LastUsedStep: step})
+	require.NoError(t, err)
+	h := New(db, &config.Config{AdminPassword: "test-password", AdminTokenSecret: "test-token-secret", SubcollectionID: "test-collection", MFAEnabled: true})
+
+	router := gin.New()
+	router.POST("/api/admin/mfa/disable", func(c *gin.Context) {
+		c.Set(middleware.AdminIDKey, defaultAdminID)
+		h.DisableMFA(c)
+	})
+	disable := func(step int64) int {
+		code, err := totp.CodeAt(secret, step)
+		require.NoError(t, err)
+		body, _ := json.Marshal(map[string]string{"code": code})
+		req, _ := http.NewRequest("POST", "/api/admin/mfa/disable", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// The code that completed the last login can't be replayed
+	assert.Equal(t, http.StatusUnauthorized, disable(step))
+	_, err = ref.Get(db.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, disable(step+1))
+	_, err = ref.Get(db.Context())
+	assert.Error(t, err)
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"appdirect-workshop-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+)
+
+var domainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+type OrganizationDomainRequest struct {
+	Organization string `json:"organization" binding:"required,max=200"`
+}
+
+type OrganizationCount struct {
+	Organization string   `json:"organization"`
+	Domains      []string `json:"domains"`
+	Count        int      `json:"count"`
+	// Mapped is false when Organization is just the email domain
+	Mapped bool `json:"mapped"`
+}
+
+type DomainCount struct {
+	Domain string `json:"domain"`
+	Count  int    `json:"count"`
+}
+
+type FreeMailBreakdown struct {
+	Count   int           `json:"count"`
+	Domains []DomainCount `json:"domains"`
+}
+
+type OrganizationBreakdown struct {
+	Total         int                 `json:"total"`
+	Organizations []OrganizationCount `json:"organizations"`
+	FreeMail      FreeMailBreakdown   `json:"freeMail"`
+	// Invalid counts registrations whose email has no usable domain
+	Invalid int `json:"invalid"`
+}
+
+// organizationDomains loads the domain to organization mapping
+func (h *Handlers) organizationDomains() ([]models.OrganizationDomain, error) {
+	domains := make([]models.OrganizationDomain, 0)
+	iter := h.db.Collection("organization_domains").Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var mapping models.OrganizationDomain
+		if err := doc.DataTo(&mapping); err != nil {
+			continue
+		}
+		mapping.Domain = doc.Ref.ID
+		domains = append(domains, mapping)
+	}
+	sort.Slice(domains, func(i, j int) bool { return domains[i].Domain < domains[j].Domain })
+	return domains, nil
+}
+
+func (h *Handlers) GetOrganizationDomains(c *gin.Context) {
+	domains, err := h.organizationDomains()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch organization domains"})
+		return
+	}
+	c.JSON(http.StatusOK, domains)
+}
+
+// SetOrganizationDomain maps the :domain route parameter to an organization
+func (h *Handlers) SetOrganizationDomain(c *gin.Context) {
+	domain := strings.ToLower(strings.TrimSpace(c.Param("domain")))
+	if !domainPattern.MatchString(domain) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "domain must be a domain name such as example.com"})
+		return
+	}
+
+	var req OrganizationDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	mapping := models.OrganizationDomain{Domain: domain, Organization: strings.TrimSpace(req.Organization)}
+	if mapping.Organization == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization is required"})
+		return
+	}
+
+	before := h.getSnapshot("organization_domains", domain)
+	if _, err := h.db.Collection("organization_domains").Doc(domain).Set(h.db.Context(), mapping); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save organization domain"})
+		return
+	}
+
+	h.recordAudit(c, "organization.map", "organization_domain", domain, before, mapping)
+	c.JSON(http.StatusOK, mapping)
+}
+
+func (h *Handlers) DeleteOrganizationDomain(c *gin.Context) {
+	domain := strings.ToLower(c.Param("domain"))
+	before := h.getSnapshot("organization_domains", domain)
+	if before == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization domain not found"})
+		return
+	}
+
+	if _, err := h.db.Collection("organization_domains").Doc(domain).Delete(h.db.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete organization domain"})
+		return
+	}
+
+	h.recordAudit(c, "organization.unmap", "organization_domain", domain, before, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Organization domain deleted successfully"})
+}
+
+// GetOrganizationBreakdown groups registrations by the organization behind their email
+// domain. Free-mail providers (FREE_MAIL_DOMAINS) are counted separately. Accepts the
+// attendee list filters.
+func (h *Handlers) GetOrganizationBreakdown(c *gin.Context) {
+	filter, err := parseAttendeeFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	domains, err := h.organizationDomains()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch organization domains"})
+		return
+	}
+	mapping := make(map[string]string, len(domains))
+	for _, d := range domains {
+		mapping[d.Domain] = d.Organization
+	}
+
+	var emails []string
+	err = h.forEachAttendee(filter, func(reg models.Registration) error {
+		emails = append(emails, reg.Email)
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch registrations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, organizationBreakdown(emails, h.cfg.FreeMailDomains, mapping))
+}
+
+// organizationBreakdown aggregates emails by organization. A mapping for a domain also
+// covers its subdomains, so "acme.com" catches "eng.acme.com"; unmapped domains are
+// reported as their own organization. Largest groups come first.
+func organizationBreakdown(emails []string, freeMailDomains []string, mapping map[string]string) OrganizationBreakdown {
+	breakdown := OrganizationBreakdown{
+		Total:         len(emails),
+		Organizations: []OrganizationCount{},
+		FreeMail:      FreeMailBreakdown{Domains: []DomainCount{}},
+	}
+
+	freeMail := make(map[string]bool, len(freeMailDomains))
+	for _, domain := range freeMailDomains {
+		freeMail[strings.ToLower(domain)] = true
+	}
+
+	freeMailCounts := map[string]int{}
+	orgs := map[string]*OrganizationCount{}
+	orgDomains := map[string]map[string]bool{}
+	for _, email := range emails {
+		domain := emailDomain(email)
+		if domain == "" {
+			breakdown.Invalid++
+			continue
+		}
+		if freeMail[domain] {
+			breakdown.FreeMail.Count++
+			freeMailCounts[domain]++
+			continue
+		}
+
+		name, mapped := lookupOrganization(domain, mapping)
+		if !mapped {
+			name = domain
+		}
+		org, ok := orgs[name]
+		if !ok {
+			org = &OrganizationCount{Organization: name, Mapped: mapped}
+			orgs[name] = org
+			orgDomains[name] = map[string]bool{}
+		}
+		org.Count++
+		if !orgDomains[name][domain] {
+			orgDomains[name][domain] = true
+			org.Domains = append(org.Domains, domain)
+		}
+	}
+
+	for _, org := range orgs {
+		sort.Strings(org.Domains)
+		breakdown.Organizations = append(breakdown.Organizations, *org)
+	}
+	sort.Slice(breakdown.Organizations, func(i, j int) bool {
+		a, b := breakdown.Organizations[i], breakdown.Organizations[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Organization < b.Organization
+	})
+
+	for domain, count := range freeMailCounts {
+		breakdown.FreeMail.Domains = append(breakdown.FreeMail.Domains, DomainCount{Domain: domain, Count: count})
+	}
+	sort.Slice(breakdown.FreeMail.Domains, func(i, j int) bool {
+		a, b := breakdown.FreeMail.Domains[i], breakdown.FreeMail.Domains[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Domain < b.Domain
+	})
+	return breakdown
+}
+
+// emailDomain returns the lowercased domain of email, or "" if it has none
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	domain := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(email[at+1:])), ".")
+	if !strings.Contains(domain, ".") {
+		return ""
+	}
+	return domain
+}
+
+// lookupOrganization tries domain and then each parent domain
+func lookupOrganization(domain string, mapping map[string]string) (string, bool) {
+	for d := domain; strings.Contains(d, "."); {
+		if name, ok := mapping[d]; ok {
+			return name, true
+		}
+		_, d, _ = strings.Cut(d, ".")
+	}
+	return "", false
+}
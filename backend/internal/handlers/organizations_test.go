@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrganizationBreakdown(t *testing.T) {
+	emails := []string{
+		"a@acme.com",
+		"b@eng.acme.com",
+		"c@Acme.Com",
+		"d@gmail.com",
+		"e@gmail.com",
+		"f@yahoo.com",
+		"g@globex.io",
+		"h@initech.co.uk",
+		"not-an-email",
+		"i@localhost",
+	}
+	mapping := map[string]string{
+		"acme.com":      "Acme Corp",
+		"initech.co.uk": "Initech",
+	}
+
+	breakdown := organizationBreakdown(emails, []string{"gmail.com", "Yahoo.com"}, mapping)
+
+	assert.Equal(t, 10, breakdown.Total)
+	assert.Equal(t, 2, breakdown.Invalid)
+	assert.Equal(t, FreeMailBreakdown{
+		Count: 3,
+		Domains: []DomainCount{
+			{Domain: "gmail.com", Count: 2},
+			{Domain: "yahoo.com", Count: 1},
+		},
+	}, breakdown.FreeMail)
+	assert.Equal(t, []OrganizationCount{
+		{Organization: "Acme Corp", Domains: []string{"acme.com", "eng.acme.com"}, Count: 3, Mapped: true},
+		{Organization: "Initech", Domains: []string{"initech.co.uk"}, Count: 1, Mapped: true},
+		{Organization: "globex.io", Domains: []string{"globex.io"}, Count: 1, Mapped: false},
+	}, breakdown.Organizations)
+}
+
+func TestLookupOrganization(t *testing.T) {
+	mapping := map[string]string{"acme.com": "Acme Corp", "labs.acme.com": "Acme Labs"}
+
+	tests := []struct {
+		domain string
+		want   string
+		mapped bool
+	}{
+		{domain: "acme.com", want: "Acme Corp", mapped: true},
+		{domain: "eu.acme.com", want: "Acme Corp", mapped: true},
+		{domain: "x.labs.acme.com", want: "Acme Labs", mapped: true},
+		{domain: "notacme.com", mapped: false},
+		{domain: "com", mapped: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			got, mapped := lookupOrganization(tt.domain, mapping)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.mapped, mapped)
+		})
+	}
+}
+
+func TestSetOrganizationDomainValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		domain string
+		body   string
+	}{
+		{name: "not a domain", domain: "acme", body: `{"organization":"Acme"}`},
+		{name: "invalid characters", domain: "acme_corp.com", body: `{"organization":"Acme"}`},
+		{name: "missing organization", domain: "acme.com", body: `{}`},
+		{name: "blank organization", domain: "acme.com", body: `{"organization":"  "}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkshopTestHandlers()
+			router := gin.New()
+			router.PUT("/api/admin/organizations/:domain", h.SetOrganizationDomain)
+
+			req, _ := http.NewRequest("PUT", "/api/admin/organizations/"+tt.domain, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
@@ -0,0 +1,528 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"appdirect-workshop-backend/internal/mail"
+	"appdirect-workshop-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Outbox event states
+const (
+	OutboxPending    = "pending"
+	OutboxDispatched = "dispatched"
+	OutboxFailed     = "failed"
+)
+
+const (
+	outboxCollection = "outbox"
+
+	// outboxLease is how long a dispatcher has to hand a claimed event to every
+	// subscriber before another dispatcher may take it over
+	outboxLease = 5 * time.Minute
+	// outboxPollInterval is how often due events are picked up; new events don't wait
+	// for it
+	outboxPollInterval = 30 * time.Second
+	outboxBatchSize    = 50
+	maxOutboxAttempts  = 10
+	// outboxWorkers bounds how many queued events and deliveries are dispatched at once;
+	// beyond outboxQueueSize waiting ones, new ones are left to the poller
+	outboxWorkers   = 4
+	outboxQueueSize = 256
+	// outboxRetention is how long dispatched events are kept
+	outboxRetention = 7 * 24 * time.Hour
+)
+
+// outboxSubscriber is one consumer of outbox events. Delivery is at least once: handle
+// gets the event again if the dispatcher stops before recording it as handled, so it
+// uses the event ID to recognize repeats.
+type outboxSubscriber struct {
+	name   string
+	handle func(h *Handlers, ctx context.Context, event models.OutboxEvent) error
+}
+
+// outboxSubscribers receive every event. Outbox events use the webhook event names.
+var outboxSubscribers = []outboxSubscriber{
+	{name: "live", handle: (*Handlers).publishOutboxEvent},
+	{name: "webhooks", handle: (*Handlers).deliverOutboxEvent},
+	{name: "mail", handle: (*Handlers).mailOutboxEvent},
+}
+
+// GetOutboxEvents lists outbox events, newest first, optionally only those with the
+// given status
+func (h *Handlers) GetOutboxEvents(c *gin.Context) {
+	limit := defaultDeliveryLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxDeliveryLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+			return
+		}
+		limit = n
+	}
+	eventStatus := c.Query("status")
+	if eventStatus != "" && eventStatus != OutboxPending && eventStatus != OutboxDispatched && eventStatus != OutboxFailed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, dispatched or failed"})
+		return
+	}
+
+	query := h.db.Collection(outboxCollection).Query
+	if eventStatus != "" {
+		query = query.Where("status", "==", eventStatus)
+	}
+	events := make([]models.OutboxEvent, 0)
+	iter := query.OrderBy("createdAt", firestore.Desc).Limit(limit).Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch outbox events"})
+			return
+		}
+
+		var event models.OutboxEvent
+		if err := doc.DataTo(&event); err != nil {
+			continue
+		}
+		event.ID = doc.Ref.ID
+		events = append(events, event)
+	}
+	c.JSON(http.StatusOK, events)
+}
+
+// RetryOutboxEvent puts a failed event back in the queue with fresh attempts. Only the
+// subscribers that haven't handled it get it again.
+func (h *Handlers) RetryOutboxEvent(c *gin.Context) {
+	id := c.Param("id")
+	ref := h.db.Collection(outboxCollection).Doc(id)
+
+	var event models.OutboxEvent
+	err := h.db.RunTransaction(func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+		if err := doc.DataTo(&event); err != nil {
+			return err
+		}
+		if event.Status != OutboxFailed {
+			return errOutboxNotFailed
+		}
+		event.Status = OutboxPending
+		event.Attempts = 0
+		event.NextAttemptAt = time.Now()
+		return tx.Set(ref, event)
+	})
+	switch {
+	case status.Code(err) == codes.NotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	case errors.Is(err, errOutboxNotFailed):
+		c.JSON(http.StatusConflict, gin.H{"error": "Only failed events can be retried"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retry event"})
+		return
+	}
+
+	event.ID = id
+	h.queueDispatch(func() { h.dispatchOutboxEvent(id) })
+	h.recordAudit(c, "outbox.retry", "outbox", id, nil, nil)
+	c.JSON(http.StatusAccepted, event)
+}
+
+var errOutboxNotFailed = errors.New("outbox event has not failed")
+
+// outboxEntry is an event to record along with a change
+type outboxEntry struct {
+	eventType string
+	data      interface{}
+}
+
+// writeWithEvent runs write in a transaction that also records an outbox event, so the
+// event is stored if and only if the change is, then queues it for dispatch
+func (h *Handlers) writeWithEvent(eventType string, data interface{}, write func(tx *firestore.Transaction) error) error {
+	return h.transactWithEvents(func(tx *firestore.Transaction) ([]outboxEntry, error) {
+		return []outboxEntry{{eventType, data}}, write(tx)
+	})
+}
+
+// transactWithEvents is writeWithEvent for changes whose events depend on what the
+// transaction reads: fn makes the change and returns the events describing it
+func (h *Handlers) transactWithEvents(fn func(tx *firestore.Transaction) ([]outboxEntry, error)) error {
+	var ids []string
+	err := h.db.RunTransaction(func(ctx context.Context, tx *firestore.Transaction) error {
+		ids = nil
+		entries, err := fn(tx)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			event, err := newOutboxEvent(entry.eventType, entry.data, time.Now())
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(h.db.Collection(outboxCollection).Doc(event.ID), event); err != nil {
+				return err
+			}
+			ids = append(ids, event.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		id := id
+		h.queueDispatch(func() { h.dispatchOutboxEvent(id) })
+	}
+	return nil
+}
+
+// queueDispatch hands fn to the dispatch workers without waiting. When the queue is
+// full fn is dropped; what it would dispatch is stored as due, so the next poll picks
+// it up.
+func (h *Handlers) queueDispatch(fn func()) {
+	select {
+	case h.dispatch <- fn:
+	default:
+	}
+}
+
+func newOutboxEvent(eventType string, data interface{}, now time.Time) (models.OutboxEvent, error) {
+	id, err := generateToken(eventIDPrefix)
+	if err != nil {
+		return models.OutboxEvent{}, err
+	}
+	body, err := json.Marshal(data)
+	if err != nil {
+		return models.OutboxEvent{}, err
+	}
+	return models.OutboxEvent{
+		ID:            id,
+		Type:          eventType,
+		Data:          string(body),
+		Status:        OutboxPending,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+		Handled:       []string{},
+	}, nil
+}
+
+// RunOutbox dispatches every workshop's due outbox events and webhook deliveries until
+// ctx is done. Both are queued for its workers as soon as they are written; polling
+// retries the ones that failed and picks up those that didn't fit in the queue or whose
+// dispatcher stopped, such as on a restart.
+func (h *Handlers) RunOutbox(ctx context.Context) {
+	for i := 0; i < outboxWorkers; i++ {
+		go h.runDispatchWorker(ctx)
+	}
+
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		slugs, err := h.workshopSlugs()
+		if err != nil {
+			log.Printf("Failed to list workshops for the outbox: %v", err)
+			continue
+		}
+		now := time.Now()
+		for _, slug := range slugs {
+			scoped := h.forWorkshop(slug)
+			scoped.dispatchDueOutboxEvents(now)
+			scoped.dispatchDueDeliveries(now)
+			scoped.pruneOutbox(now)
+		}
+	}
+}
+
+func (h *Handlers) runDispatchWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case fn := <-h.dispatch:
+			fn()
+		}
+	}
+}
+
+// workshopSlugs lists every workshop with data, including ones without a workshop
+// document such as the default workshop
+func (h *Handlers) workshopSlugs() ([]string, error) {
+	slugs := []string{h.cfg.SubcollectionID}
+	iter := h.db.Workshops().DocumentRefs(h.db.Context())
+	for {
+		ref, err := iter.Next()
+		if err == iterator.Done {
+			return slugs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ref.ID != h.cfg.SubcollectionID {
+			slugs = append(slugs, ref.ID)
+		}
+	}
+}
+
+func (h *Handlers) dispatchDueOutboxEvents(now time.Time) {
+	iter := h.db.Collection(outboxCollection).
+		Where("status", "==", OutboxPending).
+		Where("nextAttemptAt", "<=", now).
+		OrderBy("nextAttemptAt", firestore.Asc).
+		Limit(outboxBatchSize).
+		Documents(h.db.Context())
+	defer iter.Stop()
+
+	var ids []string
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Printf("Failed to fetch due outbox events for %s: %v", h.cfg.SubcollectionID, err)
+			return
+		}
+		ids = append(ids, doc.Ref.ID)
+	}
+	for _, id := range ids {
+		h.dispatchOutboxEvent(id)
+	}
+}
+
+// pruneOutbox deletes dispatched events past the retention period; they hold copies of
+// registrations
+func (h *Handlers) pruneOutbox(now time.Time) {
+	iter := h.db.Collection(outboxCollection).
+		Where("status", "==", OutboxDispatched).
+		Where("dispatchedAt", "<", now.Add(-outboxRetention)).
+		Limit(outboxBatchSize).
+		Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to prune outbox for %s: %v", h.cfg.SubcollectionID, err)
+			return
+		}
+		if _, err := doc.Ref.Delete(h.db.Context()); err != nil {
+			log.Printf("Failed to delete outbox event %s: %v", doc.Ref.ID, err)
+		}
+	}
+}
+
+// dispatchOutboxEvent claims an event and hands it to the subscribers that haven't
+// handled it yet. An event that isn't due, or is claimed by another dispatcher, is left
+// alone.
+func (h *Handlers) dispatchOutboxEvent(id string) {
+	ref := h.db.Collection(outboxCollection).Doc(id)
+	event, ok, err := h.claimOutboxEvent(ref, time.Now())
+	if err != nil {
+		log.Printf("Failed to claim outbox event %s: %v", id, err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	handled, err := h.handleOutboxEvent(h.db.Context(), event, outboxSubscribers)
+	if err != nil {
+		log.Printf("Outbox event %s (%s) attempt %d: %v", id, event.Type, event.Attempts, err)
+	}
+	event = outboxResult(event, handled, err, time.Now())
+	if _, err := ref.Set(h.db.Context(), event); err != nil {
+		log.Printf("Failed to save outbox event %s: %v", id, err)
+	}
+}
+
+// claimOutboxEvent takes the lease on a due event and counts the attempt
+func (h *Handlers) claimOutboxEvent(ref *firestore.DocumentRef, now time.Time) (models.OutboxEvent, bool, error) {
+	var event models.OutboxEvent
+	claimed := false
+	err := h.db.RunTransaction(func(ctx context.Context, tx *firestore.Transaction) error {
+		claimed = false
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+		if err := doc.DataTo(&event); err != nil {
+			return err
+		}
+		if event.Status != OutboxPending || event.NextAttemptAt.After(now) {
+			return nil
+		}
+		event.Attempts++
+		event.NextAttemptAt = now.Add(outboxLease)
+		claimed = true
+		return tx.Update(ref, []firestore.Update{
+			{Path: "attempts", Value: event.Attempts},
+			{Path: "nextAttemptAt", Value: event.NextAttemptAt},
+		})
+	})
+	event.ID = ref.ID
+	return event, claimed, err
+}
+
+// handleOutboxEvent passes the event to each subscriber not yet in event.Handled and
+// returns the updated list. A failing subscriber doesn't hold up the others.
+func (h *Handlers) handleOutboxEvent(ctx context.Context, event models.OutboxEvent, subscribers []outboxSubscriber) ([]string, error) {
+	handled := append([]string{}, event.Handled...)
+	done := map[string]bool{}
+	for _, name := range handled {
+		done[name] = true
+	}
+
+	var errs []error
+	for _, s := range subscribers {
+		if done[s.name] {
+			continue
+		}
+		if err := s.handle(h, ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+			continue
+		}
+		handled = append(handled, s.name)
+	}
+	return handled, errors.Join(errs...)
+}
+
+// outboxResult records the outcome of an attempt: dispatched once every subscriber has
+// handled the event, otherwise due again after a backoff, or failed when the attempts
+// run out
+func outboxResult(event models.OutboxEvent, handled []string, err error, now time.Time) models.OutboxEvent {
+	event.Handled = handled
+	if err == nil {
+		event.Status = OutboxDispatched
+		event.LastError = ""
+		event.DispatchedAt = &now
+		return event
+	}
+
+	event.LastError = err.Error()
+	if event.Attempts >= maxOutboxAttempts {
+		event.Status = OutboxFailed
+		return event
+	}
+	event.NextAttemptAt = now.Add(outboxRetryDelay(event.Attempts))
+	return event
+}
+
+// outboxRetryDelay doubles from 30 seconds after the first attempt up to an hour
+func outboxRetryDelay(attempts int) time.Duration {
+	delay := 30 * time.Second
+	for i := 1; i < attempts && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	if delay > time.Hour {
+		delay = time.Hour
+	}
+	return delay
+}
+
+// publishOutboxEvent pushes the change to clients on the public stream. Repeats are
+// harmless: each event carries the whole session or speaker, or triggers a recount.
+func (h *Handlers) publishOutboxEvent(ctx context.Context, event models.OutboxEvent) error {
+	data := json.RawMessage(event.Data)
+	switch event.Type {
+	case WebhookRegistrationCreated, WebhookRegistrationCancelled:
+		h.publishRegistrationCount()
+	case WebhookSessionCreated, WebhookSessionUpdated:
+		var session models.Session
+		if err := json.Unmarshal(data, &session); err != nil {
+			return err
+		}
+		// Drafts aren't on the public agenda, so clients drop them
+		if session.Status == SessionDraft {
+			h.publishPublic(EventSessionDeleted, gin.H{"id": session.ID})
+		} else {
+			h.publishPublic(EventSessionUpdated, data)
+		}
+	case WebhookSessionDeleted:
+		h.publishPublic(EventSessionDeleted, data)
+	case WebhookSpeakerCreated, WebhookSpeakerUpdated:
+		h.publishPublic(EventSpeakerUpdated, data)
+	case WebhookSpeakerDeleted:
+		h.publishPublic(EventSpeakerDeleted, data)
+	}
+	return nil
+}
+
+// deliverOutboxEvent starts a delivery to every webhook subscribed to the event. Each
+// delivery's document ID combines the event and webhook IDs, so a repeated event finds
+// the delivery it already started instead of sending it again.
+func (h *Handlers) deliverOutboxEvent(ctx context.Context, event models.OutboxEvent) error {
+	hooks, err := h.subscribedWebhooks(event.Type)
+	if err != nil {
+		return err
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	delivery, err := h.webhookPayload(event)
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		ref := h.db.Collection("webhook_deliveries").Doc(event.ID + "_" + hook.ID)
+		if _, err := h.startDelivery(ref, hook, delivery); err != nil && status.Code(err) != codes.AlreadyExists {
+			return err
+		}
+	}
+	return nil
+}
+
+// mailOutboxEvent sends the registration confirmation. Its Message-ID comes from the
+// event ID, so a confirmation sent twice shows up as one message in most mail clients.
+func (h *Handlers) mailOutboxEvent(ctx context.Context, event models.OutboxEvent) error {
+	if event.Type != WebhookRegistrationCreated || h.mailer == nil {
+		return nil
+	}
+	var reg models.Registration
+	if err := json.Unmarshal([]byte(event.Data), &reg); err != nil {
+		return err
+	}
+
+	title := ""
+	if workshop, err := h.getWorkshop(h.cfg.SubcollectionID); err == nil {
+		title = workshopDisplayName(workshop)
+	}
+	subject, body := confirmationEmail(reg, title)
+	return h.mailer.Send(ctx, mail.Message{To: reg.Email, Subject: subject, Body: body, ID: event.ID + ".confirmation"})
+}
+
+func confirmationEmail(reg models.Registration, workshopTitle string) (subject, body string) {
+	if workshopTitle == "" {
+		return "Registration confirmed", "Hi " + reg.Name + ",\n\nYour registration is confirmed. See you there!\n"
+	}
+	return "You're registered for " + workshopTitle,
+		"Hi " + reg.Name + ",\n\nYour registration for " + workshopTitle + " is confirmed. See you there!\n"
+}
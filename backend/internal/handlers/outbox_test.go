@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"appdirect-workshop-backend/internal/config"
+	"appdirect-workshop-backend/internal/database/firestoretest"
+	"appdirect-workshop-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOutboxEvent(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	event, err := newOutboxEvent(WebhookSessionDeleted, gin.H{"id": "s1"}, now)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(event.ID, eventIDPrefix))
+	assert.Equal(t, WebhookSessionDeleted, event.Type)
+	assert.Equal(t, `{"id":"s1"}`, event.Data)
+	assert.Equal(t, OutboxPending, event.Status)
+	assert.Equal(t, now, event.NextAttemptAt)
+	assert.Empty(t, event.Handled)
+
+	other, err := newOutboxEvent(WebhookSessionDeleted, gin.H{"id": "s1"}, now)
+	require.NoError(t, err)
+	assert.NotEqual(t, event.ID, other.ID)
+}
+
+func TestHandleOutboxEvent(t *testing.T) {
+	h := newWorkshopTestHandlers()
+	var calls []string
+	subscriber := func(name string, err error) outboxSubscriber {
+		return outboxSubscriber{name: name, handle: func(h *Handlers, ctx context.Context, event models.OutboxEvent) error {
+			calls = append(calls, name)
+			return err
+		}}
+	}
+	subscribers := []outboxSubscriber{
+		subscriber("live", nil),
+		subscriber("webhooks", errors.New("unavailable")),
+		subscriber("mail", nil),
+	}
+
+	event := models.OutboxEvent{ID: "evt_1", Handled: []string{"live"}}
+	handled, err := h.handleOutboxEvent(context.Background(), event, subscribers)
+
+	// Subscribers that already handled the event are skipped, and a failure doesn't
+	// stop the rest
+	assert.Equal(t, []string{"webhooks", "mail"}, calls)
+	assert.Equal(t, []string{"live", "mail"}, handled)
+	require.Error(t, err)
+	assert.Equal(t, "webhooks: unavailable", err.Error())
+	assert.Equal(t, []string{"live"}, event.Handled)
+}
+
+func TestOutboxResult(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	all := []string{"live", "webhooks", "mail"}
+
+	t.Run("dispatched", func(t *testing.T) {
+		event := outboxResult(models.OutboxEvent{Status: OutboxPending, Attempts: 2, LastError: "earlier"}, all, nil, now)
+		assert.Equal(t, OutboxDispatched, event.Status)
+		assert.Equal(t, all, event.Handled)
+		assert.Empty(t, event.LastError)
+		require.NotNil(t, event.DispatchedAt)
+		assert.Equal(t, now, *event.DispatchedAt)
+	})
+
+	t.Run("retried with backoff", func(t *testing.T) {
+		event := outboxResult(models.OutboxEvent{Status: OutboxPending, Attempts: 3}, all[:1], errors.New("webhooks: down"), now)
+		assert.Equal(t, OutboxPending, event.Status)
+		assert.Equal(t, "webhooks: down", event.LastError)
+		assert.Equal(t, now.Add(2*time.Minute), event.NextAttemptAt)
+		assert.Nil(t, event.DispatchedAt)
+	})
+
+	t.Run("failed after the last attempt", func(t *testing.T) {
+		event := outboxResult(models.OutboxEvent{Status: OutboxPending, Attempts: maxOutboxAttempts}, nil, errors.New("mail: refused"), now)
+		assert.Equal(t, OutboxFailed, event.Status)
+		assert.Equal(t, "mail: refused", event.LastError)
+	})
+}
+
+func TestOutboxRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, outboxRetryDelay(1))
+	assert.Equal(t, time.Minute, outboxRetryDelay(2))
+	assert.Equal(t, 4*time.Minute, outboxRetryDelay(4))
+	assert.Equal(t, time.Hour, outboxRetryDelay(8))
+	assert.Equal(t, time.Hour, outboxRetryDelay(maxOutboxAttempts))
+}
+
+func TestMailOutboxEvent(t *testing.T) {
+	h := newWorkshopTestHandlers()
+	sender := &fakeSender{}
+	h.mailer = sender
+	h.workshops.put(models.Workshop{Slug: "default-workshop", Name: "AI Workshop"})
+
+	created, err := newOutboxEvent(WebhookRegistrationCreated, models.Registration{ID: "reg1", Name: "Ada", Email: "ada@example.com"}, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, h.mailOutboxEvent(context.Background(), created))
+
+	require.Len(t, sender.sent, 1)
+	msg := sender.sent[0]
+	assert.Equal(t, "ada@example.com", msg.To)
+	assert.Equal(t, "You're registered for AI Workshop", msg.Subject)
+	assert.Equal(t, "Hi Ada,\n\nYour registration for AI Workshop is confirmed. See you there!\n", msg.Body)
+	assert.Equal(t, created.ID+".confirmation", msg.ID)
+
+	// Other events send nothing
+	updated, err := newOutboxEvent(WebhookSessionUpdated, models.Session{ID: "s1"}, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, h.mailOutboxEvent(context.Background(), updated))
+	assert.Len(t, sender.sent, 1)
+}
+
+func TestMailOutboxEventWithoutSMTP(t *testing.T) {
+	h := newWorkshopTestHandlers()
+	event, err := newOutboxEvent(WebhookRegistrationCreated, models.Registration{Email: "ada@example.com"}, time.Now())
+	require.NoError(t, err)
+	assert.NoError(t, h.mailOutboxEvent(context.Background(), event))
+}
+
+func TestPublishOutboxEvent(t *testing.T) {
+	h := newWorkshopTestHandlers()
+	events, cancel := h.hub.Subscribe(h.publicTopic())
+	defer cancel()
+
+	event, err := newOutboxEvent(WebhookSpeakerCreated, models.Speaker{ID: "sp1", Name: "Grace"}, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, h.publishOutboxEvent(context.Background(), event))
+
+	select {
+	case got := <-events:
+		assert.Equal(t, EventSpeakerUpdated, got.Type)
+		var buf bytes.Buffer
+		require.NoError(t, writeEvent(&buf, got))
+		assert.Contains(t, buf.String(), `"name":"Grace"`)
+	case <-time.After(time.Second):
+		t.Fatal("no event published")
+	}
+}
+
+func TestPublishOutboxEventDraftSession(t *testing.T) {
+	h := newWorkshopTestHandlers()
+	events, cancel := h.hub.Subscribe(h.publicTopic())
+	defer cancel()
+
+	event, err := newOutboxEvent(WebhookSessionCreated, models.Session{ID: "s1", Title: "Draft", Status: SessionDraft}, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, h.publishOutboxEvent(context.Background(), event))
+
+	select {
+	case got := <-events:
+		assert.Equal(t, EventSessionDeleted, got.Type)
+		assert.Equal(t, gin.H{"id": "s1"}, got.Data)
+	case <-time.After(time.Second):
+		t.Fatal("no event published")
+	}
+}
+
+func TestOutboxRequestValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "bad limit", path: "/api/admin/outbox?limit=0"},
+		{name: "unknown status", path: "/api/admin/outbox?status=retrying"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkshopTestHandlers()
+			router := gin.New()
+			router.GET("/api/admin/outbox", h.GetOutboxEvents)
+
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestTransactWithEventsQueuesDispatch(t *testing.T) {
+	db := firestoretest.NewDatabase(t, "test-collection")
+	h := New(db, &config.Config{SubcollectionID: "test-collection"})
+
+	// One more event than the queue holds: the extra one is left to the poller
+	// instead of waiting for a worker
+	err := h.transactWithEvents(func(tx *firestore.Transaction) ([]outboxEntry, error) {
+		entries := make([]outboxEntry, outboxQueueSize+1)
+		for i := range entries {
+			entries[i] = outboxEntry{WebhookSessionDeleted, gin.H{"id": i}}
+		}
+		return entries, nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, h.dispatch, outboxQueueSize)
+
+	countByStatus := func() map[string]int {
+		docs, err := db.Collection(outboxCollection).Documents(db.Context()).GetAll()
+		require.NoError(t, err)
+		counts := map[string]int{}
+		for _, doc := range docs {
+			var event models.OutboxEvent
+			require.NoError(t, doc.DataTo(&event))
+			counts[event.Status]++
+		}
+		return counts
+	}
+	assert.Equal(t, map[string]int{OutboxPending: outboxQueueSize + 1}, countByStatus())
+
+	// A worker running a queued dispatch handles its event
+	fn := <-h.dispatch
+	fn()
+	assert.Equal(t, map[string]int{OutboxPending: outboxQueueSize, OutboxDispatched: 1}, countByStatus())
+}
@@ -0,0 +1,428 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"appdirect-workshop-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Poll states. Drafts are only visible to admins; votes are accepted while open.
+const (
+	PollDraft  = "draft"
+	PollOpen   = "open"
+	PollClosed = "closed"
+)
+
+// Events on a session's poll stream. Updates carry the poll with its results;
+// removals carry just its id.
+const (
+	EventPollUpdated = "poll.updated"
+	EventPollRemoved = "poll.removed"
+)
+
+const maxPollOptions = 10
+
+var (
+	errPollNotFound = errors.New("poll not found")
+	errPollNotOpen  = errors.New("poll is not open")
+	// errInvalidChoice is wrapped with the reason a ballot was rejected
+	errInvalidChoice = errors.New("invalid choice")
+)
+
+type CreatePollRequest struct {
+	Question string   `json:"question" binding:"required,max=300"`
+	Options  []string `json:"options" binding:"required,min=2,max=10,dive,max=200"`
+	Multiple bool     `json:"multiple"`
+}
+
+type UpdatePollStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=draft open closed"`
+}
+
+type PollVoteRequest struct {
+	OptionIDs []string `json:"optionIds" binding:"required,min=1"`
+}
+
+// GetPolls lists a session's open and closed polls with their results
+func (h *Handlers) GetPolls(c *gin.Context) {
+	polls, err := h.sessionPolls(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch polls"})
+		return
+	}
+
+	published := make([]models.Poll, 0, len(polls))
+	for _, poll := range polls {
+		if poll.Status != PollDraft {
+			published = append(published, poll)
+		}
+	}
+	c.JSON(http.StatusOK, published)
+}
+
+// StreamPolls pushes poll openings, closings and result changes as Server-Sent Events
+func (h *Handlers) StreamPolls(c *gin.Context) {
+	sessionID := c.Param("id")
+	if !h.sessionExists(c, sessionID) {
+		return
+	}
+
+	h.stream(c, h.pollsTopic(sessionID))
+}
+
+// VotePoll records the authenticated attendee's ballot
+func (h *Handlers) VotePoll(c *gin.Context) {
+	attendee := c.MustGet(AttendeeKey).(*models.Registration)
+
+	var req PollVoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	poll, err := h.castPollVote(c.Param("id"), c.Param("pollId"), attendee.ID, req.OptionIDs)
+	switch {
+	case errors.Is(err, errPollNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Poll not found"})
+		return
+	case errors.Is(err, errPollNotOpen):
+		c.JSON(http.StatusConflict, gin.H{"error": "This poll is not open for voting"})
+		return
+	case errors.Is(err, errAlreadyVoted):
+		c.JSON(http.StatusConflict, gin.H{"error": "You have already voted in this poll"})
+		return
+	case errors.Is(err, errInvalidChoice):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record vote"})
+		return
+	}
+
+	h.hub.Publish(h.pollsTopic(poll.SessionID), EventPollUpdated, poll)
+	c.JSON(http.StatusOK, poll)
+}
+
+// GetMyPollVotes maps the session's poll IDs to the options the attendee chose
+func (h *Handlers) GetMyPollVotes(c *gin.Context) {
+	attendee := c.MustGet(AttendeeKey).(*models.Registration)
+
+	votes := map[string][]string{}
+	iter := h.db.Collection("poll_votes").
+		Where("registrationId", "==", attendee.ID).
+		Where("sessionId", "==", c.Param("id")).
+		Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch votes"})
+			return
+		}
+
+		var vote models.PollVote
+		if err := doc.DataTo(&vote); err != nil {
+			continue
+		}
+		votes[vote.PollID] = vote.OptionIDs
+	}
+	c.JSON(http.StatusOK, votes)
+}
+
+// GetAllPolls lists every poll of a session, drafts included
+func (h *Handlers) GetAllPolls(c *gin.Context) {
+	polls, err := h.sessionPolls(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch polls"})
+		return
+	}
+	c.JSON(http.StatusOK, polls)
+}
+
+// CreatePoll adds a draft poll to a session
+func (h *Handlers) CreatePoll(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	var req CreatePollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	poll, err := req.poll(sessionID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.sessionExists(c, sessionID) {
+		return
+	}
+
+	docRef, _, err := h.db.Collection("polls").Add(h.db.Context(), poll)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create poll"})
+		return
+	}
+
+	poll.ID = docRef.ID
+	h.recordAudit(c, "poll.create", "poll", poll.ID, nil, poll)
+	c.JSON(http.StatusCreated, poll)
+}
+
+// UpdatePollStatus opens or closes a poll. Results are kept when a poll is reopened.
+func (h *Handlers) UpdatePollStatus(c *gin.Context) {
+	sessionID, pollID := c.Param("id"), c.Param("pollId")
+
+	var req UpdatePollStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	poll, err := h.getPoll(sessionID, pollID)
+	if errors.Is(err, errPollNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Poll not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch poll"})
+		return
+	}
+	if req.Status == PollDraft && poll.Voters > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "A poll with votes can't return to draft"})
+		return
+	}
+
+	_, err = h.db.Collection("polls").Doc(pollID).Update(h.db.Context(), []firestore.Update{{Path: "status", Value: req.Status}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update poll"})
+		return
+	}
+
+	previous := poll.Status
+	poll.Status = req.Status
+	h.recordAudit(c, "poll.status", "poll", pollID, gin.H{"status": previous}, gin.H{"status": req.Status})
+	if poll.Status == PollDraft {
+		h.hub.Publish(h.pollsTopic(sessionID), EventPollRemoved, gin.H{"id": pollID})
+	} else {
+		h.hub.Publish(h.pollsTopic(sessionID), EventPollUpdated, poll)
+	}
+	c.JSON(http.StatusOK, poll)
+}
+
+// DeletePoll removes a poll; its ballots are left for the audit trail
+func (h *Handlers) DeletePoll(c *gin.Context) {
+	sessionID, pollID := c.Param("id"), c.Param("pollId")
+
+	poll, err := h.getPoll(sessionID, pollID)
+	if errors.Is(err, errPollNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Poll not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch poll"})
+		return
+	}
+
+	if _, err := h.db.Collection("polls").Doc(pollID).Delete(h.db.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete poll"})
+		return
+	}
+
+	h.recordAudit(c, "poll.delete", "poll", pollID, poll, nil)
+	if poll.Status != PollDraft {
+		h.hub.Publish(h.pollsTopic(sessionID), EventPollRemoved, gin.H{"id": pollID})
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Poll deleted successfully"})
+}
+
+// castPollVote stores the ballot and updates the counts in one transaction. The ballot
+// is created rather than set, so a registration's second vote fails even when both
+// arrive at once.
+func (h *Handlers) castPollVote(sessionID, pollID, registrationID string, optionIDs []string) (models.Poll, error) {
+	pollRef := h.db.Collection("polls").Doc(pollID)
+	voteRef := h.db.Collection("poll_votes").Doc(pollID + "_" + registrationID)
+
+	var poll models.Poll
+	err := h.db.RunTransaction(func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(pollRef)
+		if status.Code(err) == codes.NotFound {
+			return errPollNotFound
+		}
+		if err != nil {
+			return err
+		}
+		poll = models.Poll{}
+		if err := doc.DataTo(&poll); err != nil {
+			return err
+		}
+		poll.ID = doc.Ref.ID
+		if poll.SessionID != sessionID || poll.Status == PollDraft {
+			return errPollNotFound
+		}
+		if poll.Status != PollOpen {
+			return errPollNotOpen
+		}
+		choice, err := validateChoice(poll, optionIDs)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Get(voteRef)
+		if err == nil {
+			return errAlreadyVoted
+		}
+		if status.Code(err) != codes.NotFound {
+			return err
+		}
+
+		err = tx.Create(voteRef, models.PollVote{
+			PollID:         pollID,
+			SessionID:      sessionID,
+			RegistrationID: registrationID,
+			OptionIDs:      choice,
+			CreatedAt:      time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+
+		updates := []firestore.Update{{Path: "voters", Value: firestore.Increment(1)}}
+		if poll.Votes == nil {
+			poll.Votes = map[string]int{}
+		}
+		for _, id := range choice {
+			updates = append(updates, firestore.Update{Path: "votes." + id, Value: firestore.Increment(1)})
+			poll.Votes[id]++
+		}
+		poll.Voters++
+		return tx.Update(pollRef, updates)
+	})
+	// A concurrent ballot from the same registration was committed first
+	if status.Code(err) == codes.AlreadyExists {
+		return poll, errAlreadyVoted
+	}
+	return poll, err
+}
+
+// validateChoice checks a ballot against the poll's options and returns the chosen
+// option IDs without duplicates
+func validateChoice(poll models.Poll, optionIDs []string) ([]string, error) {
+	valid := make(map[string]bool, len(poll.Options))
+	for _, option := range poll.Options {
+		valid[option.ID] = true
+	}
+
+	seen := map[string]bool{}
+	var choice []string
+	for _, id := range optionIDs {
+		if !valid[id] {
+			return nil, fmt.Errorf("%w: unknown option %q", errInvalidChoice, id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			choice = append(choice, id)
+		}
+	}
+	if len(choice) == 0 {
+		return nil, fmt.Errorf("%w: choose an option", errInvalidChoice)
+	}
+	if !poll.Multiple && len(choice) > 1 {
+		return nil, fmt.Errorf("%w: this poll accepts a single option", errInvalidChoice)
+	}
+	return choice, nil
+}
+
+// poll validates the request and builds a draft poll with option IDs opt1, opt2, ...
+func (req *CreatePollRequest) poll(sessionID string) (models.Poll, error) {
+	poll := models.Poll{
+		SessionID: sessionID,
+		Question:  strings.TrimSpace(req.Question),
+		Multiple:  req.Multiple,
+		Status:    PollDraft,
+		Votes:     map[string]int{},
+		CreatedAt: time.Now(),
+	}
+	if poll.Question == "" {
+		return poll, errors.New("question is required")
+	}
+
+	seen := map[string]bool{}
+	for _, text := range req.Options {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return poll, errors.New("options must not be blank")
+		}
+		key := strings.ToLower(text)
+		if seen[key] {
+			return poll, fmt.Errorf("option %q is listed twice", text)
+		}
+		seen[key] = true
+		id := fmt.Sprintf("opt%d", len(poll.Options)+1)
+		poll.Options = append(poll.Options, models.PollOption{ID: id, Text: text})
+		poll.Votes[id] = 0
+	}
+	if len(poll.Options) < 2 || len(poll.Options) > maxPollOptions {
+		return poll, fmt.Errorf("a poll needs between 2 and %d options", maxPollOptions)
+	}
+	return poll, nil
+}
+
+func (h *Handlers) getPoll(sessionID, pollID string) (models.Poll, error) {
+	var poll models.Poll
+	doc, err := h.db.Collection("polls").Doc(pollID).Get(h.db.Context())
+	if status.Code(err) == codes.NotFound {
+		return poll, errPollNotFound
+	}
+	if err != nil {
+		return poll, err
+	}
+	if err := doc.DataTo(&poll); err != nil || poll.SessionID != sessionID {
+		return poll, errPollNotFound
+	}
+	poll.ID = doc.Ref.ID
+	return poll, nil
+}
+
+// sessionPolls loads a session's polls, oldest first
+func (h *Handlers) sessionPolls(sessionID string) ([]models.Poll, error) {
+	polls := make([]models.Poll, 0)
+	iter := h.db.Collection("polls").Where("sessionId", "==", sessionID).Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var poll models.Poll
+		if err := doc.DataTo(&poll); err != nil {
+			continue
+		}
+		poll.ID = doc.Ref.ID
+		polls = append(polls, poll)
+	}
+	sort.SliceStable(polls, func(i, j int) bool { return polls[i].CreatedAt.Before(polls[j].CreatedAt) })
+	return polls, nil
+}
+
+func (h *Handlers) pollsTopic(sessionID string) string {
+	return h.topic("sessions/" + sessionID + "/polls")
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"appdirect-workshop-backend/internal/config"
+	"appdirect-workshop-backend/internal/database/firestoretest"
+	"appdirect-workshop-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreatePollRequest(t *testing.T) {
+	req := CreatePollRequest{Question: "  Favourite language? ", Options: []string{" Go ", "Rust"}, Multiple: true}
+	poll, err := req.poll("s1")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Favourite language?", poll.Question)
+	assert.Equal(t, PollDraft, poll.Status)
+	assert.Equal(t, []models.PollOption{{ID: "opt1", Text: "Go"}, {ID: "opt2", Text: "Rust"}}, poll.Options)
+	assert.Equal(t, map[string]int{"opt1": 0, "opt2": 0}, poll.Votes)
+
+	invalid := []CreatePollRequest{
+		{Question: " ", Options: []string{"Go", "Rust"}},
+		{Question: "Q?", Options: []string{"Go", " "}},
+		{Question: "Q?", Options: []string{"Go", "go"}},
+	}
+	for _, req := range invalid {
+		_, err := req.poll("s1")
+		assert.Error(t, err)
+	}
+}
+
+func TestValidateChoice(t *testing.T) {
+	options := []models.PollOption{{ID: "opt1", Text: "Go"}, {ID: "opt2", Text: "Rust"}, {ID: "opt3", Text: "Zig"}}
+	single := models.Poll{Options: options}
+	multiple := models.Poll{Options: options, Multiple: true}
+
+	tests := []struct {
+		name    string
+		poll    models.Poll
+		choice  []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "single option", poll: single, choice: []string{"opt2"}, want: []string{"opt2"}},
+		{name: "repeated option counts once", poll: single, choice: []string{"opt2", "opt2"}, want: []string{"opt2"}},
+		{name: "two options on single choice", poll: single, choice: []string{"opt1", "opt2"}, wantErr: true},
+		{name: "unknown option", poll: multiple, choice: []string{"opt9"}, wantErr: true},
+		{name: "no options", poll: multiple, choice: nil, wantErr: true},
+		{name: "multiple options", poll: multiple, choice: []string{"opt3", "opt1"}, want: []string{"opt3", "opt1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateChoice(tt.poll, tt.choice)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errInvalidChoice)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPollRequestValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{name: "create without options", method: "POST", path: "/api/admin/sessions/s1/polls", body: `{"question":"Q?"}`},
+		{name: "create with one option", method: "POST", path: "/api/admin/sessions/s1/polls", body: `{"question":"Q?","options":["Yes"]}`},
+		{name: "create with too many options", method: "POST", path: "/api/admin/sessions/s1/polls", body: `{"question":"Q?","options":["1","2","3","4","5","6","7","8","9","10","11"]}`},
+		{name: "unknown status", method: "PUT", path: "/api/admin/sessions/s1/polls/p1/status", body: `{"status":"archived"}`},
+		{name: "vote without options", method: "POST", path: "/api/sessions/s1/polls/p1/vote", body: `{"optionIds":[]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkshopTestHandlers()
+			router := gin.New()
+			router.POST("/api/admin/sessions/:id/polls", h.CreatePoll)
+			router.PUT("/api/admin/sessions/:id/polls/:pollId/status", h.UpdatePollStatus)
+			router.POST("/api/sessions/:id/polls/:pollId/vote", func(c *gin.Context) {
+				c.Set(AttendeeKey, &models.Registration{ID: "reg1"})
+			}, h.VotePoll)
+
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestVotePoll(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := firestoretest.NewDatabase(t, "test-collection")
+	options := []models.PollOption{{ID: "opt1", Text: "Go"}, {ID: "opt2", Text: "Rust"}}
+	for id, state := range map[string]string{"open": PollOpen, "closed": PollClosed} {
+		_, err := db.Collection("polls").Doc(id).Set(db.Context(), models.Poll{
+			SessionID: "s1",
+			Question:  "Favourite language?",
+			Options:   options,
+			Status:    state,
+			Votes:     map[string]int{"opt1": 0, "opt2": 0},
+		})
+		require.NoError(t, err)
+	}
+	h := New(db, &config.Config{SubcollectionID: "test-collection"})
+
+	router := gin.New()
+	router.POST("/api/sessions/:id/polls/:pollId/vote", func(c *gin.Context) {
+		c.Set(AttendeeKey, &models.Registration{ID: c.GetHeader("X-Registration")})
+	}, h.VotePoll)
+	vote := func(pollID, registrationID, optionID string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(PollVoteRequest{OptionIDs: []string{optionID}})
+		req, _ := http.NewRequest("POST", "/api/sessions/s1/polls/"+pollID+"/vote", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Registration", registrationID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := vote("open", "reg1", "opt2")
+	require.Equal(t, http.StatusOK, w.Code)
+	var poll models.Poll
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &poll))
+	assert.Equal(t, map[string]int{"opt1": 0, "opt2": 1}, poll.Votes)
+	assert.Equal(t, 1, poll.Voters)
+
+	require.Equal(t, http.StatusOK, vote("open", "reg2", "opt2").Code)
+
+	// One ballot per registration, even for a different option
+	assert.Equal(t, http.StatusConflict, vote("open", "reg1", "opt1").Code)
+
+	// Ballots sent at once still count once
+	results := make(chan int, 2)
+	for _, optionID := range []string{"opt1", "opt2"} {
+		go func(optionID string) { results <- vote("open", "reg3", optionID).Code }(optionID)
+	}
+	assert.ElementsMatch(t, []int{http.StatusOK, http.StatusConflict}, []int{<-results, <-results})
+
+	assert.Equal(t, http.StatusConflict, vote("closed", "reg1", "opt1").Code)
+	_, err := db.Collection("poll_votes").Doc("closed_reg1").Get(db.Context())
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	stored, err := h.getPoll("s1", "open")
+	require.NoError(t, err)
+	assert.Equal(t, 3, stored.Voters)
+	assert.Equal(t, 3, stored.Votes["opt1"]+stored.Votes["opt2"])
+	closed, err := h.getPoll("s1", "closed")
+	require.NoError(t, err)
+	assert.Equal(t, 0, closed.Voters)
+
+	doc, err := db.Collection("poll_votes").Doc("open_reg1").Get(db.Context())
+	require.NoError(t, err)
+	var ballot models.PollVote
+	require.NoError(t, doc.DataTo(&ballot))
+	assert.Equal(t, []string{"opt2"}, ballot.OptionIDs)
+}
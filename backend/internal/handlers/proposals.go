@@ -0,0 +1,520 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"appdirect-workshop-backend/internal/middleware"
+	"appdirect-workshop-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Proposal states
+const (
+	ProposalSubmitted = "submitted"
+	ProposalAccepted  = "accepted"
+	ProposalRejected  = "rejected"
+)
+
+const maxProposalLinks = 5
+
+type ProposalRequest struct {
+	Title    string   `json:"title" binding:"required,max=200"`
+	Abstract string   `json:"abstract" binding:"required,max=5000"`
+	Name     string   `json:"name" binding:"required,max=200"`
+	Email    string   `json:"email" binding:"required,email"`
+	Bio      string   `json:"bio" binding:"required,max=2000"`
+	Links    []string `json:"links"`
+
+	// Website, Challenge and Solution work as in RegisterRequest
+	Website   string `json:"website"`
+	Challenge string `json:"challenge"`
+	Solution  string `json:"solution"`
+}
+
+type ProposalReviewRequest struct {
+	Score   int    `json:"score" binding:"required,min=1,max=5"`
+	Comment string `json:"comment" binding:"max=2000"`
+}
+
+type UpdateCFPRequest struct {
+	Open     bool       `json:"open"`
+	ClosesAt *time.Time `json:"closesAt"`
+}
+
+// CFP is whether the call for papers is taking proposals
+type CFP struct {
+	Open     bool       `json:"open"`
+	ClosesAt *time.Time `json:"closesAt,omitempty"`
+}
+
+// ProposalSummary adds the average review score, which is omitted until someone reviews
+type ProposalSummary struct {
+	models.Proposal
+	AverageScore *float64 `json:"averageScore,omitempty"`
+}
+
+// ProposalDetails is a proposal with every review
+type ProposalDetails struct {
+	ProposalSummary
+	Reviews []models.ProposalReview `json:"reviews"`
+}
+
+// AcceptProposalResponse returns the speaker and draft session created on acceptance
+type AcceptProposalResponse struct {
+	Proposal models.Proposal `json:"proposal"`
+	Speaker  models.Speaker  `json:"speaker"`
+	Session  models.Session  `json:"session"`
+}
+
+var errProposalDecided = errors.New("proposal has already been decided")
+
+// SubmitProposal accepts a talk proposal while the call for papers is open
+func (h *Handlers) SubmitProposal(c *gin.Context) {
+	var req ProposalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Website != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid proposal"})
+		return
+	}
+
+	if workshop, ok := c.Get(WorkshopKey); ok {
+		if !workshopCFP(workshop.(*models.Workshop), time.Now()).Open {
+			c.JSON(http.StatusForbidden, gin.H{"error": "The call for papers is closed"})
+			return
+		}
+	}
+
+	proposal, err := req.proposal(time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.verifyHuman(c, req.Challenge, req.Solution) {
+		return
+	}
+
+	docRef, _, err := h.db.Collection("proposals").Add(h.db.Context(), proposal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit proposal"})
+		return
+	}
+
+	// Only echo back what the speaker sent; review state is for admins
+	c.JSON(http.StatusCreated, gin.H{"id": docRef.ID, "title": proposal.Title, "status": proposal.Status})
+}
+
+// GetProposals lists proposals, newest first, optionally only those with the given status
+func (h *Handlers) GetProposals(c *gin.Context) {
+	proposalStatus := c.Query("status")
+	if proposalStatus != "" && !validProposalStatus(proposalStatus) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be submitted, accepted or rejected"})
+		return
+	}
+
+	query := h.db.Collection("proposals").Query
+	if proposalStatus != "" {
+		query = query.Where("status", "==", proposalStatus)
+	}
+	proposals := make([]ProposalSummary, 0)
+	iter := query.OrderBy("createdAt", firestore.Desc).Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch proposals"})
+			return
+		}
+
+		var proposal models.Proposal
+		if err := doc.DataTo(&proposal); err != nil {
+			continue
+		}
+		proposal.ID = doc.Ref.ID
+		proposals = append(proposals, summarizeProposal(proposal))
+	}
+	c.JSON(http.StatusOK, proposals)
+}
+
+// GetProposal returns a proposal with its reviews, oldest first
+func (h *Handlers) GetProposal(c *gin.Context) {
+	id := c.Param("id")
+	proposal, err := h.getProposal(id)
+	if status.Code(err) == codes.NotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Proposal not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch proposal"})
+		return
+	}
+
+	reviews := make([]models.ProposalReview, 0)
+	iter := h.db.Collection("proposal_reviews").Where("proposalId", "==", id).OrderBy("createdAt", firestore.Asc).Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reviews"})
+			return
+		}
+
+		var review models.ProposalReview
+		if err := doc.DataTo(&review); err != nil {
+			continue
+		}
+		review.ID = doc.Ref.ID
+		reviews = append(reviews, review)
+	}
+	c.JSON(http.StatusOK, ProposalDetails{ProposalSummary: summarizeProposal(proposal), Reviews: reviews})
+}
+
+// ReviewProposal sets the caller's score and comment on a proposal, replacing their
+// earlier review. The proposal's totals are updated in the same transaction.
+func (h *Handlers) ReviewProposal(c *gin.Context) {
+	id := c.Param("id")
+	var req ProposalReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reviewerID := c.GetString(middleware.AdminIDKey)
+	proposalRef := h.db.Collection("proposals").Doc(id)
+	reviewRef := h.db.Collection("proposal_reviews").Doc(id + "_" + reviewerID)
+	now := time.Now()
+
+	var review models.ProposalReview
+	err := h.db.RunTransaction(func(ctx context.Context, tx *firestore.Transaction) error {
+		proposalDoc, err := tx.Get(proposalRef)
+		if err != nil {
+			return err
+		}
+		var proposal models.Proposal
+		if err := proposalDoc.DataTo(&proposal); err != nil {
+			return err
+		}
+		if proposal.Status != ProposalSubmitted {
+			return errProposalDecided
+		}
+
+		var previous *models.ProposalReview
+		reviewDoc, err := tx.Get(reviewRef)
+		switch {
+		case status.Code(err) == codes.NotFound:
+		case err != nil:
+			return err
+		default:
+			previous = &models.ProposalReview{}
+			if err := reviewDoc.DataTo(previous); err != nil {
+				return err
+			}
+		}
+
+		review = models.ProposalReview{
+			ProposalID: id,
+			ReviewerID: reviewerID,
+			Score:      req.Score,
+			Comment:    strings.TrimSpace(req.Comment),
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		if previous != nil {
+			review.CreatedAt = previous.CreatedAt
+		}
+		count, total := tallyReview(proposal, previous, review.Score)
+		if err := tx.Set(reviewRef, review); err != nil {
+			return err
+		}
+		return tx.Update(proposalRef, []firestore.Update{
+			{Path: "reviewCount", Value: count},
+			{Path: "scoreTotal", Value: total},
+		})
+	})
+	switch {
+	case status.Code(err) == codes.NotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Proposal not found"})
+		return
+	case errors.Is(err, errProposalDecided):
+		c.JSON(http.StatusConflict, gin.H{"error": "Proposal has already been decided"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save review"})
+		return
+	}
+
+	review.ID = reviewRef.ID
+	h.recordAudit(c, "proposal.review", "proposal", id, nil, review)
+	c.JSON(http.StatusOK, review)
+}
+
+// AcceptProposal creates a speaker and a draft session for the proposal, linked by
+// SpeakerIDs, and marks it accepted, all in one transaction
+func (h *Handlers) AcceptProposal(c *gin.Context) {
+	id := c.Param("id")
+	proposalRef := h.db.Collection("proposals").Doc(id)
+	speakerRef := h.db.Collection("speakers").NewDoc()
+	sessionRef := h.db.Collection("sessions").NewDoc()
+	now := time.Now()
+
+	var resp AcceptProposalResponse
+	err := h.transactWithEvents(func(tx *firestore.Transaction) ([]outboxEntry, error) {
+		proposal, err := h.decideProposal(tx, proposalRef)
+		if err != nil {
+			return nil, err
+		}
+
+		speaker, session := proposalSpeaker(proposal), proposalSession(proposal)
+		speaker.ID, session.ID = speakerRef.ID, sessionRef.ID
+		session.SpeakerIDs = []string{speaker.ID}
+		if err := tx.Create(speakerRef, speaker); err != nil {
+			return nil, err
+		}
+		if err := tx.Create(sessionRef, session); err != nil {
+			return nil, err
+		}
+
+		proposal.Status = ProposalAccepted
+		proposal.SpeakerID, proposal.SessionID = speaker.ID, session.ID
+		proposal.DecidedBy, proposal.DecidedAt = c.GetString(middleware.AdminIDKey), &now
+		if err := tx.Set(proposalRef, proposal); err != nil {
+			return nil, err
+		}
+
+		resp = AcceptProposalResponse{Proposal: proposal, Speaker: speaker, Session: session}
+		return []outboxEntry{{WebhookSpeakerCreated, speaker}, {WebhookSessionCreated, session}}, nil
+	})
+	if !h.proposalDecisionError(c, err) {
+		return
+	}
+
+	resp.Proposal.ID = id
+	h.recordAudit(c, "proposal.accept", "proposal", id, nil, gin.H{"speaker": resp.Speaker.ID, "session": resp.Session.ID})
+	c.JSON(http.StatusOK, resp)
+}
+
+// RejectProposal marks a proposal rejected
+func (h *Handlers) RejectProposal(c *gin.Context) {
+	id := c.Param("id")
+	proposalRef := h.db.Collection("proposals").Doc(id)
+	now := time.Now()
+
+	var proposal models.Proposal
+	err := h.db.RunTransaction(func(ctx context.Context, tx *firestore.Transaction) error {
+		var err error
+		proposal, err = h.decideProposal(tx, proposalRef)
+		if err != nil {
+			return err
+		}
+		proposal.Status = ProposalRejected
+		proposal.DecidedBy, proposal.DecidedAt = c.GetString(middleware.AdminIDKey), &now
+		return tx.Set(proposalRef, proposal)
+	})
+	if !h.proposalDecisionError(c, err) {
+		return
+	}
+
+	proposal.ID = id
+	h.recordAudit(c, "proposal.reject", "proposal", id, nil, nil)
+	c.JSON(http.StatusOK, proposal)
+}
+
+// UpdateCFP opens or closes the call for papers
+func (h *Handlers) UpdateCFP(c *gin.Context) {
+	workshop := c.MustGet(WorkshopKey).(*models.Workshop)
+
+	var req UpdateCFPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := map[string]interface{}{
+		"name":        workshop.Name,
+		"cfpOpen":     req.Open,
+		"cfpClosesAt": firestore.Delete,
+	}
+	if req.ClosesAt != nil {
+		updates["cfpClosesAt"] = *req.ClosesAt
+	}
+
+	// Set rather than update so the default workshop gets a document on first use
+	_, err := h.db.Workshops().Doc(workshop.Slug).Set(h.db.Context(), updates,
+		firestore.Merge([]string{"name"}, []string{"cfpOpen"}, []string{"cfpClosesAt"}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update call for papers"})
+		return
+	}
+	h.workshops.invalidate(workshop.Slug)
+
+	updated := *workshop
+	updated.CFPOpen, updated.CFPClosesAt = req.Open, req.ClosesAt
+	cfp := workshopCFP(&updated, time.Now())
+	h.recordAudit(c, "workshop.cfp.update", "workshop", workshop.Slug, workshopCFP(workshop, time.Now()), cfp)
+	c.JSON(http.StatusOK, cfp)
+}
+
+// decideProposal reads a proposal that is still awaiting a decision
+func (h *Handlers) decideProposal(tx *firestore.Transaction, ref *firestore.DocumentRef) (models.Proposal, error) {
+	var proposal models.Proposal
+	doc, err := tx.Get(ref)
+	if err != nil {
+		return proposal, err
+	}
+	if err := doc.DataTo(&proposal); err != nil {
+		return proposal, err
+	}
+	if proposal.Status != ProposalSubmitted {
+		return proposal, errProposalDecided
+	}
+	return proposal, nil
+}
+
+// proposalDecisionError responds to a failed accept or reject, returning false if there
+// was an error
+func (h *Handlers) proposalDecisionError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case status.Code(err) == codes.NotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Proposal not found"})
+	case errors.Is(err, errProposalDecided):
+		c.JSON(http.StatusConflict, gin.H{"error": "Proposal has already been decided"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update proposal"})
+	}
+	return false
+}
+
+func (h *Handlers) getProposal(id string) (models.Proposal, error) {
+	var proposal models.Proposal
+	doc, err := h.db.Collection("proposals").Doc(id).Get(h.db.Context())
+	if err != nil {
+		return proposal, err
+	}
+	if err := doc.DataTo(&proposal); err != nil {
+		return proposal, err
+	}
+	proposal.ID = doc.Ref.ID
+	return proposal, nil
+}
+
+// proposal validates the request beyond its binding rules. Links must be http or https
+// URLs; blank ones are dropped.
+func (req *ProposalRequest) proposal(now time.Time) (models.Proposal, error) {
+	proposal := models.Proposal{
+		Title:     strings.TrimSpace(req.Title),
+		Abstract:  strings.TrimSpace(req.Abstract),
+		Name:      strings.TrimSpace(req.Name),
+		Email:     strings.TrimSpace(req.Email),
+		Bio:       strings.TrimSpace(req.Bio),
+		Links:     []string{},
+		Status:    ProposalSubmitted,
+		CreatedAt: now,
+	}
+	if proposal.Title == "" || proposal.Abstract == "" || proposal.Name == "" || proposal.Bio == "" {
+		return proposal, errors.New("title, abstract, name and bio must not be blank")
+	}
+
+	for _, link := range req.Links {
+		link = strings.TrimSpace(link)
+		if link == "" {
+			continue
+		}
+		u, err := url.Parse(link)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return proposal, errors.New("links must be http or https URLs")
+		}
+		proposal.Links = append(proposal.Links, link)
+	}
+	if len(proposal.Links) > maxProposalLinks {
+		return proposal, errors.New("at most 5 links are allowed")
+	}
+	return proposal, nil
+}
+
+// tallyReview returns the proposal's review count and score total after a reviewer's
+// previous review, if any, is replaced by one with the given score
+func tallyReview(proposal models.Proposal, previous *models.ProposalReview, score int) (count, total int) {
+	count, total = proposal.ReviewCount, proposal.ScoreTotal
+	if previous != nil {
+		count--
+		total -= previous.Score
+	}
+	return count + 1, total + score
+}
+
+func summarizeProposal(proposal models.Proposal) ProposalSummary {
+	summary := ProposalSummary{Proposal: proposal}
+	if proposal.ReviewCount > 0 {
+		average := float64(proposal.ScoreTotal) / float64(proposal.ReviewCount)
+		summary.AverageScore = &average
+	}
+	return summary
+}
+
+// proposalSpeaker builds the speaker for an accepted proposal, picking the LinkedIn and
+// Twitter profiles out of its links
+func proposalSpeaker(proposal models.Proposal) models.Speaker {
+	speaker := models.Speaker{Name: proposal.Name, Bio: proposal.Bio}
+	for _, link := range proposal.Links {
+		u, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+		switch {
+		case speaker.LinkedInURL == "" && (host == "linkedin.com" || strings.HasSuffix(host, ".linkedin.com")):
+			speaker.LinkedInURL = link
+		case speaker.TwitterURL == "" && (host == "twitter.com" || host == "x.com"):
+			speaker.TwitterURL = link
+		}
+	}
+	return speaker
+}
+
+// proposalSession builds the draft session for an accepted proposal; organizers set its
+// time and duration before publishing it
+func proposalSession(proposal models.Proposal) models.Session {
+	return models.Session{
+		Title:       proposal.Title,
+		Description: proposal.Abstract,
+		SpeakerIDs:  []string{},
+		Status:      SessionDraft,
+	}
+}
+
+// workshopCFP reports whether the workshop takes proposals at now. A finished workshop
+// never does.
+func workshopCFP(workshop *models.Workshop, now time.Time) CFP {
+	cfp := CFP{Open: workshop.CFPOpen, ClosesAt: workshop.CFPClosesAt}
+	if cfp.ClosesAt != nil && !now.Before(*cfp.ClosesAt) {
+		cfp.Open = false
+	}
+	if workshopLifecycle(workshop, now).State == StateFinished {
+		cfp.Open = false
+	}
+	return cfp
+}
+
+func validProposalStatus(s string) bool {
+	return s == ProposalSubmitted || s == ProposalAccepted || s == ProposalRejected
+}
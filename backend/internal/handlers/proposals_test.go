@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"appdirect-workshop-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProposalRequest(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	req := ProposalRequest{
+		Title:    " Agents in production ",
+		Abstract: " What we learned ",
+		Name:     " Ada ",
+		Email:    "ada@example.com",
+		Bio:      " Engineer ",
+		Links:    []string{" https://linkedin.com/in/ada ", "", "http://ada.dev"},
+	}
+	proposal, err := req.proposal(now)
+	require.NoError(t, err)
+	assert.Equal(t, models.Proposal{
+		Title:     "Agents in production",
+		Abstract:  "What we learned",
+		Name:      "Ada",
+		Email:     "ada@example.com",
+		Bio:       "Engineer",
+		Links:     []string{"https://linkedin.com/in/ada", "http://ada.dev"},
+		Status:    ProposalSubmitted,
+		CreatedAt: now,
+	}, proposal)
+
+	invalid := []ProposalRequest{
+		{Title: " ", Abstract: "A", Name: "N", Bio: "B"},
+		{Title: "T", Abstract: "A", Name: "N", Bio: "B", Links: []string{"javascript:alert(1)"}},
+		{Title: "T", Abstract: "A", Name: "N", Bio: "B", Links: []string{"ada.dev"}},
+		{Title: "T", Abstract: "A", Name: "N", Bio: "B", Links: strings.Split(strings.Repeat("https://a.dev,", 6), ",")},
+	}
+	for _, req := range invalid {
+		_, err := req.proposal(now)
+		assert.Error(t, err)
+	}
+}
+
+func TestTallyReview(t *testing.T) {
+	proposal := models.Proposal{ReviewCount: 2, ScoreTotal: 7}
+
+	count, total := tallyReview(proposal, nil, 5)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, 12, total)
+
+	// Replacing a review keeps the count and swaps the score
+	count, total = tallyReview(proposal, &models.ProposalReview{Score: 4}, 2)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 5, total)
+}
+
+func TestSummarizeProposal(t *testing.T) {
+	assert.Nil(t, summarizeProposal(models.Proposal{}).AverageScore)
+
+	summary := summarizeProposal(models.Proposal{ReviewCount: 3, ScoreTotal: 11})
+	require.NotNil(t, summary.AverageScore)
+	assert.InDelta(t, 3.667, *summary.AverageScore, 0.001)
+}
+
+func TestProposalSpeakerAndSession(t *testing.T) {
+	proposal := models.Proposal{
+		Title:    "Agents in production",
+		Abstract: "What we learned",
+		Name:     "Ada",
+		Bio:      "Engineer",
+		Links:    []string{"https://ada.dev", "https://www.linkedin.com/in/ada", "https://x.com/ada", "https://twitter.com/ada2"},
+	}
+
+	assert.Equal(t, models.Speaker{
+		Name:        "Ada",
+		Bio:         "Engineer",
+		LinkedInURL: "https://www.linkedin.com/in/ada",
+		TwitterURL:  "https://x.com/ada",
+	}, proposalSpeaker(proposal))
+
+	session := proposalSession(proposal)
+	assert.Equal(t, "Agents in production", session.Title)
+	assert.Equal(t, "What we learned", session.Description)
+	assert.Equal(t, SessionDraft, session.Status)
+}
+
+func TestWorkshopCFP(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	later, earlier := now.Add(time.Hour), now.Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		workshop models.Workshop
+		open     bool
+	}{
+		{name: "closed by default", workshop: models.Workshop{}, open: false},
+		{name: "open", workshop: models.Workshop{CFPOpen: true}, open: true},
+		{name: "open until later", workshop: models.Workshop{CFPOpen: true, CFPClosesAt: &later}, open: true},
+		{name: "past its close time", workshop: models.Workshop{CFPOpen: true, CFPClosesAt: &earlier}, open: false},
+		{name: "event finished", workshop: models.Workshop{CFPOpen: true, Status: StateFinished}, open: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.open, workshopCFP(&tt.workshop, now).Open)
+		})
+	}
+}
+
+func TestProposalRequestValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	valid := `{"title":"T","abstract":"A","name":"N","email":"ada@example.com","bio":"B"}`
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		workshop models.Workshop
+		status   int
+	}{
+		{name: "CFP closed", method: "POST", path: "/api/proposals", body: valid, status: http.StatusForbidden},
+		{name: "missing bio", method: "POST", path: "/api/proposals", body: `{"title":"T","abstract":"A","name":"N","email":"ada@example.com"}`, workshop: models.Workshop{CFPOpen: true}, status: http.StatusBadRequest},
+		{name: "honeypot filled", method: "POST", path: "/api/proposals", body: `{"title":"T","abstract":"A","name":"N","email":"ada@example.com","bio":"B","website":"spam"}`, workshop: models.Workshop{CFPOpen: true}, status: http.StatusBadRequest},
+		{name: "bad link", method: "POST", path: "/api/proposals", body: `{"title":"T","abstract":"A","name":"N","email":"ada@example.com","bio":"B","links":["ftp://x"]}`, workshop: models.Workshop{CFPOpen: true}, status: http.StatusBadRequest},
+		{name: "score out of range", method: "PUT", path: "/api/admin/proposals/p1/review", body: `{"score":6}`, status: http.StatusBadRequest},
+		{name: "unknown status filter", method: "GET", path: "/api/admin/proposals?status=pending", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkshopTestHandlers()
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				workshop := tt.workshop
+				c.Set(WorkshopKey, &workshop)
+			})
+			router.POST("/api/proposals", h.SubmitProposal)
+			router.GET("/api/admin/proposals", h.GetProposals)
+			router.PUT("/api/admin/proposals/:id/review", h.ReviewProposal)
+
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
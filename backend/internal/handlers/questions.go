@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"appdirect-workshop-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Question moderation states. Only approved and answered questions are public.
+const (
+	QuestionPending  = "pending"
+	QuestionApproved = "approved"
+	QuestionHidden   = "hidden"
+	QuestionAnswered = "answered"
+)
+
+// Events on a session's question stream. Updates carry the public question; removals
+// carry just its id, for questions hidden or sent back to moderation.
+const (
+	EventQuestionUpdated = "question.updated"
+	EventQuestionRemoved = "question.removed"
+)
+
+var (
+	errQuestionNotFound = errors.New("question not found")
+	errAlreadyVoted     = errors.New("already voted")
+	errNotVoted         = errors.New("not voted")
+)
+
+type AskQuestionRequest struct {
+	Text string `json:"text" binding:"required,max=500"`
+	// Anonymous questions are shown without the attendee's name
+	Anonymous bool `json:"anonymous"`
+}
+
+type ModerateQuestionRequest struct {
+	Status string `json:"status" binding:"required,oneof=pending approved hidden answered"`
+}
+
+// GetQuestions lists a session's public questions
+func (h *Handlers) GetQuestions(c *gin.Context) {
+	questions, err := h.sessionQuestions(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch questions"})
+		return
+	}
+
+	visible := make([]models.Question, 0, len(questions))
+	for _, q := range questions {
+		if questionVisible(q) {
+			visible = append(visible, publicQuestion(q))
+		}
+	}
+	c.JSON(http.StatusOK, visible)
+}
+
+// StreamQuestions pushes changes to a session's public questions as Server-Sent Events
+func (h *Handlers) StreamQuestions(c *gin.Context) {
+	sessionID := c.Param("id")
+	if !h.sessionExists(c, sessionID) {
+		return
+	}
+
+	h.stream(c, h.questionsTopic(sessionID))
+}
+
+// AskQuestion submits the authenticated attendee's question for moderation
+func (h *Handlers) AskQuestion(c *gin.Context) {
+	attendee := c.MustGet(AttendeeKey).(*models.Registration)
+	sessionID := c.Param("id")
+
+	var req AskQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "text is required"})
+		return
+	}
+	if !h.sessionExists(c, sessionID) {
+		return
+	}
+
+	question := models.Question{
+		SessionID:      sessionID,
+		RegistrationID: attendee.ID,
+		Text:           text,
+		Status:         QuestionPending,
+		CreatedAt:      time.Now(),
+	}
+	if !req.Anonymous {
+		question.AuthorName = attendee.Name
+	}
+
+	docRef, _, err := h.db.Collection("questions").Add(h.db.Context(), question)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit question"})
+		return
+	}
+
+	question.ID = docRef.ID
+	c.JSON(http.StatusCreated, publicQuestion(question))
+}
+
+func (h *Handlers) VoteQuestion(c *gin.Context) {
+	h.vote(c, true)
+}
+
+func (h *Handlers) UnvoteQuestion(c *gin.Context) {
+	h.vote(c, false)
+}
+
+// GetMyVotes lists the IDs of the session's questions the attendee has voted for
+func (h *Handlers) GetMyVotes(c *gin.Context) {
+	attendee := c.MustGet(AttendeeKey).(*models.Registration)
+
+	ids := make([]string, 0)
+	iter := h.db.Collection("question_votes").
+		Where("registrationId", "==", attendee.ID).
+		Where("sessionId", "==", c.Param("id")).
+		Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch votes"})
+			return
+		}
+		if id, ok := doc.Data()["questionId"].(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	c.JSON(http.StatusOK, ids)
+}
+
+// GetAllQuestions lists every question of a session for moderators, optionally
+// filtered by status
+func (h *Handlers) GetAllQuestions(c *gin.Context) {
+	questions, err := h.sessionQuestions(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch questions"})
+		return
+	}
+
+	if want := c.Query("status"); want != "" {
+		filtered := make([]models.Question, 0, len(questions))
+		for _, q := range questions {
+			if q.Status == want {
+				filtered = append(filtered, q)
+			}
+		}
+		questions = filtered
+	}
+	c.JSON(http.StatusOK, questions)
+}
+
+// ModerateQuestion approves, hides or answers a question, or returns it to moderation
+func (h *Handlers) ModerateQuestion(c *gin.Context) {
+	sessionID, questionID := c.Param("id"), c.Param("questionId")
+
+	var req ModerateQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ref := h.db.Collection("questions").Doc(questionID)
+	doc, err := ref.Get(h.db.Context())
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch question"})
+		return
+	}
+	var question models.Question
+	if err := doc.DataTo(&question); err != nil || question.SessionID != sessionID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+		return
+	}
+	question.ID = doc.Ref.ID
+	before := question
+
+	updates := []firestore.Update{{Path: "status", Value: req.Status}}
+	question.Status = req.Status
+	switch {
+	case req.Status == QuestionAnswered && question.AnsweredAt == nil:
+		now := time.Now()
+		question.AnsweredAt = &now
+		updates = append(updates, firestore.Update{Path: "answeredAt", Value: now})
+	case req.Status != QuestionAnswered && question.AnsweredAt != nil:
+		question.AnsweredAt = nil
+		updates = append(updates, firestore.Update{Path: "answeredAt", Value: firestore.Delete})
+	}
+
+	if _, err := ref.Update(h.db.Context(), updates); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update question"})
+		return
+	}
+
+	h.recordAudit(c, "question.moderate", "question", questionID, before, question)
+	if questionVisible(question) {
+		h.hub.Publish(h.questionsTopic(sessionID), EventQuestionUpdated, publicQuestion(question))
+	} else if questionVisible(before) {
+		h.hub.Publish(h.questionsTopic(sessionID), EventQuestionRemoved, gin.H{"id": questionID})
+	}
+	c.JSON(http.StatusOK, question)
+}
+
+func (h *Handlers) vote(c *gin.Context, add bool) {
+	attendee := c.MustGet(AttendeeKey).(*models.Registration)
+
+	question, err := h.changeVote(c.Param("id"), c.Param("questionId"), attendee.ID, add)
+	switch {
+	case errors.Is(err, errQuestionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+		return
+	case errors.Is(err, errAlreadyVoted):
+		c.JSON(http.StatusConflict, gin.H{"error": "You have already voted for this question"})
+		return
+	case errors.Is(err, errNotVoted):
+		c.JSON(http.StatusNotFound, gin.H{"error": "You have not voted for this question"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record vote"})
+		return
+	}
+
+	public := publicQuestion(question)
+	h.hub.Publish(h.questionsTopic(question.SessionID), EventQuestionUpdated, public)
+	c.JSON(http.StatusOK, public)
+}
+
+// changeVote adds or removes a vote and adjusts the question's count in one
+// transaction, so the count always matches the votes
+func (h *Handlers) changeVote(sessionID, questionID, registrationID string, add bool) (models.Question, error) {
+	questionRef := h.db.Collection("questions").Doc(questionID)
+	voteRef := h.db.Collection("question_votes").Doc(questionID + "_" + registrationID)
+
+	var question models.Question
+	err := h.db.RunTransaction(func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(questionRef)
+		if status.Code(err) == codes.NotFound {
+			return errQuestionNotFound
+		}
+		if err != nil {
+			return err
+		}
+		question = models.Question{}
+		if err := doc.DataTo(&question); err != nil {
+			return err
+		}
+		question.ID = doc.Ref.ID
+		if question.SessionID != sessionID || !questionVisible(question) {
+			return errQuestionNotFound
+		}
+
+		_, err = tx.Get(voteRef)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		voted := err == nil
+		if add && voted {
+			return errAlreadyVoted
+		}
+		if !add && !voted {
+			return errNotVoted
+		}
+
+		delta := 1
+		if add {
+			err = tx.Create(voteRef, models.QuestionVote{
+				QuestionID:     questionID,
+				SessionID:      sessionID,
+				RegistrationID: registrationID,
+				CreatedAt:      time.Now(),
+			})
+		} else {
+			delta = -1
+			err = tx.Delete(voteRef)
+		}
+		if err != nil {
+			return err
+		}
+		question.Votes += delta
+		return tx.Update(questionRef, []firestore.Update{{Path: "votes", Value: firestore.Increment(delta)}})
+	})
+	// A concurrent vote from the same registration was committed first
+	if status.Code(err) == codes.AlreadyExists {
+		return question, errAlreadyVoted
+	}
+	return question, err
+}
+
+// sessionQuestions loads a session's questions in display order
+func (h *Handlers) sessionQuestions(sessionID string) ([]models.Question, error) {
+	questions := make([]models.Question, 0)
+	iter := h.db.Collection("questions").Where("sessionId", "==", sessionID).Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var q models.Question
+		if err := doc.DataTo(&q); err != nil {
+			continue
+		}
+		q.ID = doc.Ref.ID
+		questions = append(questions, q)
+	}
+	sortQuestions(questions)
+	return questions, nil
+}
+
+// sessionExists writes a 404 or 500 response and returns false if the session can't be found
+func (h *Handlers) sessionExists(c *gin.Context, sessionID string) bool {
+	_, err := h.db.Collection("sessions").Doc(sessionID).Get(h.db.Context())
+	if status.Code(err) == codes.NotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch session"})
+		return false
+	}
+	return true
+}
+
+func (h *Handlers) questionsTopic(sessionID string) string {
+	return h.topic("sessions/" + sessionID + "/questions")
+}
+
+func questionVisible(q models.Question) bool {
+	return q.Status == QuestionApproved || q.Status == QuestionAnswered
+}
+
+// publicQuestion hides which registration asked the question
+func publicQuestion(q models.Question) models.Question {
+	q.RegistrationID = ""
+	return q
+}
+
+// sortQuestions puts unanswered questions before answered ones, each ordered by votes
+// and then oldest first
+func sortQuestions(questions []models.Question) {
+	sort.SliceStable(questions, func(i, j int) bool {
+		a, b := questions[i], questions[j]
+		if answeredA, answeredB := a.Status == QuestionAnswered, b.Status == QuestionAnswered; answeredA != answeredB {
+			return answeredB
+		}
+		if a.Votes != b.Votes {
+			return a.Votes > b.Votes
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+}
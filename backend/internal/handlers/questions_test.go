@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"appdirect-workshop-backend/internal/config"
+	"appdirect-workshop-backend/internal/database/firestoretest"
+	"appdirect-workshop-backend/internal/live"
+	"appdirect-workshop-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortQuestions(t *testing.T) {
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	questions := []models.Question{
+		{ID: "answered-popular", Status: QuestionAnswered, Votes: 10, CreatedAt: base},
+		{ID: "newer", Status: QuestionApproved, Votes: 3, CreatedAt: base.Add(2 * time.Minute)},
+		{ID: "older", Status: QuestionApproved, Votes: 3, CreatedAt: base.Add(time.Minute)},
+		{ID: "top", Status: QuestionApproved, Votes: 5, CreatedAt: base.Add(3 * time.Minute)},
+		{ID: "answered", Status: QuestionAnswered, Votes: 1, CreatedAt: base},
+	}
+
+	sortQuestions(questions)
+
+	var order []string
+	for _, q := range questions {
+		order = append(order, q.ID)
+	}
+	assert.Equal(t, []string{"top", "older", "newer", "answered-popular", "answered"}, order)
+}
+
+func TestQuestionVisibility(t *testing.T) {
+	tests := []struct {
+		status  string
+		visible bool
+	}{
+		{status: QuestionPending, visible: false},
+		{status: QuestionApproved, visible: true},
+		{status: QuestionHidden, visible: false},
+		{status: QuestionAnswered, visible: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			assert.Equal(t, tt.visible, questionVisible(models.Question{Status: tt.status}))
+		})
+	}
+
+	public := publicQuestion(models.Question{ID: "q1", RegistrationID: "reg1", AuthorName: "Asha"})
+	assert.Empty(t, public.RegistrationID)
+	assert.Equal(t, "Asha", public.AuthorName)
+}
+
+func TestAskQuestionValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "missing text", body: `{}`},
+		{name: "blank text", body: `{"text":"   "}`},
+		{name: "text too long", body: `{"text":"` + string(bytes.Repeat([]byte("a"), 501)) + `"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkshopTestHandlers()
+			router := gin.New()
+			router.POST("/api/sessions/:id/questions", func(c *gin.Context) {
+				c.Set(AttendeeKey, &models.Registration{ID: "reg1", Name: "Asha"})
+			}, h.AskQuestion)
+
+			req, _ := http.NewRequest("POST", "/api/sessions/s1/questions", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestModerateQuestionRejectsUnknownStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := newWorkshopTestHandlers()
+	router := gin.New()
+	router.PUT("/api/admin/sessions/:id/questions/:questionId", h.ModerateQuestion)
+
+	req, _ := http.NewRequest("PUT", "/api/admin/sessions/s1/questions/q1", bytes.NewBufferString(`{"status":"deleted"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestStreamEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	hub := live.NewHub()
+	events, unsubscribe := hub.Subscribe("default-workshop/sessions/s1/questions")
+	first := hub.Publish("default-workshop/sessions/s1/questions", EventQuestionUpdated, models.Question{ID: "q1", Text: "Why?", Status: QuestionApproved, Votes: 2})
+	second := hub.Publish("default-workshop/sessions/s1/questions", EventQuestionRemoved, gin.H{"id": "q2"})
+	// The stream ends once the subscription is closed
+	unsubscribe()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/api/sessions/s1/questions/stream", nil)
+	streamEvents(c, events, false)
+
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "id: "+first.ID+"\nevent: question.updated\n"+
+		`data: {"id":"q1","sessionId":"","text":"Why?","status":"approved","votes":2,"createdAt":"0001-01-01T00:00:00Z"}`+"\n\n"+
+		"id: "+second.ID+"\nevent: question.removed\n"+
+		`data: {"id":"q2"}`+"\n\n", w.Body.String())
+}
+
+func TestQuestionVotes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := firestoretest.NewDatabase(t, "test-collection")
+	_, err := db.Collection("sessions").Doc("s1").Set(db.Context(), models.Session{Title: "Keynote"})
+	require.NoError(t, err)
+	questions := map[string]models.Question{
+		"approved": {SessionID: "s1", RegistrationID: "reg9", Text: "Why Go?", Status: QuestionApproved},
+		"pending":  {SessionID: "s1", RegistrationID: "reg9", Text: "Why not Rust?", Status: QuestionPending},
+		"other":    {SessionID: "s2", RegistrationID: "reg9", Text: "Lunch?", Status: QuestionApproved},
+	}
+	for id, q := range questions {
+		_, err := db.Collection("questions").Doc(id).Set(db.Context(), q)
+		require.NoError(t, err)
+	}
+	tokens := map[string]string{}
+	for _, id := range []string{"reg1", "reg2", "reg3"} {
+		tokens[id] = seedAttendee(t, db, id)
+	}
+	h := New(db, &config.Config{SubcollectionID: "test-collection"})
+
+	router := gin.New()
+	router.GET("/api/sessions/:id/questions/votes", h.RequireAttendee(), h.GetMyVotes)
+	router.POST("/api/sessions/:id/questions/:questionId/vote", h.RequireAttendee(), h.VoteQuestion)
+	router.DELETE("/api/sessions/:id/questions/:questionId/vote", h.RequireAttendee(), h.UnvoteQuestion)
+	serve := func(method, path, registrationID string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Set(RegistrationTokenHeader, tokens[registrationID])
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	storedVotes := func() int {
+		doc, err := db.Collection("questions").Doc("approved").Get(db.Context())
+		require.NoError(t, err)
+		var q models.Question
+		require.NoError(t, doc.DataTo(&q))
+		return q.Votes
+	}
+
+	w := serve("POST", "/api/sessions/s1/questions/approved/vote", "reg1")
+	require.Equal(t, http.StatusOK, w.Code)
+	var question models.Question
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &question))
+	assert.Equal(t, 1, question.Votes)
+	assert.Empty(t, question.RegistrationID)
+
+	assert.Equal(t, http.StatusConflict, serve("POST", "/api/sessions/s1/questions/approved/vote", "reg1").Code)
+	require.Equal(t, http.StatusOK, serve("POST", "/api/sessions/s1/questions/approved/vote", "reg2").Code)
+	assert.Equal(t, 2, storedVotes())
+
+	// Only the session's public questions can be voted on
+	assert.Equal(t, http.StatusNotFound, serve("POST", "/api/sessions/s1/questions/pending/vote", "reg1").Code)
+	assert.Equal(t, http.StatusNotFound, serve("POST", "/api/sessions/s1/questions/other/vote", "reg1").Code)
+	assert.Equal(t, http.StatusNotFound, serve("POST", "/api/sessions/s1/questions/missing/vote", "reg1").Code)
+
+	w = serve("GET", "/api/sessions/s1/questions/votes", "reg1")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `["approved"]`, w.Body.String())
+
+	w = serve("DELETE", "/api/sessions/s1/questions/approved/vote", "reg1")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &question))
+	assert.Equal(t, 1, question.Votes)
+	assert.Equal(t, http.StatusNotFound, serve("DELETE", "/api/sessions/s1/questions/approved/vote", "reg1").Code)
+	assert.Equal(t, http.StatusNotFound, serve("DELETE", "/api/sessions/s1/questions/approved/vote", "reg3").Code)
+	assert.Equal(t, 1, storedVotes())
+
+	w = serve("GET", "/api/sessions/s1/questions/votes", "reg1")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[]`, w.Body.String())
+
+	// Votes sent at once still count once
+	results := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		go func() { results <- serve("POST", "/api/sessions/s1/questions/approved/vote", "reg3").Code }()
+	}
+	assert.ElementsMatch(t, []int{http.StatusOK, http.StatusConflict}, []int{<-results, <-results})
+	assert.Equal(t, 2, storedVotes())
+}
+
+func TestModerateQuestion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := firestoretest.NewDatabase(t, "test-collection")
+	ref := db.Collection("questions").Doc("q1")
+	_, err := ref.Set(db.Context(), models.Question{SessionID: "s1", RegistrationID: "reg1", Text: "Why Go?", Status: QuestionPending})
+	require.NoError(t, err)
+	h := New(db, &config.Config{SubcollectionID: "test-collection"})
+
+	router := gin.New()
+	router.PUT("/api/admin/sessions/:id/questions/:questionId", h.ModerateQuestion)
+	moderate := func(sessionID, state string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(ModerateQuestionRequest{Status: state})
+		req, _ := http.NewRequest("PUT", "/api/admin/sessions/"+sessionID+"/questions/q1", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	stored := func() models.Question {
+		doc, err := ref.Get(db.Context())
+		require.NoError(t, err)
+		var q models.Question
+		require.NoError(t, doc.DataTo(&q))
+		return q
+	}
+
+	assert.Equal(t, http.StatusNotFound, moderate("s2", QuestionApproved).Code)
+	assert.Equal(t, QuestionPending, stored().Status)
+
+	require.Equal(t, http.StatusOK, moderate("s1", QuestionApproved).Code)
+	assert.Equal(t, QuestionApproved, stored().Status)
+	assert.Nil(t, stored().AnsweredAt)
+
+	w := moderate("s1", QuestionAnswered)
+	require.Equal(t, http.StatusOK, w.Code)
+	var answered models.Question
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &answered))
+	require.NotNil(t, answered.AnsweredAt)
+	answeredAt := stored().AnsweredAt
+	require.NotNil(t, answeredAt)
+	assert.WithinDuration(t, *answered.AnsweredAt, *answeredAt, time.Millisecond)
+
+	// Answering again keeps the first answer time
+	require.Equal(t, http.StatusOK, moderate("s1", QuestionAnswered).Code)
+	assert.True(t, stored().AnsweredAt.Equal(*answeredAt))
+
+	// Reopening clears it
+	w = moderate("s1", QuestionApproved)
+	require.Equal(t, http.StatusOK, w.Code)
+	var reopened models.Question
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reopened))
+	assert.Nil(t, reopened.AnsweredAt)
+	assert.Equal(t, QuestionApproved, stored().Status)
+	assert.Nil(t, stored().AnsweredAt)
+}
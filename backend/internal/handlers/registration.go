@@ -1,19 +1,36 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
+	"appdirect-workshop-backend/internal/challenge"
+	"appdirect-workshop-backend/internal/middleware"
 	"appdirect-workshop-backend/internal/models"
 
+	"cloud.google.com/go/firestore"
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
 	"github.com/gin-gonic/gin"
-	"google.golang.org/api/iterator"
 )
 
 type RegisterRequest struct {
 	Name        string `json:"name" binding:"required"`
 	Email       string `json:"email" binding:"required,email"`
 	Designation string `json:"designation" binding:"required"`
+
+	// Website is a honeypot: the field is hidden from people, so only bots fill it in
+	Website string `json:"website"`
+	// Challenge and Solution carry the proof-of-work or CAPTCHA response
+	Challenge string `json:"challenge"`
+	Solution  string `json:"solution"`
+}
+
+// RegisterResponse includes the attendee's registration token. It is only returned
+// here; attendee routes such as session feedback require it.
+type RegisterResponse struct {
+	models.Registration
+	Token string `json:"token"`
 }
 
 func (h *Handlers) Register(c *gin.Context) {
@@ -23,39 +40,126 @@ func (h *Handlers) Register(c *gin.Context) {
 		return
 	}
 
+	if req.Website != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid registration"})
+		return
+	}
+
+	if workshop, ok := c.Get(WorkshopKey); ok {
+		if lifecycle := workshopLifecycle(workshop.(*models.Workshop), time.Now()); !lifecycle.RegistrationOpen {
+			c.JSON(http.StatusForbidden, registrationRefusal(lifecycle))
+			return
+		}
+	}
+
+	if !h.verifyHuman(c, req.Challenge, req.Solution) {
+		return
+	}
+
+	token, err := generateToken(registrationTokenPrefix)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create registration"})
+		return
+	}
+
 	// Create registration
 	reg := models.Registration{
 		Name:        req.Name,
 		Email:       req.Email,
 		Designation: req.Designation,
 		CreatedAt:   time.Now(),
+		TokenHash:   hashToken(token),
 	}
 
-	docRef, _, err := h.db.Collection("registrations").Add(h.db.Context(), reg)
+	err = h.transactWithEvents(func(tx *firestore.Transaction) ([]outboxEntry, error) {
+		reg.CanonicalDesignation = h.canonicalDesignation(req.Designation)
+		docRef := h.db.Collection("registrations").NewDoc()
+		reg.ID = docRef.ID
+		return []outboxEntry{{WebhookRegistrationCreated, reg}}, tx.Create(docRef, reg)
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create registration"})
 		return
 	}
 
-	reg.ID = docRef.ID
-	c.JSON(http.StatusCreated, reg)
+	c.JSON(http.StatusCreated, RegisterResponse{Registration: reg, Token: token})
+}
+
+// verifyHuman checks the proof-of-work or CAPTCHA response of a public form, responding
+// with an error and returning false when it fails
+func (h *Handlers) verifyHuman(c *gin.Context, challengeID, solution string) bool {
+	if h.verifier == nil {
+		return true
+	}
+	err := h.verifier.Verify(c.Request.Context(), challenge.Proof{
+		Challenge: challengeID,
+		Solution:  solution,
+		RemoteIP:  middleware.ClientIP(c),
+	})
+	if errors.Is(err, challenge.ErrFailed) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Bot verification failed, please try again"})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Bot verification is unavailable, please try again later"})
+		return false
+	}
+	return true
+}
+
+// CancelRegistration lets an attendee withdraw. The registration is deleted, so its
+// token stops working.
+func (h *Handlers) CancelRegistration(c *gin.Context) {
+	attendee := c.MustGet(AttendeeKey).(*models.Registration)
+
+	err := h.writeWithEvent(WebhookRegistrationCancelled, attendee, func(tx *firestore.Transaction) error {
+		return tx.Delete(h.db.Collection("registrations").Doc(attendee.ID))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel registration"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Registration cancelled"})
 }
 
 func (h *Handlers) GetRegistrationCount(c *gin.Context) {
-	iter := h.db.Collection("registrations").Documents(h.db.Context())
-	count := 0
-	for {
-		_, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count registrations"})
-			return
-		}
-		count++
+	count, err := h.registrationCount()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count registrations"})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"count": count})
 }
 
+// registrationCount counts registrations with an aggregation query, so Firestore doesn't
+// send every document
+func (h *Handlers) registrationCount() (int64, error) {
+	result, err := h.db.Collection("registrations").NewAggregationQuery().WithCount("all").Get(h.db.Context())
+	if err != nil {
+		return 0, err
+	}
+	count, ok := result["all"].(*firestorepb.Value)
+	if !ok {
+		return 0, errors.New("count missing from aggregation result")
+	}
+	return count.GetIntegerValue(), nil
+}
+
+// GetRegistrationChallenge issues a proof-of-work challenge to solve before registering
+func (h *Handlers) GetRegistrationChallenge(c *gin.Context) {
+	if h.pow == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Proof-of-work challenge is not enabled"})
+		return
+	}
+
+	issued, err := h.pow.Issue()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue challenge"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, issued)
+}
@@ -2,11 +2,14 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"appdirect-workshop-backend/internal/challenge"
 	"appdirect-workshop-backend/internal/config"
 	"appdirect-workshop-backend/internal/database"
 	"appdirect-workshop-backend/internal/models"
@@ -127,3 +130,121 @@ func TestGetRegistrationCount(t *testing.T) {
 	assert.True(t, w.Code == http.StatusOK || w.Code == http.StatusInternalServerError)
 }
 
+// stubVerifier stands in for an external CAPTCHA provider
+type stubVerifier struct {
+	err error
+}
+
+func (s stubVerifier) Verify(ctx context.Context, proof challenge.Proof) error {
+	return s.err
+}
+
+func TestRegisterBotProtection(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	valid := map[string]string{
+		"name":        "John Doe",
+		"email":       "john@example.com",
+		"designation": "Software Engineer",
+	}
+	withField := func(key, value string) map[string]string {
+		body := map[string]string{key: value}
+		for k, v := range valid {
+			body[k] = v
+		}
+		return body
+	}
+
+	tests := []struct {
+		name           string
+		verifier       challenge.Verifier
+		requestBody    map[string]string
+		expectedStatus int
+	}{
+		{
+			name:           "honeypot filled",
+			requestBody:    withField("website", "http://spam.example.com"),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "challenge rejected",
+			verifier:       stubVerifier{err: fmt.Errorf("%w: CAPTCHA rejected", challenge.ErrFailed)},
+			requestBody:    withField("solution", "bot"),
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "verifier unavailable",
+			verifier:       stubVerifier{err: fmt.Errorf("connection refused")},
+			requestBody:    withField("solution", "human"),
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				AdminPassword:   "test-password",
+				SubcollectionID: "test-collection",
+			}
+			h := New(createMockDB(), cfg)
+			h.verifier = tt.verifier
+
+			router := gin.New()
+			router.POST("/api/register", h.Register)
+
+			body, _ := json.Marshal(tt.requestBody)
+			req, _ := http.NewRequest("POST", "/api/register", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestGetRegistrationChallenge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		mode           string
+		expectedStatus int
+	}{
+		{name: "disabled", mode: "none", expectedStatus: http.StatusNotFound},
+		{name: "proof of work", mode: "pow", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				AdminPassword:   "test-password",
+				SubcollectionID: "test-collection",
+				ChallengeMode:   tt.mode,
+				PoWDifficulty:   4,
+			}
+			h := New(createMockDB(), cfg)
+
+			router := gin.New()
+			router.GET("/api/register/challenge", h.GetRegistrationChallenge)
+
+			req, _ := http.NewRequest("GET", "/api/register/challenge", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if w.Code == http.StatusOK {
+				var issued challenge.Challenge
+				json.Unmarshal(w.Body.Bytes(), &issued)
+				assert.NotEmpty(t, issued.Challenge)
+				assert.Equal(t, 4, issued.Difficulty)
+
+				// The issued challenge is accepted once solved
+				nonce := challenge.Solve(issued.Challenge, issued.Difficulty)
+				assert.NoError(t, h.verifier.Verify(context.Background(), challenge.Proof{Challenge: issued.Challenge, Solution: nonce}))
+			}
+		})
+	}
+}
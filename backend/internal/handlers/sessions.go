@@ -5,13 +5,27 @@ import (
 
 	"appdirect-workshop-backend/internal/models"
 
+	"cloud.google.com/go/firestore"
 	"github.com/gin-gonic/gin"
 	"google.golang.org/api/iterator"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+// SessionDraft marks a session hidden from the public agenda
+const SessionDraft = "draft"
+
+// GetSessions lists the published sessions
 func (h *Handlers) GetSessions(c *gin.Context) {
+	h.listSessions(c, false)
+}
+
+// GetAllSessions lists every session, including drafts
+func (h *Handlers) GetAllSessions(c *gin.Context) {
+	h.listSessions(c, true)
+}
+
+func (h *Handlers) listSessions(c *gin.Context, includeDrafts bool) {
 	sessions := make([]models.Session, 0)
 	iter := h.db.Collection("sessions").Documents(h.db.Context())
 	for {
@@ -28,6 +42,9 @@ func (h *Handlers) GetSessions(c *gin.Context) {
 		if err := doc.DataTo(&session); err != nil {
 			continue
 		}
+		if session.Status == SessionDraft && !includeDrafts {
+			continue
+		}
 		session.ID = doc.Ref.ID
 		sessions = append(sessions, session)
 	}
@@ -45,14 +62,22 @@ func (h *Handlers) CreateSession(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if session.Status != "" && session.Status != SessionDraft {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be draft or empty"})
+		return
+	}
 
-	docRef, _, err := h.db.Collection("sessions").Add(h.db.Context(), session)
+	docRef := h.db.Collection("sessions").NewDoc()
+	session.ID = docRef.ID
+	err := h.writeWithEvent(WebhookSessionCreated, session, func(tx *firestore.Transaction) error {
+		return tx.Create(docRef, session)
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
 		return
 	}
 
-	session.ID = docRef.ID
+	h.recordAudit(c, "session.create", "session", session.ID, nil, session)
 	c.JSON(http.StatusCreated, session)
 }
 
@@ -63,8 +88,16 @@ func (h *Handlers) UpdateSession(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if updates.Status != "" && updates.Status != SessionDraft {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be draft or empty"})
+		return
+	}
 
-	_, err := h.db.Collection("sessions").Doc(id).Set(h.db.Context(), updates)
+	before := h.getSnapshot("sessions", id)
+	updates.ID = id
+	err := h.writeWithEvent(WebhookSessionUpdated, updates, func(tx *firestore.Transaction) error {
+		return tx.Set(h.db.Collection("sessions").Doc(id), updates)
+	})
 	if err != nil {
 		if status.Code(err) == codes.NotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
@@ -74,13 +107,16 @@ func (h *Handlers) UpdateSession(c *gin.Context) {
 		return
 	}
 
-	updates.ID = id
+	h.recordAudit(c, "session.update", "session", id, before, updates)
 	c.JSON(http.StatusOK, updates)
 }
 
 func (h *Handlers) DeleteSession(c *gin.Context) {
 	id := c.Param("id")
-	_, err := h.db.Collection("sessions").Doc(id).Delete(h.db.Context())
+	before := h.getSnapshot("sessions", id)
+	err := h.writeWithEvent(WebhookSessionDeleted, gin.H{"id": id}, func(tx *firestore.Transaction) error {
+		return tx.Delete(h.db.Collection("sessions").Doc(id))
+	})
 	if err != nil {
 		if status.Code(err) == codes.NotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
@@ -90,6 +126,7 @@ func (h *Handlers) DeleteSession(c *gin.Context) {
 		return
 	}
 
+	h.recordAudit(c, "session.delete", "session", id, before, nil)
 	c.JSON(http.StatusOK, gin.H{"message": "Session deleted successfully"})
 }
 
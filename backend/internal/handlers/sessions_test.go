@@ -136,3 +136,25 @@ func TestDeleteSession(t *testing.T) {
 	assert.True(t, w.Code == http.StatusOK || w.Code == http.StatusNotFound || w.Code == http.StatusInternalServerError)
 }
 
+
+func TestSessionStatusValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := newWorkshopTestHandlers()
+	router := gin.New()
+	router.POST("/api/admin/sessions", h.CreateSession)
+	router.PUT("/api/admin/sessions/:id", h.UpdateSession)
+
+	for _, method := range []string{"POST", "PUT"} {
+		path := "/api/admin/sessions"
+		if method == "PUT" {
+			path += "/s1"
+		}
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(`{"title":"Keynote","status":"hidden"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, method)
+	}
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"appdirect-workshop-backend/internal/blob"
+	"appdirect-workshop-backend/internal/images"
+	"appdirect-workshop-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// imagePath is where stored images are served; the rest of the URL is the blob key
+	imagePath = "/api/images/"
+	// imageCacheControl lets browsers and CDNs keep images for a year. Keys include a
+	// hash of the upload, so new content always gets a new URL.
+	imageCacheControl = "public, max-age=31536000, immutable"
+	// multipartOverhead allows for the form encoding around the file
+	multipartOverhead = 64 << 10
+)
+
+// UploadSpeakerImage stores a photo for the speaker in every size and format and
+// points the speaker at it. The multipart field is "image".
+func (h *Handlers) UploadSpeakerImage(c *gin.Context) {
+	id := c.Param("id")
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, images.MaxBytes+multipartOverhead)
+	file, _, err := c.Request.FormFile("image")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Image must be %d MB or smaller", images.MaxBytes>>20)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "An image file is required in the \"image\" field"})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, images.MaxBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read image"})
+		return
+	}
+	if len(data) > images.MaxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Image must be %d MB or smaller", images.MaxBytes>>20)})
+		return
+	}
+
+	processed, err := images.Process(data)
+	if errors.Is(err, images.ErrUnsupported) {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Store the files first; the speaker only points at them once they all exist. Files
+	// of a failed upload are left behind, and an upload of the same image reuses them.
+	prefix := speakerImagePrefix(h.cfg.SubcollectionID, id, data)
+	if !blob.ValidKey(prefix) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Speaker not found"})
+		return
+	}
+	variants := make([]models.ImageVariant, 0, len(processed))
+	for _, v := range processed {
+		key := prefix + "-" + v.Size + v.Extension()
+		if err := h.blobs.Put(c.Request.Context(), key, blob.Object{Data: v.Data, ContentType: v.ContentType}); err != nil {
+			log.Printf("Failed to store image %s: %v", key, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store image"})
+			return
+		}
+		variants = append(variants, models.ImageVariant{
+			Size:   v.Size,
+			Format: v.Format,
+			Width:  v.Width,
+			Height: v.Height,
+			URL:    imagePath + key,
+		})
+	}
+
+	before, speaker, err := h.setSpeakerImages(id, variants)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Speaker not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update speaker"})
+		return
+	}
+
+	h.recordAudit(c, "speaker.image.upload", "speaker", id, before, speaker)
+	c.JSON(http.StatusOK, speaker)
+}
+
+// DeleteSpeakerImage removes the speaker's photo. The files are kept, since cloned
+// workshops and backups may still point at them.
+func (h *Handlers) DeleteSpeakerImage(c *gin.Context) {
+	id := c.Param("id")
+	before, speaker, err := h.setSpeakerImages(id, nil)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Speaker not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update speaker"})
+		return
+	}
+
+	h.recordAudit(c, "speaker.image.delete", "speaker", id, before, speaker)
+	c.JSON(http.StatusOK, speaker)
+}
+
+// GetImage serves a stored image with long-lived cache headers
+func (h *Handlers) GetImage(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if !blob.ValidKey(key) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+
+	obj, err := h.blobs.Get(c.Request.Context(), key)
+	if errors.Is(err, blob.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to read image %s: %v", key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read image"})
+		return
+	}
+
+	// Stored images never change, so the key identifies the content. The object is read
+	// first so a deleted image isn't revalidated as still present.
+	etag := `"` + key + `"`
+	if c.GetHeader("If-None-Match") == etag {
+		c.Header("Cache-Control", imageCacheControl)
+		c.Header("ETag", etag)
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Header("Cache-Control", imageCacheControl)
+	c.Header("ETag", etag)
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, obj.ContentType, obj.Data)
+}
+
+// setSpeakerImages replaces the speaker's images, pointing ImageURL at the large
+// fallback, and returns the speaker before and after
+func (h *Handlers) setSpeakerImages(id string, variants []models.ImageVariant) (models.Speaker, models.Speaker, error) {
+	ref := h.db.Collection("speakers").Doc(id)
+	var before, speaker models.Speaker
+	err := h.transactWithEvents(func(tx *firestore.Transaction) ([]outboxEntry, error) {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return nil, err
+		}
+		if err := doc.DataTo(&before); err != nil {
+			return nil, err
+		}
+
+		speaker = before
+		speaker.ID = id
+		speaker.Images = variants
+		speaker.ImageURL = fallbackImageURL(variants)
+		return []outboxEntry{{WebhookSpeakerUpdated, speaker}}, tx.Set(ref, speaker)
+	})
+	return before, speaker, err
+}
+
+// speakerImagePrefix names an upload's files after the workshop, the speaker and a
+// hash of the upload, so each upload gets URLs of its own
+func speakerImagePrefix(workshop, speakerID string, data []byte) string {
+	sum := sha256.Sum256(data)
+	return workshop + "/speakers/" + speakerID + "/" + hex.EncodeToString(sum[:8])
+}
+
+// fallbackImageURL is the large variant in a format every browser can show
+func fallbackImageURL(variants []models.ImageVariant) string {
+	for _, v := range variants {
+		if v.Size == images.Sizes[0].Name && v.Format != "webp" {
+			return v.URL
+		}
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"appdirect-workshop-backend/internal/blob"
+	"appdirect-workshop-backend/internal/images"
+	"appdirect-workshop-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func multipartImage(t *testing.T, field string, data []byte) (*bytes.Buffer, string) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile(field, "photo.png")
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return &body, w.FormDataContentType()
+}
+
+func TestUploadSpeakerImageValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var tiny bytes.Buffer
+	require.NoError(t, png.Encode(&tiny, image.NewNRGBA(image.Rect(0, 0, 8, 8))))
+
+	tests := []struct {
+		name   string
+		field  string
+		data   []byte
+		status int
+	}{
+		{name: "missing file", field: "photo", data: tiny.Bytes(), status: http.StatusBadRequest},
+		{name: "not an image", field: "image", data: []byte("<svg/>"), status: http.StatusUnsupportedMediaType},
+		{name: "too small", field: "image", data: tiny.Bytes(), status: http.StatusBadRequest},
+		{name: "too large", field: "image", data: make([]byte, images.MaxBytes+1), status: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkshopTestHandlers()
+			router := gin.New()
+			router.POST("/api/admin/speakers/:id/image", h.UploadSpeakerImage)
+
+			body, contentType := multipartImage(t, tt.field, tt.data)
+			req, _ := http.NewRequest("POST", "/api/admin/speakers/sp1/image", body)
+			req.Header.Set("Content-Type", contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestGetImage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := newWorkshopTestHandlers()
+	h.blobs = blob.NewLocal(t.TempDir())
+	key := "default-workshop/speakers/sp1/0123456789abcdef-thumb.webp"
+	require.NoError(t, h.blobs.Put(context.Background(), key, blob.Object{Data: []byte("RIFF"), ContentType: "image/webp"}))
+
+	router := gin.New()
+	router.GET("/api/images/*key", h.GetImage)
+	get := func(path, etag string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/api/images/"+key, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "RIFF", w.Body.String())
+	assert.Equal(t, "image/webp", w.Header().Get("Content-Type"))
+	assert.Equal(t, "public, max-age=31536000, immutable", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+
+	w = get("/api/images/"+key, w.Header().Get("ETag"))
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	// A revalidation of an image that's gone is a miss, not a 304
+	missing := "default-workshop/speakers/sp1/missing.webp"
+	assert.Equal(t, http.StatusNotFound, get("/api/images/"+missing, "").Code)
+	assert.Equal(t, http.StatusNotFound, get("/api/images/"+missing, `"`+missing+`"`).Code)
+	assert.Equal(t, http.StatusNotFound, get("/api/images/default-workshop/../secrets.webp", "").Code)
+}
+
+func TestSpeakerImageNames(t *testing.T) {
+	prefix := speakerImagePrefix("default-workshop", "sp1", []byte("photo"))
+	assert.Regexp(t, `^default-workshop/speakers/sp1/[0-9a-f]{16}$`, prefix)
+	assert.Equal(t, prefix, speakerImagePrefix("default-workshop", "sp1", []byte("photo")))
+	assert.NotEqual(t, prefix, speakerImagePrefix("default-workshop", "sp1", []byte("other photo")))
+
+	variants := []models.ImageVariant{
+		{Size: "large", Format: "webp", URL: "/api/images/a-large.webp"},
+		{Size: "large", Format: "jpeg", URL: "/api/images/a-large.jpg"},
+		{Size: "thumb", Format: "jpeg", URL: "/api/images/a-thumb.jpg"},
+	}
+	assert.Equal(t, "/api/images/a-large.jpg", fallbackImageURL(variants))
+	assert.Empty(t, fallbackImageURL(nil))
+}
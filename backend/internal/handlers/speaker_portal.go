@@ -0,0 +1,533 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"appdirect-workshop-backend/internal/mail"
+	"appdirect-workshop-backend/internal/middleware"
+	"appdirect-workshop-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// SpeakerTokenHeader carries the token from a speaker's magic link
+	SpeakerTokenHeader = "X-Speaker-Token"
+	// SpeakerKey holds the authenticated *models.SpeakerInvite in the gin context
+	SpeakerKey = "speaker"
+
+	speakerTokenPrefix = "spk_"
+	speakerInviteTTL   = 30 * 24 * time.Hour
+)
+
+var (
+	errUnknownSpeakerToken = errors.New("unknown speaker token")
+	errExpiredSpeakerToken = errors.New("speaker token has expired")
+	errNoSpeakerChange     = errors.New("no pending speaker change")
+)
+
+type InviteSpeakerRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// InviteSpeakerResponse includes the magic link only when it wasn't emailed, so an
+// admin can pass it on another way
+type InviteSpeakerResponse struct {
+	models.SpeakerInvite
+	Emailed bool   `json:"emailed"`
+	Link    string `json:"link,omitempty"`
+}
+
+// SpeakerProfileRequest holds the fields a speaker may edit themselves
+type SpeakerProfileRequest struct {
+	Name        string `json:"name" binding:"required,max=200"`
+	Bio         string `json:"bio" binding:"max=2000"`
+	LinkedInURL string `json:"linkedinUrl"`
+	TwitterURL  string `json:"twitterUrl"`
+}
+
+// SpeakerProfile is what a speaker sees in the portal: their public profile, their
+// sessions including drafts, and an edit still waiting for approval
+type SpeakerProfile struct {
+	Speaker          models.Speaker        `json:"speaker"`
+	Sessions         []models.Session      `json:"sessions"`
+	PendingChange    *models.SpeakerChange `json:"pendingChange,omitempty"`
+	RequiresApproval bool                  `json:"requiresApproval"`
+}
+
+// SpeakerChangeDetails pairs a pending edit with the profile it would replace
+type SpeakerChangeDetails struct {
+	models.SpeakerChange
+	Current models.Speaker `json:"current"`
+}
+
+type SpeakerPortalSettings struct {
+	RequireApproval bool `json:"requireApproval"`
+}
+
+// RequireSpeaker authenticates speaker portal routes by magic-link token. Like
+// RequireAttendee it runs after ResolveWorkshop.
+func (h *Handlers) RequireSpeaker() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader(SpeakerTokenHeader)
+		if !strings.HasPrefix(token, speakerTokenPrefix) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Speaker token required"})
+			c.Abort()
+			return
+		}
+
+		invite, err := h.scopedFrom(c.Request.Context()).speakerInviteByToken(token, time.Now())
+		if errors.Is(err, errUnknownSpeakerToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid speaker link"})
+			c.Abort()
+			return
+		}
+		if errors.Is(err, errExpiredSpeakerToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "This speaker link has expired; ask the organizers for a new one"})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify speaker token"})
+			c.Abort()
+			return
+		}
+
+		c.Set(SpeakerKey, invite)
+		c.Next()
+	}
+}
+
+// SpeakerID returns the speaker ID set by RequireSpeaker
+func SpeakerID(c *gin.Context) string {
+	if invite, ok := c.Get(SpeakerKey); ok {
+		return invite.(*models.SpeakerInvite).SpeakerID
+	}
+	return ""
+}
+
+// InviteSpeaker emails a speaker a magic link to their profile. Inviting again
+// replaces the previous link.
+func (h *Handlers) InviteSpeaker(c *gin.Context) {
+	id := c.Param("id")
+	var req InviteSpeakerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	speaker, err := h.getSpeaker(id)
+	if status.Code(err) == codes.NotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Speaker not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch speaker"})
+		return
+	}
+
+	token, err := generateToken(speakerTokenPrefix)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate speaker link"})
+		return
+	}
+	now := time.Now()
+	invite := models.SpeakerInvite{
+		SpeakerID: id,
+		Email:     strings.TrimSpace(req.Email),
+		TokenHash: hashToken(token),
+		InvitedBy: c.GetString(middleware.AdminIDKey),
+		CreatedAt: now,
+		ExpiresAt: now.Add(speakerInviteTTL),
+	}
+	if _, err := h.db.Collection("speaker_invites").Doc(id).Set(h.db.Context(), invite); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create speaker invite"})
+		return
+	}
+	h.recordAudit(c, "speaker.invite", "speaker", id, nil, gin.H{"email": invite.Email, "expiresAt": invite.ExpiresAt})
+
+	// Invites sent from /api/admin/w/:slug link to that workshop's portal
+	link := speakerPortalLink(h.cfg.CORSOrigin, c.Param("slug"), token)
+	resp := InviteSpeakerResponse{SpeakerInvite: invite, Link: link}
+	if h.mailer != nil {
+		title := ""
+		if workshop, ok := c.Get(WorkshopKey); ok {
+			title = workshopDisplayName(workshop.(*models.Workshop))
+		}
+		subject, body := speakerInviteEmail(speaker.Name, title, link, invite.ExpiresAt)
+		err := h.mailer.Send(h.db.Context(), mail.Message{To: invite.Email, Subject: subject, Body: body})
+		if err == nil {
+			resp.Emailed, resp.Link = true, ""
+		} else {
+			log.Printf("Failed to email speaker invite for %s: %v", id, err)
+		}
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetSpeakerProfile returns the signed-in speaker's profile and sessions
+func (h *Handlers) GetSpeakerProfile(c *gin.Context) {
+	id := SpeakerID(c)
+	speaker, err := h.getSpeaker(id)
+	if status.Code(err) == codes.NotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Speaker not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch speaker"})
+		return
+	}
+
+	sessions := make([]models.Session, 0)
+	iter := h.db.Collection("sessions").Where("speakerIds", "array-contains", id).Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+			return
+		}
+		var session models.Session
+		if err := doc.DataTo(&session); err != nil {
+			continue
+		}
+		session.ID = doc.Ref.ID
+		sessions = append(sessions, session)
+	}
+
+	profile := SpeakerProfile{Speaker: speaker, Sessions: sessions, RequiresApproval: h.speakerEditsNeedApproval(c)}
+	change, err := h.getSpeakerChange(id)
+	if err == nil {
+		profile.PendingChange = &change
+	} else if status.Code(err) != codes.NotFound {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pending changes"})
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdateSpeakerProfile applies a speaker's edit to their profile, or holds it for
+// approval when the workshop requires that
+func (h *Handlers) UpdateSpeakerProfile(c *gin.Context) {
+	id := SpeakerID(c)
+	var req SpeakerProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	change, err := req.change(time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	change.SpeakerID = id
+
+	if h.speakerEditsNeedApproval(c) {
+		if _, err := h.getSpeaker(id); err != nil {
+			if status.Code(err) == codes.NotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Speaker not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch speaker"})
+			return
+		}
+		if _, err := h.db.Collection("speaker_changes").Doc(id).Set(h.db.Context(), change); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit profile changes"})
+			return
+		}
+		h.recordAudit(c, "speaker.change.submit", "speaker", id, nil, change)
+		c.JSON(http.StatusAccepted, gin.H{"pendingChange": change})
+		return
+	}
+
+	before, after, err := h.applySpeakerChange(id, func(tx *firestore.Transaction) (models.SpeakerChange, error) {
+		return change, nil
+	})
+	if err != nil {
+		h.speakerChangeError(c, err)
+		return
+	}
+
+	h.recordAudit(c, "speaker.profile.update", "speaker", id, before, after)
+	c.JSON(http.StatusOK, gin.H{"speaker": after})
+}
+
+// GetSpeakerChanges lists profile edits waiting for approval, oldest first
+func (h *Handlers) GetSpeakerChanges(c *gin.Context) {
+	changes := make([]SpeakerChangeDetails, 0)
+	iter := h.db.Collection("speaker_changes").OrderBy("submittedAt", firestore.Asc).Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch speaker changes"})
+			return
+		}
+		var change models.SpeakerChange
+		if err := doc.DataTo(&change); err != nil {
+			continue
+		}
+		change.SpeakerID = doc.Ref.ID
+
+		// Changes for deleted speakers have nothing left to apply to
+		current, err := h.getSpeaker(change.SpeakerID)
+		if status.Code(err) == codes.NotFound {
+			continue
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch speaker changes"})
+			return
+		}
+		changes = append(changes, SpeakerChangeDetails{SpeakerChange: change, Current: current})
+	}
+	c.JSON(http.StatusOK, changes)
+}
+
+// ApproveSpeakerChange publishes a speaker's pending edit
+func (h *Handlers) ApproveSpeakerChange(c *gin.Context) {
+	id := c.Param("id")
+	changeRef := h.db.Collection("speaker_changes").Doc(id)
+	before, after, err := h.applySpeakerChange(id, func(tx *firestore.Transaction) (models.SpeakerChange, error) {
+		var change models.SpeakerChange
+		doc, err := tx.Get(changeRef)
+		if status.Code(err) == codes.NotFound {
+			return change, errNoSpeakerChange
+		}
+		if err != nil {
+			return change, err
+		}
+		return change, doc.DataTo(&change)
+	})
+	if err != nil {
+		h.speakerChangeError(c, err)
+		return
+	}
+
+	h.recordAudit(c, "speaker.change.approve", "speaker", id, before, after)
+	c.JSON(http.StatusOK, after)
+}
+
+// RejectSpeakerChange discards a speaker's pending edit
+func (h *Handlers) RejectSpeakerChange(c *gin.Context) {
+	id := c.Param("id")
+	before := h.getSnapshot("speaker_changes", id)
+	if before == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No pending changes for this speaker"})
+		return
+	}
+	if _, err := h.db.Collection("speaker_changes").Doc(id).Delete(h.db.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reject speaker changes"})
+		return
+	}
+
+	h.recordAudit(c, "speaker.change.reject", "speaker", id, before, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Speaker changes rejected"})
+}
+
+// GetSpeakerPortal returns the speaker portal settings of the resolved workshop
+func (h *Handlers) GetSpeakerPortal(c *gin.Context) {
+	c.JSON(http.StatusOK, SpeakerPortalSettings{RequireApproval: h.speakerEditsNeedApproval(c)})
+}
+
+// UpdateSpeakerPortal sets whether speaker edits need approval. Edits already waiting
+// stay pending until an admin decides on them.
+func (h *Handlers) UpdateSpeakerPortal(c *gin.Context) {
+	workshop := c.MustGet(WorkshopKey).(*models.Workshop)
+
+	var req SpeakerPortalSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set rather than update so the default workshop gets a document on first use
+	_, err := h.db.Workshops().Doc(workshop.Slug).Set(h.db.Context(), map[string]interface{}{
+		"name":                     workshop.Name,
+		"speakerEditsNeedApproval": req.RequireApproval,
+	}, firestore.Merge([]string{"name"}, []string{"speakerEditsNeedApproval"}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update speaker portal settings"})
+		return
+	}
+	h.workshops.invalidate(workshop.Slug)
+
+	h.recordAudit(c, "workshop.speaker_portal.update", "workshop", workshop.Slug,
+		SpeakerPortalSettings{RequireApproval: workshop.SpeakerEditsNeedApproval}, req)
+	c.JSON(http.StatusOK, req)
+}
+
+// applySpeakerChange updates the speaker with the change read by load and drops any
+// pending change, in one transaction with the speaker.updated event
+func (h *Handlers) applySpeakerChange(id string, load func(*firestore.Transaction) (models.SpeakerChange, error)) (before, after models.Speaker, err error) {
+	speakerRef := h.db.Collection("speakers").Doc(id)
+	changeRef := h.db.Collection("speaker_changes").Doc(id)
+	err = h.transactWithEvents(func(tx *firestore.Transaction) ([]outboxEntry, error) {
+		change, err := load(tx)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := tx.Get(speakerRef)
+		if err != nil {
+			return nil, err
+		}
+		if err := doc.DataTo(&before); err != nil {
+			return nil, err
+		}
+		before.ID = id
+		after = withSpeakerChange(before, change)
+		if err := tx.Set(speakerRef, after); err != nil {
+			return nil, err
+		}
+		if err := tx.Delete(changeRef); err != nil {
+			return nil, err
+		}
+		return []outboxEntry{{WebhookSpeakerUpdated, after}}, nil
+	})
+	return before, after, err
+}
+
+func (h *Handlers) speakerChangeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errNoSpeakerChange):
+		c.JSON(http.StatusNotFound, gin.H{"error": "No pending changes for this speaker"})
+	case status.Code(err) == codes.NotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Speaker not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update speaker"})
+	}
+}
+
+func (h *Handlers) speakerInviteByToken(token string, now time.Time) (*models.SpeakerInvite, error) {
+	iter := h.db.Collection("speaker_invites").Where("tokenHash", "==", hashToken(token)).Limit(1).Documents(h.db.Context())
+	defer iter.Stop()
+	doc, err := iter.Next()
+	if err == iterator.Done {
+		return nil, errUnknownSpeakerToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var invite models.SpeakerInvite
+	if err := doc.DataTo(&invite); err != nil {
+		return nil, err
+	}
+	invite.SpeakerID = doc.Ref.ID
+	if !now.Before(invite.ExpiresAt) {
+		return nil, errExpiredSpeakerToken
+	}
+	return &invite, nil
+}
+
+func (h *Handlers) getSpeaker(id string) (models.Speaker, error) {
+	var speaker models.Speaker
+	doc, err := h.db.Collection("speakers").Doc(id).Get(h.db.Context())
+	if err != nil {
+		return speaker, err
+	}
+	if err := doc.DataTo(&speaker); err != nil {
+		return speaker, err
+	}
+	speaker.ID = doc.Ref.ID
+	return speaker, nil
+}
+
+func (h *Handlers) getSpeakerChange(id string) (models.SpeakerChange, error) {
+	var change models.SpeakerChange
+	doc, err := h.db.Collection("speaker_changes").Doc(id).Get(h.db.Context())
+	if err != nil {
+		return change, err
+	}
+	if err := doc.DataTo(&change); err != nil {
+		return change, err
+	}
+	change.SpeakerID = doc.Ref.ID
+	return change, nil
+}
+
+func (h *Handlers) speakerEditsNeedApproval(c *gin.Context) bool {
+	if workshop, ok := c.Get(WorkshopKey); ok {
+		return workshop.(*models.Workshop).SpeakerEditsNeedApproval
+	}
+	return false
+}
+
+// change validates the request beyond its binding rules. Profile links must be http or
+// https URLs, since the site renders them as links.
+func (req *SpeakerProfileRequest) change(now time.Time) (models.SpeakerChange, error) {
+	change := models.SpeakerChange{
+		Name:        strings.TrimSpace(req.Name),
+		Bio:         strings.TrimSpace(req.Bio),
+		LinkedInURL: strings.TrimSpace(req.LinkedInURL),
+		TwitterURL:  strings.TrimSpace(req.TwitterURL),
+		SubmittedAt: now,
+	}
+	if change.Name == "" {
+		return change, errors.New("name must not be blank")
+	}
+
+	urls := []struct{ field, value string }{
+		{"linkedinUrl", change.LinkedInURL},
+		{"twitterUrl", change.TwitterURL},
+	}
+	for _, f := range urls {
+		if f.value == "" {
+			continue
+		}
+		u, err := url.Parse(f.value)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return change, errors.New(f.field + " must be an http or https URL")
+		}
+	}
+	return change, nil
+}
+
+// withSpeakerChange returns the speaker with the editable fields replaced; the photo
+// and ID stay as they are
+func withSpeakerChange(speaker models.Speaker, change models.SpeakerChange) models.Speaker {
+	speaker.Name = change.Name
+	speaker.Bio = change.Bio
+	speaker.LinkedInURL = change.LinkedInURL
+	speaker.TwitterURL = change.TwitterURL
+	return speaker
+}
+
+// speakerPortalLink builds the magic link on the frontend at origin. The token goes in
+// the fragment so it isn't sent to the server or logged with the page request. An
+// empty slug means the default workshop.
+func speakerPortalLink(origin, slug, token string) string {
+	link := strings.TrimSuffix(origin, "/") + "/speaker"
+	if slug != "" {
+		link += "?workshop=" + url.QueryEscape(slug)
+	}
+	return link + "#token=" + url.QueryEscape(token)
+}
+
+func speakerInviteEmail(name, workshopTitle, link string, expiresAt time.Time) (subject, body string) {
+	event := "the workshop"
+	subject = "Update your speaker profile"
+	if workshopTitle != "" {
+		event = workshopTitle
+		subject += " for " + workshopTitle
+	}
+	body = "Hi " + name + ",\n\n" +
+		"You can review your speaker profile and sessions for " + event + " and correct your bio here:\n\n" +
+		link + "\n\n" +
+		"The link is personal and works until " + expiresAt.UTC().Format("January 2, 2006") + ". Please don't forward it.\n"
+	return subject, body
+}
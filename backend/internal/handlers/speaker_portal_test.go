@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"appdirect-workshop-backend/internal/config"
+	"appdirect-workshop-backend/internal/database"
+	"appdirect-workshop-backend/internal/database/firestoretest"
+	"appdirect-workshop-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireSpeakerRejectsMissingToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, token := range []string{"", "wrt_a-registration-token"} {
+		h := newWorkshopTestHandlers()
+		router := gin.New()
+		router.GET("/api/speaker/me", h.RequireSpeaker(), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		req, _ := http.NewRequest("GET", "/api/speaker/me", nil)
+		req.Header.Set(SpeakerTokenHeader, token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestSpeakerPortalValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{name: "invite without email", method: "POST", path: "/api/admin/speakers/sp1/invite", body: `{}`},
+		{name: "invite with bad email", method: "POST", path: "/api/admin/speakers/sp1/invite", body: `{"email":"ada"}`},
+		{name: "profile without name", method: "PUT", path: "/api/speaker/me", body: `{"bio":"Engineer"}`},
+		{name: "profile with bad link", method: "PUT", path: "/api/speaker/me", body: `{"name":"Ada","twitterUrl":"javascript:alert(1)"}`},
+		{name: "portal settings", method: "PUT", path: "/api/admin/workshop/speaker-portal", body: `{"requireApproval":"yes"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkshopTestHandlers()
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set(WorkshopKey, &models.Workshop{Slug: "default-workshop"})
+				c.Set(SpeakerKey, &models.SpeakerInvite{SpeakerID: "sp1"})
+			})
+			router.POST("/api/admin/speakers/:id/invite", h.InviteSpeaker)
+			router.PUT("/api/speaker/me", h.UpdateSpeakerProfile)
+			router.PUT("/api/admin/workshop/speaker-portal", h.UpdateSpeakerPortal)
+
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestSpeakerProfileRequest(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	req := SpeakerProfileRequest{
+		Name:        " Ada ",
+		Bio:         " Engineer ",
+		LinkedInURL: " https://linkedin.com/in/ada ",
+	}
+	change, err := req.change(now)
+	require.NoError(t, err)
+	assert.Equal(t, models.SpeakerChange{
+		Name:        "Ada",
+		Bio:         "Engineer",
+		LinkedInURL: "https://linkedin.com/in/ada",
+		SubmittedAt: now,
+	}, change)
+
+	invalid := []SpeakerProfileRequest{
+		{Name: " "},
+		{Name: "Ada", LinkedInURL: "linkedin.com/in/ada"},
+		{Name: "Ada", TwitterURL: "javascript:alert(1)"},
+	}
+	for _, req := range invalid {
+		_, err := req.change(now)
+		assert.Error(t, err)
+	}
+}
+
+func TestWithSpeakerChange(t *testing.T) {
+	speaker := models.Speaker{
+		ID:         "sp1",
+		Name:       "Ada",
+		Bio:        "Old bio",
+		ImageURL:   "/api/images/a-large.jpg",
+		TwitterURL: "https://x.com/ada",
+		Images:     []models.ImageVariant{{Size: "large", Format: "jpeg", URL: "/api/images/a-large.jpg"}},
+	}
+	updated := withSpeakerChange(speaker, models.SpeakerChange{Name: "Ada L.", Bio: "New bio"})
+
+	assert.Equal(t, models.Speaker{
+		ID:       "sp1",
+		Name:     "Ada L.",
+		Bio:      "New bio",
+		ImageURL: "/api/images/a-large.jpg",
+		Images:   speaker.Images,
+	}, updated)
+}
+
+func TestSpeakerPortalLink(t *testing.T) {
+	assert.Equal(t, "https://workshop.example.com/speaker#token=spk_abc-_123",
+		speakerPortalLink("https://workshop.example.com/", "", "spk_abc-_123"))
+	assert.Equal(t, "https://workshop.example.com/speaker?workshop=devfest-2026#token=spk_abc",
+		speakerPortalLink("https://workshop.example.com", "devfest-2026", "spk_abc"))
+}
+
+func TestSpeakerInviteEmail(t *testing.T) {
+	expires := time.Date(2026, 4, 2, 9, 0, 0, 0, time.UTC)
+
+	subject, body := speakerInviteEmail("Ada", "DevFest 2026", "https://example.com/speaker#token=spk_abc", expires)
+	assert.Equal(t, "Update your speaker profile for DevFest 2026", subject)
+	assert.Contains(t, body, "Hi Ada,")
+	assert.Contains(t, body, "https://example.com/speaker#token=spk_abc")
+	assert.Contains(t, body, "April 2, 2026")
+
+	subject, _ = speakerInviteEmail("Ada", "", "https://example.com/speaker#token=spk_abc", expires)
+	assert.Equal(t, "Update your speaker profile", subject)
+}
+
+// newSpeakerPortalRouter serves the portal and its admin routes for a workshop whose
+// approval setting is taken from requireApproval
+func newSpeakerPortalRouter(t *testing.T, requireApproval bool) (*gin.Engine, database.DatabaseInterface) {
+	t.Helper()
+	db := firestoretest.NewDatabase(t, "test-collection")
+	for id, name := range map[string]string{"sp1": "Ada", "sp2": "Grace"} {
+		_, err := db.Collection("speakers").Doc(id).Set(db.Context(), models.Speaker{Name: name, Bio: name + "'s bio"})
+		require.NoError(t, err)
+	}
+	for id, speakers := range map[string][]string{"s1": {"sp1"}, "s2": {"sp2"}} {
+		_, err := db.Collection("sessions").Doc(id).Set(db.Context(), models.Session{Title: id, SpeakerIDs: speakers})
+		require.NoError(t, err)
+	}
+	h := New(db, &config.Config{SubcollectionID: "test-collection", CORSOrigin: "https://workshop.example.com"})
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(WorkshopKey, &models.Workshop{Slug: "test-collection", SpeakerEditsNeedApproval: requireApproval})
+	})
+	router.GET("/api/speaker/me", h.RequireSpeaker(), h.GetSpeakerProfile)
+	router.PUT("/api/speaker/me", h.RequireSpeaker(), h.UpdateSpeakerProfile)
+	router.POST("/api/admin/speakers/:id/invite", h.InviteSpeaker)
+	router.GET("/api/admin/speaker-changes", h.GetSpeakerChanges)
+	router.POST("/api/admin/speaker-changes/:id/approve", h.ApproveSpeakerChange)
+	router.POST("/api/admin/speaker-changes/:id/reject", h.RejectSpeakerChange)
+	return router, db
+}
+
+// inviteSpeaker invites a speaker and returns the token from the magic link
+func inviteSpeaker(t *testing.T, router *gin.Engine, id string) string {
+	t.Helper()
+	w := serveSpeakerPortal(router, "POST", "/api/admin/speakers/"+id+"/invite", "", `{"email":"`+id+`@example.com"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var resp InviteSpeakerResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	link, err := url.Parse(resp.Link)
+	require.NoError(t, err)
+	fragment, err := url.ParseQuery(link.Fragment)
+	require.NoError(t, err)
+	return fragment.Get("token")
+}
+
+func serveSpeakerPortal(router *gin.Engine, method, path, token, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set(SpeakerTokenHeader, token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func storedSpeaker(t *testing.T, db database.DatabaseInterface, id string) models.Speaker {
+	t.Helper()
+	doc, err := db.Collection("speakers").Doc(id).Get(db.Context())
+	require.NoError(t, err)
+	var speaker models.Speaker
+	require.NoError(t, doc.DataTo(&speaker))
+	return speaker
+}
+
+func TestSpeakerEditsOwnProfileOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router, db := newSpeakerPortalRouter(t, false)
+	token := inviteSpeaker(t, router, "sp1")
+
+	w := serveSpeakerPortal(router, "GET", "/api/speaker/me", token, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var profile SpeakerProfile
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &profile))
+	assert.Equal(t, "sp1", profile.Speaker.ID)
+	require.Len(t, profile.Sessions, 1)
+	assert.Equal(t, "s1", profile.Sessions[0].ID)
+	assert.False(t, profile.RequiresApproval)
+
+	// The token decides whose profile changes; an ID in the body is ignored
+	w = serveSpeakerPortal(router, "PUT", "/api/speaker/me", token, `{"id":"sp2","name":"Ada Lovelace","bio":"Analyst"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Ada Lovelace", storedSpeaker(t, db, "sp1").Name)
+	assert.Equal(t, "Grace", storedSpeaker(t, db, "sp2").Name)
+	assert.Equal(t, "Grace's bio", storedSpeaker(t, db, "sp2").Bio)
+
+	// Inviting again replaces the link
+	newToken := inviteSpeaker(t, router, "sp1")
+	assert.Equal(t, http.StatusUnauthorized, serveSpeakerPortal(router, "GET", "/api/speaker/me", token, "").Code)
+	assert.Equal(t, http.StatusOK, serveSpeakerPortal(router, "GET", "/api/speaker/me", newToken, "").Code)
+
+	_, err := db.Collection("speaker_invites").Doc("sp1").Update(db.Context(), []firestore.Update{{Path: "expiresAt", Value: time.Now().Add(-time.Minute)}})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, serveSpeakerPortal(router, "PUT", "/api/speaker/me", newToken, `{"name":"Mallory"}`).Code)
+	assert.Equal(t, "Ada Lovelace", storedSpeaker(t, db, "sp1").Name)
+}
+
+func TestSpeakerEditsWaitForApproval(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router, db := newSpeakerPortalRouter(t, true)
+	token := inviteSpeaker(t, router, "sp1")
+
+	w := serveSpeakerPortal(router, "PUT", "/api/speaker/me", token, `{"name":"Ada Lovelace","bio":"Analyst"}`)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "Ada", storedSpeaker(t, db, "sp1").Name)
+
+	w = serveSpeakerPortal(router, "GET", "/api/speaker/me", token, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var profile SpeakerProfile
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &profile))
+	assert.True(t, profile.RequiresApproval)
+	assert.Equal(t, "Ada", profile.Speaker.Name)
+	require.NotNil(t, profile.PendingChange)
+	assert.Equal(t, "Ada Lovelace", profile.PendingChange.Name)
+
+	w = serveSpeakerPortal(router, "GET", "/api/admin/speaker-changes", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var changes []SpeakerChangeDetails
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &changes))
+	require.Len(t, changes, 1)
+	assert.Equal(t, "sp1", changes[0].SpeakerID)
+	assert.Equal(t, "Ada", changes[0].Current.Name)
+
+	w = serveSpeakerPortal(router, "POST", "/api/admin/speaker-changes/sp1/approve", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	approved := storedSpeaker(t, db, "sp1")
+	assert.Equal(t, "Ada Lovelace", approved.Name)
+	assert.Equal(t, "Analyst", approved.Bio)
+	assert.Equal(t, http.StatusNotFound, serveSpeakerPortal(router, "POST", "/api/admin/speaker-changes/sp1/approve", "", "").Code)
+
+	// A rejected edit never reaches the profile
+	require.Equal(t, http.StatusAccepted, serveSpeakerPortal(router, "PUT", "/api/speaker/me", token, `{"name":"Countess"}`).Code)
+	require.Equal(t, http.StatusOK, serveSpeakerPortal(router, "POST", "/api/admin/speaker-changes/sp1/reject", "", "").Code)
+	assert.Equal(t, "Ada Lovelace", storedSpeaker(t, db, "sp1").Name)
+	assert.Equal(t, http.StatusNotFound, serveSpeakerPortal(router, "POST", "/api/admin/speaker-changes/sp1/reject", "", "").Code)
+
+	w = serveSpeakerPortal(router, "GET", "/api/admin/speaker-changes", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[]`, w.Body.String())
+}
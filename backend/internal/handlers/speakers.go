@@ -5,6 +5,7 @@ import (
 
 	"appdirect-workshop-backend/internal/models"
 
+	"cloud.google.com/go/firestore"
 	"github.com/gin-gonic/gin"
 	"google.golang.org/api/iterator"
 	"google.golang.org/grpc/codes"
@@ -46,13 +47,17 @@ func (h *Handlers) CreateSpeaker(c *gin.Context) {
 		return
 	}
 
-	docRef, _, err := h.db.Collection("speakers").Add(h.db.Context(), speaker)
+	docRef := h.db.Collection("speakers").NewDoc()
+	speaker.ID = docRef.ID
+	err := h.writeWithEvent(WebhookSpeakerCreated, speaker, func(tx *firestore.Transaction) error {
+		return tx.Create(docRef, speaker)
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create speaker"})
 		return
 	}
 
-	speaker.ID = docRef.ID
+	h.recordAudit(c, "speaker.create", "speaker", speaker.ID, nil, speaker)
 	c.JSON(http.StatusCreated, speaker)
 }
 
@@ -64,7 +69,22 @@ func (h *Handlers) UpdateSpeaker(c *gin.Context) {
 		return
 	}
 
-	_, err := h.db.Collection("speakers").Doc(id).Set(h.db.Context(), updates)
+	before := h.getSnapshot("speakers", id)
+	updates.ID = id
+	ref := h.db.Collection("speakers").Doc(id)
+	err := h.transactWithEvents(func(tx *firestore.Transaction) ([]outboxEntry, error) {
+		// Uploaded images stay until the image URL is replaced
+		updates.Images = nil
+		doc, err := tx.Get(ref)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return nil, err
+		}
+		var current models.Speaker
+		if err == nil && doc.DataTo(&current) == nil && current.ImageURL == updates.ImageURL {
+			updates.Images = current.Images
+		}
+		return []outboxEntry{{WebhookSpeakerUpdated, updates}}, tx.Set(ref, updates)
+	})
 	if err != nil {
 		if status.Code(err) == codes.NotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Speaker not found"})
@@ -74,13 +94,22 @@ func (h *Handlers) UpdateSpeaker(c *gin.Context) {
 		return
 	}
 
-	updates.ID = id
+	h.recordAudit(c, "speaker.update", "speaker", id, before, updates)
 	c.JSON(http.StatusOK, updates)
 }
 
 func (h *Handlers) DeleteSpeaker(c *gin.Context) {
 	id := c.Param("id")
-	_, err := h.db.Collection("speakers").Doc(id).Delete(h.db.Context())
+	before := h.getSnapshot("speakers", id)
+	err := h.writeWithEvent(WebhookSpeakerDeleted, gin.H{"id": id}, func(tx *firestore.Transaction) error {
+		// The speaker's magic link and pending edits go with them
+		for _, collection := range []string{"speaker_invites", "speaker_changes"} {
+			if err := tx.Delete(h.db.Collection(collection).Doc(id)); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(h.db.Collection("speakers").Doc(id))
+	})
 	if err != nil {
 		if status.Code(err) == codes.NotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Speaker not found"})
@@ -90,6 +119,7 @@ func (h *Handlers) DeleteSpeaker(c *gin.Context) {
 		return
 	}
 
+	h.recordAudit(c, "speaker.delete", "speaker", id, before, nil)
 	c.JSON(http.StatusOK, gin.H{"message": "Speaker deleted successfully"})
 }
 
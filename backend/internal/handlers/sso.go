@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"appdirect-workshop-backend/internal/middleware"
+	"appdirect-workshop-backend/internal/oidc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	ssoFlowCookie = "oidc_flow"
+	ssoFlowTTL    = 10 * time.Minute
+)
+
+// SSOLogin starts the OpenID Connect authorization-code + PKCE flow
+func (h *Handlers) SSOLogin(c *gin.Context) {
+	if h.oidc == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	state, err1 := oidc.RandomString()
+	nonce, err2 := oidc.RandomString()
+	verifier, err3 := oidc.RandomString()
+	if err1 != nil || err2 != nil || err3 != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return
+	}
+
+	authURL, err := h.oidc.AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Identity provider is unavailable"})
+		return
+	}
+
+	// The flow parameters live in a signed cookie so no server-side session is needed
+	flow := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"purpose":  "oidc",
+		"state":    state,
+		"nonce":    nonce,
+		"verifier": verifier,
+		"exp":      time.Now().Add(ssoFlowTTL).Unix(),
+	})
+	flowToken, err := flow.SignedString([]byte(h.cfg.AdminTokenSecret))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return
+	}
+
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     ssoFlowCookie,
+		Value:    flowToken,
+		Path:     "/api/admin/sso",
+		MaxAge:   int(ssoFlowTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   isSecureRequest(c),
+		SameSite: http.SameSiteLaxMode,
+	})
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// SSOCallback completes the flow and redirects to the dashboard with an admin token
+func (h *Handlers) SSOCallback(c *gin.Context) {
+	if h.oidc == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	// Clear the flow cookie whatever the outcome
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     ssoFlowCookie,
+		Path:     "/api/admin/sso",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isSecureRequest(c),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	if errParam := c.Query("error"); errParam != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login was rejected by the identity provider: " + errParam})
+		return
+	}
+
+	flowToken, err := c.Cookie(ssoFlowCookie)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Login session expired, please try again"})
+		return
+	}
+
+	flow := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(flowToken, flow, func(token *jwt.Token) (interface{}, error) {
+		return []byte(h.cfg.AdminTokenSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid || flow["purpose"] != "oidc" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Login session expired, please try again"})
+		return
+	}
+
+	state, _ := flow["state"].(string)
+	if state == "" || c.Query("state") != state {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid login state"})
+		return
+	}
+
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Authorization code is required"})
+		return
+	}
+
+	verifier, _ := flow["verifier"].(string)
+	nonce, _ := flow["nonce"].(string)
+	claims, err := h.oidc.Exchange(c.Request.Context(), code, verifier, nonce)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to verify identity"})
+		return
+	}
+
+	if err := h.checkSSODomain(claims); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, ok := h.mapSSORole(claims)
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "No admin role is assigned to this account"})
+		return
+	}
+
+	tokenString, err := h.issueSSOToken(strings.ToLower(claims.Email), role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	// The fragment keeps the token out of server logs and Referer headers
+	c.Redirect(http.StatusFound, h.cfg.OIDCPostLoginURL+"#token="+url.QueryEscape(tokenString))
+}
+
+// issueSSOToken signs an admin JWT for an identity verified by the issuer. It is
+// signed with OIDCTokenSecret so holders of the shared password can't mint one.
+func (h *Handlers) issueSSOToken(email, role string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"admin": true,
+		"sso":   true,
+		"sub":   email,
+		"role":  role,
+		"exp":   time.Now().Add(time.Hour * 24).Unix(),
+	})
+	return token.SignedString([]byte(h.cfg.OIDCTokenSecret))
+}
+
+func (h *Handlers) checkSSODomain(claims *oidc.Claims) error {
+	if claims.Email == "" {
+		return fmt.Errorf("Identity provider did not return an email address")
+	}
+	if claims.EmailVerified != nil && !*claims.EmailVerified {
+		return fmt.Errorf("Email address is not verified")
+	}
+
+	at := strings.LastIndex(claims.Email, "@")
+	domain := strings.ToLower(claims.Email[at+1:])
+	for _, allowed := range h.cfg.OIDCAllowedDomains {
+		if strings.EqualFold(domain, allowed) {
+			return nil
+		}
+	}
+	return fmt.Errorf("Email domain is not allowed")
+}
+
+// mapSSORole picks the most privileged role matching the configured claim.
+// Without a mapping every allowed user is an admin.
+func (h *Handlers) mapSSORole(claims *oidc.Claims) (string, bool) {
+	if len(h.cfg.OIDCRoleMapping) == 0 {
+		return middleware.RoleAdmin, true
+	}
+
+	var values []string
+	switch v := claims.Extra[h.cfg.OIDCRoleClaim].(type) {
+	case string:
+		values = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	role := ""
+	for _, value := range values {
+		switch h.cfg.OIDCRoleMapping[value] {
+		case middleware.RoleAdmin:
+			return middleware.RoleAdmin, true
+		case middleware.RoleViewer:
+			role = middleware.RoleViewer
+		}
+	}
+	return role, role != ""
+}
+
+func isSecureRequest(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"appdirect-workshop-backend/internal/config"
+	"appdirect-workshop-backend/internal/oidc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// newMockIssuer starts a local OpenID Connect provider that issues ID tokens with the given claims
+func newMockIssuer(t *testing.T, claims func(nonce string) jwt.MapClaims) *httptest.Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	var server *httptest.Server
+	nonces := map[string]string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 server.URL,
+			"authorization_endpoint": server.URL + "/authorize",
+			"token_endpoint":         server.URL + "/token",
+			"jwks_uri":               server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "k1",
+				"kty": "RSA",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		// Remember the nonce and PKCE challenge for the code we hand out
+		nonces[r.URL.Query().Get("code_challenge")] = r.URL.Query().Get("nonce")
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		nonce, ok := nonces[oidc.CodeChallenge(r.Form.Get("code_verifier"))]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		c := claims(nonce)
+		c["iss"] = server.URL
+		c["aud"] = "client-id"
+		c["exp"] = time.Now().Add(time.Hour).Unix()
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+		token.Header["kid"] = "k1"
+		signed, _ := token.SignedString(key)
+		json.NewEncoder(w).Encode(map[string]string{"id_token": signed})
+	})
+	server = httptest.NewServer(mux)
+	return server
+}
+
+func runSSOFlow(t *testing.T, cfg *config.Config, issuer *httptest.Server) *httptest.ResponseRecorder {
+	h := New(createMockDB(), cfg)
+	router := gin.New()
+	router.GET("/api/admin/sso/login", h.SSOLogin)
+	router.GET("/api/admin/sso/callback", h.SSOCallback)
+
+	req, _ := http.NewRequest("GET", "/api/admin/sso/login", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusFound, w.Code)
+
+	// Follow the redirect to the issuer like a browser would
+	location := w.Header().Get("Location")
+	resp, err := http.Get(location)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	authURL, _ := url.Parse(location)
+	callback := "/api/admin/sso/callback?code=abc&state=" + url.QueryEscape(authURL.Query().Get("state"))
+	req, _ = http.NewRequest("GET", callback, nil)
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestSSOFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		email          string
+		groups         []string
+		mapping        map[string]string
+		expectedStatus int
+		expectedRole   string
+	}{
+		{
+			name:           "allowed domain without mapping",
+			email:          "alice@example.com",
+			expectedStatus: http.StatusFound,
+			expectedRole:   "admin",
+		},
+		{
+			name:           "mapped viewer group",
+			email:          "bob@example.com",
+			groups:         []string{"staff", "volunteers"},
+			mapping:        map[string]string{"organizers": "admin", "volunteers": "viewer"},
+			expectedStatus: http.StatusFound,
+			expectedRole:   "viewer",
+		},
+		{
+			name:           "unmapped group",
+			email:          "carol@example.com",
+			groups:         []string{"staff"},
+			mapping:        map[string]string{"organizers": "admin"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "disallowed domain",
+			email:          "mallory@evil.com",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := newMockIssuer(t, func(nonce string) jwt.MapClaims {
+				return jwt.MapClaims{"sub": "user", "nonce": nonce, "email": tt.email, "groups": tt.groups}
+			})
+			defer issuer.Close()
+
+			cfg := &config.Config{
+				AdminPassword:      "test-password",
+				AdminTokenSecret:   "test-token-secret",
+				SubcollectionID:    "test-collection",
+				OIDCIssuer:         issuer.URL,
+				OIDCClientID:       "client-id",
+				OIDCRedirectURL:    "http://localhost/api/admin/sso/callback",
+				OIDCAllowedDomains: []string{"example.com"},
+				OIDCRoleClaim:      "groups",
+				OIDCRoleMapping:    tt.mapping,
+				OIDCPostLoginURL:   "/admin",
+				OIDCTokenSecret:    "sso-secret",
+			}
+
+			w := runSSOFlow(t, cfg, issuer)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusFound {
+				return
+			}
+
+			location := w.Header().Get("Location")
+			assert.True(t, strings.HasPrefix(location, "/admin#token="))
+			tokenString, _ := url.QueryUnescape(strings.TrimPrefix(location, "/admin#token="))
+
+			claims := jwt.MapClaims{}
+			_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+				return []byte("sso-secret"), nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, true, claims["admin"])
+			assert.Equal(t, true, claims["sso"])
+			assert.Equal(t, tt.email, claims["sub"])
+			assert.Equal(t, tt.expectedRole, claims["role"])
+		})
+	}
+}
+
+func TestSSOCallbackRejectsBadState(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		AdminPassword:      "test-password",
+		AdminTokenSecret:   "test-token-secret",
+		OIDCIssuer:         "http://127.0.0.1:0",
+		OIDCClientID:       "client-id",
+		OIDCRedirectURL:    "http://localhost/api/admin/sso/callback",
+		OIDCAllowedDomains: []string{"example.com"},
+	}
+	h := New(createMockDB(), cfg)
+	router := gin.New()
+	router.GET("/api/admin/sso/callback", h.SSOCallback)
+
+	// No flow cookie at all
+	req, _ := http.NewRequest("GET", "/api/admin/sso/callback?code=abc&state=xyz", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSSONotConfigured(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := New(createMockDB(), &config.Config{AdminPassword: "test-password", AdminTokenSecret: "test-token-secret"})
+	router := gin.New()
+	router.GET("/api/admin/sso/login", h.SSOLogin)
+
+	req, _ := http.NewRequest("GET", "/api/admin/sso/login", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
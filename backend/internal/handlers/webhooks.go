@@ -0,0 +1,526 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"appdirect-workshop-backend/internal/middleware"
+	"appdirect-workshop-backend/internal/models"
+	"appdirect-workshop-backend/internal/webhook"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Events webhooks can subscribe to
+const (
+	WebhookRegistrationCreated   = "registration.created"
+	WebhookRegistrationCancelled = "registration.cancelled"
+	WebhookSessionCreated        = "session.created"
+	WebhookSessionUpdated        = "session.updated"
+	WebhookSessionDeleted        = "session.deleted"
+	WebhookSpeakerCreated        = "speaker.created"
+	WebhookSpeakerUpdated        = "speaker.updated"
+	WebhookSpeakerDeleted        = "speaker.deleted"
+)
+
+// Webhook delivery states
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+const (
+	webhookSecretPrefix = "whsec_"
+	eventIDPrefix       = "evt_"
+
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 200
+
+	// deliveryLease is how long an attempt has to finish before the delivery may be
+	// tried again by another instance
+	deliveryLease = time.Minute
+)
+
+var webhookEvents = []string{
+	WebhookRegistrationCreated,
+	WebhookRegistrationCancelled,
+	WebhookSessionCreated,
+	WebhookSessionUpdated,
+	WebhookSessionDeleted,
+	WebhookSpeakerCreated,
+	WebhookSpeakerUpdated,
+	WebhookSpeakerDeleted,
+}
+
+type WebhookRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	Events      []string `json:"events" binding:"required,min=1"`
+	Description string   `json:"description" binding:"max=200"`
+	// Active defaults to true
+	Active *bool `json:"active"`
+}
+
+// CreateWebhookResponse includes the signing secret, which is only returned here
+type CreateWebhookResponse struct {
+	models.Webhook
+	Secret string `json:"secret"`
+}
+
+// WebhookPayload is the JSON body of every delivery
+type WebhookPayload struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	Workshop  string      `json:"workshop"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+// GetWebhookEvents lists the events webhooks can subscribe to
+func (h *Handlers) GetWebhookEvents(c *gin.Context) {
+	c.JSON(http.StatusOK, webhookEvents)
+}
+
+func (h *Handlers) GetWebhooks(c *gin.Context) {
+	webhooks := make([]models.Webhook, 0)
+	iter := h.db.Collection("webhooks").OrderBy("createdAt", firestore.Asc).Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhooks"})
+			return
+		}
+
+		var hook models.Webhook
+		if err := doc.DataTo(&hook); err != nil {
+			continue
+		}
+		hook.ID = doc.Ref.ID
+		webhooks = append(webhooks, hook)
+	}
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// CreateWebhook registers an endpoint and returns its signing secret once
+func (h *Handlers) CreateWebhook(c *gin.Context) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	hook, err := req.webhook()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	secret, err := generateToken(webhookSecretPrefix)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+	hook.Secret = secret
+	hook.CreatedBy = c.GetString(middleware.AdminIDKey)
+	hook.CreatedAt = time.Now()
+
+	docRef, _, err := h.db.Collection("webhooks").Add(h.db.Context(), hook)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	hook.ID = docRef.ID
+	h.recordAudit(c, "webhook.create", "webhook", hook.ID, nil, hook)
+	c.JSON(http.StatusCreated, CreateWebhookResponse{Webhook: hook, Secret: secret})
+}
+
+// UpdateWebhook changes a webhook's URL, events, description or active flag; the secret
+// is kept
+func (h *Handlers) UpdateWebhook(c *gin.Context) {
+	id := c.Param("id")
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	hook, err := req.webhook()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	before, err := h.getWebhook(id)
+	if status.Code(err) == codes.NotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhook"})
+		return
+	}
+	hook.ID = id
+	hook.Secret = before.Secret
+	hook.CreatedBy = before.CreatedBy
+	hook.CreatedAt = before.CreatedAt
+
+	if _, err := h.db.Collection("webhooks").Doc(id).Set(h.db.Context(), hook); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+		return
+	}
+
+	h.recordAudit(c, "webhook.update", "webhook", id, before, hook)
+	c.JSON(http.StatusOK, hook)
+}
+
+// DeleteWebhook removes a webhook; its delivery log is kept
+func (h *Handlers) DeleteWebhook(c *gin.Context) {
+	id := c.Param("id")
+	before, err := h.getWebhook(id)
+	if status.Code(err) == codes.NotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhook"})
+		return
+	}
+
+	if _, err := h.db.Collection("webhooks").Doc(id).Delete(h.db.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+
+	h.recordAudit(c, "webhook.delete", "webhook", id, before, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// GetWebhookDeliveries lists a webhook's deliveries, newest first, optionally only
+// those with the given status
+func (h *Handlers) GetWebhookDeliveries(c *gin.Context) {
+	limit := defaultDeliveryLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxDeliveryLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+			return
+		}
+		limit = n
+	}
+	deliveryStatus := c.Query("status")
+	if deliveryStatus != "" && deliveryStatus != DeliveryPending && deliveryStatus != DeliverySucceeded && deliveryStatus != DeliveryFailed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, succeeded or failed"})
+		return
+	}
+
+	query := h.db.Collection("webhook_deliveries").Where("webhookId", "==", c.Param("id"))
+	if deliveryStatus != "" {
+		query = query.Where("status", "==", deliveryStatus)
+	}
+	deliveries := make([]models.WebhookDelivery, 0)
+	iter := query.OrderBy("createdAt", firestore.Desc).Limit(limit).Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deliveries"})
+			return
+		}
+
+		var delivery models.WebhookDelivery
+		if err := doc.DataTo(&delivery); err != nil {
+			continue
+		}
+		delivery.ID = doc.Ref.ID
+		deliveries = append(deliveries, delivery)
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// RedeliverWebhook sends a logged delivery's payload again as a new delivery with the
+// same event ID, whatever the original's outcome, and returns it with 202
+func (h *Handlers) RedeliverWebhook(c *gin.Context) {
+	id, deliveryID := c.Param("id"), c.Param("deliveryId")
+
+	hook, err := h.getWebhook(id)
+	if status.Code(err) == codes.NotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhook"})
+		return
+	}
+
+	doc, err := h.db.Collection("webhook_deliveries").Doc(deliveryID).Get(h.db.Context())
+	if status.Code(err) == codes.NotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch delivery"})
+		return
+	}
+	var original models.WebhookDelivery
+	if err := doc.DataTo(&original); err != nil || original.WebhookID != id {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	}
+
+	ref := h.db.Collection("webhook_deliveries").NewDoc()
+	delivery, err := h.startDelivery(ref, hook, models.WebhookDelivery{
+		EventID:      original.EventID,
+		Event:        original.Event,
+		Payload:      original.Payload,
+		RedeliveryOf: deliveryID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to redeliver"})
+		return
+	}
+
+	h.recordAudit(c, "webhook.redeliver", "webhook", id, nil, gin.H{"delivery": delivery.ID, "redeliveryOf": deliveryID})
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// webhookPayload builds the delivery for an outbox event, leaving the webhook unset. The
+// event ID identifies it to receivers.
+func (h *Handlers) webhookPayload(event models.OutboxEvent) (models.WebhookDelivery, error) {
+	body, err := json.Marshal(WebhookPayload{
+		ID:        event.ID,
+		Type:      event.Type,
+		Workshop:  h.cfg.SubcollectionID,
+		CreatedAt: event.CreatedAt,
+		Data:      json.RawMessage(event.Data),
+	})
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	return models.WebhookDelivery{EventID: event.ID, Event: event.Type, Payload: string(body)}, nil
+}
+
+// startDelivery creates ref as a pending delivery to hook and queues the first attempt.
+// It fails with AlreadyExists if ref was created before.
+func (h *Handlers) startDelivery(ref *firestore.DocumentRef, hook models.Webhook, delivery models.WebhookDelivery) (models.WebhookDelivery, error) {
+	now := time.Now()
+	delivery.WebhookID = hook.ID
+	delivery.Status = DeliveryPending
+	delivery.Attempts = []models.WebhookAttempt{}
+	delivery.CreatedAt, delivery.UpdatedAt = now, now
+	delivery.NextAttemptAt = now
+
+	if _, err := ref.Create(h.db.Context(), delivery); err != nil {
+		return delivery, err
+	}
+	delivery.ID = ref.ID
+
+	h.queueDispatch(func() { h.attemptDelivery(ref.ID) })
+	return delivery, nil
+}
+
+// dispatchDueDeliveries makes the attempts that are due, such as retries scheduled by
+// an earlier attempt or first attempts whose instance stopped, and waits for them
+func (h *Handlers) dispatchDueDeliveries(now time.Time) {
+	iter := h.db.Collection("webhook_deliveries").
+		Where("status", "==", DeliveryPending).
+		Where("nextAttemptAt", "<=", now).
+		OrderBy("nextAttemptAt", firestore.Asc).
+		Limit(outboxBatchSize).
+		Documents(h.db.Context())
+	defer iter.Stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to fetch due webhook deliveries for %s: %v", h.cfg.SubcollectionID, err)
+			return
+		}
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			h.attemptDelivery(id)
+		}(doc.Ref.ID)
+	}
+}
+
+// attemptDelivery claims a due delivery and makes one attempt. A failed attempt is
+// retried after the dispatcher's next backoff; the schedule is stored on the delivery,
+// so retries survive restarts. A delivery whose webhook was deleted fails.
+func (h *Handlers) attemptDelivery(id string) {
+	ref := h.db.Collection("webhook_deliveries").Doc(id)
+	delivery, ok, err := h.claimDelivery(ref, time.Now())
+	if err != nil {
+		log.Printf("Failed to claim webhook delivery %s: %v", id, err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	backoff := h.webhooks.Backoff
+	var attempt webhook.Attempt
+	hook, err := h.getWebhook(delivery.WebhookID)
+	switch {
+	case status.Code(err) == codes.NotFound:
+		attempt = webhook.Attempt{At: time.Now(), Error: "webhook was deleted"}
+		backoff = nil
+	case err != nil:
+		// Tried again once the lease runs out
+		log.Printf("Failed to fetch webhook for delivery %s: %v", id, err)
+		return
+	default:
+		msg := webhook.Message{ID: delivery.EventID, Event: delivery.Event, Body: []byte(delivery.Payload)}
+		attempt = h.webhooks.Send(h.db.Context(), hook.URL, hook.Secret, msg)
+	}
+
+	delivery = deliveryResult(delivery, attempt, backoff, time.Now())
+	if _, err := ref.Set(h.db.Context(), delivery); err != nil {
+		log.Printf("Failed to log webhook delivery %s: %v", id, err)
+	}
+}
+
+// claimDelivery takes the lease on a due pending delivery
+func (h *Handlers) claimDelivery(ref *firestore.DocumentRef, now time.Time) (models.WebhookDelivery, bool, error) {
+	var delivery models.WebhookDelivery
+	claimed := false
+	err := h.db.RunTransaction(func(ctx context.Context, tx *firestore.Transaction) error {
+		claimed = false
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+		if err := doc.DataTo(&delivery); err != nil {
+			return err
+		}
+		if delivery.Status != DeliveryPending || delivery.NextAttemptAt.After(now) {
+			return nil
+		}
+		delivery.NextAttemptAt = now.Add(deliveryLease)
+		claimed = true
+		return tx.Update(ref, []firestore.Update{{Path: "nextAttemptAt", Value: delivery.NextAttemptAt}})
+	})
+	delivery.ID = ref.ID
+	return delivery, claimed, err
+}
+
+// deliveryResult records an attempt: succeeded when the receiver accepted it, due again
+// after backoff[i] following the i-th failed attempt, or failed when backoff runs out
+func deliveryResult(delivery models.WebhookDelivery, attempt webhook.Attempt, backoff []time.Duration, now time.Time) models.WebhookDelivery {
+	delivery.Attempts = append(delivery.Attempts, models.WebhookAttempt{
+		At:         attempt.At,
+		StatusCode: attempt.StatusCode,
+		Error:      attempt.Error,
+		Response:   attempt.Response,
+		DurationMS: attempt.Duration.Milliseconds(),
+	})
+	delivery.UpdatedAt = now
+
+	failures := len(delivery.Attempts)
+	switch {
+	case attempt.OK():
+		delivery.Status = DeliverySucceeded
+	case failures > len(backoff):
+		delivery.Status = DeliveryFailed
+	default:
+		delivery.NextAttemptAt = now.Add(backoff[failures-1])
+	}
+	return delivery
+}
+
+// subscribedWebhooks lists the active webhooks subscribed to event
+func (h *Handlers) subscribedWebhooks(event string) ([]models.Webhook, error) {
+	var hooks []models.Webhook
+	iter := h.db.Collection("webhooks").Where("events", "array-contains", event).Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return hooks, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var hook models.Webhook
+		if err := doc.DataTo(&hook); err != nil || !hook.Active {
+			continue
+		}
+		hook.ID = doc.Ref.ID
+		hooks = append(hooks, hook)
+	}
+}
+
+func (h *Handlers) getWebhook(id string) (models.Webhook, error) {
+	var hook models.Webhook
+	doc, err := h.db.Collection("webhooks").Doc(id).Get(h.db.Context())
+	if err != nil {
+		return hook, err
+	}
+	if err := doc.DataTo(&hook); err != nil {
+		return hook, err
+	}
+	hook.ID = doc.Ref.ID
+	return hook, nil
+}
+
+// webhook validates the request beyond its binding rules. Receivers must be absolute
+// http or https URLs; repeated events are dropped.
+func (req *WebhookRequest) webhook() (models.Webhook, error) {
+	hook := models.Webhook{
+		URL:         strings.TrimSpace(req.URL),
+		Description: strings.TrimSpace(req.Description),
+		Active:      req.Active == nil || *req.Active,
+	}
+
+	u, err := url.Parse(hook.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return hook, errors.New("url must be an http or https URL")
+	}
+	if u.User != nil {
+		return hook, errors.New("url must not contain credentials")
+	}
+
+	seen := map[string]bool{}
+	for _, event := range req.Events {
+		if !validWebhookEvent(event) {
+			return hook, errors.New("unknown event " + strconv.Quote(event) + ", expected one of " + strings.Join(webhookEvents, ", "))
+		}
+		if !seen[event] {
+			seen[event] = true
+			hook.Events = append(hook.Events, event)
+		}
+	}
+	return hook, nil
+}
+
+func validWebhookEvent(event string) bool {
+	for _, e := range webhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"appdirect-workshop-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+)
+
+var hexColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// WorkshopInfo is the public view of a workshop; admins and archive state stay private
+type WorkshopInfo struct {
+	Slug string `json:"slug"`
+	models.WorkshopSettings
+	Lifecycle Lifecycle `json:"lifecycle"`
+	CFP       CFP       `json:"cfp"`
+}
+
+type UpdateWorkshopSettingsRequest struct {
+	Title       string     `json:"title" binding:"required,max=200"`
+	Tagline     string     `json:"tagline" binding:"max=300"`
+	Description string     `json:"description" binding:"max=5000"`
+	StartsAt    *time.Time `json:"startsAt"`
+	EndsAt      *time.Time `json:"endsAt"`
+	TimeZone    string     `json:"timeZone"`
+	Venue       struct {
+		Name        string `json:"name" binding:"max=200"`
+		Address     string `json:"address" binding:"max=500"`
+		MapURL      string `json:"mapUrl"`
+		MapEmbedURL string `json:"mapEmbedUrl"`
+	} `json:"venue"`
+	Branding struct {
+		LogoURL        string `json:"logoUrl"`
+		PrimaryColor   string `json:"primaryColor"`
+		SecondaryColor string `json:"secondaryColor"`
+	} `json:"branding"`
+}
+
+// GetWorkshopInfo returns the event details of the resolved workshop. The title falls
+// back to the workshop name until organizers set one.
+func (h *Handlers) GetWorkshopInfo(c *gin.Context) {
+	workshop := c.MustGet(WorkshopKey).(*models.Workshop)
+
+	info := WorkshopInfo{
+		Slug:             workshop.Slug,
+		WorkshopSettings: workshop.Settings,
+		Lifecycle:        workshopLifecycle(workshop, time.Now()),
+		CFP:              workshopCFP(workshop, time.Now()),
+	}
+	if info.Title == "" {
+		info.Title = workshop.Name
+	}
+	c.JSON(http.StatusOK, info)
+}
+
+// UpdateWorkshopSettings replaces the event details of the resolved workshop
+func (h *Handlers) UpdateWorkshopSettings(c *gin.Context) {
+	workshop := c.MustGet(WorkshopKey).(*models.Workshop)
+
+	var req UpdateWorkshopSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	settings, err := req.settings()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	settings.UpdatedAt = &now
+
+	// Set rather than update so the default workshop gets a document on first use
+	_, err = h.db.Workshops().Doc(workshop.Slug).Set(h.db.Context(), map[string]interface{}{
+		"name":     workshop.Name,
+		"settings": settings,
+	}, firestore.Merge([]string{"name"}, []string{"settings"}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update workshop settings"})
+		return
+	}
+	h.workshops.invalidate(workshop.Slug)
+
+	h.recordAudit(c, "workshop.settings.update", "workshop", workshop.Slug, workshop.Settings, settings)
+
+	updated := *workshop
+	updated.Settings = settings
+	c.JSON(http.StatusOK, WorkshopInfo{
+		Slug:             workshop.Slug,
+		WorkshopSettings: settings,
+		Lifecycle:        workshopLifecycle(&updated, time.Now()),
+	})
+}
+
+// settings validates the request beyond its binding rules. URLs must be absolute https
+// links because the landing page renders them as links, images and an iframe.
+func (req *UpdateWorkshopSettingsRequest) settings() (models.WorkshopSettings, error) {
+	settings := models.WorkshopSettings{
+		Title:       strings.TrimSpace(req.Title),
+		Tagline:     strings.TrimSpace(req.Tagline),
+		Description: strings.TrimSpace(req.Description),
+		StartsAt:    req.StartsAt,
+		EndsAt:      req.EndsAt,
+		TimeZone:    strings.TrimSpace(req.TimeZone),
+		Venue: models.WorkshopVenue{
+			Name:        strings.TrimSpace(req.Venue.Name),
+			Address:     strings.TrimSpace(req.Venue.Address),
+			MapURL:      strings.TrimSpace(req.Venue.MapURL),
+			MapEmbedURL: strings.TrimSpace(req.Venue.MapEmbedURL),
+		},
+		Branding: models.WorkshopBranding{
+			LogoURL:        strings.TrimSpace(req.Branding.LogoURL),
+			PrimaryColor:   strings.TrimSpace(req.Branding.PrimaryColor),
+			SecondaryColor: strings.TrimSpace(req.Branding.SecondaryColor),
+		},
+	}
+
+	if settings.Title == "" {
+		return settings, errors.New("title is required")
+	}
+	if settings.StartsAt != nil && settings.EndsAt != nil && settings.EndsAt.Before(*settings.StartsAt) {
+		return settings, errors.New("endsAt must not be before startsAt")
+	}
+	if settings.EndsAt != nil && settings.StartsAt == nil {
+		return settings, errors.New("startsAt is required with endsAt")
+	}
+	if settings.TimeZone != "" {
+		if _, err := time.LoadLocation(settings.TimeZone); err != nil {
+			return settings, errors.New("timeZone must be an IANA time zone such as Asia/Kolkata")
+		}
+	}
+
+	urls := []struct{ field, value string }{
+		{"venue.mapUrl", settings.Venue.MapURL},
+		{"venue.mapEmbedUrl", settings.Venue.MapEmbedURL},
+		{"branding.logoUrl", settings.Branding.LogoURL},
+	}
+	for _, f := range urls {
+		if f.value == "" {
+			continue
+		}
+		u, err := url.Parse(f.value)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return settings, errors.New(f.field + " must be an https URL")
+		}
+	}
+
+	colors := []struct{ field, value string }{
+		{"branding.primaryColor", settings.Branding.PrimaryColor},
+		{"branding.secondaryColor", settings.Branding.SecondaryColor},
+	}
+	for _, f := range colors {
+		if f.value != "" && !hexColorPattern.MatchString(f.value) {
+			return settings, errors.New(f.field + " must be a hex color such as #2563eb")
+		}
+	}
+
+	return settings, nil
+}
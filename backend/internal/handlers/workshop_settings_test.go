@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"appdirect-workshop-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetWorkshopInfo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	startsAt := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		workshop      models.Workshop
+		expectedTitle string
+	}{
+		{
+			name:          "title falls back to name",
+			workshop:      models.Workshop{Slug: "devfest", Name: "DevFest"},
+			expectedTitle: "DevFest",
+		},
+		{
+			name: "configured settings",
+			workshop: models.Workshop{
+				Slug:     "devfest",
+				Name:     "DevFest",
+				Admins:   map[string]string{"alice": "admin"},
+				Settings: models.WorkshopSettings{Title: "DevFest Pune 2025", StartsAt: &startsAt},
+			},
+			expectedTitle: "DevFest Pune 2025",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkshopTestHandlers()
+			workshop := tt.workshop
+
+			router := gin.New()
+			router.GET("/api/workshop", func(c *gin.Context) { c.Set(WorkshopKey, &workshop) }, h.GetWorkshopInfo)
+
+			req, _ := http.NewRequest("GET", "/api/workshop", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, "devfest", body["slug"])
+			assert.Equal(t, tt.expectedTitle, body["title"])
+			assert.NotContains(t, body, "admins")
+		})
+	}
+}
+
+func TestUpdateWorkshopSettingsValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		body          string
+		expectedError string
+	}{
+		{name: "missing title", body: `{"tagline":"AI day"}`, expectedError: "Title"},
+		{name: "blank title", body: `{"title":"   "}`, expectedError: "title is required"},
+		{
+			name:          "ends before it starts",
+			body:          `{"title":"AI Workshop","startsAt":"2025-03-14T09:00:00Z","endsAt":"2025-03-14T08:00:00Z"}`,
+			expectedError: "endsAt must not be before startsAt",
+		},
+		{name: "end without start", body: `{"title":"AI Workshop","endsAt":"2025-03-14T08:00:00Z"}`, expectedError: "startsAt is required with endsAt"},
+		{name: "unknown time zone", body: `{"title":"AI Workshop","timeZone":"Mars/Olympus"}`, expectedError: "timeZone must be an IANA time zone"},
+		{
+			name:          "script map embed",
+			body:          `{"title":"AI Workshop","venue":{"mapEmbedUrl":"javascript:alert(1)"}}`,
+			expectedError: "venue.mapEmbedUrl must be an https URL",
+		},
+		{
+			name:          "plain http logo",
+			body:          `{"title":"AI Workshop","branding":{"logoUrl":"http://example.com/logo.png"}}`,
+			expectedError: "branding.logoUrl must be an https URL",
+		},
+		{
+			name:          "named color",
+			body:          `{"title":"AI Workshop","branding":{"primaryColor":"blue"}}`,
+			expectedError: "branding.primaryColor must be a hex color",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkshopTestHandlers()
+			workshop := models.Workshop{Slug: "devfest", Name: "DevFest"}
+
+			router := gin.New()
+			router.PUT("/api/admin/workshop", func(c *gin.Context) { c.Set(WorkshopKey, &workshop) }, h.UpdateWorkshopSettings)
+
+			req, _ := http.NewRequest("PUT", "/api/admin/workshop", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedError)
+		})
+	}
+}
+
+func TestWorkshopSettingsFromRequest(t *testing.T) {
+	req := UpdateWorkshopSettingsRequest{Title: "  AI Workshop  ", TimeZone: "Asia/Kolkata"}
+	req.Venue.MapEmbedURL = "https://www.google.com/maps/embed?pb=abc"
+	req.Branding.PrimaryColor = "#2563EB"
+
+	settings, err := req.settings()
+	require.NoError(t, err)
+	assert.Equal(t, "AI Workshop", settings.Title)
+	assert.Equal(t, "Asia/Kolkata", settings.TimeZone)
+	assert.Equal(t, "https://www.google.com/maps/embed?pb=abc", settings.Venue.MapEmbedURL)
+	assert.Equal(t, "#2563EB", settings.Branding.PrimaryColor)
+}
@@ -0,0 +1,515 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"appdirect-workshop-backend/internal/backup"
+	"appdirect-workshop-backend/internal/middleware"
+	"appdirect-workshop-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WorkshopKey is the context key holding the *models.Workshop resolved for the request
+const WorkshopKey = "workshop"
+
+// workshopCacheTTL bounds how long another instance's workshop changes take to show up
+const workshopCacheTTL = 30 * time.Second
+
+// slugPattern applies to new workshops; existing IDs only need to be path-safe
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+var errWorkshopNotFound = errors.New("workshop not found")
+
+// scopedHandlersKey carries the workshop's Handlers in the request context so code
+// without the gin context, such as API key validation, reads the right workshop
+type scopedHandlersKey struct{}
+
+type CreateWorkshopRequest struct {
+	Slug   string            `json:"slug" binding:"required"`
+	Name   string            `json:"name" binding:"required"`
+	Admins map[string]string `json:"admins"`
+}
+
+type CloneWorkshopRequest struct {
+	Slug                 string `json:"slug" binding:"required"`
+	Name                 string `json:"name" binding:"required"`
+	IncludeRegistrations bool   `json:"includeRegistrations"`
+}
+
+type UpdateWorkshopAdminsRequest struct {
+	Admins map[string]string `json:"admins"`
+}
+
+type workshopCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedWorkshop
+}
+
+type cachedWorkshop struct {
+	workshop models.Workshop
+	expires  time.Time
+}
+
+func newWorkshopCache() *workshopCache {
+	return &workshopCache{entries: make(map[string]cachedWorkshop)}
+}
+
+func (wc *workshopCache) get(slug string) (models.Workshop, bool) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	entry, ok := wc.entries[slug]
+	if !ok || time.Now().After(entry.expires) {
+		return models.Workshop{}, false
+	}
+	return entry.workshop, true
+}
+
+func (wc *workshopCache) put(workshop models.Workshop) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	wc.entries[workshop.Slug] = cachedWorkshop{workshop: workshop, expires: time.Now().Add(workshopCacheTTL)}
+}
+
+func (wc *workshopCache) invalidate(slug string) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	delete(wc.entries, slug)
+}
+
+// forWorkshop returns handlers whose database and config point at another workshop
+func (h *Handlers) forWorkshop(slug string) *Handlers {
+	if slug == h.cfg.SubcollectionID {
+		return h
+	}
+	scoped := *h
+	cfg := *h.cfg
+	cfg.SubcollectionID = slug
+	scoped.cfg = &cfg
+	scoped.db = h.db.ForWorkshop(slug)
+	return &scoped
+}
+
+// getWorkshop loads a workshop. The deployment's default workshop (SUBSCOLLECTION_ID)
+// always exists, even before it has a workshop document of its own.
+func (h *Handlers) getWorkshop(slug string) (*models.Workshop, error) {
+	if workshop, ok := h.workshops.get(slug); ok {
+		return &workshop, nil
+	}
+
+	var workshop models.Workshop
+	doc, err := h.db.Workshops().Doc(slug).Get(h.db.Context())
+	switch {
+	case status.Code(err) == codes.NotFound && slug == h.cfg.SubcollectionID:
+		workshop = models.Workshop{Name: slug}
+	case status.Code(err) == codes.NotFound:
+		return nil, errWorkshopNotFound
+	case err != nil:
+		return nil, err
+	default:
+		if err := doc.DataTo(&workshop); err != nil {
+			return nil, err
+		}
+	}
+	workshop.Slug = slug
+
+	h.workshops.put(workshop)
+	return &workshop, nil
+}
+
+// ResolveWorkshop loads the workshop named by the :slug route parameter, or the default
+// workshop on unscoped routes, and points Scoped handlers at its data
+func (h *Handlers) ResolveWorkshop() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		slug := c.Param("slug")
+		if slug == "" {
+			slug = h.cfg.SubcollectionID
+		}
+		if !backup.ValidWorkshopID(slug) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workshop not found"})
+			c.Abort()
+			return
+		}
+
+		workshop, err := h.getWorkshop(slug)
+		if errors.Is(err, errWorkshopNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workshop not found"})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load workshop"})
+			c.Abort()
+			return
+		}
+
+		scoped := h.forWorkshop(slug)
+		c.Set(WorkshopKey, workshop)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), scopedHandlersKey{}, scoped))
+		c.Next()
+	}
+}
+
+// RequirePublicWorkshop hides archived workshops from public routes
+func (h *Handlers) RequirePublicWorkshop() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if workshop := c.MustGet(WorkshopKey).(*models.Workshop); workshop.Archived {
+			c.JSON(http.StatusGone, gin.H{"error": "This workshop has been archived"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireWorkshopAccess runs after AuthMiddleware. Super admins and API keys (which
+// belong to a single workshop) pass; other admins need an entry in the workshop's
+// admins, whose role caps the token's role. The default workshop stays open to every
+// admin until it lists admins of its own. Archived workshops are read-only.
+func (h *Handlers) RequireWorkshopAccess() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workshop := c.MustGet(WorkshopKey).(*models.Workshop)
+		role := c.GetString(middleware.RoleKey)
+
+		if role != middleware.RoleAPIKey && !h.isSuperAdmin(c) {
+			granted, listed := workshop.Admins[c.GetString(middleware.AdminIDKey)]
+			switch {
+			case listed:
+				if granted == middleware.RoleViewer {
+					role = middleware.RoleViewer
+					c.Set(middleware.RoleKey, role)
+				}
+			case workshop.Slug == h.cfg.SubcollectionID && len(workshop.Admins) == 0:
+			default:
+				c.JSON(http.StatusForbidden, gin.H{"error": "You don't have access to this workshop"})
+				c.Abort()
+				return
+			}
+		}
+
+		readOnly := c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead
+		if role == middleware.RoleViewer && !readOnly {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			c.Abort()
+			return
+		}
+		if workshop.Archived && !readOnly {
+			c.JSON(http.StatusConflict, gin.H{"error": "Workshop is archived"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Scoped adapts a handler method expression, such as (*Handlers).GetSpeakers, to run
+// against the workshop chosen by ResolveWorkshop
+func (h *Handlers) Scoped(handler func(*Handlers, *gin.Context)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handler(h.scopedFrom(c.Request.Context()), c)
+	}
+}
+
+func (h *Handlers) scopedFrom(ctx context.Context) *Handlers {
+	if scoped, ok := ctx.Value(scopedHandlersKey{}).(*Handlers); ok {
+		return scoped
+	}
+	return h
+}
+
+func (h *Handlers) isSuperAdmin(c *gin.Context) bool {
+	if c.GetString(middleware.RoleKey) != middleware.RoleAdmin {
+		return false
+	}
+	adminID := c.GetString(middleware.AdminIDKey)
+	for _, id := range h.cfg.SuperAdmins {
+		if id == adminID {
+			return true
+		}
+	}
+	return false
+}
+
+// canAccessWorkshop mirrors RequireWorkshopAccess for listing workshops
+func (h *Handlers) canAccessWorkshop(c *gin.Context, workshop models.Workshop) bool {
+	if h.isSuperAdmin(c) {
+		return true
+	}
+	if _, listed := workshop.Admins[c.GetString(middleware.AdminIDKey)]; listed {
+		return true
+	}
+	return workshop.Slug == h.cfg.SubcollectionID && len(workshop.Admins) == 0
+}
+
+func (h *Handlers) requireSuperAdmin(c *gin.Context) bool {
+	if !h.isSuperAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only super admins can manage workshops"})
+		return false
+	}
+	return true
+}
+
+// GetWorkshops lists the workshops the caller can access, default workshop first
+func (h *Handlers) GetWorkshops(c *gin.Context) {
+	workshops := make([]models.Workshop, 0)
+	hasDefault := false
+
+	iter := h.db.Workshops().Documents(h.db.Context())
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workshops"})
+			return
+		}
+
+		var workshop models.Workshop
+		if err := doc.DataTo(&workshop); err != nil {
+			continue
+		}
+		workshop.Slug = doc.Ref.ID
+		hasDefault = hasDefault || workshop.Slug == h.cfg.SubcollectionID
+		if h.canAccessWorkshop(c, workshop) {
+			workshops = append(workshops, workshop)
+		}
+	}
+
+	// The default workshop's data predates workshop documents
+	if !hasDefault {
+		workshops = append(workshops, models.Workshop{Slug: h.cfg.SubcollectionID, Name: h.cfg.SubcollectionID})
+	}
+
+	sort.SliceStable(workshops, func(i, j int) bool {
+		return workshops[i].Slug == h.cfg.SubcollectionID && workshops[j].Slug != h.cfg.SubcollectionID
+	})
+	c.JSON(http.StatusOK, workshops)
+}
+
+func (h *Handlers) CreateWorkshop(c *gin.Context) {
+	if !h.requireSuperAdmin(c) {
+		return
+	}
+
+	var req CreateWorkshopRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !slugPattern.MatchString(req.Slug) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "slug must be 2-63 lowercase letters, digits or dashes"})
+		return
+	}
+	if err := validateWorkshopAdmins(req.Admins); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workshop := models.Workshop{
+		Slug:      req.Slug,
+		Name:      strings.TrimSpace(req.Name),
+		Admins:    req.Admins,
+		CreatedAt: time.Now(),
+		Status:    StateDraft,
+	}
+	if _, err := h.db.Workshops().Doc(req.Slug).Create(h.db.Context(), workshop); err != nil {
+		if status.Code(err) == codes.AlreadyExists {
+			c.JSON(http.StatusConflict, gin.H{"error": "A workshop with this slug already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create workshop"})
+		return
+	}
+
+	h.workshops.invalidate(req.Slug)
+	h.forWorkshop(req.Slug).recordAudit(c, "workshop.create", "workshop", req.Slug, nil, workshop)
+	c.JSON(http.StatusCreated, workshop)
+}
+
+// CloneWorkshop creates a workshop with a copy of another one's speakers and sessions
+// (and registrations if asked), keeping document IDs and workshop admins
+func (h *Handlers) CloneWorkshop(c *gin.Context) {
+	if !h.requireSuperAdmin(c) {
+		return
+	}
+
+	var req CloneWorkshopRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !slugPattern.MatchString(req.Slug) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "slug must be 2-63 lowercase letters, digits or dashes"})
+		return
+	}
+
+	sourceSlug := c.Param("slug")
+	source, err := h.getWorkshop(sourceSlug)
+	if errors.Is(err, errWorkshopNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workshop not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load workshop"})
+		return
+	}
+
+	archive, err := backup.Export(h.forWorkshop(sourceSlug).db, sourceSlug)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read workshop data"})
+		return
+	}
+	if !req.IncludeRegistrations {
+		archive.Registrations = nil
+	}
+
+	// Venue and branding carry over; the new edition gets its own dates
+	settings := source.Settings
+	settings.StartsAt, settings.EndsAt, settings.UpdatedAt = nil, nil, nil
+
+	workshop := models.Workshop{
+		Slug:       req.Slug,
+		Name:       strings.TrimSpace(req.Name),
+		Admins:     source.Admins,
+		ClonedFrom: sourceSlug,
+		CreatedAt:  time.Now(),
+		Settings:   settings,
+		Status:     StateDraft,
+	}
+	if _, err := h.db.Workshops().Doc(req.Slug).Create(h.db.Context(), workshop); err != nil {
+		if status.Code(err) == codes.AlreadyExists {
+			c.JSON(http.StatusConflict, gin.H{"error": "A workshop with this slug already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create workshop"})
+		return
+	}
+	h.workshops.invalidate(req.Slug)
+
+	target := h.forWorkshop(req.Slug)
+	result, err := backup.Restore(target.db, req.Slug, archive, backup.ConflictFail)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Workshop created but copying its data failed", "workshop": workshop})
+		return
+	}
+
+	target.recordAudit(c, "workshop.clone", "workshop", req.Slug, nil, workshop)
+	c.JSON(http.StatusCreated, gin.H{"workshop": workshop, "copied": result.Collections})
+}
+
+func (h *Handlers) ArchiveWorkshop(c *gin.Context) {
+	h.setWorkshopArchived(c, true)
+}
+
+func (h *Handlers) UnarchiveWorkshop(c *gin.Context) {
+	h.setWorkshopArchived(c, false)
+}
+
+func (h *Handlers) setWorkshopArchived(c *gin.Context, archived bool) {
+	if !h.requireSuperAdmin(c) {
+		return
+	}
+
+	slug := c.Param("slug")
+	if slug == h.cfg.SubcollectionID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The default workshop cannot be archived"})
+		return
+	}
+
+	updates := []firestore.Update{{Path: "archived", Value: archived}}
+	if archived {
+		updates = append(updates, firestore.Update{Path: "archivedAt", Value: time.Now()})
+	} else {
+		updates = append(updates, firestore.Update{Path: "archivedAt", Value: firestore.Delete})
+	}
+	if _, err := h.db.Workshops().Doc(slug).Update(h.db.Context(), updates); err != nil {
+		if status.Code(err) == codes.NotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workshop not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update workshop"})
+		return
+	}
+	h.workshops.invalidate(slug)
+
+	action := "workshop.unarchive"
+	if archived {
+		action = "workshop.archive"
+	}
+	h.forWorkshop(slug).recordAudit(c, action, "workshop", slug, gin.H{"archived": !archived}, gin.H{"archived": archived})
+	c.JSON(http.StatusOK, gin.H{"message": "Workshop updated successfully", "archived": archived})
+}
+
+// UpdateWorkshopAdmins replaces the admins allowed into a workshop and their roles
+func (h *Handlers) UpdateWorkshopAdmins(c *gin.Context) {
+	if !h.requireSuperAdmin(c) {
+		return
+	}
+
+	var req UpdateWorkshopAdminsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateWorkshopAdmins(req.Admins); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	slug := c.Param("slug")
+	if !backup.ValidWorkshopID(slug) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workshop not found"})
+		return
+	}
+	before, err := h.getWorkshop(slug)
+	if errors.Is(err, errWorkshopNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workshop not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load workshop"})
+		return
+	}
+
+	// Set rather than update so the default workshop gets a document on first use;
+	// merging on the admins path replaces the whole map
+	_, err = h.db.Workshops().Doc(slug).Set(h.db.Context(), map[string]interface{}{
+		"name":   before.Name,
+		"admins": req.Admins,
+	}, firestore.Merge([]string{"name"}, []string{"admins"}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update workshop admins"})
+		return
+	}
+	h.workshops.invalidate(slug)
+
+	h.forWorkshop(slug).recordAudit(c, "workshop.admins.update", "workshop", slug,
+		gin.H{"admins": before.Admins}, gin.H{"admins": req.Admins})
+	c.JSON(http.StatusOK, gin.H{"slug": slug, "admins": req.Admins})
+}
+
+func validateWorkshopAdmins(admins map[string]string) error {
+	for adminID, role := range admins {
+		if strings.TrimSpace(adminID) == "" {
+			return errors.New("admin IDs must not be empty")
+		}
+		if role != middleware.RoleAdmin && role != middleware.RoleViewer {
+			return errors.New("admin roles must be admin or viewer")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"appdirect-workshop-backend/internal/config"
+	"appdirect-workshop-backend/internal/database"
+	"appdirect-workshop-backend/internal/middleware"
+	"appdirect-workshop-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newWorkshopTestHandlers() *Handlers {
+	mockDB := &database.MockFirestoreClient{}
+	mockDB.ForWorkshopFunc = func(id string) database.DatabaseInterface {
+		return &database.MockFirestoreClient{}
+	}
+	return New(mockDB, &config.Config{
+		AdminPassword:    "test-password",
+		AdminTokenSecret: "test-token-secret",
+		SubcollectionID:  "default-workshop",
+		SuperAdmins:      []string{"root"},
+	})
+}
+
+// asAdmin stands in for AuthMiddleware
+func asAdmin(adminID, role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(middleware.AdminIDKey, adminID)
+		c.Set(middleware.RoleKey, role)
+		c.Next()
+	}
+}
+
+func TestRequireWorkshopAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	restricted := models.Workshop{Slug: "devfest", Admins: map[string]string{"alice": "admin", "bob": "viewer"}}
+
+	tests := []struct {
+		name           string
+		workshop       models.Workshop
+		adminID        string
+		role           string
+		method         string
+		expectedStatus int
+	}{
+		{name: "super admin", workshop: restricted, adminID: "root", role: "admin", method: "POST", expectedStatus: http.StatusOK},
+		{name: "super admin id with viewer token", workshop: restricted, adminID: "root", role: "viewer", method: "GET", expectedStatus: http.StatusForbidden},
+		{name: "listed admin", workshop: restricted, adminID: "alice", role: "admin", method: "POST", expectedStatus: http.StatusOK},
+		{name: "listed viewer reads", workshop: restricted, adminID: "bob", role: "admin", method: "GET", expectedStatus: http.StatusOK},
+		{name: "listed viewer cannot write", workshop: restricted, adminID: "bob", role: "admin", method: "POST", expectedStatus: http.StatusForbidden},
+		{name: "unlisted admin", workshop: restricted, adminID: "mallory", role: "admin", method: "GET", expectedStatus: http.StatusForbidden},
+		{name: "api key", workshop: restricted, adminID: "apikey:k1", role: middleware.RoleAPIKey, method: "POST", expectedStatus: http.StatusOK},
+		{name: "open default workshop", workshop: models.Workshop{Slug: "default-workshop"}, adminID: "mallory", role: "admin", method: "POST", expectedStatus: http.StatusOK},
+		{
+			name:           "default workshop with admins",
+			workshop:       models.Workshop{Slug: "default-workshop", Admins: map[string]string{"alice": "admin"}},
+			adminID:        "mallory",
+			role:           "admin",
+			method:         "GET",
+			expectedStatus: http.StatusForbidden,
+		},
+		{name: "archived workshop reads", workshop: models.Workshop{Slug: "old", Archived: true}, adminID: "root", role: "admin", method: "GET", expectedStatus: http.StatusOK},
+		{name: "archived workshop writes", workshop: models.Workshop{Slug: "old", Archived: true}, adminID: "root", role: "admin", method: "POST", expectedStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkshopTestHandlers()
+			workshop := tt.workshop
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) { c.Set(WorkshopKey, &workshop) }, asAdmin(tt.adminID, tt.role), h.RequireWorkshopAccess())
+			router.Handle(tt.method, "/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req, _ := http.NewRequest(tt.method, "/test", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestWorkshopAccessNeedsVerifiedIdentity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := newWorkshopTestHandlers()
+	h.cfg.OIDCTokenSecret = "sso-secret"
+	workshop := models.Workshop{Slug: "devfest", Admins: map[string]string{"alice@example.com": "admin"}}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set(WorkshopKey, &workshop) },
+		middleware.AuthMiddleware(h.cfg.AdminTokenSecret, h.cfg.OIDCTokenSecret), h.RequireWorkshopAccess())
+	router.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// A shared-password token naming a listed admin is still just the password admin
+	forged, err := h.issueAdminToken("alice@example.com", middleware.RoleAdmin)
+	assert.NoError(t, err)
+	verified, err := h.issueSSOToken("alice@example.com", middleware.RoleAdmin)
+	assert.NoError(t, err)
+
+	for token, expectedStatus := range map[string]int{forged: http.StatusForbidden, verified: http.StatusOK} {
+		req, _ := http.NewRequest("GET", "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, expectedStatus, w.Code)
+	}
+}
+
+func TestResolveWorkshop(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := newWorkshopTestHandlers()
+	h.workshops.put(models.Workshop{Slug: "devfest", Name: "DevFest"})
+	h.workshops.put(models.Workshop{Slug: "old", Archived: true})
+	h.workshops.put(models.Workshop{Slug: "default-workshop"})
+
+	router := gin.New()
+	scopedSlug := func(scoped *Handlers, c *gin.Context) {
+		c.String(http.StatusOK, scoped.cfg.SubcollectionID)
+	}
+	router.GET("/api/speakers", h.ResolveWorkshop(), h.RequirePublicWorkshop(), h.Scoped(scopedSlug))
+	router.GET("/api/w/:slug/speakers", h.ResolveWorkshop(), h.RequirePublicWorkshop(), h.Scoped(scopedSlug))
+
+	tests := []struct {
+		path           string
+		expectedStatus int
+		expectedSlug   string
+	}{
+		{path: "/api/speakers", expectedStatus: http.StatusOK, expectedSlug: "default-workshop"},
+		{path: "/api/w/devfest/speakers", expectedStatus: http.StatusOK, expectedSlug: "devfest"},
+		{path: "/api/w/old/speakers", expectedStatus: http.StatusGone},
+		{path: "/api/w/bad.slug/speakers", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedSlug != "" {
+				assert.Equal(t, tt.expectedSlug, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateWorkshopValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		adminID        string
+		body           string
+		expectedStatus int
+	}{
+		{name: "not a super admin", adminID: "alice", body: `{"slug":"devfest","name":"DevFest"}`, expectedStatus: http.StatusForbidden},
+		{name: "missing name", adminID: "root", body: `{"slug":"devfest"}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid slug", adminID: "root", body: `{"slug":"Dev Fest","name":"DevFest"}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid admin role", adminID: "root", body: `{"slug":"devfest","name":"DevFest","admins":{"alice":"owner"}}`, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkshopTestHandlers()
+			router := gin.New()
+			router.POST("/api/admin/workshops", asAdmin(tt.adminID, "admin"), h.CreateWorkshop)
+
+			req, _ := http.NewRequest("POST", "/api/admin/workshops", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestArchiveDefaultWorkshop(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := newWorkshopTestHandlers()
+	router := gin.New()
+	router.POST("/api/admin/workshops/:slug/archive", asAdmin("root", "admin"), h.ArchiveWorkshop)
+
+	req, _ := http.NewRequest("POST", "/api/admin/workshops/default-workshop/archive", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,152 @@
+// Package images validates uploaded images and converts them to the sizes and
+// formats the site serves: a JPEG (or PNG, for images with transparency) fallback
+// and a WebP version of each size.
+package images
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"appdirect-workshop-backend/internal/webp"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// MaxBytes is the largest upload accepted
+	MaxBytes = 5 << 20
+	// maxDimension bounds the decoded size, so a small file can't expand into a huge
+	// bitmap
+	maxDimension = 6000
+	minDimension = 32
+	jpegQuality  = 85
+)
+
+var (
+	ErrUnsupported = errors.New("image must be a JPEG, PNG, GIF or WebP")
+	ErrDimensions  = fmt.Errorf("image must be between %d and %d pixels wide and high", minDimension, maxDimension)
+)
+
+// supportedFormats are the decoders the package accepts, by the names image registers
+var supportedFormats = map[string]bool{"jpeg": true, "png": true, "gif": true, "webp": true}
+
+// Size is a variant's bounding box; images are scaled down to fit it, never up
+type Size struct {
+	Name string
+	Max  int
+}
+
+// Sizes are the variants generated for every upload
+var Sizes = []Size{
+	{Name: "large", Max: 800},
+	{Name: "thumb", Max: 200},
+}
+
+// Variant is an encoded image in one size and format
+type Variant struct {
+	Size        string
+	Format      string
+	Width       int
+	Height      int
+	ContentType string
+	Data        []byte
+}
+
+// Extension is the file extension for the variant's format
+func (v Variant) Extension() string {
+	if v.Format == "jpeg" {
+		return ".jpg"
+	}
+	return "." + v.Format
+}
+
+// Process decodes an upload and returns its variants, the fallback format before
+// WebP for each size. Animated GIFs keep their first frame.
+func Process(data []byte) ([]Variant, error) {
+	if len(data) > MaxBytes {
+		return nil, fmt.Errorf("image must be %d MB or smaller", MaxBytes>>20)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || !supportedFormats[format] {
+		return nil, ErrUnsupported
+	}
+	if cfg.Width < minDimension || cfg.Height < minDimension || cfg.Width > maxDimension || cfg.Height > maxDimension {
+		return nil, ErrDimensions
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("image could not be decoded: %w", err)
+	}
+
+	var variants []Variant
+	for _, size := range Sizes {
+		img := resize(src, size.Max)
+		fallback, err := encodeFallback(img)
+		if err != nil {
+			return nil, err
+		}
+		fallback.Size = size.Name
+		var buf bytes.Buffer
+		if err := webp.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+		b := img.Bounds()
+		variants = append(variants, fallback, Variant{
+			Size:        size.Name,
+			Format:      "webp",
+			Width:       b.Dx(),
+			Height:      b.Dy(),
+			ContentType: "image/webp",
+			Data:        buf.Bytes(),
+		})
+	}
+	return variants, nil
+}
+
+// resize scales src to fit within limit x limit, keeping its aspect ratio
+func resize(src image.Image, limit int) *image.NRGBA {
+	b := src.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width > limit || height > limit {
+		if width >= height {
+			width, height = limit, max(1, height*limit/width)
+		} else {
+			width, height = max(1, width*limit/height), limit
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	if width == b.Dx() && height == b.Dy() {
+		draw.Draw(dst, dst.Rect, src, b.Min, draw.Src)
+	} else {
+		draw.CatmullRom.Scale(dst, dst.Rect, src, b, draw.Src, nil)
+	}
+	return dst
+}
+
+// encodeFallback encodes img for browsers without WebP: JPEG, unless it has
+// transparency JPEG can't keep
+func encodeFallback(img *image.NRGBA) (Variant, error) {
+	var buf bytes.Buffer
+	b := img.Bounds()
+	v := Variant{Width: b.Dx(), Height: b.Dy()}
+	if img.Opaque() {
+		v.Format, v.ContentType = "jpeg", "image/jpeg"
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return Variant{}, err
+		}
+	} else {
+		v.Format, v.ContentType = "png", "image/png"
+		if err := png.Encode(&buf, img); err != nil {
+			return Variant{}, err
+		}
+	}
+	v.Data = buf.Bytes()
+	return v, nil
+}
@@ -0,0 +1,78 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+)
+
+func encodePNG(t *testing.T, width, height int, alpha uint8) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x), uint8(y), 128, alpha})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestProcess(t *testing.T) {
+	variants, err := Process(encodePNG(t, 1000, 500, 0xff))
+	require.NoError(t, err)
+
+	type summary struct {
+		size, format, contentType string
+		width, height             int
+	}
+	var got []summary
+	for _, v := range variants {
+		got = append(got, summary{v.Size, v.Format, v.ContentType, v.Width, v.Height})
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(v.Data))
+		require.NoError(t, err)
+		assert.Equal(t, v.Format, format)
+		assert.Equal(t, v.Width, cfg.Width)
+		assert.Equal(t, v.Height, cfg.Height)
+	}
+	assert.Equal(t, []summary{
+		{"large", "jpeg", "image/jpeg", 800, 400},
+		{"large", "webp", "image/webp", 800, 400},
+		{"thumb", "jpeg", "image/jpeg", 200, 100},
+		{"thumb", "webp", "image/webp", 200, 100},
+	}, got)
+
+	_, err = webp.Decode(bytes.NewReader(variants[3].Data))
+	assert.NoError(t, err)
+}
+
+func TestProcessKeepsTransparencyAndSmallImages(t *testing.T) {
+	variants, err := Process(encodePNG(t, 120, 300, 0x80))
+	require.NoError(t, err)
+	require.Len(t, variants, 4)
+
+	// Portrait images fit the box by height; nothing is scaled up
+	assert.Equal(t, "png", variants[0].Format)
+	assert.Equal(t, [2]int{120, 300}, [2]int{variants[0].Width, variants[0].Height})
+	assert.Equal(t, [2]int{80, 200}, [2]int{variants[2].Width, variants[2].Height})
+	assert.Equal(t, ".png", variants[0].Extension())
+	assert.Equal(t, ".webp", variants[1].Extension())
+}
+
+func TestProcessRejects(t *testing.T) {
+	assert.ErrorIs(t, must(Process([]byte("not an image"))), ErrUnsupported)
+	assert.ErrorIs(t, must(Process([]byte("<svg xmlns='http://www.w3.org/2000/svg'/>"))), ErrUnsupported)
+	assert.ErrorIs(t, must(Process(encodePNG(t, 20, 400, 0xff))), ErrDimensions)
+	assert.ErrorIs(t, must(Process(encodePNG(t, 6001, 40, 0xff))), ErrDimensions)
+	assert.Error(t, must(Process(make([]byte, MaxBytes+1))))
+}
+
+func must(_ []Variant, err error) error {
+	return err
+}
@@ -0,0 +1,174 @@
+// Package live fans events published by handlers out to connected clients, such as
+// Server-Sent Events streams. Events are held in memory, so subscribers only see events
+// published on the same server instance.
+package live
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// subscriberBuffer is how many undelivered events a subscriber may fall behind by
+	subscriberBuffer = 32
+	// historySize is how many recent events per topic are kept for resuming
+	historySize = 256
+)
+
+// Event is a typed message on a topic. IDs have the form "<epoch>-<sequence>": the
+// sequence increases across all topics of a hub, and the epoch changes whenever a hub
+// is created, so IDs from a restarted server or another instance are never mistaken
+// for this hub's.
+type Event struct {
+	ID   string
+	Type string
+	Data interface{}
+}
+
+type Hub struct {
+	mu     sync.Mutex
+	epoch  string
+	seq    uint64
+	topics map[string]*topic
+}
+
+type topic struct {
+	subscribers map[chan Event]struct{}
+	history     []entry
+	// evicted is the sequence of the newest event dropped from history
+	evicted uint64
+}
+
+type entry struct {
+	seq   uint64
+	event Event
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		epoch:  strconv.FormatInt(time.Now().UnixNano(), 36),
+		topics: make(map[string]*topic),
+	}
+}
+
+// Publish delivers an event to the topic's current subscribers and keeps it for
+// resuming. A subscriber whose buffer is full is dropped and its channel closed rather
+// than blocking the publisher; clients reconnect and resume. Publishing on a nil hub is
+// a no-op.
+func (h *Hub) Publish(topicName, eventType string, data interface{}) Event {
+	if h == nil {
+		return Event{}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	event := Event{ID: fmt.Sprintf("%s-%d", h.epoch, h.seq), Type: eventType, Data: data}
+
+	t := h.topic(topicName)
+	t.history = append(t.history, entry{seq: h.seq, event: event})
+	if len(t.history) > historySize {
+		t.evicted = t.history[0].seq
+		t.history = t.history[1:]
+	}
+
+	for ch := range t.subscribers {
+		select {
+		case ch <- event:
+		default:
+			t.remove(ch)
+		}
+	}
+	return event
+}
+
+// Subscribe returns the topic's events from now on and a function that unsubscribes.
+// The channel is closed when unsubscribed or dropped for falling behind.
+func (h *Hub) Subscribe(topicName string) (<-chan Event, func()) {
+	events, unsubscribe, _ := h.Resume(topicName, "")
+	return events, unsubscribe
+}
+
+// Resume is Subscribe for a client that last saw lastEventID: the events it missed are
+// delivered first. It reports false when they can't all be replayed, because the ID is
+// from another hub or too old; the client should then reload its state. An empty
+// lastEventID subscribes without replay.
+func (h *Hub) Resume(topicName, lastEventID string) (<-chan Event, func(), bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t := h.topic(topicName)
+	var missed []Event
+	complete := true
+	if lastEventID != "" {
+		seq, ok := h.sequence(lastEventID)
+		complete = ok && seq >= t.evicted
+		for _, e := range t.history {
+			if e.seq > seq {
+				missed = append(missed, e.event)
+			}
+		}
+	}
+
+	ch := make(chan Event, subscriberBuffer+len(missed))
+	for _, event := range missed {
+		ch <- event
+	}
+	t.subscribers[ch] = struct{}{}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			t.remove(ch)
+		})
+	}, complete
+}
+
+// Subscribers reports how many subscribers a topic has
+func (h *Hub) Subscribers(topicName string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if t, ok := h.topics[topicName]; ok {
+		return len(t.subscribers)
+	}
+	return 0
+}
+
+// sequence parses an event ID issued by this hub
+func (h *Hub) sequence(id string) (uint64, bool) {
+	epoch, seq, ok := strings.Cut(id, "-")
+	if !ok || epoch != h.epoch {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil || n > h.seq {
+		return 0, false
+	}
+	return n, true
+}
+
+// topic must be called with h.mu held. Topics are kept once created, along with their
+// history.
+func (h *Hub) topic(name string) *topic {
+	t, ok := h.topics[name]
+	if !ok {
+		t = &topic{subscribers: make(map[chan Event]struct{})}
+		h.topics[name] = t
+	}
+	return t
+}
+
+// remove must be called with the hub's lock held. Removing an already dropped channel
+// is a no-op.
+func (t *topic) remove(ch chan Event) {
+	if _, ok := t.subscribers[ch]; !ok {
+		return
+	}
+	delete(t.subscribers, ch)
+	close(ch)
+}
@@ -0,0 +1,126 @@
+package live
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishReachesTopicSubscribers(t *testing.T) {
+	hub := NewHub()
+	a, unsubscribeA := hub.Subscribe("session/1")
+	defer unsubscribeA()
+	b, unsubscribeB := hub.Subscribe("session/2")
+	defer unsubscribeB()
+
+	first := hub.Publish("session/1", "question.updated", "q1")
+	second := hub.Publish("session/2", "question.updated", "q2")
+
+	assert.Equal(t, Event{ID: hub.epoch + "-1", Type: "question.updated", Data: "q1"}, <-a)
+	assert.Equal(t, Event{ID: hub.epoch + "-2", Type: "question.updated", Data: "q2"}, <-b)
+	assert.Equal(t, first, Event{ID: hub.epoch + "-1", Type: "question.updated", Data: "q1"})
+	assert.Equal(t, hub.epoch+"-2", second.ID)
+	assert.Empty(t, a)
+}
+
+func TestResumeReplaysMissedEvents(t *testing.T) {
+	hub := NewHub()
+	first := hub.Publish("topic", "tick", 1)
+	hub.Publish("other", "tick", "elsewhere")
+	hub.Publish("topic", "tick", 2)
+	hub.Publish("topic", "tick", 3)
+
+	events, unsubscribe, complete := hub.Resume("topic", first.ID)
+	defer unsubscribe()
+	require.True(t, complete)
+
+	hub.Publish("topic", "tick", 4)
+	var received []interface{}
+	for i := 0; i < 3; i++ {
+		received = append(received, (<-events).Data)
+	}
+	assert.Equal(t, []interface{}{2, 3, 4}, received)
+	assert.Empty(t, events)
+}
+
+func TestResumeReportsUnavailableEvents(t *testing.T) {
+	hub := NewHub()
+	first := hub.Publish("topic", "tick", 0)
+	latest := first
+
+	tests := []struct {
+		name        string
+		lastEventID func() string
+		complete    bool
+	}{
+		{"no last event", func() string { return "" }, true},
+		{"latest event", func() string { return latest.ID }, true},
+		{"another hub", func() string { return "other-1" }, false},
+		{"malformed", func() string { return hub.epoch + "-x" }, false},
+		{"not issued yet", func() string { return hub.epoch + "-99999" }, false},
+		{"evicted from history", func() string { return first.ID }, false},
+	}
+
+	for i := 1; i <= historySize+1; i++ {
+		latest = hub.Publish("topic", "tick", i)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, unsubscribe, complete := hub.Resume("topic", tt.lastEventID())
+			defer unsubscribe()
+			assert.Equal(t, tt.complete, complete)
+		})
+	}
+}
+
+func TestResumeIncompleteReplaysHistory(t *testing.T) {
+	hub := NewHub()
+	for i := 1; i <= historySize+2; i++ {
+		hub.Publish("topic", "tick", i)
+	}
+
+	// Event 2 was evicted, so a client that last saw event 1 can't be caught up fully
+	events, unsubscribe, complete := hub.Resume("topic", hub.epoch+"-1")
+	defer unsubscribe()
+	assert.False(t, complete)
+	assert.Len(t, events, historySize)
+	assert.Equal(t, 3, (<-events).Data)
+}
+
+func TestUnsubscribeClosesChannel(t *testing.T) {
+	hub := NewHub()
+	events, unsubscribe := hub.Subscribe("topic")
+	assert.Equal(t, 1, hub.Subscribers("topic"))
+
+	unsubscribe()
+	unsubscribe()
+
+	_, open := <-events
+	assert.False(t, open)
+	assert.Equal(t, 0, hub.Subscribers("topic"))
+	hub.Publish("topic", "ignored", nil)
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	hub := NewHub()
+	events, unsubscribe := hub.Subscribe("topic")
+	defer unsubscribe()
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		hub.Publish("topic", "tick", i)
+	}
+
+	received := 0
+	for range events {
+		received++
+	}
+	require.Equal(t, subscriberBuffer, received)
+	assert.Equal(t, 0, hub.Subscribers("topic"))
+}
+
+func TestNilHubPublishIsNoop(t *testing.T) {
+	var hub *Hub
+	assert.Equal(t, Event{}, hub.Publish("topic", "tick", nil))
+}
@@ -0,0 +1,166 @@
+// Package mail sends plain-text email, such as announcements, to registrants.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dialTimeout = 10 * time.Second
+	// sendTimeout bounds one whole SMTP conversation
+	sendTimeout = time.Minute
+)
+
+// Message is a plain-text email to a single recipient
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+	// ID, when set, becomes the Message-ID, so a message sent twice can be recognized
+	// as the same one. It may only contain letters, digits, '.', '-' and '_'.
+	ID string
+}
+
+// Sender delivers messages
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// SMTP sends through an SMTP relay, upgrading to TLS with STARTTLS when the server
+// offers it. Credentials are optional for relays that authenticate by network.
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// From is an address such as "Workshop <noreply@example.com>"
+	From string
+}
+
+func NewSMTP(host string, port int, username, password, from string) *SMTP {
+	return &SMTP{Host: host, Port: port, Username: username, Password: password, From: from}
+}
+
+// Send implements Sender
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	from, err := netmail.ParseAddress(s.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %v", err)
+	}
+	data, err := compose(from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	dialer := net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(s.Host, strconv.Itoa(s.Port)))
+	if err != nil {
+		return err
+	}
+	// smtp.SendMail has no timeouts, so a stalled relay would hold up a whole job
+	conn.SetDeadline(time.Now().Add(sendTimeout))
+
+	client, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.Host}); err != nil {
+			return err
+		}
+	}
+	if s.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// compose renders the message with its headers. The recipient must be a bare address
+// and the subject a single line, so neither can inject headers.
+func compose(from *netmail.Address, msg Message, date time.Time) ([]byte, error) {
+	to, err := netmail.ParseAddress(msg.To)
+	if err != nil || to.Address != msg.To {
+		return nil, fmt.Errorf("invalid recipient address %q", msg.To)
+	}
+	if strings.ContainsAny(msg.Subject, "\r\n") {
+		return nil, errors.New("subject must be a single line")
+	}
+	if strings.IndexFunc(msg.ID, invalidIDRune) >= 0 {
+		return nil, fmt.Errorf("invalid message ID %q", msg.ID)
+	}
+
+	var buf bytes.Buffer
+	headers := [][2]string{
+		{"From", from.String()},
+		{"To", to.Address},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Subject)},
+		{"Date", date.Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "text/plain; charset=UTF-8"},
+		{"Content-Transfer-Encoding", "quoted-printable"},
+	}
+	if msg.ID != "" {
+		domain := from.Address[strings.LastIndex(from.Address, "@")+1:]
+		headers = append(headers, [2]string{"Message-ID", "<" + msg.ID + "@" + domain + ">"})
+	}
+	for _, header := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", header[0], header[1])
+	}
+	buf.WriteString("\r\n")
+
+	body := strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n")
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write([]byte(body)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func invalidIDRune(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	case r == '.', r == '-', r == '_':
+		return false
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package mail
+
+import (
+	netmail "net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompose(t *testing.T) {
+	from := &netmail.Address{Name: "AI Workshop", Address: "noreply@example.com"}
+	date := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	data, err := compose(from, Message{
+		To:      "ada@example.com",
+		Subject: "Room change — Hall B",
+		Body:    "The keynote moves to Hall B.\nSee you there!",
+	}, date)
+	require.NoError(t, err)
+
+	headers, body, ok := strings.Cut(string(data), "\r\n\r\n")
+	require.True(t, ok)
+	assert.Contains(t, headers, "From: \"AI Workshop\" <noreply@example.com>\r\n")
+	assert.Contains(t, headers, "To: ada@example.com\r\n")
+	assert.Contains(t, headers, "Subject: =?utf-8?q?Room_change_=E2=80=94_Hall_B?=\r\n")
+	assert.Contains(t, headers, "Date: Sun, 01 Mar 2026 10:00:00 +0000\r\n")
+	assert.NotContains(t, headers, "Message-ID")
+	assert.Equal(t, "The keynote moves to Hall B.\r\nSee you there!", body)
+}
+
+func TestComposeMessageID(t *testing.T) {
+	from := &netmail.Address{Address: "noreply@example.com"}
+
+	data, err := compose(from, Message{To: "ada@example.com", Subject: "Hi", ID: "evt_abc-1.mail"}, time.Now())
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Message-ID: <evt_abc-1.mail@example.com>\r\n")
+}
+
+func TestComposeRejectsHeaderInjection(t *testing.T) {
+	from := &netmail.Address{Address: "noreply@example.com"}
+
+	tests := []struct {
+		name string
+		msg  Message
+	}{
+		{"recipient with extra header", Message{To: "ada@example.com\r\nBcc: eve@example.com", Subject: "Hi"}},
+		{"recipient with display name", Message{To: "Ada <ada@example.com>", Subject: "Hi"}},
+		{"invalid recipient", Message{To: "not-an-address", Subject: "Hi"}},
+		{"multi-line subject", Message{To: "ada@example.com", Subject: "Hi\r\nBcc: eve@example.com"}},
+		{"message ID with extra header", Message{To: "ada@example.com", Subject: "Hi", ID: "evt_1>\r\nBcc: eve@example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compose(from, tt.msg, time.Now())
+			assert.Error(t, err)
+		})
+	}
+}
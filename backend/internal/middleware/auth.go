@@ -1,14 +1,40 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func AuthMiddleware(adminPassword string) gin.HandlerFunc {
+const (
+	// AdminIDKey is the context key holding the authenticated admin's ID
+	AdminIDKey = "adminID"
+	// RoleKey is the context key holding the authenticated admin's role
+	RoleKey = "role"
+)
+
+// Admin roles carried in the token's "role" claim
+const (
+	RoleAdmin  = "admin"
+	RoleViewer = "viewer"
+)
+
+// DefaultAdminID is the identity of every shared-password session. The password
+// doesn't say who is logging in, so only SSO tokens carry a per-person identity.
+const DefaultAdminID = "admin"
+
+// AuthMiddleware accepts admin JWTs signed with tokenSecret and, when a validator is
+// given, scoped API keys. Tokens with the "sso" claim are verified with ssoSecret;
+// when it is empty SSO tokens are rejected.
+func AuthMiddleware(tokenSecret, ssoSecret string, apiKeys ...APIKeyValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if rawKey := c.GetHeader(APIKeyHeader); rawKey != "" && len(apiKeys) > 0 {
+			authenticateAPIKey(c, apiKeys[0], rawKey)
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
@@ -21,9 +47,16 @@ func AuthMiddleware(adminPassword string) gin.HandlerFunc {
 			tokenString = authHeader[7:]
 		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return []byte(adminPassword), nil
-		})
+		claims := jwt.MapClaims{}
+		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			if isSSOToken(claims) {
+				if ssoSecret == "" {
+					return nil, errors.New("single sign-on is not configured")
+				}
+				return []byte(ssoSecret), nil
+			}
+			return []byte(tokenSecret), nil
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 		if err != nil || !token.Valid {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
@@ -31,7 +64,66 @@ func AuthMiddleware(adminPassword string) gin.HandlerFunc {
 			return
 		}
 
+		// Tokens issued mid-login (e.g. awaiting a second factor) are not admin tokens
+		if admin, _ := claims["admin"].(bool); !admin {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			c.Abort()
+			return
+		}
+
+		adminID := DefaultAdminID
+		if isSSOToken(claims) {
+			adminID, _ = claims.GetSubject()
+			if adminID == "" {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+				c.Abort()
+				return
+			}
+		}
+		c.Set(AdminIDKey, adminID)
+
+		// Tokens issued before roles existed carry full admin rights
+		role, _ := claims["role"].(string)
+		if role == "" {
+			role = RoleAdmin
+		}
+		c.Set(RoleKey, role)
+
+		if role != RoleAdmin && !isReadOnly(c.Request.Method) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	}
 }
 
+func isSSOToken(claims jwt.MapClaims) bool {
+	sso, _ := claims["sso"].(bool)
+	return sso
+}
+
+func authenticateAPIKey(c *gin.Context, validator APIKeyValidator, rawKey string) {
+	key, err := validator.ValidateAPIKey(c.Request.Context(), rawKey)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+		c.Abort()
+		return
+	}
+
+	required := RequiredScope(c.Request.Method, c.FullPath())
+	if required == "" || !hasScope(key.Scopes, required) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API key is missing the required scope"})
+		c.Abort()
+		return
+	}
+
+	c.Set(AdminIDKey, "apikey:"+key.ID)
+	c.Set(RoleKey, RoleAPIKey)
+	c.Next()
+}
+
+func isReadOnly(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
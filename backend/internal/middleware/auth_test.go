@@ -14,7 +14,7 @@ import (
 func TestAuthMiddleware(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	adminPassword := "test-password-123"
+	tokenSecret := "test-token-secret"
 
 	tests := []struct {
 		name           string
@@ -23,9 +23,20 @@ func TestAuthMiddleware(t *testing.T) {
 	}{
 		{
 			name:           "valid token",
-			authHeader:     generateValidToken(adminPassword),
+			authHeader:     generateValidToken(tokenSecret),
 			expectedStatus: http.StatusOK,
 		},
+		{
+			// Holders of the shared password must not be able to mint admin tokens
+			name:           "token signed with the admin password",
+			authHeader:     generateValidToken("test-password-123"),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "other signing method",
+			authHeader:     generateHS512Token(tokenSecret),
+			expectedStatus: http.StatusUnauthorized,
+		},
 		{
 			name:           "invalid token",
 			authHeader:     "Bearer invalid-token",
@@ -38,7 +49,12 @@ func TestAuthMiddleware(t *testing.T) {
 		},
 		{
 			name:           "expired token",
-			authHeader:     generateExpiredToken(adminPassword),
+			authHeader:     generateExpiredToken(tokenSecret),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "pending second factor token",
+			authHeader:     generatePendingMFAToken(tokenSecret),
 			expectedStatus: http.StatusUnauthorized,
 		},
 	}
@@ -46,7 +62,7 @@ func TestAuthMiddleware(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			router := gin.New()
-			router.Use(AuthMiddleware(adminPassword))
+			router.Use(AuthMiddleware(tokenSecret, ""))
 			router.GET("/test", func(c *gin.Context) {
 				c.JSON(http.StatusOK, gin.H{"message": "success"})
 			})
@@ -64,21 +80,163 @@ func TestAuthMiddleware(t *testing.T) {
 	}
 }
 
-func generateValidToken(password string) string {
+func generateValidToken(secret string) string {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"admin": true,
 		"exp":   time.Now().Add(time.Hour * 24).Unix(),
 	})
-	tokenString, _ := token.SignedString([]byte(password))
+	tokenString, _ := token.SignedString([]byte(secret))
 	return "Bearer " + tokenString
 }
 
-func generateExpiredToken(password string) string {
+func generateHS512Token(secret string) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS512, jwt.MapClaims{
+		"admin": true,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+	tokenString, _ := token.SignedString([]byte(secret))
+	return "Bearer " + tokenString
+}
+
+func generateExpiredToken(secret string) string {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"admin": true,
 		"exp":   time.Now().Add(-time.Hour).Unix(), // Expired
 	})
-	tokenString, _ := token.SignedString([]byte(password))
+	tokenString, _ := token.SignedString([]byte(secret))
+	return "Bearer " + tokenString
+}
+
+func generatePendingMFAToken(secret string) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "admin",
+		"mfa": "pending",
+		"exp": time.Now().Add(5 * time.Minute).Unix(),
+	})
+	tokenString, _ := token.SignedString([]byte(secret))
 	return "Bearer " + tokenString
 }
 
+func TestAuthMiddlewareViewerRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tokenSecret := "test-token-secret"
+	ssoSecret := "sso-secret"
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"admin": true,
+		"sso":   true,
+		"sub":   "viewer@example.com",
+		"role":  RoleViewer,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+	tokenString, _ := token.SignedString([]byte(ssoSecret))
+
+	tests := []struct {
+		method         string
+		expectedStatus int
+	}{
+		{method: "GET", expectedStatus: http.StatusOK},
+		{method: "POST", expectedStatus: http.StatusForbidden},
+		{method: "DELETE", expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			router := gin.New()
+			router.Use(AuthMiddleware(tokenSecret, ssoSecret))
+			router.Handle(tt.method, "/test", func(c *gin.Context) {
+				assert.Equal(t, "viewer@example.com", c.GetString(AdminIDKey))
+				c.JSON(http.StatusOK, gin.H{"message": "success"})
+			})
+
+			req, _ := http.NewRequest(tt.method, "/test", nil)
+			req.Header.Set("Authorization", "Bearer "+tokenString)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestAuthMiddlewareIdentity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tokenSecret := "test-token-secret"
+	ssoSecret := "sso-secret"
+	sign := func(key string, claims jwt.MapClaims) string {
+		claims["admin"] = true
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		tokenString, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(key))
+		return tokenString
+	}
+
+	tests := []struct {
+		name            string
+		ssoSecret       string
+		token           string
+		expectedStatus  int
+		expectedAdminID string
+	}{
+		{
+			name:            "password login token",
+			ssoSecret:       ssoSecret,
+			token:           sign(tokenSecret, jwt.MapClaims{"sub": "admin"}),
+			expectedStatus:  http.StatusOK,
+			expectedAdminID: "admin",
+		},
+		{
+			// The password doesn't identify anyone, so the subject is ignored
+			name:            "password login token claiming another admin",
+			ssoSecret:       ssoSecret,
+			token:           sign(tokenSecret, jwt.MapClaims{"sub": "alice@example.com"}),
+			expectedStatus:  http.StatusOK,
+			expectedAdminID: "admin",
+		},
+		{
+			name:           "SSO token signed with the admin token secret",
+			ssoSecret:      ssoSecret,
+			token:          sign(tokenSecret, jwt.MapClaims{"sso": true, "sub": "alice@example.com"}),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "SSO token without SSO configured",
+			token:          sign(tokenSecret, jwt.MapClaims{"sso": true, "sub": "alice@example.com"}),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:            "SSO token",
+			ssoSecret:       ssoSecret,
+			token:           sign(ssoSecret, jwt.MapClaims{"sso": true, "sub": "alice@example.com"}),
+			expectedStatus:  http.StatusOK,
+			expectedAdminID: "alice@example.com",
+		},
+		{
+			name:           "SSO token without subject",
+			ssoSecret:      ssoSecret,
+			token:          sign(ssoSecret, jwt.MapClaims{"sso": true}),
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(AuthMiddleware(tokenSecret, tt.ssoSecret))
+			router.GET("/test", func(c *gin.Context) {
+				c.String(http.StatusOK, c.GetString(AdminIDKey))
+			})
+
+			req, _ := http.NewRequest("GET", "/test", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, tt.expectedAdminID, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"appdirect-workshop-backend/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientIPKey is the context key holding the client IP resolved by ClientIPMiddleware
+const ClientIPKey = "clientIP"
+
+// ClientIPMiddleware resolves the real client IP behind a fixed number of proxies.
+// Each trusted proxy appends the address it saw to X-Forwarded-For, so the client
+// is the entry that many positions from the right; anything further left is
+// client-supplied and cannot be trusted.
+func ClientIPMiddleware(trustedHops int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(ClientIPKey, resolveClientIP(c.Request, trustedHops))
+		c.Next()
+	}
+}
+
+// ClientIP returns the IP resolved by ClientIPMiddleware, falling back to the connection address
+func ClientIP(c *gin.Context) string {
+	if ip := c.GetString(ClientIPKey); ip != "" {
+		return ip
+	}
+	return resolveClientIP(c.Request, 0)
+}
+
+func resolveClientIP(r *http.Request, trustedHops int) string {
+	if trustedHops > 0 {
+		var hops []string
+		for _, header := range r.Header.Values("X-Forwarded-For") {
+			for _, item := range strings.Split(header, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					hops = append(hops, item)
+				}
+			}
+		}
+		if len(hops) >= trustedHops {
+			if ip := net.ParseIP(hops[len(hops)-trustedHops]); ip != nil {
+				return ip.String()
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RateLimiter holds in-memory token buckets keyed by route and client IP.
+// Limits apply per server instance.
+type RateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   config.RateLimit
+}
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Limit returns middleware enforcing the budget for the named route per client IP.
+// Responses carry RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers, plus
+// Retry-After when the request is rejected.
+func (l *RateLimiter) Limit(route string, limit config.RateLimit) gin.HandlerFunc {
+	return l.LimitBy(route, limit, ClientIP)
+}
+
+// LimitBy is Limit with buckets keyed by key(c) instead of the client IP, e.g. by the
+// authenticated attendee
+func (l *RateLimiter) LimitBy(route string, limit config.RateLimit, key func(*gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, remaining, reset, retryAfter := l.take(route+"|"+key(c), limit)
+
+		c.Header("RateLimit-Limit", strconv.Itoa(limit.Requests))
+		c.Header("RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(reset)))
+
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, please try again later"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// take consumes a token if one is available. It reports the whole tokens left, the
+// time until the bucket is full again, and the time until the next token.
+func (l *RateLimiter) take(key string, limit config.RateLimit) (bool, int, time.Duration, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	capacity := float64(limit.Requests)
+	perToken := limit.Period / time.Duration(limit.Requests)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now, limit: limit}
+		l.buckets[key] = b
+	} else {
+		elapsed := now.Sub(b.updated)
+		b.tokens = math.Min(capacity, b.tokens+float64(elapsed)/float64(perToken))
+		b.updated = now
+	}
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	untilFull := time.Duration((capacity - b.tokens) * float64(perToken))
+	var untilNext time.Duration
+	if b.tokens < 1 {
+		untilNext = time.Duration((1 - b.tokens) * float64(perToken))
+	}
+	return allowed, int(b.tokens), untilFull, untilNext
+}
+
+// sweep drops buckets that have refilled completely; they behave exactly like new ones
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) >= b.limit.Period {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"appdirect-workshop-backend/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterBucket(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l := NewRateLimiter()
+	l.now = func() time.Time { return now }
+	limit := config.RateLimit{Requests: 3, Period: 3 * time.Second}
+
+	for i := 2; i >= 0; i-- {
+		allowed, remaining, _, _ := l.take("ip", limit)
+		assert.True(t, allowed)
+		assert.Equal(t, i, remaining)
+	}
+
+	allowed, remaining, reset, retryAfter := l.take("ip", limit)
+	assert.False(t, allowed)
+	assert.Equal(t, 0, remaining)
+	assert.Equal(t, 3*time.Second, reset)
+	assert.Equal(t, time.Second, retryAfter)
+
+	// Other clients have their own bucket
+	allowed, _, _, _ = l.take("other-ip", limit)
+	assert.True(t, allowed)
+
+	// One token refills per second
+	now = now.Add(time.Second)
+	allowed, _, _, _ = l.take("ip", limit)
+	assert.True(t, allowed)
+	allowed, _, _, _ = l.take("ip", limit)
+	assert.False(t, allowed)
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	l := NewRateLimiter()
+	router := gin.New()
+	router.Use(ClientIPMiddleware(1))
+	router.POST("/api/register", l.Limit("register", config.RateLimit{Requests: 2, Period: time.Minute}), func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	send := func(forwardedFor string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/register", nil)
+		req.RemoteAddr = "169.254.1.1:40000"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("203.0.113.7")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", w.Header().Get("RateLimit-Reset"))
+
+	// A spoofed leading entry does not give the client a fresh bucket
+	w = send("198.51.100.1, 203.0.113.7")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = send("198.51.100.2, 203.0.113.7")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+
+	w = send("203.0.113.8")
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestRateLimitByKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	l := NewRateLimiter()
+	router := gin.New()
+	limit := config.RateLimit{Requests: 1, Period: time.Minute}
+	router.POST("/api/questions", l.LimitBy("question", limit, func(c *gin.Context) string {
+		return c.GetHeader("X-Attendee")
+	}), func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	send := func(attendee string) int {
+		req, _ := http.NewRequest("POST", "/api/questions", nil)
+		req.RemoteAddr = "169.254.1.1:40000"
+		req.Header.Set("X-Attendee", attendee)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Attendees behind the same address have their own buckets
+	assert.Equal(t, http.StatusCreated, send("reg1"))
+	assert.Equal(t, http.StatusTooManyRequests, send("reg1"))
+	assert.Equal(t, http.StatusCreated, send("reg2"))
+}
+
+func TestResolveClientIP(t *testing.T) {
+	tests := []struct {
+		name         string
+		forwardedFor string
+		hops         int
+		expected     string
+	}{
+		{name: "no proxies ignores header", forwardedFor: "203.0.113.7", hops: 0, expected: "10.0.0.1"},
+		{name: "one hop", forwardedFor: "203.0.113.7", hops: 1, expected: "203.0.113.7"},
+		{name: "one hop with spoofed entry", forwardedFor: "1.2.3.4, 203.0.113.7", hops: 1, expected: "203.0.113.7"},
+		{name: "two hops", forwardedFor: "203.0.113.7, 10.1.1.1", hops: 2, expected: "203.0.113.7"},
+		{name: "missing header", forwardedFor: "", hops: 1, expected: "10.0.0.1"},
+		{name: "garbage entry", forwardedFor: "not-an-ip", hops: 1, expected: "10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.RemoteAddr = "10.0.0.1:1234"
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			assert.Equal(t, tt.expected, resolveClientIP(req, tt.hops))
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"appdirect-workshop-backend/internal/models"
+)
+
+// APIKeyHeader carries a machine credential instead of an admin JWT
+const APIKeyHeader = "X-API-Key"
+
+// RoleAPIKey marks requests authenticated by an API key; access is governed by scopes
+const RoleAPIKey = "api_key"
+
+// APIKeyValidator resolves a raw API key, returning an error if it is unknown, expired or revoked
+type APIKeyValidator interface {
+	ValidateAPIKey(ctx context.Context, rawKey string) (*models.APIKey, error)
+}
+
+// ScopeResources lists the admin resources API keys can be granted access to
+var ScopeResources = []string{"attendees", "speakers", "sessions", "analytics"}
+
+// ValidScope reports whether scope is "<resource>:read" or "<resource>:write" for a grantable resource
+func ValidScope(scope string) bool {
+	resource, action, ok := strings.Cut(scope, ":")
+	if !ok || (action != "read" && action != "write") {
+		return false
+	}
+	for _, r := range ScopeResources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiredScope maps an admin route to the scope needed to call it, e.g.
+// GET /api/admin/attendees/:id needs "attendees:read". Workshop-scoped routes under
+// /api/admin/w/:slug/ map the same way. It returns "" for routes API keys may never
+// call, such as key management itself.
+func RequiredScope(method, fullPath string) string {
+	rest := strings.TrimPrefix(fullPath, "/api/admin/")
+	rest = strings.TrimPrefix(rest, "w/:slug/")
+	resource, _, _ := strings.Cut(rest, "/")
+
+	action := "write"
+	if isReadOnly(method) {
+		action = "read"
+	}
+
+	scope := resource + ":" + action
+	if !ValidScope(scope) {
+		return ""
+	}
+	return scope
+}
+
+// hasScope treats write access to a resource as implying read access
+func hasScope(granted []string, required string) bool {
+	resource, _, _ := strings.Cut(required, ":")
+	for _, scope := range granted {
+		if scope == required || scope == resource+":write" {
+			return true
+		}
+	}
+	return false
+}
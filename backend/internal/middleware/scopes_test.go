@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"appdirect-workshop-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeAPIKeys map[string]*models.APIKey
+
+func (f fakeAPIKeys) ValidateAPIKey(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	if key, ok := f[rawKey]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown API key")
+}
+
+func TestRequiredScope(t *testing.T) {
+	tests := []struct {
+		method   string
+		path     string
+		expected string
+	}{
+		{method: "GET", path: "/api/admin/attendees", expected: "attendees:read"},
+		{method: "GET", path: "/api/admin/attendees/:id", expected: "attendees:read"},
+		{method: "PUT", path: "/api/admin/sessions/:id", expected: "sessions:write"},
+		{method: "GET", path: "/api/admin/analytics/designations", expected: "analytics:read"},
+		{method: "POST", path: "/api/admin/api-keys", expected: ""},
+		{method: "GET", path: "/api/admin/mfa", expected: ""},
+		{method: "GET", path: "/api/admin/w/:slug/attendees", expected: "attendees:read"},
+		{method: "DELETE", path: "/api/admin/w/:slug/speakers/:id", expected: "speakers:write"},
+		{method: "POST", path: "/api/admin/w/:slug/api-keys", expected: ""},
+		{method: "POST", path: "/api/admin/workshops", expected: ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, RequiredScope(tt.method, tt.path), tt.method+" "+tt.path)
+	}
+}
+
+func TestAuthMiddlewareAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keys := fakeAPIKeys{
+		"wsk_reader": {ID: "k1", Scopes: []string{"attendees:read"}},
+		"wsk_writer": {ID: "k2", Scopes: []string{"sessions:write"}},
+	}
+
+	tests := []struct {
+		name           string
+		key            string
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		{name: "read scope", key: "wsk_reader", method: "GET", path: "/api/admin/attendees", expectedStatus: http.StatusOK},
+		{name: "missing write scope", key: "wsk_reader", method: "DELETE", path: "/api/admin/attendees/abc", expectedStatus: http.StatusForbidden},
+		{name: "other resource", key: "wsk_reader", method: "GET", path: "/api/admin/sessions", expectedStatus: http.StatusForbidden},
+		{name: "write implies read", key: "wsk_writer", method: "GET", path: "/api/admin/sessions", expectedStatus: http.StatusOK},
+		{name: "write scope", key: "wsk_writer", method: "POST", path: "/api/admin/sessions", expectedStatus: http.StatusOK},
+		{name: "key management is never allowed", key: "wsk_writer", method: "POST", path: "/api/admin/api-keys", expectedStatus: http.StatusForbidden},
+		{name: "unknown key", key: "wsk_unknown", method: "GET", path: "/api/admin/attendees", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			admin := router.Group("/api/admin")
+			admin.Use(AuthMiddleware("test-password", "", keys))
+			handler := func(c *gin.Context) {
+				assert.Equal(t, RoleAPIKey, c.GetString(RoleKey))
+				c.JSON(http.StatusOK, gin.H{"message": "success"})
+			}
+			admin.GET("/attendees", handler)
+			admin.DELETE("/attendees/:id", handler)
+			admin.GET("/sessions", handler)
+			admin.POST("/sessions", handler)
+			admin.POST("/api-keys", handler)
+
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set(APIKeyHeader, tt.key)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
@@ -8,6 +8,13 @@ type Registration struct {
 	Email       string    `json:"email" firestore:"email"`
 	Designation string    `json:"designation" firestore:"designation"`
 	CreatedAt   time.Time `json:"createdAt" firestore:"createdAt"`
+	// CanonicalDesignation is Designation mapped through the designation taxonomy;
+	// empty when no taxonomy entry matches, and Designation as typed when the taxonomy
+	// couldn't be read at registration
+	CanonicalDesignation string `json:"canonicalDesignation,omitempty" firestore:"canonicalDesignation,omitempty"`
+	// TokenHash is the SHA-256 of the token the attendee got when registering; the
+	// token itself is never stored
+	TokenHash string `json:"-" firestore:"tokenHash,omitempty"`
 }
 
 type Speaker struct {
@@ -17,6 +24,18 @@ type Speaker struct {
 	ImageURL    string `json:"imageUrl,omitempty" firestore:"imageUrl,omitempty"`
 	LinkedInURL string `json:"linkedinUrl,omitempty" firestore:"linkedinUrl,omitempty"`
 	TwitterURL  string `json:"twitterUrl,omitempty" firestore:"twitterUrl,omitempty"`
+	// Images are the sizes and formats an uploaded photo was converted to; ImageURL
+	// points at the large fallback
+	Images []ImageVariant `json:"images,omitempty" firestore:"images,omitempty"`
+}
+
+// ImageVariant is one size and format of an uploaded image
+type ImageVariant struct {
+	Size   string `json:"size" firestore:"size"`
+	Format string `json:"format" firestore:"format"`
+	Width  int    `json:"width" firestore:"width"`
+	Height int    `json:"height" firestore:"height"`
+	URL    string `json:"url" firestore:"url"`
 }
 
 type Session struct {
@@ -26,10 +45,327 @@ type Session struct {
 	Time        string   `json:"time" firestore:"time"`
 	Duration    string   `json:"duration" firestore:"duration"`
 	SpeakerIDs  []string `json:"speakerIds" firestore:"speakerIds"`
+	// Status is "draft" for sessions hidden from the public agenda; empty means published
+	Status string `json:"status,omitempty" firestore:"status,omitempty"`
+}
+
+// Proposal is a talk submitted through the call for papers. ReviewCount and ScoreTotal
+// summarize its reviews; SpeakerID and SessionID are set when it is accepted.
+type Proposal struct {
+	ID          string     `json:"id" firestore:"-"`
+	Title       string     `json:"title" firestore:"title"`
+	Abstract    string     `json:"abstract" firestore:"abstract"`
+	Name        string     `json:"name" firestore:"name"`
+	Email       string     `json:"email" firestore:"email"`
+	Bio         string     `json:"bio" firestore:"bio"`
+	Links       []string   `json:"links" firestore:"links"`
+	Status      string     `json:"status" firestore:"status"`
+	ReviewCount int        `json:"reviewCount" firestore:"reviewCount"`
+	ScoreTotal  int        `json:"scoreTotal" firestore:"scoreTotal"`
+	SpeakerID   string     `json:"speakerId,omitempty" firestore:"speakerId,omitempty"`
+	SessionID   string     `json:"sessionId,omitempty" firestore:"sessionId,omitempty"`
+	DecidedBy   string     `json:"decidedBy,omitempty" firestore:"decidedBy,omitempty"`
+	DecidedAt   *time.Time `json:"decidedAt,omitempty" firestore:"decidedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt" firestore:"createdAt"`
+}
+
+// SpeakerInvite lets a speaker edit their own profile with the magic link emailed to
+// them. Its document ID is the speaker ID, so inviting again replaces the old link.
+type SpeakerInvite struct {
+	SpeakerID string    `json:"speakerId" firestore:"-"`
+	Email     string    `json:"email" firestore:"email"`
+	TokenHash string    `json:"-" firestore:"tokenHash"`
+	InvitedBy string    `json:"invitedBy,omitempty" firestore:"invitedBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt" firestore:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt" firestore:"expiresAt"`
+}
+
+// SpeakerChange is a speaker's profile edit waiting for an admin to approve it. Its
+// document ID is the speaker ID, so a later edit replaces the pending one.
+type SpeakerChange struct {
+	SpeakerID   string    `json:"speakerId" firestore:"-"`
+	Name        string    `json:"name" firestore:"name"`
+	Bio         string    `json:"bio" firestore:"bio"`
+	LinkedInURL string    `json:"linkedinUrl,omitempty" firestore:"linkedinUrl,omitempty"`
+	TwitterURL  string    `json:"twitterUrl,omitempty" firestore:"twitterUrl,omitempty"`
+	SubmittedAt time.Time `json:"submittedAt" firestore:"submittedAt"`
+}
+
+// ProposalReview is an admin's score and comment on a proposal. Its document ID
+// combines the proposal and reviewer IDs, so each admin has one review per proposal.
+type ProposalReview struct {
+	ID         string    `json:"id" firestore:"-"`
+	ProposalID string    `json:"proposalId" firestore:"proposalId"`
+	ReviewerID string    `json:"reviewerId" firestore:"reviewerId"`
+	Score      int       `json:"score" firestore:"score"`
+	Comment    string    `json:"comment,omitempty" firestore:"comment,omitempty"`
+	CreatedAt  time.Time `json:"createdAt" firestore:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt" firestore:"updatedAt"`
+}
+
+// SessionFeedback is an attendee's rating of a session. Its document ID combines the
+// session and registration IDs, so each attendee can rate a session once.
+type SessionFeedback struct {
+	ID             string    `json:"id" firestore:"-"`
+	SessionID      string    `json:"sessionId" firestore:"sessionId"`
+	RegistrationID string    `json:"registrationId" firestore:"registrationId"`
+	Rating         int       `json:"rating" firestore:"rating"`
+	Comment        string    `json:"comment,omitempty" firestore:"comment,omitempty"`
+	CreatedAt      time.Time `json:"createdAt" firestore:"createdAt"`
+}
+
+// Question is an attendee's question for a session's live Q&A. New questions wait for
+// a moderator; Votes is kept in step with the question's QuestionVote documents.
+type Question struct {
+	ID             string     `json:"id" firestore:"-"`
+	SessionID      string     `json:"sessionId" firestore:"sessionId"`
+	RegistrationID string     `json:"registrationId,omitempty" firestore:"registrationId"`
+	AuthorName     string     `json:"authorName,omitempty" firestore:"authorName,omitempty"`
+	Text           string     `json:"text" firestore:"text"`
+	Status         string     `json:"status" firestore:"status"`
+	Votes          int        `json:"votes" firestore:"votes"`
+	CreatedAt      time.Time  `json:"createdAt" firestore:"createdAt"`
+	AnsweredAt     *time.Time `json:"answeredAt,omitempty" firestore:"answeredAt,omitempty"`
+}
+
+// QuestionVote is one attendee's upvote. Its document ID combines the question and
+// registration IDs, so each attendee can vote for a question once.
+type QuestionVote struct {
+	QuestionID     string    `json:"questionId" firestore:"questionId"`
+	SessionID      string    `json:"sessionId" firestore:"sessionId"`
+	RegistrationID string    `json:"registrationId" firestore:"registrationId"`
+	CreatedAt      time.Time `json:"createdAt" firestore:"createdAt"`
+}
+
+// Poll is a quick audience poll run during a session. Votes counts votes per option ID
+// and Voters counts registrations that voted; both are kept in step with the poll's
+// PollVote documents.
+type Poll struct {
+	ID        string         `json:"id" firestore:"-"`
+	SessionID string         `json:"sessionId" firestore:"sessionId"`
+	Question  string         `json:"question" firestore:"question"`
+	Options   []PollOption   `json:"options" firestore:"options"`
+	Multiple  bool           `json:"multiple" firestore:"multiple"`
+	Status    string         `json:"status" firestore:"status"`
+	Votes     map[string]int `json:"votes" firestore:"votes"`
+	Voters    int            `json:"voters" firestore:"voters"`
+	CreatedAt time.Time      `json:"createdAt" firestore:"createdAt"`
+}
+
+type PollOption struct {
+	ID   string `json:"id" firestore:"id"`
+	Text string `json:"text" firestore:"text"`
+}
+
+// PollVote is one registration's ballot. Its document ID combines the poll and
+// registration IDs, so each registration can vote once.
+type PollVote struct {
+	PollID         string    `json:"pollId" firestore:"pollId"`
+	SessionID      string    `json:"sessionId" firestore:"sessionId"`
+	RegistrationID string    `json:"registrationId" firestore:"registrationId"`
+	OptionIDs      []string  `json:"optionIds" firestore:"optionIds"`
+	CreatedAt      time.Time `json:"createdAt" firestore:"createdAt"`
+}
+
+// Webhook is an endpoint notified of the Events it subscribes to. Secret signs every
+// delivery and is only shown when the webhook is created.
+type Webhook struct {
+	ID          string    `json:"id" firestore:"-"`
+	URL         string    `json:"url" firestore:"url"`
+	Events      []string  `json:"events" firestore:"events"`
+	Description string    `json:"description,omitempty" firestore:"description,omitempty"`
+	Active      bool      `json:"active" firestore:"active"`
+	Secret      string    `json:"-" firestore:"secret"`
+	CreatedBy   string    `json:"createdBy" firestore:"createdBy"`
+	CreatedAt   time.Time `json:"createdAt" firestore:"createdAt"`
+}
+
+// WebhookDelivery logs one event sent to one webhook. Payload is the exact body sent,
+// so a redelivery repeats it byte for byte; EventID stays the same across redeliveries.
+type WebhookDelivery struct {
+	ID           string           `json:"id" firestore:"-"`
+	WebhookID    string           `json:"webhookId" firestore:"webhookId"`
+	EventID      string           `json:"eventId" firestore:"eventId"`
+	Event        string           `json:"event" firestore:"event"`
+	Payload      string           `json:"payload" firestore:"payload"`
+	Status       string           `json:"status" firestore:"status"`
+	Attempts     []WebhookAttempt `json:"attempts" firestore:"attempts"`
+	RedeliveryOf string           `json:"redeliveryOf,omitempty" firestore:"redeliveryOf,omitempty"`
+	CreatedAt    time.Time        `json:"createdAt" firestore:"createdAt"`
+	UpdatedAt    time.Time        `json:"updatedAt" firestore:"updatedAt"`
+	// NextAttemptAt is when a pending delivery is next tried; an attempt claims the
+	// delivery by moving it forward
+	NextAttemptAt time.Time `json:"nextAttemptAt" firestore:"nextAttemptAt"`
+}
+
+type WebhookAttempt struct {
+	At         time.Time `json:"at" firestore:"at"`
+	StatusCode int       `json:"statusCode,omitempty" firestore:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty" firestore:"error,omitempty"`
+	Response   string    `json:"response,omitempty" firestore:"response,omitempty"`
+	DurationMS int64     `json:"durationMs" firestore:"durationMs"`
+}
+
+// OutboxEvent is a domain event written in the same transaction as the change it
+// describes and handed to each subscriber until every one has handled it. Data is the
+// event's JSON, kept as written so every subscriber sees the same document.
+type OutboxEvent struct {
+	ID        string    `json:"id" firestore:"-"`
+	Type      string    `json:"type" firestore:"type"`
+	Data      string    `json:"data" firestore:"data"`
+	Status    string    `json:"status" firestore:"status"`
+	CreatedAt time.Time `json:"createdAt" firestore:"createdAt"`
+	// NextAttemptAt is when the event is next due; a dispatcher claims an event by
+	// moving it forward
+	NextAttemptAt time.Time  `json:"nextAttemptAt" firestore:"nextAttemptAt"`
+	Attempts      int        `json:"attempts" firestore:"attempts"`
+	Handled       []string   `json:"handled" firestore:"handled"`
+	LastError     string     `json:"lastError,omitempty" firestore:"lastError,omitempty"`
+	DispatchedAt  *time.Time `json:"dispatchedAt,omitempty" firestore:"dispatchedAt,omitempty"`
+}
+
+// Announcement is an organizer message shown on the landing page between PublishAt and
+// ExpiresAt; without ExpiresAt it stays up until deleted
+type Announcement struct {
+	ID        string     `json:"id" firestore:"-"`
+	Title     string     `json:"title" firestore:"title"`
+	Body      string     `json:"body" firestore:"body"`
+	Severity  string     `json:"severity" firestore:"severity"`
+	PublishAt time.Time  `json:"publishAt" firestore:"publishAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" firestore:"expiresAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt" firestore:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt" firestore:"updatedAt"`
+}
+
+// AnnouncementEmail tracks a background job emailing an announcement to every
+// registrant. Sent and Failed are updated as the job runs; Errors keeps the first
+// failures.
+type AnnouncementEmail struct {
+	ID             string     `json:"id" firestore:"-"`
+	AnnouncementID string     `json:"announcementId" firestore:"announcementId"`
+	Status         string     `json:"status" firestore:"status"`
+	Total          int        `json:"total" firestore:"total"`
+	Sent           int        `json:"sent" firestore:"sent"`
+	Failed         int        `json:"failed" firestore:"failed"`
+	Errors         []string   `json:"errors,omitempty" firestore:"errors,omitempty"`
+	RequestedBy    string     `json:"requestedBy" firestore:"requestedBy"`
+	CreatedAt      time.Time  `json:"createdAt" firestore:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt" firestore:"updatedAt"`
+	FinishedAt     *time.Time `json:"finishedAt,omitempty" firestore:"finishedAt,omitempty"`
 }
 
 type DesignationBreakdown struct {
 	Designation string `json:"designation"`
 	Count       int    `json:"count"`
+	// Unmapped marks the bucket of registrations no taxonomy entry matches; Values lists
+	// their designations so admins can add aliases
+	Unmapped bool     `json:"unmapped,omitempty"`
+	Values   []string `json:"values,omitempty"`
+}
+
+// OrganizationDomain maps an email domain, stored as the document ID, to the
+// organization shown in analytics. It also covers the domain's subdomains.
+type OrganizationDomain struct {
+	Domain       string `json:"domain" firestore:"-"`
+	Organization string `json:"organization" firestore:"organization"`
+}
+
+// Designation is a canonical designation in the taxonomy. Registrations match it by its
+// name or a synonym, ignoring case and extra spaces, or by one of its regex patterns.
+type Designation struct {
+	ID       string   `json:"id" firestore:"-"`
+	Name     string   `json:"name" firestore:"name"`
+	Synonyms []string `json:"synonyms" firestore:"synonyms"`
+	Patterns []string `json:"patterns" firestore:"patterns"`
+}
+
+// AdminMFA holds an admin's TOTP enrollment, keyed by admin ID
+type AdminMFA struct {
+	Secret        string    `json:"-" firestore:"secret"`
+	Enabled       bool      `json:"enabled" firestore:"enabled"`
+	RecoveryCodes []string  `json:"-" firestore:"recoveryCodes"`
+	LastUsedStep  int64     `json:"-" firestore:"lastUsedStep"`
+	EnrolledAt    time.Time `json:"enrolledAt,omitempty" firestore:"enrolledAt,omitempty"`
+}
+
+// APIKey is a named machine credential; only a hash of the key is stored
+type APIKey struct {
+	ID         string     `json:"id" firestore:"-"`
+	Name       string     `json:"name" firestore:"name"`
+	Prefix     string     `json:"prefix" firestore:"prefix"`
+	KeyHash    string     `json:"-" firestore:"keyHash"`
+	Scopes     []string   `json:"scopes" firestore:"scopes"`
+	CreatedBy  string     `json:"createdBy" firestore:"createdBy"`
+	CreatedAt  time.Time  `json:"createdAt" firestore:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty" firestore:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty" firestore:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty" firestore:"revokedAt,omitempty"`
+}
+
+// AuditEntry records one administrative change; entries are never updated or deleted
+type AuditEntry struct {
+	ID         string                 `json:"id" firestore:"-"`
+	Actor      string                 `json:"actor" firestore:"actor"`
+	Action     string                 `json:"action" firestore:"action"`
+	TargetType string                 `json:"targetType" firestore:"targetType"`
+	TargetID   string                 `json:"targetId" firestore:"targetId"`
+	Changes    map[string]AuditChange `json:"changes,omitempty" firestore:"changes,omitempty"`
+	IP         string                 `json:"ip" firestore:"ip"`
+	CreatedAt  time.Time              `json:"createdAt" firestore:"createdAt"`
+}
+
+type AuditChange struct {
+	Before interface{} `json:"before" firestore:"before"`
+	After  interface{} `json:"after" firestore:"after"`
+}
+
+// Workshop is the document under workshops/{slug} that holds an event's collections.
+// Admins maps admin IDs to their role in this workshop.
+type Workshop struct {
+	Slug       string            `json:"slug" firestore:"-"`
+	Name       string            `json:"name" firestore:"name"`
+	Admins     map[string]string `json:"admins,omitempty" firestore:"admins,omitempty"`
+	ClonedFrom string            `json:"clonedFrom,omitempty" firestore:"clonedFrom,omitempty"`
+	Archived   bool              `json:"archived" firestore:"archived"`
+	ArchivedAt *time.Time        `json:"archivedAt,omitempty" firestore:"archivedAt,omitempty"`
+	CreatedAt  time.Time         `json:"createdAt" firestore:"createdAt"`
+	Settings   WorkshopSettings  `json:"settings" firestore:"settings"`
+	// Status is the lifecycle state set by organizers; empty behaves like "open" so
+	// workshops created before lifecycles keep accepting registrations
+	Status               string     `json:"status,omitempty" firestore:"status,omitempty"`
+	RegistrationOpensAt  *time.Time `json:"registrationOpensAt,omitempty" firestore:"registrationOpensAt,omitempty"`
+	RegistrationClosesAt *time.Time `json:"registrationClosesAt,omitempty" firestore:"registrationClosesAt,omitempty"`
+	// CFPOpen accepts talk proposals until CFPClosesAt, if set
+	CFPOpen     bool       `json:"cfpOpen" firestore:"cfpOpen"`
+	CFPClosesAt *time.Time `json:"cfpClosesAt,omitempty" firestore:"cfpClosesAt,omitempty"`
+	// SpeakerEditsNeedApproval holds profile edits made through the speaker portal
+	// until an admin approves them
+	SpeakerEditsNeedApproval bool `json:"speakerEditsNeedApproval" firestore:"speakerEditsNeedApproval"`
+}
+
+// WorkshopSettings are the public event details shown on the landing page
+type WorkshopSettings struct {
+	Title       string           `json:"title" firestore:"title"`
+	Tagline     string           `json:"tagline,omitempty" firestore:"tagline,omitempty"`
+	Description string           `json:"description,omitempty" firestore:"description,omitempty"`
+	StartsAt    *time.Time       `json:"startsAt,omitempty" firestore:"startsAt,omitempty"`
+	EndsAt      *time.Time       `json:"endsAt,omitempty" firestore:"endsAt,omitempty"`
+	TimeZone    string           `json:"timeZone,omitempty" firestore:"timeZone,omitempty"`
+	Venue       WorkshopVenue    `json:"venue" firestore:"venue"`
+	Branding    WorkshopBranding `json:"branding" firestore:"branding"`
+	UpdatedAt   *time.Time       `json:"updatedAt,omitempty" firestore:"updatedAt,omitempty"`
+}
+
+type WorkshopVenue struct {
+	Name        string `json:"name,omitempty" firestore:"name,omitempty"`
+	Address     string `json:"address,omitempty" firestore:"address,omitempty"`
+	MapURL      string `json:"mapUrl,omitempty" firestore:"mapUrl,omitempty"`
+	MapEmbedURL string `json:"mapEmbedUrl,omitempty" firestore:"mapEmbedUrl,omitempty"`
+}
+
+type WorkshopBranding struct {
+	LogoURL        string `json:"logoUrl,omitempty" firestore:"logoUrl,omitempty"`
+	PrimaryColor   string `json:"primaryColor,omitempty" firestore:"primaryColor,omitempty"`
+	SecondaryColor string `json:"secondaryColor,omitempty" firestore:"secondaryColor,omitempty"`
 }
 
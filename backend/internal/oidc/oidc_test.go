@@ -0,0 +1,149 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockIssuer is a minimal OpenID Connect provider for tests
+type mockIssuer struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	claims   jwt.MapClaims
+	verifier string
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	m := &mockIssuer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "test-key",
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("code") != "good-code" || CodeChallenge(r.Form.Get("code_verifier")) != CodeChallenge(m.verifier) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": m.sign(t, m.claims)})
+	})
+	m.server = httptest.NewServer(mux)
+	return m
+}
+
+func (m *mockIssuer) sign(t *testing.T, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test-key"
+	signed, err := token.SignedString(m.key)
+	assert.NoError(t, err)
+	return signed
+}
+
+func TestProviderFlow(t *testing.T) {
+	issuer := newMockIssuer(t)
+	defer issuer.server.Close()
+
+	p := NewProvider(issuer.server.URL, "client-id", "", "http://localhost/callback")
+	ctx := context.Background()
+
+	authURL, err := p.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-1")
+	assert.NoError(t, err)
+	parsed, _ := url.Parse(authURL)
+	assert.Equal(t, "state-1", parsed.Query().Get("state"))
+	assert.Equal(t, CodeChallenge("verifier-1"), parsed.Query().Get("code_challenge"))
+	assert.Equal(t, "S256", parsed.Query().Get("code_challenge_method"))
+
+	issuer.verifier = "verifier-1"
+	issuer.claims = jwt.MapClaims{
+		"iss":    issuer.server.URL,
+		"aud":    "client-id",
+		"sub":    "user-1",
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"nonce":  "nonce-1",
+		"email":  "alice@example.com",
+		"groups": []string{"organizers"},
+	}
+
+	claims, err := p.Exchange(ctx, "good-code", "verifier-1", "nonce-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice@example.com", claims.Email)
+	assert.Equal(t, []interface{}{"organizers"}, claims.Extra["groups"])
+
+	_, err = p.Exchange(ctx, "good-code", "wrong-verifier", "nonce-1")
+	assert.Error(t, err)
+
+	_, err = p.Exchange(ctx, "good-code", "verifier-1", "other-nonce")
+	assert.Error(t, err)
+}
+
+func TestVerifyIDTokenRejects(t *testing.T) {
+	issuer := newMockIssuer(t)
+	defer issuer.server.Close()
+
+	p := NewProvider(issuer.server.URL, "client-id", "", "http://localhost/callback")
+	base := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss": issuer.server.URL,
+			"aud": "client-id",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(jwt.MapClaims)
+	}{
+		{name: "wrong audience", mutate: func(c jwt.MapClaims) { c["aud"] = "other-client" }},
+		{name: "wrong issuer", mutate: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		{name: "expired", mutate: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{name: "missing expiry", mutate: func(c jwt.MapClaims) { delete(c, "exp") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := base()
+			tt.mutate(claims)
+			_, err := p.VerifyIDToken(context.Background(), issuer.sign(t, claims), "")
+			assert.Error(t, err)
+		})
+	}
+
+	// A token signed by a different key must be rejected
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, base())
+	token.Header["kid"] = "test-key"
+	forged, _ := token.SignedString(otherKey)
+	_, err := p.VerifyIDToken(context.Background(), forged, "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,76 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHOTPRFC6238Vectors(t *testing.T) {
+	// Test vectors from RFC 6238 Appendix B (SHA1)
+	key := []byte("12345678901234567890")
+
+	tests := []struct {
+		unix     int64
+		expected string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, hotp(key, uint64(tt.unix/Period), 8))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(59, 0)
+
+	tests := []struct {
+		name     string
+		code     string
+		at       time.Time
+		expected bool
+	}{
+		{name: "current step", code: "287082", at: now, expected: true},
+		{name: "with spaces", code: "287 082", at: now, expected: true},
+		{name: "previous step within skew", code: "287082", at: now.Add(Period * time.Second), expected: true},
+		{name: "outside skew", code: "287082", at: now.Add(3 * Period * time.Second), expected: false},
+		{name: "wrong code", code: "000000", at: now, expected: false},
+		{name: "wrong length", code: "28708", at: now, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(secret, tt.code, tt.at, 1)
+			assert.Equal(t, tt.expected, ok)
+			if ok {
+				assert.Equal(t, Step(now), step)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	code, err := CodeAt(secret, Step(time.Now()))
+	assert.NoError(t, err)
+	assert.Len(t, code, Digits)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("AppDirect Workshop", "admin", "JBSWY3DPEHPK3PXP")
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/AppDirect%20Workshop:admin?"))
+	assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(t, uri, "issuer=AppDirect+Workshop")
+}
@@ -0,0 +1,155 @@
+// Package webhook delivers signed event notifications to endpoints registered by
+// organizers, retrying failed deliveries with backoff.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers sent with every delivery
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderID        = "X-Webhook-Id"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// maxResponseSnippet bounds how much of a receiver's response is kept per attempt
+const maxResponseSnippet = 512
+
+// DefaultBackoff spaces six attempts over about 45 minutes
+var DefaultBackoff = []time.Duration{
+	10 * time.Second,
+	time.Minute,
+	5 * time.Minute,
+	10 * time.Minute,
+	30 * time.Minute,
+}
+
+// Message is one event to deliver. ID identifies the event, so receivers can ignore a
+// message they have already handled; redeliveries reuse it.
+type Message struct {
+	ID    string
+	Event string
+	Body  []byte
+}
+
+// Attempt records one delivery attempt. StatusCode is 0 when no response arrived.
+type Attempt struct {
+	At         time.Time
+	StatusCode int
+	Error      string
+	// Response is the start of the receiver's response body
+	Response string
+	Duration time.Duration
+}
+
+// OK reports whether the receiver accepted the message with a 2xx response
+func (a Attempt) OK() bool {
+	return a.Error == "" && a.StatusCode >= 200 && a.StatusCode < 300
+}
+
+// Dispatcher posts messages and retries failures, waiting Backoff[i] after the i-th
+// failed attempt, so a message is tried len(Backoff)+1 times at most.
+type Dispatcher struct {
+	Client  *http.Client
+	Backoff []time.Duration
+	// sleep waits between attempts; replaced in tests
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		Client:  &http.Client{Timeout: 10 * time.Second},
+		Backoff: DefaultBackoff,
+		sleep:   sleepContext,
+	}
+}
+
+// Deliver sends msg to url until it is accepted or the attempts run out, passing every
+// attempt to record as it completes. It reports whether the message was accepted.
+func (d *Dispatcher) Deliver(ctx context.Context, url, secret string, msg Message, record func(Attempt)) bool {
+	for i := 0; ; i++ {
+		attempt := d.Send(ctx, url, secret, msg)
+		record(attempt)
+		if attempt.OK() {
+			return true
+		}
+		if i >= len(d.Backoff) {
+			return false
+		}
+		if err := d.sleep(ctx, d.Backoff[i]); err != nil {
+			return false
+		}
+	}
+}
+
+// Send makes a single signed attempt
+func (d *Dispatcher) Send(ctx context.Context, url, secret string, msg Message) Attempt {
+	start := time.Now()
+	attempt := Attempt{At: start}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(msg.Body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	timestamp := strconv.FormatInt(start.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "workshop-webhooks/1")
+	req.Header.Set(HeaderEvent, msg.Event)
+	req.Header.Set(HeaderID, msg.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, msg.Body))
+
+	resp, err := d.Client.Do(req)
+	attempt.Duration = time.Since(start)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseSnippet))
+	attempt.StatusCode = resp.StatusCode
+	attempt.Response = string(snippet)
+	if !attempt.OK() {
+		attempt.Error = fmt.Sprintf("receiver returned %d", resp.StatusCode)
+	}
+	return attempt
+}
+
+// Sign computes the signature header value: "sha256=" and the hex HMAC-SHA256 of
+// "<timestamp>.<body>". Signing the timestamp lets receivers reject replayed messages.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature in constant time, for receivers written in Go
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
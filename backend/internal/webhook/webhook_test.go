@@ -0,0 +1,142 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver is a local endpoint that answers with the given statuses in turn, then 200
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+	w.Write([]byte("handled"))
+}
+
+func testDispatcher(backoff ...time.Duration) (*Dispatcher, *[]time.Duration) {
+	var waits []time.Duration
+	d := NewDispatcher()
+	d.Backoff = backoff
+	d.sleep = func(ctx context.Context, wait time.Duration) error {
+		waits = append(waits, wait)
+		return nil
+	}
+	return d, &waits
+}
+
+func TestSendSignsMessage(t *testing.T) {
+	recv := &receiver{}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	d, _ := testDispatcher()
+	msg := Message{ID: "evt_1", Event: "registration.created", Body: []byte(`{"type":"registration.created"}`)}
+	attempt := d.Send(context.Background(), server.URL, "whsec_test", msg)
+
+	require.True(t, attempt.OK())
+	assert.Equal(t, http.StatusOK, attempt.StatusCode)
+	assert.Equal(t, "handled", attempt.Response)
+
+	require.Len(t, recv.requests, 1)
+	req := recv.requests[0]
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, "registration.created", req.Header.Get(HeaderEvent))
+	assert.Equal(t, "evt_1", req.Header.Get(HeaderID))
+	assert.Equal(t, msg.Body, recv.bodies[0])
+	assert.True(t, Verify("whsec_test", req.Header.Get(HeaderTimestamp), recv.bodies[0], req.Header.Get(HeaderSignature)))
+	assert.False(t, Verify("other-secret", req.Header.Get(HeaderTimestamp), recv.bodies[0], req.Header.Get(HeaderSignature)))
+}
+
+func TestSign(t *testing.T) {
+	// Same as: printf '1700000000.{}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "sha256=b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163", Sign("secret", "1700000000", []byte("{}")))
+	assert.NotEqual(t, Sign("secret", "1700000000", []byte("{}")), Sign("secret", "1700000001", []byte("{}")))
+}
+
+func TestDeliverRetriesWithBackoff(t *testing.T) {
+	recv := &receiver{statuses: []int{http.StatusInternalServerError, http.StatusTooManyRequests}}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	d, waits := testDispatcher(time.Second, time.Minute, time.Hour)
+	var attempts []Attempt
+	ok := d.Deliver(context.Background(), server.URL, "secret", Message{ID: "evt_1", Body: []byte("{}")}, func(a Attempt) {
+		attempts = append(attempts, a)
+	})
+
+	assert.True(t, ok)
+	require.Len(t, attempts, 3)
+	assert.Equal(t, http.StatusInternalServerError, attempts[0].StatusCode)
+	assert.Equal(t, "receiver returned 500", attempts[0].Error)
+	assert.Equal(t, http.StatusTooManyRequests, attempts[1].StatusCode)
+	assert.True(t, attempts[2].OK())
+	assert.Equal(t, []time.Duration{time.Second, time.Minute}, *waits)
+}
+
+func TestDeliverGivesUp(t *testing.T) {
+	recv := &receiver{statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	d, waits := testDispatcher(time.Second, time.Second)
+	attempts := 0
+	ok := d.Deliver(context.Background(), server.URL, "secret", Message{Body: []byte("{}")}, func(Attempt) { attempts++ })
+
+	assert.False(t, ok)
+	assert.Equal(t, 3, attempts)
+	assert.Len(t, *waits, 2)
+}
+
+func TestDeliverUnreachable(t *testing.T) {
+	server := httptest.NewServer(&receiver{})
+	url := server.URL
+	server.Close()
+
+	d, _ := testDispatcher()
+	var attempt Attempt
+	ok := d.Deliver(context.Background(), url, "secret", Message{Body: []byte("{}")}, func(a Attempt) { attempt = a })
+
+	assert.False(t, ok)
+	assert.Zero(t, attempt.StatusCode)
+	assert.NotEmpty(t, attempt.Error)
+}
+
+func TestDeliverStopsWhenCancelled(t *testing.T) {
+	server := httptest.NewServer(&receiver{statuses: []int{http.StatusInternalServerError}})
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := NewDispatcher()
+	d.Backoff = []time.Duration{time.Hour}
+	attempts := 0
+	ok := d.Deliver(ctx, server.URL, "secret", Message{Body: []byte("{}")}, func(Attempt) {
+		attempts++
+		cancel()
+	})
+
+	assert.False(t, ok)
+	assert.Equal(t, 1, attempts)
+}
@@ -0,0 +1,236 @@
+package webp
+
+// Longest codes VP8L allows for symbols and for the code lengths themselves
+const (
+	maxCodeLength           = 15
+	maxCodeLengthCodeLength = 7
+)
+
+// codeLengthCodeOrder is the order code length code lengths are written in
+var codeLengthCodeOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// prefixCode maps symbols to canonical Huffman codes, stored bit-reversed since the
+// decoder reads a code's first bit from the lowest bit
+type prefixCode struct {
+	codes   []uint32
+	lengths []int
+}
+
+func (p prefixCode) write(bw *bitWriter, symbol int) {
+	if n := p.lengths[symbol]; n > 0 {
+		bw.writeBits(p.codes[symbol], uint(n))
+	}
+}
+
+// writePrefixCode writes a code for symbols with the given counts and returns it
+func writePrefixCode(bw *bitWriter, counts []int) prefixCode {
+	used, symbol := 0, 0
+	for s, count := range counts {
+		if count > 0 {
+			used++
+			symbol = s
+		}
+	}
+
+	// A single symbol takes no bits at all with a simple code
+	if used <= 1 && symbol < 256 {
+		bw.writeBits(1, 1)
+		bw.writeBits(0, 1)
+		if symbol < 2 {
+			bw.writeBits(0, 1)
+			bw.writeBits(uint32(symbol), 1)
+		} else {
+			bw.writeBits(1, 1)
+			bw.writeBits(uint32(symbol), 8)
+		}
+		return prefixCode{codes: make([]uint32, len(counts)), lengths: make([]int, len(counts))}
+	}
+
+	lengths := huffmanLengths(counts, maxCodeLength)
+	bw.writeBits(0, 1)
+	writeCodeLengths(bw, lengths)
+	return prefixCode{codes: canonicalCodes(lengths), lengths: lengths}
+}
+
+// codeLengthToken is one symbol of the code length alphabet: a length from 0 to 15,
+// or 16 to 18 to repeat one, with its extra bits
+type codeLengthToken struct {
+	symbol    int
+	extraBits uint
+	extra     int
+}
+
+// writeCodeLengths writes lengths run-length encoded with the code length code
+func writeCodeLengths(bw *bitWriter, lengths []int) {
+	var tokens []codeLengthToken
+	for i := 0; i < len(lengths); {
+		length, run := lengths[i], 1
+		for i+run < len(lengths) && lengths[i+run] == length {
+			run++
+		}
+		i += run
+
+		if length == 0 {
+			for run >= 11 {
+				n := min(run, 138)
+				tokens = append(tokens, codeLengthToken{18, 7, n - 11})
+				run -= n
+			}
+			if run >= 3 {
+				tokens = append(tokens, codeLengthToken{17, 3, run - 3})
+				run = 0
+			}
+		} else {
+			// 16 repeats the previous non-zero length
+			tokens = append(tokens, codeLengthToken{symbol: length})
+			run--
+			for run >= 3 {
+				n := min(run, 6)
+				tokens = append(tokens, codeLengthToken{16, 2, n - 3})
+				run -= n
+			}
+		}
+		for ; run > 0; run-- {
+			tokens = append(tokens, codeLengthToken{symbol: length})
+		}
+	}
+
+	counts := make([]int, len(codeLengthCodeOrder))
+	for _, t := range tokens {
+		counts[t.symbol]++
+	}
+	codeLengths := huffmanLengths(counts, maxCodeLengthCodeLength)
+	codes := canonicalCodes(codeLengths)
+
+	n := len(codeLengthCodeOrder)
+	for n > 4 && codeLengths[codeLengthCodeOrder[n-1]] == 0 {
+		n--
+	}
+	bw.writeBits(uint32(n-4), 4)
+	for _, symbol := range codeLengthCodeOrder[:n] {
+		bw.writeBits(uint32(codeLengths[symbol]), 3)
+	}
+	// Every symbol's length is written, so no max_symbol
+	bw.writeBits(0, 1)
+
+	for _, t := range tokens {
+		bw.writeBits(codes[t.symbol], uint(codeLengths[t.symbol]))
+		if t.extraBits > 0 {
+			bw.writeBits(uint32(t.extra), t.extraBits)
+		}
+	}
+}
+
+// huffmanLengths returns Huffman code lengths for counts, no longer than maxLength.
+// Codes that are too long are flattened by raising the smallest counts until they fit.
+func huffmanLengths(counts []int, maxLength int) []int {
+	counts = append([]int(nil), counts...)
+	used := 0
+	for _, count := range counts {
+		if count > 0 {
+			used++
+		}
+	}
+	// A complete code needs at least two symbols
+	for i := range counts {
+		if used >= 2 {
+			break
+		}
+		if counts[i] == 0 {
+			counts[i] = 1
+			used++
+		}
+	}
+
+	for minCount := 1; ; minCount *= 2 {
+		lengths := buildLengths(counts, minCount)
+		longest := 0
+		for _, length := range lengths {
+			longest = max(longest, length)
+		}
+		if longest <= maxLength {
+			return lengths
+		}
+	}
+}
+
+// buildLengths builds a Huffman tree over the used symbols, counting each as at
+// least minCount, and returns every symbol's depth
+func buildLengths(counts []int, minCount int) []int {
+	type node struct {
+		weight int
+		parent int
+	}
+	var nodes []node
+	var leaves []int
+	for symbol, count := range counts {
+		if count > 0 {
+			nodes = append(nodes, node{weight: max(count, minCount), parent: -1})
+			leaves = append(leaves, symbol)
+		}
+	}
+
+	// Merge the two lightest roots until one is left. Alphabets are small enough
+	// for linear scans.
+	roots := make([]int, len(nodes))
+	for i := range roots {
+		roots[i] = i
+	}
+	lightest := func() int {
+		best := 0
+		for i := range roots {
+			if nodes[roots[i]].weight < nodes[roots[best]].weight {
+				best = i
+			}
+		}
+		n := roots[best]
+		roots = append(roots[:best], roots[best+1:]...)
+		return n
+	}
+	for len(roots) > 1 {
+		a, b := lightest(), lightest()
+		nodes = append(nodes, node{weight: nodes[a].weight + nodes[b].weight, parent: -1})
+		nodes[a].parent = len(nodes) - 1
+		nodes[b].parent = len(nodes) - 1
+		roots = append(roots, len(nodes)-1)
+	}
+
+	lengths := make([]int, len(counts))
+	for i, symbol := range leaves {
+		for n := i; nodes[n].parent >= 0; n = nodes[n].parent {
+			lengths[symbol]++
+		}
+	}
+	return lengths
+}
+
+// canonicalCodes assigns canonical codes to lengths, as DEFLATE does, bit-reversed
+func canonicalCodes(lengths []int) []uint32 {
+	var lengthCounts [maxCodeLength + 1]uint32
+	for _, length := range lengths {
+		if length > 0 {
+			lengthCounts[length]++
+		}
+	}
+	var next [maxCodeLength + 1]uint32
+	code := uint32(0)
+	for length := 1; length <= maxCodeLength; length++ {
+		code = (code + lengthCounts[length-1]) << 1
+		next[length] = code
+	}
+
+	codes := make([]uint32, len(lengths))
+	for symbol, length := range lengths {
+		if length == 0 {
+			continue
+		}
+		c := next[length]
+		next[length]++
+		var reversed uint32
+		for i := 0; i < length; i++ {
+			reversed = reversed<<1 | c>>i&1
+		}
+		codes[symbol] = reversed
+	}
+	return codes
+}
@@ -0,0 +1,283 @@
+// Package webp encodes images as lossless WebP (VP8L).
+//
+// The encoder applies the subtract-green and predictor transforms and entropy codes
+// the residuals with one group of prefix codes. It doesn't use backward references or
+// a color cache, so files are larger than those of libwebp, but any WebP decoder can
+// read them.
+package webp
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"io"
+)
+
+// maxDimension is the largest width or height VP8L can describe
+const maxDimension = 1 << 14
+
+// predictorBits sets the predictor tile size to 16x16 pixels
+const predictorBits = 4
+
+const (
+	transformPredictor     = 0
+	transformSubtractGreen = 2
+)
+
+// Predictor modes the encoder chooses from for each tile. VP8L defines 14; these
+// cover smooth gradients and edges without needing the top-right neighbour.
+const (
+	predictLeft            = 1
+	predictTop             = 2
+	predictAverageLeftTop  = 7
+	predictClampedGradient = 12
+)
+
+var predictorModes = []uint32{predictLeft, predictTop, predictAverageLeftTop, predictClampedGradient}
+
+// Encode writes m to w as a lossless WebP image
+func Encode(w io.Writer, m image.Image) error {
+	b := m.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width < 1 || height < 1 || width > maxDimension || height > maxDimension {
+		return errors.New("webp: image must be between 1 and 16384 pixels in each dimension")
+	}
+
+	nrgba, ok := m.(*image.NRGBA)
+	if !ok || nrgba.Rect.Min != (image.Point{}) {
+		nrgba = image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(nrgba, nrgba.Rect, m, b.Min, draw.Src)
+	}
+
+	pixels := make([]uint32, width*height)
+	hasAlpha := false
+	for y := 0; y < height; y++ {
+		row := nrgba.Pix[y*nrgba.Stride:]
+		for x := 0; x < width; x++ {
+			p := row[x*4 : x*4+4]
+			pixels[y*width+x] = uint32(p[3])<<24 | uint32(p[0])<<16 | uint32(p[1])<<8 | uint32(p[2])
+			if p[3] != 0xff {
+				hasAlpha = true
+			}
+		}
+	}
+
+	var bw bitWriter
+	bw.writeBits(0x2f, 8)
+	bw.writeBits(uint32(width-1), 14)
+	bw.writeBits(uint32(height-1), 14)
+	if hasAlpha {
+		bw.writeBits(1, 1)
+	} else {
+		bw.writeBits(0, 1)
+	}
+	bw.writeBits(0, 3)
+
+	// Transforms are undone in reverse order, so they're applied in the order written
+	subtractGreen(pixels)
+	bw.writeBits(1, 1)
+	bw.writeBits(transformSubtractGreen, 2)
+
+	modes, tilesWide := choosePredictors(pixels, width, height)
+	bw.writeBits(1, 1)
+	bw.writeBits(transformPredictor, 2)
+	bw.writeBits(predictorBits-2, 3)
+	writeImage(&bw, modes, false)
+	// No further transforms
+	bw.writeBits(0, 1)
+
+	writeImage(&bw, predict(pixels, width, height, modes, tilesWide), true)
+
+	data := bw.bytes()
+	size := len(data)
+	header := make([]byte, 20)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(12+size+size&1))
+	copy(header[8:12], "WEBP")
+	copy(header[12:16], "VP8L")
+	binary.LittleEndian.PutUint32(header[16:20], uint32(size))
+	if size&1 == 1 {
+		data = append(data, 0)
+	}
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// subtractGreen removes the green component from red and blue, which correlate with it
+func subtractGreen(pixels []uint32) {
+	for i, p := range pixels {
+		g := (p >> 8) & 0xff
+		r := ((p >> 16) - g) & 0xff
+		b := (p - g) & 0xff
+		pixels[i] = p&0xff00ff00 | r<<16 | b
+	}
+}
+
+// choosePredictors picks, for each tile, the mode with the smallest residuals. The
+// returned predictor image carries each tile's mode in its green channel.
+func choosePredictors(pixels []uint32, width, height int) ([]uint32, int) {
+	tile := 1 << predictorBits
+	tilesWide := (width + tile - 1) / tile
+	tilesHigh := (height + tile - 1) / tile
+	modes := make([]uint32, tilesWide*tilesHigh)
+
+	for ty := 0; ty < tilesHigh; ty++ {
+		for tx := 0; tx < tilesWide; tx++ {
+			best, bestCost := predictorModes[0], -1
+			for _, mode := range predictorModes {
+				cost := 0
+				for y := ty * tile; y < min(height, (ty+1)*tile); y++ {
+					for x := tx * tile; x < min(width, (tx+1)*tile); x++ {
+						cost += residualCost(pixels[y*width+x], predictPixel(pixels, width, x, y, mode))
+					}
+				}
+				if bestCost < 0 || cost < bestCost {
+					best, bestCost = mode, cost
+				}
+			}
+			modes[ty*tilesWide+tx] = 0xff000000 | best<<8
+		}
+	}
+	return modes, tilesWide
+}
+
+// predict replaces every pixel with its difference from the prediction
+func predict(pixels []uint32, width, height int, modes []uint32, tilesWide int) []uint32 {
+	residuals := make([]uint32, len(pixels))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			mode := (modes[(y>>predictorBits)*tilesWide+x>>predictorBits] >> 8) & 0xf
+			residuals[y*width+x] = subPixels(pixels[y*width+x], predictPixel(pixels, width, x, y, mode))
+		}
+	}
+	return residuals
+}
+
+// predictPixel is the decoder's prediction for the pixel at x, y. The first row and
+// column use fixed modes regardless of the tile's.
+func predictPixel(pixels []uint32, width, x, y int, mode uint32) uint32 {
+	switch {
+	case x == 0 && y == 0:
+		return 0xff000000
+	case y == 0:
+		return pixels[x-1]
+	case x == 0:
+		return pixels[(y-1)*width]
+	}
+
+	left := pixels[y*width+x-1]
+	top := pixels[(y-1)*width+x]
+	topLeft := pixels[(y-1)*width+x-1]
+	switch mode {
+	case predictLeft:
+		return left
+	case predictTop:
+		return top
+	case predictAverageLeftTop:
+		return average2(left, top)
+	default:
+		return clampedGradient(left, top, topLeft)
+	}
+}
+
+func average2(a, b uint32) uint32 {
+	return (((a ^ b) & 0xfefefefe) >> 1) + (a & b)
+}
+
+// clampedGradient is left + top - topLeft per channel, clamped to 0..255
+func clampedGradient(left, top, topLeft uint32) uint32 {
+	var out uint32
+	for shift := 0; shift < 32; shift += 8 {
+		v := int(left>>shift&0xff) + int(top>>shift&0xff) - int(topLeft>>shift&0xff)
+		out |= uint32(max(0, min(255, v))) << shift
+	}
+	return out
+}
+
+// subPixels subtracts b from a per channel, modulo 256
+func subPixels(a, b uint32) uint32 {
+	alphaGreen := 0x00ff00ff + (a & 0xff00ff00) - (b & 0xff00ff00)
+	redBlue := 0xff00ff00 + (a & 0x00ff00ff) - (b & 0x00ff00ff)
+	return alphaGreen&0xff00ff00 | redBlue&0x00ff00ff
+}
+
+// residualCost estimates how well a prediction compresses by the size of its
+// residuals, treating each channel as a signed byte
+func residualCost(pixel, prediction uint32) int {
+	diff := subPixels(pixel, prediction)
+	cost := 0
+	for shift := 0; shift < 32; shift += 8 {
+		cost += abs(int(int8(diff >> shift)))
+	}
+	return cost
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// writeImage entropy codes argb pixels as literals with one prefix code per channel.
+// Only the main image signals whether it uses meta prefix codes.
+func writeImage(bw *bitWriter, pixels []uint32, main bool) {
+	// No color cache
+	bw.writeBits(0, 1)
+	if main {
+		// No meta prefix codes
+		bw.writeBits(0, 1)
+	}
+
+	var green, red, blue, alpha [256]int
+	for _, p := range pixels {
+		green[p>>8&0xff]++
+		red[p>>16&0xff]++
+		blue[p&0xff]++
+		alpha[p>>24]++
+	}
+
+	// Green also covers the 24 length prefixes of backward references, which are unused
+	greenCodes := writePrefixCode(bw, append(green[:], make([]int, 24)...))
+	redCodes := writePrefixCode(bw, red[:])
+	blueCodes := writePrefixCode(bw, blue[:])
+	alphaCodes := writePrefixCode(bw, alpha[:])
+	// Distance code, unused
+	writePrefixCode(bw, make([]int, 40))
+
+	for _, p := range pixels {
+		greenCodes.write(bw, int(p>>8&0xff))
+		redCodes.write(bw, int(p>>16&0xff))
+		blueCodes.write(bw, int(p&0xff))
+		alphaCodes.write(bw, int(p>>24))
+	}
+}
+
+// bitWriter packs values least significant bit first, as VP8L reads them
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nbits uint
+}
+
+func (w *bitWriter) writeBits(v uint32, n uint) {
+	w.acc |= uint64(v) << w.nbits
+	w.nbits += n
+	for w.nbits >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.nbits -= 8
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	if w.nbits > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc, w.nbits = 0, 0
+	}
+	return w.buf
+}
@@ -0,0 +1,114 @@
+package webp
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+)
+
+func TestEncodeRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	gradient := image.NewNRGBA(image.Rect(0, 0, 70, 45))
+	noise := image.NewNRGBA(image.Rect(0, 0, 33, 17))
+	translucent := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+	for y := 0; y < 45; y++ {
+		for x := 0; x < 70; x++ {
+			gradient.SetNRGBA(x, y, color.NRGBA{uint8(x * 3), uint8(y * 5), uint8(x + y), 0xff})
+			if x < 33 && y < 17 {
+				noise.SetNRGBA(x, y, color.NRGBA{uint8(rng.Intn(256)), uint8(rng.Intn(256)), uint8(rng.Intn(256)), 0xff})
+			}
+			if x < 20 && y < 20 {
+				translucent.SetNRGBA(x, y, color.NRGBA{200, 10, uint8(x * 10), uint8(y * 12)})
+			}
+		}
+	}
+	solid := image.NewNRGBA(image.Rect(0, 0, 300, 2))
+	for i := range solid.Pix {
+		solid.Pix[i] = 0x80
+	}
+	// Encode accepts any image type and bounds
+	offset := image.NewRGBA(image.Rect(10, 10, 27, 31))
+	for y := 10; y < 31; y++ {
+		for x := 10; x < 27; x++ {
+			offset.Set(x, y, color.RGBA{uint8(x), uint8(y), 0, 0xff})
+		}
+	}
+
+	tests := []struct {
+		name string
+		img  image.Image
+	}{
+		{name: "single pixel", img: image.NewNRGBA(image.Rect(0, 0, 1, 1))},
+		{name: "gradient", img: gradient},
+		{name: "noise", img: noise},
+		{name: "translucent", img: translucent},
+		{name: "solid", img: solid},
+		{name: "offset bounds", img: offset},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, Encode(&buf, tt.img))
+
+			decoded, err := webp.Decode(&buf)
+			require.NoError(t, err)
+
+			b := tt.img.Bounds()
+			require.Equal(t, b.Size(), decoded.Bounds().Size())
+			for y := 0; y < b.Dy(); y++ {
+				for x := 0; x < b.Dx(); x++ {
+					want := color.NRGBAModel.Convert(tt.img.At(b.Min.X+x, b.Min.Y+y))
+					got := color.NRGBAModel.Convert(decoded.At(x, y))
+					if want != got {
+						t.Fatalf("pixel %d,%d: got %v, want %v", x, y, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeCompressesGradients(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 200, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 200; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x), uint8(y), uint8(x / 2), 0xff})
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, img))
+	assert.Less(t, buf.Len(), 200*200*3/5)
+}
+
+func TestEncodeRejectsEmptyImage(t *testing.T) {
+	assert.Error(t, Encode(&bytes.Buffer{}, image.NewNRGBA(image.Rect(0, 0, 0, 5))))
+}
+
+func TestHuffmanLengths(t *testing.T) {
+	// Skewed counts would give codes longer than the limit without flattening
+	counts := make([]int, 30)
+	for i := range counts {
+		counts[i] = 1 << i
+	}
+	lengths := huffmanLengths(counts, 7)
+
+	kraft := 0.0
+	for _, length := range lengths {
+		assert.LessOrEqual(t, length, 7)
+		require.Positive(t, length)
+		kraft += 1 / float64(int(1)<<length)
+	}
+	assert.InDelta(t, 1.0, kraft, 1e-9)
+
+	// One used symbol still gets a complete code
+	assert.Equal(t, []int{1, 1, 0}, huffmanLengths([]int{0, 5, 0}, 15))
+}
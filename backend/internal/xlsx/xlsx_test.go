@@ -0,0 +1,81 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnName(t *testing.T) {
+	tests := []struct {
+		index    int
+		expected string
+	}{
+		{0, "A"},
+		{25, "Z"},
+		{26, "AA"},
+		{51, "AZ"},
+		{52, "BA"},
+		{701, "ZZ"},
+		{702, "AAA"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, ColumnName(tt.index))
+	}
+}
+
+func TestSerial(t *testing.T) {
+	tests := []struct {
+		name     string
+		time     time.Time
+		expected float64
+	}{
+		{"excel epoch", time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC), 2},
+		{"midday", time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC), 45366.5},
+		{"wall clock is kept", time.Date(2024, 3, 15, 12, 0, 0, 0, time.FixedZone("IST", 5*3600+1800)), 45366.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, serial(tt.time))
+		})
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "Attendees & Co")
+	assert.NoError(t, err)
+	assert.NoError(t, w.WriteRow("Name", "Count", "When"))
+	assert.NoError(t, w.WriteRow("<Ann>", 3, time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)))
+	assert.Error(t, w.WriteRow(struct{}{}))
+	assert.NoError(t, w.WriteRow("last"))
+	assert.NoError(t, w.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+
+	assert.Contains(t, files, "[Content_Types].xml")
+	assert.Contains(t, files["xl/workbook.xml"], `name="Attendees &amp; Co"`)
+	sheet := files["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<c r="A2" t="inlineStr"><is><t xml:space="preserve">&lt;Ann&gt;</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="B2"><v>3</v></c>`)
+	assert.Contains(t, sheet, `<c r="C2" s="1"><v>45366.5</v></c>`)
+	assert.Contains(t, sheet, `<row r="3"><c r="A3" t="inlineStr">`)
+	assert.True(t, strings.HasSuffix(sheet, sheetEnd))
+}
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"appdirect-workshop-backend/internal/backup"
 	"appdirect-workshop-backend/internal/config"
 	"appdirect-workshop-backend/internal/database"
 	"appdirect-workshop-backend/internal/handlers"
@@ -29,6 +31,14 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Maintenance commands such as "backup" and "restore" run instead of the server
+	if len(os.Args) > 1 {
+		if err := backup.RunCommand(cfg, os.Args[1:]); err != nil {
+			log.Fatalf("%s failed: %v", os.Args[1], err)
+		}
+		return
+	}
+
 	// Initialize Firestore
 	db, err := database.NewFirestoreClient(cfg)
 	if err != nil {
@@ -43,18 +53,28 @@ func main() {
 
 	r := gin.Default()
 
+	// Client IPs come from X-Forwarded-For per TRUSTED_PROXY_HOPS, never from gin's
+	// default of trusting every proxy
+	if err := r.SetTrustedProxies(nil); err != nil {
+		log.Fatalf("Failed to configure trusted proxies: %v", err)
+	}
+	r.Use(middleware.ClientIPMiddleware(cfg.TrustedProxyHops))
+
 	// CORS configuration
 	corsConfig := cors.DefaultConfig()
 	// Remove trailing slash from CORS origin if present
 	corsOrigin := strings.TrimSuffix(cfg.CORSOrigin, "/")
 	corsConfig.AllowOrigins = []string{corsOrigin}
 	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Authorization"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Authorization", middleware.APIKeyHeader, handlers.RegistrationTokenHeader, handlers.SpeakerTokenHeader}
+	corsConfig.ExposeHeaders = []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"}
 	corsConfig.AllowCredentials = true
 	r.Use(cors.New(corsConfig))
 
 	// Initialize handlers
 	h := handlers.New(db, cfg)
+	// Retry domain events that weren't dispatched when they were written
+	go h.RunOutbox(context.Background())
 
 	// Serve static files (frontend build)
 	staticDir := "./static"
@@ -78,32 +98,165 @@ func main() {
 		log.Printf("Warning: Static files directory not found at: %s", staticDir)
 	}
 
-	// Public routes
-	public := r.Group("/api")
-	{
-		public.POST("/register", h.Register)
-		public.GET("/registrations/count", h.GetRegistrationCount)
-		public.GET("/speakers", h.GetSpeakers)
-		public.GET("/sessions", h.GetSessions)
+	// Per-route rate limits keyed by client IP
+	limiter := middleware.NewRateLimiter()
+	// A route name without a budget is a typo; refuse to start rather than serve it
+	// with a zero limit
+	routeLimit := func(route string) config.RateLimit {
+		limit, ok := cfg.RateLimits[route]
+		if !ok {
+			log.Fatalf("No rate limit configured for route %q", route)
+		}
+		return limit
+	}
+	rateLimit := func(route string) gin.HandlerFunc {
+		if !cfg.RateLimitEnabled {
+			return func(c *gin.Context) { c.Next() }
+		}
+		return limiter.Limit(route, routeLimit(route))
 	}
+	// Attendee actions are limited per registration; they follow RequireAttendee
+	attendeeRateLimit := func(route string) gin.HandlerFunc {
+		if !cfg.RateLimitEnabled {
+			return func(c *gin.Context) { c.Next() }
+		}
+		return limiter.LimitBy(route, routeLimit(route), handlers.AttendeeID)
+	}
+
+	// Uploaded images are served like static files: not rate limited, and the same for
+	// every workshop since keys start with the workshop
+	r.GET("/api/images/*key", h.GetImage)
+
+	// Public routes. Unscoped routes serve the default workshop (SUBSCOLLECTION_ID);
+	// /api/w/:slug serves any other.
+	publicRoutes := func(public *gin.RouterGroup) {
+		public.POST("/register", rateLimit("register"), h.Scoped((*handlers.Handlers).Register))
+		public.GET("/register/challenge", rateLimit("default"), h.GetRegistrationChallenge)
+		public.GET("/registrations/count", rateLimit("count"), h.Scoped((*handlers.Handlers).GetRegistrationCount))
+		public.GET("/events", rateLimit("default"), h.Scoped((*handlers.Handlers).StreamEvents))
+		public.GET("/announcements", rateLimit("default"), h.Scoped((*handlers.Handlers).GetAnnouncements))
+		public.GET("/speakers", rateLimit("default"), h.Scoped((*handlers.Handlers).GetSpeakers))
+		public.GET("/sessions", rateLimit("default"), h.Scoped((*handlers.Handlers).GetSessions))
+		public.GET("/workshop", rateLimit("default"), h.Scoped((*handlers.Handlers).GetWorkshopInfo))
+		public.POST("/proposals", rateLimit("proposal"), h.Scoped((*handlers.Handlers).SubmitProposal))
+		public.POST("/sessions/:id/feedback", rateLimit("default"), h.RequireAttendee(), h.Scoped((*handlers.Handlers).SubmitSessionFeedback))
+		public.DELETE("/me/registration", rateLimit("default"), h.RequireAttendee(), h.Scoped((*handlers.Handlers).CancelRegistration))
+		public.GET("/me/feedback", rateLimit("default"), h.RequireAttendee(), h.Scoped((*handlers.Handlers).GetMyFeedback))
+		public.GET("/sessions/:id/questions", rateLimit("default"), h.Scoped((*handlers.Handlers).GetQuestions))
+		public.GET("/sessions/:id/questions/stream", rateLimit("default"), h.Scoped((*handlers.Handlers).StreamQuestions))
+		public.POST("/sessions/:id/questions", rateLimit("default"), h.RequireAttendee(), attendeeRateLimit("question"), h.Scoped((*handlers.Handlers).AskQuestion))
+		public.GET("/sessions/:id/questions/votes", rateLimit("default"), h.RequireAttendee(), h.Scoped((*handlers.Handlers).GetMyVotes))
+		public.POST("/sessions/:id/questions/:questionId/vote", rateLimit("default"), h.RequireAttendee(), attendeeRateLimit("vote"), h.Scoped((*handlers.Handlers).VoteQuestion))
+		public.DELETE("/sessions/:id/questions/:questionId/vote", rateLimit("default"), h.RequireAttendee(), attendeeRateLimit("vote"), h.Scoped((*handlers.Handlers).UnvoteQuestion))
+		public.GET("/speaker/me", rateLimit("default"), h.RequireSpeaker(), h.Scoped((*handlers.Handlers).GetSpeakerProfile))
+		public.PUT("/speaker/me", rateLimit("default"), h.RequireSpeaker(), h.Scoped((*handlers.Handlers).UpdateSpeakerProfile))
+		public.GET("/sessions/:id/polls", rateLimit("default"), h.Scoped((*handlers.Handlers).GetPolls))
+		public.GET("/sessions/:id/polls/stream", rateLimit("default"), h.Scoped((*handlers.Handlers).StreamPolls))
+		public.GET("/sessions/:id/polls/votes", rateLimit("default"), h.RequireAttendee(), h.Scoped((*handlers.Handlers).GetMyPollVotes))
+		public.POST("/sessions/:id/polls/:pollId/vote", rateLimit("default"), h.RequireAttendee(), attendeeRateLimit("vote"), h.Scoped((*handlers.Handlers).VotePoll))
+	}
+	publicRoutes(r.Group("/api", h.ResolveWorkshop(), h.RequirePublicWorkshop()))
+	publicRoutes(r.Group("/api/w/:slug", h.ResolveWorkshop(), h.RequirePublicWorkshop()))
 
 	// Admin routes
 	admin := r.Group("/api/admin")
-	admin.POST("/login", h.AdminLogin)
-	admin.Use(middleware.AuthMiddleware(cfg.AdminPassword))
+	admin.POST("/login", rateLimit("login"), h.AdminLogin)
+	admin.POST("/login/verify", rateLimit("login"), h.VerifyMFA)
+	admin.GET("/sso/login", rateLimit("login"), h.SSOLogin)
+	admin.GET("/sso/callback", rateLimit("login"), h.SSOCallback)
+
+	// Account and workshop management span workshops
+	account := admin.Group("", middleware.AuthMiddleware(cfg.AdminTokenSecret, cfg.OIDCTokenSecret, h))
 	{
-		admin.GET("/attendees", h.GetAttendees)
-		admin.GET("/attendees/:id", h.GetAttendee)
-		admin.GET("/speakers", h.GetSpeakers)
-		admin.POST("/speakers", h.CreateSpeaker)
-		admin.PUT("/speakers/:id", h.UpdateSpeaker)
-		admin.DELETE("/speakers/:id", h.DeleteSpeaker)
-		admin.GET("/sessions", h.GetSessions)
-		admin.POST("/sessions", h.CreateSession)
-		admin.PUT("/sessions/:id", h.UpdateSession)
-		admin.DELETE("/sessions/:id", h.DeleteSession)
-		admin.GET("/analytics/designations", h.GetDesignationBreakdown)
+		account.GET("/mfa", h.GetMFAStatus)
+		account.POST("/mfa/enroll", h.EnrollMFA)
+		account.POST("/mfa/confirm", h.ConfirmMFA)
+		account.DELETE("/mfa", h.DisableMFA)
+		account.GET("/workshops", h.GetWorkshops)
+		account.POST("/workshops", h.CreateWorkshop)
+		account.POST("/workshops/:slug/clone", h.CloneWorkshop)
+		account.POST("/workshops/:slug/archive", h.ArchiveWorkshop)
+		account.POST("/workshops/:slug/unarchive", h.UnarchiveWorkshop)
+		account.PUT("/workshops/:slug/admins", h.UpdateWorkshopAdmins)
+	}
+
+	// Workshop data, for the default workshop under /api/admin and any workshop under
+	// /api/admin/w/:slug. The workshop is resolved first so API keys are looked up in it.
+	adminRoutes := func(admin *gin.RouterGroup) {
+		admin.Use(middleware.AuthMiddleware(cfg.AdminTokenSecret, cfg.OIDCTokenSecret, h), h.RequireWorkshopAccess())
+		admin.GET("/workshop", h.Scoped((*handlers.Handlers).GetWorkshopInfo))
+		admin.PUT("/workshop", h.Scoped((*handlers.Handlers).UpdateWorkshopSettings))
+		admin.PUT("/workshop/lifecycle", h.Scoped((*handlers.Handlers).UpdateWorkshopLifecycle))
+		admin.PUT("/workshop/cfp", h.Scoped((*handlers.Handlers).UpdateCFP))
+		admin.GET("/workshop/speaker-portal", h.Scoped((*handlers.Handlers).GetSpeakerPortal))
+		admin.PUT("/workshop/speaker-portal", h.Scoped((*handlers.Handlers).UpdateSpeakerPortal))
+		admin.GET("/attendees", h.Scoped((*handlers.Handlers).GetAttendees))
+		admin.GET("/attendees/export", h.Scoped((*handlers.Handlers).ExportAttendees))
+		admin.POST("/attendees/import", h.Scoped((*handlers.Handlers).ImportAttendees))
+		admin.GET("/attendees/:id", h.Scoped((*handlers.Handlers).GetAttendee))
+		admin.GET("/speakers", h.Scoped((*handlers.Handlers).GetSpeakers))
+		admin.POST("/speakers", h.Scoped((*handlers.Handlers).CreateSpeaker))
+		admin.PUT("/speakers/:id", h.Scoped((*handlers.Handlers).UpdateSpeaker))
+		admin.DELETE("/speakers/:id", h.Scoped((*handlers.Handlers).DeleteSpeaker))
+		admin.POST("/speakers/:id/image", h.Scoped((*handlers.Handlers).UploadSpeakerImage))
+		admin.DELETE("/speakers/:id/image", h.Scoped((*handlers.Handlers).DeleteSpeakerImage))
+		admin.POST("/speakers/:id/invite", h.Scoped((*handlers.Handlers).InviteSpeaker))
+		admin.GET("/speaker-changes", h.Scoped((*handlers.Handlers).GetSpeakerChanges))
+		admin.POST("/speaker-changes/:id/approve", h.Scoped((*handlers.Handlers).ApproveSpeakerChange))
+		admin.POST("/speaker-changes/:id/reject", h.Scoped((*handlers.Handlers).RejectSpeakerChange))
+		admin.GET("/sessions", h.Scoped((*handlers.Handlers).GetAllSessions))
+		admin.POST("/sessions", h.Scoped((*handlers.Handlers).CreateSession))
+		admin.PUT("/sessions/:id", h.Scoped((*handlers.Handlers).UpdateSession))
+		admin.DELETE("/sessions/:id", h.Scoped((*handlers.Handlers).DeleteSession))
+		admin.GET("/sessions/feedback", h.Scoped((*handlers.Handlers).GetFeedbackSummaries))
+		admin.GET("/sessions/feedback/export", h.Scoped((*handlers.Handlers).ExportSessionFeedback))
+		admin.GET("/sessions/:id/feedback", h.Scoped((*handlers.Handlers).GetSessionFeedback))
+		admin.GET("/sessions/:id/questions", h.Scoped((*handlers.Handlers).GetAllQuestions))
+		admin.PUT("/sessions/:id/questions/:questionId", h.Scoped((*handlers.Handlers).ModerateQuestion))
+		admin.GET("/sessions/:id/polls", h.Scoped((*handlers.Handlers).GetAllPolls))
+		admin.POST("/sessions/:id/polls", h.Scoped((*handlers.Handlers).CreatePoll))
+		admin.PUT("/sessions/:id/polls/:pollId/status", h.Scoped((*handlers.Handlers).UpdatePollStatus))
+		admin.DELETE("/sessions/:id/polls/:pollId", h.Scoped((*handlers.Handlers).DeletePoll))
+		admin.GET("/analytics/designations", h.Scoped((*handlers.Handlers).GetDesignationBreakdown))
+		admin.GET("/analytics/registrations", h.Scoped((*handlers.Handlers).GetRegistrationTimeSeries))
+		admin.GET("/analytics/organizations", h.Scoped((*handlers.Handlers).GetOrganizationBreakdown))
+		admin.GET("/organizations", h.Scoped((*handlers.Handlers).GetOrganizationDomains))
+		admin.PUT("/organizations/:domain", h.Scoped((*handlers.Handlers).SetOrganizationDomain))
+		admin.DELETE("/organizations/:domain", h.Scoped((*handlers.Handlers).DeleteOrganizationDomain))
+		admin.GET("/designations", h.Scoped((*handlers.Handlers).GetDesignations))
+		admin.POST("/designations", h.Scoped((*handlers.Handlers).CreateDesignation))
+		admin.POST("/designations/apply", h.Scoped((*handlers.Handlers).ApplyDesignations))
+		admin.PUT("/designations/:id", h.Scoped((*handlers.Handlers).UpdateDesignation))
+		admin.DELETE("/designations/:id", h.Scoped((*handlers.Handlers).DeleteDesignation))
+		admin.GET("/announcements", h.Scoped((*handlers.Handlers).GetAllAnnouncements))
+		admin.POST("/announcements", h.Scoped((*handlers.Handlers).CreateAnnouncement))
+		admin.PUT("/announcements/:id", h.Scoped((*handlers.Handlers).UpdateAnnouncement))
+		admin.DELETE("/announcements/:id", h.Scoped((*handlers.Handlers).DeleteAnnouncement))
+		admin.GET("/announcements/:id/emails", h.Scoped((*handlers.Handlers).GetAnnouncementEmails))
+		admin.POST("/announcements/:id/emails", h.Scoped((*handlers.Handlers).EmailAnnouncement))
+		admin.GET("/webhooks", h.Scoped((*handlers.Handlers).GetWebhooks))
+		admin.GET("/webhooks/events", h.GetWebhookEvents)
+		admin.POST("/webhooks", h.Scoped((*handlers.Handlers).CreateWebhook))
+		admin.PUT("/webhooks/:id", h.Scoped((*handlers.Handlers).UpdateWebhook))
+		admin.DELETE("/webhooks/:id", h.Scoped((*handlers.Handlers).DeleteWebhook))
+		admin.GET("/webhooks/:id/deliveries", h.Scoped((*handlers.Handlers).GetWebhookDeliveries))
+		admin.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", h.Scoped((*handlers.Handlers).RedeliverWebhook))
+		admin.GET("/proposals", h.Scoped((*handlers.Handlers).GetProposals))
+		admin.GET("/proposals/:id", h.Scoped((*handlers.Handlers).GetProposal))
+		admin.PUT("/proposals/:id/review", h.Scoped((*handlers.Handlers).ReviewProposal))
+		admin.POST("/proposals/:id/accept", h.Scoped((*handlers.Handlers).AcceptProposal))
+		admin.POST("/proposals/:id/reject", h.Scoped((*handlers.Handlers).RejectProposal))
+		admin.GET("/outbox", h.Scoped((*handlers.Handlers).GetOutboxEvents))
+		admin.POST("/outbox/:id/retry", h.Scoped((*handlers.Handlers).RetryOutboxEvent))
+		admin.GET("/api-keys", h.Scoped((*handlers.Handlers).GetAPIKeys))
+		admin.POST("/api-keys", h.Scoped((*handlers.Handlers).CreateAPIKey))
+		admin.DELETE("/api-keys/:id", h.Scoped((*handlers.Handlers).RevokeAPIKey))
+		admin.GET("/audit", h.Scoped((*handlers.Handlers).GetAuditLog))
+		admin.GET("/backup", h.Scoped((*handlers.Handlers).GetBackup))
+		admin.POST("/restore", h.Scoped((*handlers.Handlers).RestoreBackup))
 	}
+	adminRoutes(admin.Group("", h.ResolveWorkshop()))
+	adminRoutes(admin.Group("/w/:slug", h.ResolveWorkshop()))
 
 	// SPA routing fallback - serve index.html for non-API routes
 	// This must be last to catch all non-API routes
@@ -0,0 +1,46 @@
+import apiClient from './client'
+
+interface RegistrationChallenge {
+  challenge: string
+  difficulty: number
+  algorithm: string
+  expiresAt: string
+}
+
+const leadingZeroBits = (bytes: Uint8Array): number => {
+  let count = 0
+  for (const byte of bytes) {
+    if (byte === 0) {
+      count += 8
+      continue
+    }
+    return count + Math.clz32(byte) - 24
+  }
+  return count
+}
+
+// Finds a nonce so that sha256(challenge + ':' + nonce) starts with `difficulty` zero bits
+const solve = async (challenge: string, difficulty: number): Promise<string> => {
+  const encoder = new TextEncoder()
+  for (let i = 0; ; i++) {
+    const nonce = i.toString(16)
+    const digest = await crypto.subtle.digest('SHA-256', encoder.encode(`${challenge}:${nonce}`))
+    if (leadingZeroBits(new Uint8Array(digest)) >= difficulty) {
+      return nonce
+    }
+  }
+}
+
+// Returns the proof-of-work fields for a registration, or nothing when the server has no challenge enabled
+export const solveRegistrationChallenge = async (): Promise<{ challenge?: string; solution?: string }> => {
+  try {
+    const response = await apiClient.get<RegistrationChallenge>('/api/register/challenge')
+    const { challenge, difficulty } = response.data
+    return { challenge, solution: await solve(challenge, difficulty) }
+  } catch (err: any) {
+    if (err.response?.status === 404) {
+      return {}
+    }
+    throw err
+  }
+}
@@ -17,6 +17,16 @@ apiClient.interceptors.request.use((config) => {
   if (token && config.url && config.url.startsWith('/api/admin')) {
     config.headers.Authorization = `Bearer ${token}`
   }
+  // Attendee endpoints authenticate with the token returned on registration
+  const registrationToken = localStorage.getItem('registration_token')
+  if (registrationToken && config.url && !config.url.startsWith('/api/admin')) {
+    config.headers['X-Registration-Token'] = registrationToken
+  }
+  // Speaker portal endpoints authenticate with the token from the speaker's magic link
+  const speakerToken = localStorage.getItem('speaker_token')
+  if (speakerToken && config.url && config.url.endsWith('/speaker/me')) {
+    config.headers['X-Speaker-Token'] = speakerToken
+  }
   return config
 })
 
@@ -1,8 +1,71 @@
 import apiClient from './client'
-import { Registration, Speaker, Session, DesignationBreakdown } from '../types'
+import {
+  Registration,
+  Speaker,
+  Session,
+  DesignationBreakdown,
+  Designation,
+  RegistrationTimeSeries,
+  OrganizationBreakdown,
+  OrganizationDomain,
+  SessionFeedback,
+  FeedbackSummary,
+  SessionFeedbackReport,
+  Question,
+  QuestionStatus,
+  Poll,
+  PollStatus,
+  Announcement,
+  AnnouncementInput,
+  AnnouncementEmail,
+  Webhook,
+  WebhookInput,
+  WebhookDelivery,
+  CFP,
+  Proposal,
+  ProposalInput,
+  ProposalDetails,
+  ProposalReview,
+  ProposalStatus,
+  SpeakerChangeDetails,
+  SpeakerInvite,
+  SpeakerProfile,
+  SpeakerProfileInput,
+  LoginResponse,
+  WorkshopInfo,
+  WorkshopLifecycle,
+  WorkshopState,
+} from '../types'
 
-export const register = async (data: Omit<Registration, 'id' | 'createdAt'>) => {
+export const register = async (
+  data: Omit<Registration, 'id' | 'createdAt'> & { website?: string; challenge?: string; solution?: string }
+) => {
   const response = await apiClient.post('/api/register', data)
+  // The token is only returned once; keep it so the attendee can rate sessions later
+  if (response.data?.token) {
+    localStorage.setItem('registration_token', response.data.token)
+  }
+  return response.data
+}
+
+export const hasRegistrationToken = (): boolean => !!localStorage.getItem('registration_token')
+
+// Withdraws the attendee's registration; their token stops working
+export const cancelRegistration = async (): Promise<void> => {
+  await apiClient.delete('/api/me/registration')
+  localStorage.removeItem('registration_token')
+}
+
+export const submitSessionFeedback = async (
+  sessionId: string,
+  data: { rating: number; comment?: string }
+): Promise<SessionFeedback> => {
+  const response = await apiClient.post(`/api/sessions/${sessionId}/feedback`, data)
+  return response.data
+}
+
+export const getMyFeedback = async (): Promise<SessionFeedback[]> => {
+  const response = await apiClient.get('/api/me/feedback')
   return response.data
 }
 
@@ -11,16 +74,119 @@ export const getRegistrationCount = async (): Promise<number> => {
   return response.data.count
 }
 
-export const adminLogin = async (password: string): Promise<string> => {
+export const getWorkshopInfo = async (): Promise<WorkshopInfo> => {
+  const response = await apiClient.get('/api/workshop')
+  return response.data
+}
+
+export const updateWorkshopSettings = async (
+  data: Omit<WorkshopInfo, 'slug' | 'updatedAt' | 'lifecycle'>
+): Promise<WorkshopInfo> => {
+  const response = await apiClient.put('/api/admin/workshop', data)
+  return response.data
+}
+
+export const updateWorkshopLifecycle = async (data: {
+  status: WorkshopState
+  registrationOpensAt?: string
+  registrationClosesAt?: string
+}): Promise<WorkshopLifecycle> => {
+  const response = await apiClient.put('/api/admin/workshop/lifecycle', data)
+  return response.data
+}
+
+export const adminLogin = async (password: string): Promise<LoginResponse> => {
   const response = await apiClient.post('/api/admin/login', { password })
+  return response.data
+}
+
+export const verifyMfa = async (mfaToken: string, code: string): Promise<string> => {
+  const response = await apiClient.post('/api/admin/login/verify', { mfaToken, code })
   return response.data.token
 }
 
+// Full-page redirect target for single sign-on; the backend redirects back to /admin#token=...
+export const ssoLoginUrl = `${apiClient.defaults.baseURL || ''}/api/admin/sso/login`
+
 export const getAttendees = async (): Promise<Registration[]> => {
   const response = await apiClient.get('/api/admin/attendees')
   return response.data
 }
 
+export interface AttendeeExportOptions {
+  format?: 'csv' | 'xlsx'
+  columns?: string[]
+  tz?: string
+  designation?: string
+  search?: string
+  from?: string
+  to?: string
+}
+
+// Downloads the attendee export as a file; the browser's time zone is used unless one is given
+export const exportAttendees = async (options: AttendeeExportOptions = {}): Promise<void> => {
+  const { columns, ...rest } = options
+  const params = {
+    format: 'csv',
+    tz: Intl.DateTimeFormat().resolvedOptions().timeZone,
+    ...rest,
+    ...(columns ? { columns: columns.join(',') } : {}),
+  }
+  const response = await apiClient.get('/api/admin/attendees/export', { params, responseType: 'blob' })
+
+  const disposition: string = response.headers['content-disposition'] || ''
+  const filename = disposition.match(/filename="([^"]+)"/)?.[1] || `attendees.${params.format}`
+  const url = URL.createObjectURL(response.data)
+  const link = document.createElement('a')
+  link.href = url
+  link.download = filename
+  link.click()
+  URL.revokeObjectURL(url)
+}
+
+export interface ImportRowResult {
+  row: number
+  email?: string
+  status: 'created' | 'valid' | 'invalid' | 'duplicate' | 'failed'
+  errors?: string[]
+  id?: string
+  token?: string
+}
+
+export interface ImportReport {
+  dryRun: boolean
+  total: number
+  created: number
+  valid: number
+  invalid: number
+  duplicates: number
+  failed: number
+  rows: ImportRowResult[]
+}
+
+export const importAttendees = async (file: File, dryRun: boolean): Promise<ImportReport> => {
+  const form = new FormData()
+  form.append('file', file)
+  const response = await apiClient.post('/api/admin/attendees/import', form, { params: { dryRun } })
+  return response.data
+}
+
+// Saves the attendee tokens of an import's created rows, which the server doesn't keep,
+// as a CSV the organizers can use to send them out
+export const downloadImportTokens = (report: ImportReport): void => {
+  const quote = (value: string) => `"${value.replace(/"/g, '""')}"`
+  const lines = report.rows
+    .filter((row) => row.token)
+    .map((row) => [row.email || '', row.token!].map(quote).join(','))
+  const blob = new Blob([['email,token', ...lines].join('\n') + '\n'], { type: 'text/csv' })
+  const url = URL.createObjectURL(blob)
+  const link = document.createElement('a')
+  link.href = url
+  link.download = 'imported-attendee-tokens.csv'
+  link.click()
+  URL.revokeObjectURL(url)
+}
+
 export const getAttendee = async (id: string): Promise<Registration> => {
   const response = await apiClient.get(`/api/admin/attendees/${id}`)
   return response.data
@@ -48,6 +214,72 @@ export const deleteSpeaker = async (id: string): Promise<void> => {
   await apiClient.delete(`/api/admin/speakers/${id}`)
 }
 
+// Stores a JPEG, PNG, GIF or WebP photo of up to 5 MB in every size and format
+export const uploadSpeakerImage = async (id: string, file: File): Promise<Speaker> => {
+  const form = new FormData()
+  form.append('image', file)
+  const response = await apiClient.post(`/api/admin/speakers/${id}/image`, form)
+  return response.data
+}
+
+export const deleteSpeakerImage = async (id: string): Promise<Speaker> => {
+  const response = await apiClient.delete(`/api/admin/speakers/${id}/image`)
+  return response.data
+}
+
+// Emails the speaker a magic link to edit their own profile
+export const inviteSpeaker = async (id: string, email: string): Promise<SpeakerInvite> => {
+  const response = await apiClient.post(`/api/admin/speakers/${id}/invite`, { email })
+  return response.data
+}
+
+export const getSpeakerChanges = async (): Promise<SpeakerChangeDetails[]> => {
+  const response = await apiClient.get('/api/admin/speaker-changes')
+  return response.data
+}
+
+export const approveSpeakerChange = async (speakerId: string): Promise<Speaker> => {
+  const response = await apiClient.post(`/api/admin/speaker-changes/${speakerId}/approve`)
+  return response.data
+}
+
+export const rejectSpeakerChange = async (speakerId: string): Promise<void> => {
+  await apiClient.post(`/api/admin/speaker-changes/${speakerId}/reject`)
+}
+
+export const getSpeakerPortalSettings = async (): Promise<{ requireApproval: boolean }> => {
+  const response = await apiClient.get('/api/admin/workshop/speaker-portal')
+  return response.data
+}
+
+export const updateSpeakerPortalSettings = async (requireApproval: boolean): Promise<{ requireApproval: boolean }> => {
+  const response = await apiClient.put('/api/admin/workshop/speaker-portal', { requireApproval })
+  return response.data
+}
+
+// Speaker portal routes authenticate with the token from the magic link, which the
+// portal keeps in localStorage along with the workshop it belongs to
+const speakerPortalPath = () => {
+  const workshop = localStorage.getItem('speaker_workshop')
+  return workshop ? `/api/w/${encodeURIComponent(workshop)}/speaker/me` : '/api/speaker/me'
+}
+
+export const getSpeakerProfile = async (): Promise<SpeakerProfile> => {
+  const response = await apiClient.get(speakerPortalPath())
+  return response.data
+}
+
+// Returns the updated speaker, or the pending change when edits need approval
+export const updateSpeakerProfile = async (
+  data: SpeakerProfileInput
+): Promise<{ speaker?: Speaker; pendingChange?: SpeakerProfile['pendingChange'] }> => {
+  const response = await apiClient.put(speakerPortalPath(), data)
+  return response.data
+}
+
+// Uploaded images are served by the API, which may be on another origin
+export const mediaUrl = (url: string) => (url.startsWith('/api/') ? `${apiClient.defaults.baseURL || ''}${url}` : url)
+
 export const getSessions = async (): Promise<Session[]> => {
   // Use public endpoint for home screen, admin endpoint for admin dashboard
   const token = localStorage.getItem('admin_token')
@@ -75,3 +307,275 @@ export const getDesignationBreakdown = async (): Promise<DesignationBreakdown[]>
   return response.data
 }
 
+export const getDesignations = async (): Promise<Designation[]> => {
+  const response = await apiClient.get('/api/admin/designations')
+  return response.data
+}
+
+export const createDesignation = async (data: Omit<Designation, 'id'>): Promise<Designation> => {
+  const response = await apiClient.post('/api/admin/designations', data)
+  return response.data
+}
+
+export const updateDesignation = async (id: string, data: Omit<Designation, 'id'>): Promise<Designation> => {
+  const response = await apiClient.put(`/api/admin/designations/${id}`, data)
+  return response.data
+}
+
+export const deleteDesignation = async (id: string): Promise<void> => {
+  await apiClient.delete(`/api/admin/designations/${id}`)
+}
+
+// Re-maps every existing registration through the current taxonomy
+export const applyDesignations = async (): Promise<{ total: number; updated: number; unmapped: number }> => {
+  const response = await apiClient.post('/api/admin/designations/apply')
+  return response.data
+}
+
+// Opens the workshop's live event stream (registrations.count, session.*, speaker.*,
+// announcement.* and resync events); the caller closes it
+export const streamEvents = (): EventSource =>
+  new EventSource(`${apiClient.defaults.baseURL || ''}/api/events`)
+
+export const getAnnouncements = async (): Promise<Announcement[]> => {
+  const response = await apiClient.get('/api/announcements')
+  return response.data
+}
+
+export const getAllAnnouncements = async (): Promise<Announcement[]> => {
+  const response = await apiClient.get('/api/admin/announcements')
+  return response.data
+}
+
+export const createAnnouncement = async (data: AnnouncementInput): Promise<Announcement> => {
+  const response = await apiClient.post('/api/admin/announcements', data)
+  return response.data
+}
+
+export const updateAnnouncement = async (id: string, data: AnnouncementInput): Promise<Announcement> => {
+  const response = await apiClient.put(`/api/admin/announcements/${id}`, data)
+  return response.data
+}
+
+export const deleteAnnouncement = async (id: string): Promise<void> => {
+  await apiClient.delete(`/api/admin/announcements/${id}`)
+}
+
+// Starts emailing the announcement to every registrant; poll getAnnouncementEmails for progress
+export const emailAnnouncement = async (id: string): Promise<AnnouncementEmail> => {
+  const response = await apiClient.post(`/api/admin/announcements/${id}/emails`)
+  return response.data
+}
+
+export const getAnnouncementEmails = async (id: string): Promise<AnnouncementEmail[]> => {
+  const response = await apiClient.get(`/api/admin/announcements/${id}/emails`)
+  return response.data
+}
+
+export const submitProposal = async (
+  data: ProposalInput & { website?: string; challenge?: string; solution?: string }
+): Promise<{ id: string; title: string; status: ProposalStatus }> => {
+  const response = await apiClient.post('/api/proposals', data)
+  return response.data
+}
+
+export const updateCFP = async (data: { open: boolean; closesAt?: string }): Promise<CFP> => {
+  const response = await apiClient.put('/api/admin/workshop/cfp', data)
+  return response.data
+}
+
+export const getProposals = async (status?: ProposalStatus): Promise<Proposal[]> => {
+  const response = await apiClient.get('/api/admin/proposals', { params: status ? { status } : {} })
+  return response.data
+}
+
+export const getProposal = async (id: string): Promise<ProposalDetails> => {
+  const response = await apiClient.get(`/api/admin/proposals/${id}`)
+  return response.data
+}
+
+export const reviewProposal = async (id: string, data: { score: number; comment?: string }): Promise<ProposalReview> => {
+  const response = await apiClient.put(`/api/admin/proposals/${id}/review`, data)
+  return response.data
+}
+
+// Creates the speaker and a draft session for the proposal
+export const acceptProposal = async (
+  id: string
+): Promise<{ proposal: Proposal; speaker: Speaker; session: Session }> => {
+  const response = await apiClient.post(`/api/admin/proposals/${id}/accept`)
+  return response.data
+}
+
+export const rejectProposal = async (id: string): Promise<Proposal> => {
+  const response = await apiClient.post(`/api/admin/proposals/${id}/reject`)
+  return response.data
+}
+
+export const getWebhookEvents = async (): Promise<string[]> => {
+  const response = await apiClient.get('/api/admin/webhooks/events')
+  return response.data
+}
+
+export const getWebhooks = async (): Promise<Webhook[]> => {
+  const response = await apiClient.get('/api/admin/webhooks')
+  return response.data
+}
+
+// The signing secret is only returned here
+export const createWebhook = async (data: WebhookInput): Promise<Webhook & { secret: string }> => {
+  const response = await apiClient.post('/api/admin/webhooks', data)
+  return response.data
+}
+
+export const updateWebhook = async (id: string, data: WebhookInput): Promise<Webhook> => {
+  const response = await apiClient.put(`/api/admin/webhooks/${id}`, data)
+  return response.data
+}
+
+export const deleteWebhook = async (id: string): Promise<void> => {
+  await apiClient.delete(`/api/admin/webhooks/${id}`)
+}
+
+export const getWebhookDeliveries = async (id: string): Promise<WebhookDelivery[]> => {
+  const response = await apiClient.get(`/api/admin/webhooks/${id}/deliveries`)
+  return response.data
+}
+
+export const redeliverWebhook = async (id: string, deliveryId: string): Promise<WebhookDelivery> => {
+  const response = await apiClient.post(`/api/admin/webhooks/${id}/deliveries/${deliveryId}/redeliver`)
+  return response.data
+}
+
+export const getQuestions = async (sessionId: string): Promise<Question[]> => {
+  const response = await apiClient.get(`/api/sessions/${sessionId}/questions`)
+  return response.data
+}
+
+// Opens the live stream of a session's public questions; the caller closes it
+export const streamQuestions = (sessionId: string): EventSource =>
+  new EventSource(`${apiClient.defaults.baseURL || ''}/api/sessions/${sessionId}/questions/stream`)
+
+export const askQuestion = async (sessionId: string, data: { text: string; anonymous: boolean }): Promise<Question> => {
+  const response = await apiClient.post(`/api/sessions/${sessionId}/questions`, data)
+  return response.data
+}
+
+export const getMyVotes = async (sessionId: string): Promise<string[]> => {
+  const response = await apiClient.get(`/api/sessions/${sessionId}/questions/votes`)
+  return response.data
+}
+
+export const voteQuestion = async (sessionId: string, questionId: string, vote: boolean): Promise<Question> => {
+  const url = `/api/sessions/${sessionId}/questions/${questionId}/vote`
+  const response = vote ? await apiClient.post(url) : await apiClient.delete(url)
+  return response.data
+}
+
+export const getAllQuestions = async (sessionId: string): Promise<Question[]> => {
+  const response = await apiClient.get(`/api/admin/sessions/${sessionId}/questions`)
+  return response.data
+}
+
+export const moderateQuestion = async (
+  sessionId: string,
+  questionId: string,
+  status: QuestionStatus
+): Promise<Question> => {
+  const response = await apiClient.put(`/api/admin/sessions/${sessionId}/questions/${questionId}`, { status })
+  return response.data
+}
+
+export const getPolls = async (sessionId: string): Promise<Poll[]> => {
+  const response = await apiClient.get(`/api/sessions/${sessionId}/polls`)
+  return response.data
+}
+
+export const streamPolls = (sessionId: string): EventSource =>
+  new EventSource(`${apiClient.defaults.baseURL || ''}/api/sessions/${sessionId}/polls/stream`)
+
+// Maps poll IDs to the options the attendee chose
+export const getMyPollVotes = async (sessionId: string): Promise<Record<string, string[]>> => {
+  const response = await apiClient.get(`/api/sessions/${sessionId}/polls/votes`)
+  return response.data
+}
+
+export const votePoll = async (sessionId: string, pollId: string, optionIds: string[]): Promise<Poll> => {
+  const response = await apiClient.post(`/api/sessions/${sessionId}/polls/${pollId}/vote`, { optionIds })
+  return response.data
+}
+
+export const getAllPolls = async (sessionId: string): Promise<Poll[]> => {
+  const response = await apiClient.get(`/api/admin/sessions/${sessionId}/polls`)
+  return response.data
+}
+
+export const createPoll = async (
+  sessionId: string,
+  data: { question: string; options: string[]; multiple: boolean }
+): Promise<Poll> => {
+  const response = await apiClient.post(`/api/admin/sessions/${sessionId}/polls`, data)
+  return response.data
+}
+
+export const updatePollStatus = async (sessionId: string, pollId: string, status: PollStatus): Promise<Poll> => {
+  const response = await apiClient.put(`/api/admin/sessions/${sessionId}/polls/${pollId}/status`, { status })
+  return response.data
+}
+
+export const deletePoll = async (sessionId: string, pollId: string): Promise<void> => {
+  await apiClient.delete(`/api/admin/sessions/${sessionId}/polls/${pollId}`)
+}
+
+export const getFeedbackSummaries = async (): Promise<FeedbackSummary[]> => {
+  const response = await apiClient.get('/api/admin/sessions/feedback')
+  return response.data
+}
+
+export const getSessionFeedback = async (sessionId: string): Promise<SessionFeedbackReport> => {
+  const response = await apiClient.get(`/api/admin/sessions/${sessionId}/feedback`)
+  return response.data
+}
+
+export const exportSessionFeedback = async (): Promise<void> => {
+  const params = { tz: Intl.DateTimeFormat().resolvedOptions().timeZone }
+  const response = await apiClient.get('/api/admin/sessions/feedback/export', { params, responseType: 'blob' })
+
+  const disposition: string = response.headers['content-disposition'] || ''
+  const filename = disposition.match(/filename="([^"]+)"/)?.[1] || 'session-feedback.csv'
+  const url = URL.createObjectURL(response.data)
+  const link = document.createElement('a')
+  link.href = url
+  link.download = filename
+  link.click()
+  URL.revokeObjectURL(url)
+}
+
+export const getOrganizationBreakdown = async (): Promise<OrganizationBreakdown> => {
+  const response = await apiClient.get('/api/admin/analytics/organizations')
+  return response.data
+}
+
+export const getOrganizationDomains = async (): Promise<OrganizationDomain[]> => {
+  const response = await apiClient.get('/api/admin/organizations')
+  return response.data
+}
+
+// Maps a domain and its subdomains to an organization
+export const setOrganizationDomain = async (domain: string, organization: string): Promise<OrganizationDomain> => {
+  const response = await apiClient.put(`/api/admin/organizations/${encodeURIComponent(domain)}`, { organization })
+  return response.data
+}
+
+export const deleteOrganizationDomain = async (domain: string): Promise<void> => {
+  await apiClient.delete(`/api/admin/organizations/${encodeURIComponent(domain)}`)
+}
+
+// Sign-ups over time, bucketed in the browser's time zone unless one is given
+export const getRegistrationTimeSeries = async (
+  interval: RegistrationTimeSeries['interval'] = 'day',
+  tz: string = Intl.DateTimeFormat().resolvedOptions().timeZone
+): Promise<RegistrationTimeSeries> => {
+  const response = await apiClient.get('/api/admin/analytics/registrations', { params: { interval, tz } })
+  return response.data
+}
@@ -0,0 +1,75 @@
+import { useEffect, useState } from 'react'
+import { getAnnouncements, streamEvents } from '../api/endpoints'
+import { Announcement, AnnouncementSeverity } from '../types'
+
+const SEVERITY_STYLES: Record<AnnouncementSeverity, string> = {
+  info: 'bg-blue-50 border-blue-300 text-blue-900',
+  warning: 'bg-amber-50 border-amber-400 text-amber-900',
+  critical: 'bg-red-50 border-red-500 text-red-900',
+}
+
+const SEVERITY_ORDER: AnnouncementSeverity[] = ['critical', 'warning', 'info']
+
+const isActive = (announcement: Announcement, now: number) =>
+  Date.parse(announcement.publishAt) <= now &&
+  (!announcement.expiresAt || now < Date.parse(announcement.expiresAt))
+
+// Shows organizer announcements as they are published. Updates include scheduled
+// announcements, so the clock tick reveals and hides them on time.
+const Announcements = () => {
+  const [announcements, setAnnouncements] = useState<Announcement[]>([])
+  const [now, setNow] = useState(Date.now())
+
+  useEffect(() => {
+    const load = () =>
+      getAnnouncements()
+        .then(setAnnouncements)
+        .catch((err) => console.error('Failed to load announcements:', err))
+    load()
+
+    const source = streamEvents()
+    source.addEventListener('announcement.updated', (e) => {
+      const announcement: Announcement = JSON.parse((e as MessageEvent).data)
+      setAnnouncements((current) => [...current.filter((a) => a.id !== announcement.id), announcement])
+    })
+    source.addEventListener('announcement.deleted', (e) => {
+      const { id } = JSON.parse((e as MessageEvent).data)
+      setAnnouncements((current) => current.filter((a) => a.id !== id))
+    })
+    source.addEventListener('resync', load)
+
+    const timer = setInterval(() => setNow(Date.now()), 30000)
+    return () => {
+      source.close()
+      clearInterval(timer)
+    }
+  }, [])
+
+  const visible = announcements
+    .filter((a) => isActive(a, now))
+    .sort(
+      (a, b) =>
+        SEVERITY_ORDER.indexOf(a.severity) - SEVERITY_ORDER.indexOf(b.severity) ||
+        Date.parse(b.publishAt) - Date.parse(a.publishAt)
+    )
+  if (visible.length === 0) return null
+
+  return (
+    <section aria-live="polite" className="px-4 pt-4">
+      <div className="max-w-6xl mx-auto space-y-3">
+        {visible.map((announcement) => (
+          <div
+            key={announcement.id}
+            role={announcement.severity === 'critical' ? 'alert' : 'status'}
+            className={`border-l-4 rounded-lg p-4 ${SEVERITY_STYLES[announcement.severity]}`}
+          >
+            <h3 className="font-semibold">{announcement.title}</h3>
+            <p className="mt-1 text-sm whitespace-pre-line">{announcement.body}</p>
+          </div>
+        ))}
+      </div>
+    </section>
+  )
+}
+
+export default Announcements
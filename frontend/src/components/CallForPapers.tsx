@@ -0,0 +1,187 @@
+import { useState } from 'react'
+import { submitProposal } from '../api/endpoints'
+import { solveRegistrationChallenge } from '../api/challenge'
+import { CFP } from '../types'
+
+interface CallForPapersProps {
+  cfp?: CFP
+}
+
+const EMPTY_PROPOSAL = { title: '', abstract: '', name: '', email: '', bio: '', links: '' }
+
+const inputClass =
+  'w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-blue-500 focus:border-transparent transition-all'
+
+const CallForPapers = ({ cfp }: CallForPapersProps) => {
+  const [formData, setFormData] = useState(EMPTY_PROPOSAL)
+  // Honeypot, as on the registration form
+  const [website, setWebsite] = useState('')
+  const [loading, setLoading] = useState(false)
+  const [submitted, setSubmitted] = useState<string | null>(null)
+  const [error, setError] = useState<string | null>(null)
+
+  if (!cfp?.open) return null
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault()
+    setLoading(true)
+    setError(null)
+
+    try {
+      const proof = await solveRegistrationChallenge()
+      const proposal = await submitProposal({
+        ...formData,
+        links: formData.links.split('\n').map((link) => link.trim()).filter(Boolean),
+        website,
+        ...proof,
+      })
+      setSubmitted(proposal.title)
+      setFormData(EMPTY_PROPOSAL)
+    } catch (err: any) {
+      setError(err.response?.data?.error || 'Failed to submit your proposal. Please try again.')
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  return (
+    <section id="cfp" className="py-16 px-4">
+      <div className="max-w-2xl mx-auto">
+        <div className="bg-white rounded-lg shadow-lg p-8">
+          <div className="text-center mb-8">
+            <h2 className="text-3xl md:text-4xl font-bold mb-4 gradient-text">Call for Papers</h2>
+            <p className="text-gray-600">
+              Have something to share? Propose a talk.
+              {cfp.closesAt && ` Submissions close ${new Date(cfp.closesAt).toLocaleString()}.`}
+            </p>
+          </div>
+
+          {submitted ? (
+            <div className="bg-green-50 border border-green-200 text-green-800 px-4 py-6 rounded-lg text-center">
+              <p>Thanks! We received "{submitted}" and will be in touch after the review.</p>
+              <button onClick={() => setSubmitted(null)} className="mt-3 text-blue-600 hover:text-blue-700 underline">
+                Submit another proposal
+              </button>
+            </div>
+          ) : (
+            <form onSubmit={handleSubmit} className="space-y-6">
+              <div>
+                <label htmlFor="cfp-title" className="block text-sm font-medium text-gray-700 mb-2">
+                  Talk Title *
+                </label>
+                <input
+                  type="text"
+                  id="cfp-title"
+                  required
+                  maxLength={200}
+                  value={formData.title}
+                  onChange={(e) => setFormData({ ...formData, title: e.target.value })}
+                  className={inputClass}
+                />
+              </div>
+
+              <div>
+                <label htmlFor="cfp-abstract" className="block text-sm font-medium text-gray-700 mb-2">
+                  Abstract *
+                </label>
+                <textarea
+                  id="cfp-abstract"
+                  required
+                  rows={5}
+                  maxLength={5000}
+                  value={formData.abstract}
+                  onChange={(e) => setFormData({ ...formData, abstract: e.target.value })}
+                  className={inputClass}
+                />
+              </div>
+
+              <div className="grid grid-cols-1 md:grid-cols-2 gap-4">
+                <div>
+                  <label htmlFor="cfp-name" className="block text-sm font-medium text-gray-700 mb-2">
+                    Your Name *
+                  </label>
+                  <input
+                    type="text"
+                    id="cfp-name"
+                    required
+                    value={formData.name}
+                    onChange={(e) => setFormData({ ...formData, name: e.target.value })}
+                    className={inputClass}
+                  />
+                </div>
+                <div>
+                  <label htmlFor="cfp-email" className="block text-sm font-medium text-gray-700 mb-2">
+                    Email Address *
+                  </label>
+                  <input
+                    type="email"
+                    id="cfp-email"
+                    required
+                    value={formData.email}
+                    onChange={(e) => setFormData({ ...formData, email: e.target.value })}
+                    className={inputClass}
+                  />
+                </div>
+              </div>
+
+              <div>
+                <label htmlFor="cfp-bio" className="block text-sm font-medium text-gray-700 mb-2">
+                  Short Bio *
+                </label>
+                <textarea
+                  id="cfp-bio"
+                  required
+                  rows={3}
+                  maxLength={2000}
+                  value={formData.bio}
+                  onChange={(e) => setFormData({ ...formData, bio: e.target.value })}
+                  className={inputClass}
+                />
+              </div>
+
+              <div>
+                <label htmlFor="cfp-links" className="block text-sm font-medium text-gray-700 mb-2">
+                  Links (one per line, up to 5)
+                </label>
+                <textarea
+                  id="cfp-links"
+                  rows={3}
+                  value={formData.links}
+                  onChange={(e) => setFormData({ ...formData, links: e.target.value })}
+                  className={inputClass}
+                  placeholder="https://www.linkedin.com/in/you"
+                />
+              </div>
+
+              <div className="hidden" aria-hidden="true">
+                <label htmlFor="cfp-website">Website</label>
+                <input
+                  type="text"
+                  id="cfp-website"
+                  tabIndex={-1}
+                  autoComplete="off"
+                  value={website}
+                  onChange={(e) => setWebsite(e.target.value)}
+                />
+              </div>
+
+              {error && (
+                <div className="bg-red-50 border border-red-200 text-red-700 px-4 py-3 rounded-lg">{error}</div>
+              )}
+
+              <button
+                type="submit"
+                disabled={loading}
+                className="w-full gradient-bg text-white py-3 rounded-lg font-semibold hover:opacity-90 transition-all duration-300 disabled:opacity-50 disabled:cursor-not-allowed"
+              >
+                {loading ? 'Submitting...' : 'Submit Proposal'}
+              </button>
+            </form>
+          )}
+        </div>
+      </div>
+    </section>
+  )
+}
+
+export default CallForPapers
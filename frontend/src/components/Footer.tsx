@@ -1,10 +1,17 @@
 import { useState } from 'react'
 import { useNavigate } from 'react-router-dom'
-import { adminLogin } from '../api/endpoints'
+import { adminLogin, verifyMfa, ssoLoginUrl } from '../api/endpoints'
+import { WorkshopInfo } from '../types'
 
-const Footer = () => {
+interface FooterProps {
+  workshop: WorkshopInfo | null
+}
+
+const Footer = ({ workshop }: FooterProps) => {
   const [showLogin, setShowLogin] = useState(false)
   const [password, setPassword] = useState('')
+  const [mfaToken, setMfaToken] = useState<string | null>(null)
+  const [code, setCode] = useState('')
   const [loading, setLoading] = useState(false)
   const [error, setError] = useState<string | null>(null)
   const navigate = useNavigate()
@@ -15,12 +22,28 @@ const Footer = () => {
     setError(null)
 
     try {
-      const token = await adminLogin(password)
+      let token: string | undefined
+      if (mfaToken) {
+        token = await verifyMfa(mfaToken, code)
+      } else {
+        const response = await adminLogin(password)
+        if (response.mfaRequired && response.mfaToken) {
+          // Second step: ask for the authenticator or recovery code
+          setMfaToken(response.mfaToken)
+          return
+        }
+        token = response.token
+      }
+      if (!token) {
+        throw new Error('Login failed')
+      }
       localStorage.setItem('admin_token', token)
       setShowLogin(false)
+      setMfaToken(null)
+      setCode('')
       navigate('/admin')
     } catch (err: any) {
-      setError(err.response?.data?.error || 'Invalid password')
+      setError(err.response?.data?.error || (mfaToken ? 'Invalid code' : 'Invalid password'))
     } finally {
       setLoading(false)
     }
@@ -32,7 +55,8 @@ const Footer = () => {
         <div className="flex flex-col md:flex-row justify-between items-center">
           <div className="mb-4 md:mb-0">
             <p className="text-gray-400">
-              &copy; {new Date().getFullYear()} AppDirect India AI Workshop. All rights reserved.
+              &copy; {new Date().getFullYear()} {workshop?.title || 'AppDirect India AI Workshop'}. All rights
+              reserved.
             </p>
           </div>
           <button
@@ -50,20 +74,38 @@ const Footer = () => {
           <div className="bg-white rounded-lg p-8 max-w-md w-full animate-slide-down">
             <h3 className="text-2xl font-bold text-gray-900 mb-4">Admin Login</h3>
             <form onSubmit={handleLogin} className="space-y-4">
-              <div>
-                <label htmlFor="password" className="block text-sm font-medium text-gray-700 mb-2">
-                  Password
-                </label>
-                <input
-                  type="password"
-                  id="password"
-                  required
-                  value={password}
-                  onChange={(e) => setPassword(e.target.value)}
-                  className="w-full px-4 py-2 border border-gray-300 rounded-lg focus:ring-2 focus:ring-blue-500 focus:border-transparent"
-                  placeholder="Enter admin password"
-                />
-              </div>
+              {mfaToken ? (
+                <div>
+                  <label htmlFor="code" className="block text-sm font-medium text-gray-700 mb-2">
+                    Authentication Code
+                  </label>
+                  <input
+                    type="text"
+                    id="code"
+                    required
+                    autoComplete="one-time-code"
+                    value={code}
+                    onChange={(e) => setCode(e.target.value)}
+                    className="w-full px-4 py-2 border border-gray-300 rounded-lg focus:ring-2 focus:ring-blue-500 focus:border-transparent"
+                    placeholder="6-digit code or recovery code"
+                  />
+                </div>
+              ) : (
+                <div>
+                  <label htmlFor="password" className="block text-sm font-medium text-gray-700 mb-2">
+                    Password
+                  </label>
+                  <input
+                    type="password"
+                    id="password"
+                    required
+                    value={password}
+                    onChange={(e) => setPassword(e.target.value)}
+                    className="w-full px-4 py-2 border border-gray-300 rounded-lg focus:ring-2 focus:ring-blue-500 focus:border-transparent"
+                    placeholder="Enter admin password"
+                  />
+                </div>
+              )}
               {error && (
                 <div className="bg-red-50 border border-red-200 text-red-700 px-4 py-2 rounded-lg text-sm">
                   {error}
@@ -75,13 +117,15 @@ const Footer = () => {
                   disabled={loading}
                   className="flex-1 gradient-bg text-white py-2 rounded-lg font-semibold hover:opacity-90 transition-all disabled:opacity-50"
                 >
-                  {loading ? 'Logging in...' : 'Login'}
+                  {loading ? 'Logging in...' : mfaToken ? 'Verify' : 'Login'}
                 </button>
                 <button
                   type="button"
                   onClick={() => {
                     setShowLogin(false)
                     setPassword('')
+                    setMfaToken(null)
+                    setCode('')
                     setError(null)
                   }}
                   className="px-4 py-2 border border-gray-300 rounded-lg text-gray-700 hover:bg-gray-50 transition-all"
@@ -90,6 +134,14 @@ const Footer = () => {
                 </button>
               </div>
             </form>
+            {!mfaToken && (
+              <a
+                href={ssoLoginUrl}
+                className="block mt-4 text-center text-sm text-blue-600 hover:text-blue-800"
+              >
+                Sign in with company account
+              </a>
+            )}
           </div>
         </div>
       )}
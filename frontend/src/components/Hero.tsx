@@ -1,13 +1,31 @@
-const Hero = () => {
+import { WorkshopInfo } from '../types'
+
+interface HeroProps {
+  workshop: WorkshopInfo | null
+}
+
+const DEFAULT_TAGLINE =
+  'Join us for an exciting day of AI innovation, expert sessions, and networking opportunities. ' +
+  'Discover the future of artificial intelligence and connect with industry leaders.'
+
+const Hero = ({ workshop }: HeroProps) => {
+  const { primaryColor, secondaryColor, logoUrl }: WorkshopInfo['branding'] = workshop?.branding || {}
+  const background =
+    primaryColor && secondaryColor
+      ? { background: `linear-gradient(135deg, ${primaryColor} 0%, ${secondaryColor} 100%)` }
+      : primaryColor
+        ? { background: primaryColor }
+        : undefined
+
   return (
-    <section className="gradient-bg text-white py-20 md:py-32 px-4">
+    <section className="gradient-bg text-white py-20 md:py-32 px-4" style={background}>
       <div className="max-w-6xl mx-auto text-center animate-fade-in">
+        {logoUrl && <img src={logoUrl} alt="" className="h-16 mx-auto mb-6" />}
         <h1 className="text-4xl md:text-6xl font-bold mb-6 animate-slide-down">
-          AppDirect India AI Workshop
+          {workshop?.title || 'AppDirect India AI Workshop'}
         </h1>
         <p className="text-xl md:text-2xl mb-8 text-gray-100 max-w-3xl mx-auto animate-slide-up">
-          Join us for an exciting day of AI innovation, expert sessions, and networking opportunities.
-          Discover the future of artificial intelligence and connect with industry leaders.
+          {workshop?.tagline || DEFAULT_TAGLINE}
         </p>
         <div className="flex flex-col sm:flex-row gap-4 justify-center items-center animate-slide-up">
           <a
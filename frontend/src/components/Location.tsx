@@ -1,4 +1,33 @@
-const Location = () => {
+import { WorkshopInfo } from '../types'
+
+interface LocationProps {
+  workshop: WorkshopInfo | null
+}
+
+const DEFAULT_MAP_EMBED_URL =
+  'https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3930.310034205593!2d73.92600257972352!3d18.515585566381823!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x3bc2c18cf4eaad8d%3A0xc5835f1d9e3a91d3!2sAppDirect%20India!5e0!3m2!1sen!2sin!4v1762854087901!5m2!1sen!2sin'
+
+// Dates are shown in the workshop's own time zone so every visitor sees local event time
+const formatSchedule = (workshop: WorkshopInfo | null): string => {
+  if (!workshop?.startsAt) {
+    return 'To be announced'
+  }
+  const options: Intl.DateTimeFormatOptions = {
+    dateStyle: 'full',
+    timeStyle: 'short',
+    timeZone: workshop.timeZone || undefined,
+  }
+  const starts = new Date(workshop.startsAt).toLocaleString(undefined, options)
+  if (!workshop.endsAt) {
+    return starts
+  }
+  return `${starts} – ${new Date(workshop.endsAt).toLocaleString(undefined, options)}`
+}
+
+const Location = ({ workshop }: LocationProps) => {
+  const venue: WorkshopInfo['venue'] = workshop?.venue || {}
+  const addressLines = (venue.address || 'AppDirect India Office\nPune, Maharashtra, India').split('\n')
+
   return (
     <section id="location" className="py-16 px-4 bg-white">
       <div className="max-w-6xl mx-auto">
@@ -8,10 +37,11 @@ const Location = () => {
         <div className="grid grid-cols-1 lg:grid-cols-2 gap-8">
           <div className="space-y-6">
             <div>
-              <h3 className="text-xl font-bold mb-4 text-gray-900">AppDirect India</h3>
-              <p className="text-gray-600 mb-4">
-                Join us at our office for an immersive AI workshop experience. The event will
-                feature expert sessions, hands-on workshops, and networking opportunities.
+              <h3 className="text-xl font-bold mb-4 text-gray-900">{venue.name || 'AppDirect India'}</h3>
+              <p className="text-gray-600 mb-4 whitespace-pre-line">
+                {workshop?.description ||
+                  'Join us at our office for an immersive AI workshop experience. The event will ' +
+                    'feature expert sessions, hands-on workshops, and networking opportunities.'}
               </p>
             </div>
             <div className="space-y-3">
@@ -38,10 +68,23 @@ const Location = () => {
                 <div>
                   <p className="font-semibold text-gray-900">Address</p>
                   <p className="text-gray-600">
-                    AppDirect India Office
-                    <br />
-                    Pune, Maharashtra, India
+                    {addressLines.map((line, i) => (
+                      <span key={i}>
+                        {i > 0 && <br />}
+                        {line}
+                      </span>
+                    ))}
                   </p>
+                  {venue.mapUrl && (
+                    <a
+                      href={venue.mapUrl}
+                      target="_blank"
+                      rel="noopener noreferrer"
+                      className="text-sm text-blue-600 hover:text-blue-800"
+                    >
+                      Get directions
+                    </a>
+                  )}
                 </div>
               </div>
               <div className="flex items-start gap-3">
@@ -60,14 +103,14 @@ const Location = () => {
                 </svg>
                 <div>
                   <p className="font-semibold text-gray-900">Date & Time</p>
-                  <p className="text-gray-600">To be announced</p>
+                  <p className="text-gray-600">{formatSchedule(workshop)}</p>
                 </div>
               </div>
             </div>
           </div>
           <div className="rounded-lg overflow-hidden shadow-lg">
             <iframe
-              src="https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d3930.310034205593!2d73.92600257972352!3d18.515585566381823!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x3bc2c18cf4eaad8d%3A0xc5835f1d9e3a91d3!2sAppDirect%20India!5e0!3m2!1sen!2sin!4v1762854087901!5m2!1sen!2sin"
+              src={venue.mapEmbedUrl || DEFAULT_MAP_EMBED_URL}
               width="100%"
               height="450"
               style={{ border: 0 }}
@@ -0,0 +1,27 @@
+import { Poll } from '../types'
+
+// PollResults draws a bar per option, as a share of the people who voted
+const PollResults = ({ poll, chosen = [] }: { poll: Poll; chosen?: string[] }) => (
+  <div className="space-y-1">
+    {poll.options.map((option) => {
+      const votes = poll.votes[option.id] || 0
+      const share = poll.voters > 0 ? Math.round((votes / poll.voters) * 100) : 0
+      return (
+        <div key={option.id} className="text-sm">
+          <div className="flex justify-between">
+            <span className={chosen.includes(option.id) ? 'font-semibold' : ''}>{option.text}</span>
+            <span className="text-gray-600">
+              {votes} ({share}%)
+            </span>
+          </div>
+          <div className="bg-gray-100 rounded h-2">
+            <div className="bg-blue-500 h-2 rounded" style={{ width: `${share}%` }}></div>
+          </div>
+        </div>
+      )
+    })}
+    <p className="text-xs text-gray-500">{poll.voters} voted</p>
+  </div>
+)
+
+export default PollResults
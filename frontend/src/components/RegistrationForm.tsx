@@ -1,5 +1,13 @@
 import { useState, useEffect } from 'react'
-import { register, getRegistrationCount } from '../api/endpoints'
+import {
+  register,
+  getRegistrationCount,
+  streamEvents,
+  hasRegistrationToken,
+  cancelRegistration,
+} from '../api/endpoints'
+import { solveRegistrationChallenge } from '../api/challenge'
+import { WorkshopLifecycle } from '../types'
 
 const DESIGNATIONS = [
   'Software Engineer',
@@ -10,16 +18,39 @@ const DESIGNATIONS = [
   'Other',
 ]
 
-const RegistrationForm = () => {
+interface RegistrationFormProps {
+  lifecycle?: WorkshopLifecycle
+}
+
+// Explains why registration is refused, keyed by the backend's error codes
+const closedMessage = (lifecycle: WorkshopLifecycle): string => {
+  switch (lifecycle.code) {
+    case 'REGISTRATION_NOT_OPEN':
+      return lifecycle.registrationOpensAt
+        ? `Registration opens on ${new Date(lifecycle.registrationOpensAt).toLocaleString()}.`
+        : 'Registration is not open yet.'
+    case 'EVENT_IN_PROGRESS':
+      return 'Registration is closed because the event has started.'
+    case 'EVENT_FINISHED':
+      return 'This event has finished. Thank you to everyone who joined!'
+    default:
+      return 'Registration is closed.'
+  }
+}
+
+const RegistrationForm = ({ lifecycle }: RegistrationFormProps) => {
   const [formData, setFormData] = useState({
     name: '',
     email: '',
     designation: DESIGNATIONS[0],
   })
+  // Honeypot: hidden from people, so only bots fill it in
+  const [website, setWebsite] = useState('')
   const [count, setCount] = useState(0)
   const [loading, setLoading] = useState(false)
   const [showSuccess, setShowSuccess] = useState(false)
   const [error, setError] = useState<string | null>(null)
+  const [registered, setRegistered] = useState(hasRegistrationToken())
 
   useEffect(() => {
     const fetchCount = async () => {
@@ -32,25 +63,45 @@ const RegistrationForm = () => {
     }
 
     fetchCount()
-    // Refresh count every 30 seconds
-    const interval = setInterval(fetchCount, 30000)
-    return () => clearInterval(interval)
+    const source = streamEvents()
+    source.addEventListener('registrations.count', (e) => {
+      setCount(JSON.parse((e as MessageEvent).data).count)
+    })
+    source.addEventListener('resync', fetchCount)
+    return () => source.close()
   }, [])
 
+  const handleCancel = async () => {
+    if (!confirm('Cancel your registration? You will need to register again to attend.')) return
+    try {
+      await cancelRegistration()
+      setRegistered(false)
+    } catch (err: any) {
+      setError(err.response?.data?.error || 'Failed to cancel registration. Please try again.')
+    }
+  }
+
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault()
     setLoading(true)
     setError(null)
 
     try {
-      await register(formData)
+      const proof = await solveRegistrationChallenge()
+      await register({ ...formData, website, ...proof })
       setShowSuccess(true)
+      setRegistered(true)
       setFormData({ name: '', email: '', designation: DESIGNATIONS[0] })
       // Refresh count after successful registration
       const registrationCount = await getRegistrationCount()
       setCount(registrationCount)
     } catch (err: any) {
-      setError(err.response?.data?.error || 'Failed to register. Please try again.')
+      const data = err.response?.data
+      if (data?.code) {
+        setError(closedMessage({ ...data, registrationOpensAt: data.opensAt }))
+      } else {
+        setError(data?.error || 'Failed to register. Please try again.')
+      }
     } finally {
       setLoading(false)
     }
@@ -70,73 +121,100 @@ const RegistrationForm = () => {
             </div>
           </div>
 
-          <form onSubmit={handleSubmit} className="space-y-6">
-            <div>
-              <label htmlFor="name" className="block text-sm font-medium text-gray-700 mb-2">
-                Full Name *
-              </label>
-              <input
-                type="text"
-                id="name"
-                required
-                value={formData.name}
-                onChange={(e) => setFormData({ ...formData, name: e.target.value })}
-                className="w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-blue-500 focus:border-transparent transition-all"
-                placeholder="Enter your full name"
-              />
+          {lifecycle && !lifecycle.registrationOpen ? (
+            <div className="bg-gray-50 border border-gray-200 text-gray-700 px-4 py-6 rounded-lg text-center">
+              {closedMessage(lifecycle)}
             </div>
+          ) : (
+            <form onSubmit={handleSubmit} className="space-y-6">
+              <div>
+                <label htmlFor="name" className="block text-sm font-medium text-gray-700 mb-2">
+                  Full Name *
+                </label>
+                <input
+                  type="text"
+                  id="name"
+                  required
+                  value={formData.name}
+                  onChange={(e) => setFormData({ ...formData, name: e.target.value })}
+                  className="w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-blue-500 focus:border-transparent transition-all"
+                  placeholder="Enter your full name"
+                />
+              </div>
 
-            <div>
-              <label htmlFor="email" className="block text-sm font-medium text-gray-700 mb-2">
-                Email Address *
-              </label>
-              <input
-                type="email"
-                id="email"
-                required
-                value={formData.email}
-                onChange={(e) => setFormData({ ...formData, email: e.target.value })}
-                className="w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-blue-500 focus:border-transparent transition-all"
-                placeholder="your.email@example.com"
-              />
-            </div>
+              <div>
+                <label htmlFor="email" className="block text-sm font-medium text-gray-700 mb-2">
+                  Email Address *
+                </label>
+                <input
+                  type="email"
+                  id="email"
+                  required
+                  value={formData.email}
+                  onChange={(e) => setFormData({ ...formData, email: e.target.value })}
+                  className="w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-blue-500 focus:border-transparent transition-all"
+                  placeholder="your.email@example.com"
+                />
+              </div>
 
-            <div>
-              <label
-                htmlFor="designation"
-                className="block text-sm font-medium text-gray-700 mb-2"
-              >
-                Designation *
-              </label>
-              <select
-                id="designation"
-                required
-                value={formData.designation}
-                onChange={(e) => setFormData({ ...formData, designation: e.target.value })}
-                className="w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-blue-500 focus:border-transparent transition-all bg-white"
-              >
-                {DESIGNATIONS.map((designation) => (
-                  <option key={designation} value={designation}>
-                    {designation}
-                  </option>
-                ))}
-              </select>
-            </div>
+              <div>
+                <label
+                  htmlFor="designation"
+                  className="block text-sm font-medium text-gray-700 mb-2"
+                >
+                  Designation *
+                </label>
+                <select
+                  id="designation"
+                  required
+                  value={formData.designation}
+                  onChange={(e) => setFormData({ ...formData, designation: e.target.value })}
+                  className="w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-blue-500 focus:border-transparent transition-all bg-white"
+                >
+                  {DESIGNATIONS.map((designation) => (
+                    <option key={designation} value={designation}>
+                      {designation}
+                    </option>
+                  ))}
+                </select>
+              </div>
 
-            {error && (
-              <div className="bg-red-50 border border-red-200 text-red-700 px-4 py-3 rounded-lg">
-                {error}
+              <div className="hidden" aria-hidden="true">
+                <label htmlFor="website">Website</label>
+                <input
+                  type="text"
+                  id="website"
+                  name="website"
+                  tabIndex={-1}
+                  autoComplete="off"
+                  value={website}
+                  onChange={(e) => setWebsite(e.target.value)}
+                />
               </div>
-            )}
-
-            <button
-              type="submit"
-              disabled={loading}
-              className="w-full gradient-bg text-white py-3 rounded-lg font-semibold hover:opacity-90 transition-all duration-300 transform hover:scale-[1.02] disabled:opacity-50 disabled:cursor-not-allowed"
-            >
-              {loading ? 'Registering...' : 'Register Now'}
-            </button>
-          </form>
+
+              {error && (
+                <div className="bg-red-50 border border-red-200 text-red-700 px-4 py-3 rounded-lg">
+                  {error}
+                </div>
+              )}
+
+              <button
+                type="submit"
+                disabled={loading}
+                className="w-full gradient-bg text-white py-3 rounded-lg font-semibold hover:opacity-90 transition-all duration-300 transform hover:scale-[1.02] disabled:opacity-50 disabled:cursor-not-allowed"
+              >
+                {loading ? 'Registering...' : 'Register Now'}
+              </button>
+            </form>
+          )}
+          {registered && (
+            <p className="mt-4 text-center text-sm text-gray-500">
+              Can't make it?{' '}
+              <button onClick={handleCancel} className="text-red-600 hover:text-red-700 underline">
+                Cancel your registration
+              </button>
+            </p>
+          )}
         </div>
       </div>
 
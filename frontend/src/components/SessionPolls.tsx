@@ -0,0 +1,107 @@
+import { useEffect, useState } from 'react'
+import { getPolls, streamPolls, getMyPollVotes, votePoll, hasRegistrationToken } from '../api/endpoints'
+import { Poll } from '../types'
+import PollResults from './PollResults'
+
+const SessionPolls = ({ sessionId }: { sessionId: string }) => {
+  const [polls, setPolls] = useState<Poll[]>([])
+  const [myVotes, setMyVotes] = useState<Record<string, string[]>>({})
+  const [selection, setSelection] = useState<Record<string, string[]>>({})
+  const [error, setError] = useState<string | null>(null)
+  const canVote = hasRegistrationToken()
+
+  useEffect(() => {
+    getPolls(sessionId)
+      .then(setPolls)
+      .catch((err) => console.error('Failed to load polls:', err))
+    if (canVote) {
+      getMyPollVotes(sessionId)
+        .then(setMyVotes)
+        .catch((err) => console.error('Failed to load poll votes:', err))
+    }
+
+    const source = streamPolls(sessionId)
+    source.addEventListener('resync', () => {
+      getPolls(sessionId).then(setPolls)
+    })
+    source.addEventListener('poll.updated', (e) => {
+      const poll: Poll = JSON.parse((e as MessageEvent).data)
+      setPolls((current) =>
+        current.some((p) => p.id === poll.id) ? current.map((p) => (p.id === poll.id ? poll : p)) : [...current, poll]
+      )
+    })
+    source.addEventListener('poll.removed', (e) => {
+      const { id } = JSON.parse((e as MessageEvent).data)
+      setPolls((current) => current.filter((p) => p.id !== id))
+    })
+    return () => source.close()
+  }, [sessionId, canVote])
+
+  const toggleOption = (poll: Poll, optionId: string) => {
+    const current = selection[poll.id] || []
+    const next = poll.multiple
+      ? current.includes(optionId)
+        ? current.filter((id) => id !== optionId)
+        : [...current, optionId]
+      : [optionId]
+    setSelection({ ...selection, [poll.id]: next })
+  }
+
+  const handleVote = async (poll: Poll) => {
+    const optionIds = selection[poll.id] || []
+    try {
+      const updated = await votePoll(sessionId, poll.id, optionIds)
+      setPolls((current) => current.map((p) => (p.id === updated.id ? updated : p)))
+      setMyVotes({ ...myVotes, [poll.id]: optionIds })
+      setError(null)
+    } catch (err: any) {
+      setError(err.response?.data?.error || 'Failed to record vote')
+    }
+  }
+
+  if (polls.length === 0) return null
+
+  return (
+    <div className="space-y-4">
+      {polls.map((poll) => {
+        const voted = myVotes[poll.id]
+        const canVoteHere = canVote && poll.status === 'open' && !voted
+        return (
+          <div key={poll.id} className="space-y-2">
+            <p className="font-medium text-gray-900">
+              {poll.question}
+              {poll.status === 'closed' && <span className="text-xs text-gray-500"> (closed)</span>}
+            </p>
+            {canVoteHere ? (
+              <>
+                {poll.options.map((option) => (
+                  <label key={option.id} className="flex items-center gap-2 text-sm">
+                    <input
+                      type={poll.multiple ? 'checkbox' : 'radio'}
+                      name={`poll-${poll.id}`}
+                      checked={(selection[poll.id] || []).includes(option.id)}
+                      onChange={() => toggleOption(poll, option.id)}
+                    />
+                    {option.text}
+                  </label>
+                ))}
+                <button
+                  onClick={() => handleVote(poll)}
+                  disabled={!(selection[poll.id] || []).length}
+                  className="text-sm font-semibold text-blue-600 hover:text-blue-700 disabled:text-gray-400"
+                >
+                  Vote
+                </button>
+              </>
+            ) : (
+              <PollResults poll={poll} chosen={voted} />
+            )}
+          </div>
+        )
+      })}
+      {error && <p className="text-xs text-red-600">{error}</p>}
+    </div>
+  )
+}
+
+export default SessionPolls
@@ -0,0 +1,135 @@
+import { useEffect, useState } from 'react'
+import {
+  getQuestions,
+  streamQuestions,
+  askQuestion,
+  getMyVotes,
+  voteQuestion,
+  hasRegistrationToken,
+} from '../api/endpoints'
+import { Question } from '../types'
+
+// Same order as the server: unanswered first, then by votes and age
+const sortQuestions = (questions: Question[]) =>
+  [...questions].sort((a, b) => {
+    const answered = Number(a.status === 'answered') - Number(b.status === 'answered')
+    if (answered !== 0) return answered
+    if (a.votes !== b.votes) return b.votes - a.votes
+    return a.createdAt.localeCompare(b.createdAt)
+  })
+
+const SessionQA = ({ sessionId }: { sessionId: string }) => {
+  const [questions, setQuestions] = useState<Question[]>([])
+  const [votes, setVotes] = useState<Set<string>>(new Set())
+  const [text, setText] = useState('')
+  const [anonymous, setAnonymous] = useState(false)
+  const [message, setMessage] = useState<string | null>(null)
+  const canAsk = hasRegistrationToken()
+
+  useEffect(() => {
+    getQuestions(sessionId)
+      .then((data) => setQuestions(sortQuestions(data)))
+      .catch((err) => console.error('Failed to load questions:', err))
+    if (canAsk) {
+      getMyVotes(sessionId)
+        .then((ids) => setVotes(new Set(ids)))
+        .catch((err) => console.error('Failed to load votes:', err))
+    }
+
+    const source = streamQuestions(sessionId)
+    source.addEventListener('resync', () => {
+      getQuestions(sessionId).then((data) => setQuestions(sortQuestions(data)))
+    })
+    source.addEventListener('question.updated', (e) => {
+      const question: Question = JSON.parse((e as MessageEvent).data)
+      setQuestions((current) => sortQuestions([...current.filter((q) => q.id !== question.id), question]))
+    })
+    source.addEventListener('question.removed', (e) => {
+      const { id } = JSON.parse((e as MessageEvent).data)
+      setQuestions((current) => current.filter((q) => q.id !== id))
+    })
+    return () => source.close()
+  }, [sessionId, canAsk])
+
+  const handleAsk = async (e: React.FormEvent) => {
+    e.preventDefault()
+    try {
+      await askQuestion(sessionId, { text, anonymous })
+      setText('')
+      setMessage('Thanks! Your question will appear once a moderator approves it.')
+    } catch (err: any) {
+      setMessage(err.response?.data?.error || 'Failed to submit question')
+    }
+  }
+
+  const handleVote = async (question: Question) => {
+    const voted = votes.has(question.id)
+    try {
+      await voteQuestion(sessionId, question.id, !voted)
+      const next = new Set(votes)
+      if (voted) next.delete(question.id)
+      else next.add(question.id)
+      setVotes(next)
+    } catch (err: any) {
+      setMessage(err.response?.data?.error || 'Failed to record vote')
+    }
+  }
+
+  return (
+    <div className="space-y-3">
+      {questions.length === 0 ? (
+        <p className="text-sm text-gray-500">No questions yet.</p>
+      ) : (
+        <ul className="space-y-2 max-h-64 overflow-y-auto">
+          {questions.map((question) => (
+            <li key={question.id} className="flex gap-3 items-start text-sm">
+              <button
+                onClick={() => handleVote(question)}
+                disabled={!canAsk || question.status === 'answered'}
+                aria-label="Upvote"
+                className={`flex flex-col items-center min-w-[2.5rem] rounded border px-1 ${
+                  votes.has(question.id) ? 'border-blue-600 text-blue-600' : 'border-gray-200 text-gray-600'
+                }`}
+              >
+                <span>▲</span>
+                <span>{question.votes}</span>
+              </button>
+              <div>
+                <p className={question.status === 'answered' ? 'text-gray-500' : 'text-gray-900'}>{question.text}</p>
+                <p className="text-xs text-gray-500">
+                  {question.authorName || 'Anonymous'}
+                  {question.status === 'answered' && ' • Answered'}
+                </p>
+              </div>
+            </li>
+          ))}
+        </ul>
+      )}
+      {canAsk && (
+        <form onSubmit={handleAsk} className="space-y-2">
+          <textarea
+            required
+            maxLength={500}
+            value={text}
+            onChange={(e) => setText(e.target.value)}
+            placeholder="Ask a question"
+            className="w-full px-3 py-2 border rounded-lg text-sm"
+            rows={2}
+          />
+          <div className="flex justify-between items-center">
+            <label className="text-xs text-gray-600 flex items-center gap-1">
+              <input type="checkbox" checked={anonymous} onChange={(e) => setAnonymous(e.target.checked)} />
+              Ask anonymously
+            </label>
+            <button type="submit" className="text-sm font-semibold text-blue-600 hover:text-blue-700">
+              Ask
+            </button>
+          </div>
+        </form>
+      )}
+      {message && <p className="text-xs text-gray-600">{message}</p>}
+    </div>
+  )
+}
+
+export default SessionQA
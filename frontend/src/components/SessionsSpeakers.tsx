@@ -1,11 +1,94 @@
 import { useEffect, useState } from 'react'
-import { getSessions, getSpeakers } from '../api/endpoints'
+import {
+  getSessions,
+  getSpeakers,
+  streamEvents,
+  getMyFeedback,
+  submitSessionFeedback,
+  hasRegistrationToken,
+} from '../api/endpoints'
 import { SessionWithSpeakers } from '../types'
+import SessionQA from './SessionQA'
+import SessionPolls from './SessionPolls'
+import SpeakerImage from './SpeakerImage'
+
+// SessionFeedbackForm lets a registered attendee rate a session once
+const SessionFeedbackForm = ({
+  sessionId,
+  rated,
+  onRated,
+}: {
+  sessionId: string
+  rated?: number
+  onRated: (rating: number) => void
+}) => {
+  const [rating, setRating] = useState(0)
+  const [comment, setComment] = useState('')
+  const [error, setError] = useState<string | null>(null)
+
+  if (rated) {
+    return <p className="text-sm text-gray-600">You rated this session {rated}/5. Thanks for the feedback!</p>
+  }
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault()
+    try {
+      await submitSessionFeedback(sessionId, { rating, comment })
+      onRated(rating)
+    } catch (err: any) {
+      setError(err.response?.data?.error || 'Failed to submit feedback')
+    }
+  }
+
+  return (
+    <form onSubmit={handleSubmit} className="space-y-2">
+      <div className="flex gap-1" role="radiogroup" aria-label="Rating">
+        {[1, 2, 3, 4, 5].map((value) => (
+          <button
+            key={value}
+            type="button"
+            aria-label={`${value} star${value > 1 ? 's' : ''}`}
+            onClick={() => setRating(value)}
+            className={`text-2xl ${value <= rating ? 'text-yellow-400' : 'text-gray-300'}`}
+          >
+            ★
+          </button>
+        ))}
+      </div>
+      <textarea
+        value={comment}
+        maxLength={2000}
+        onChange={(e) => setComment(e.target.value)}
+        placeholder="Comments (optional)"
+        className="w-full px-3 py-2 border rounded-lg text-sm"
+        rows={2}
+      />
+      {error && <p className="text-sm text-red-600">{error}</p>}
+      <button
+        type="submit"
+        disabled={rating === 0}
+        className="text-sm font-semibold text-blue-600 hover:text-blue-700 disabled:text-gray-400"
+      >
+        Submit feedback
+      </button>
+    </form>
+  )
+}
 
 const SessionsSpeakers = () => {
   const [sessions, setSessions] = useState<SessionWithSpeakers[]>([])
   const [loading, setLoading] = useState(true)
   const [error, setError] = useState<string | null>(null)
+  const [ratings, setRatings] = useState<Record<string, number>>({})
+  const [openQA, setOpenQA] = useState<string | null>(null)
+  const canRate = hasRegistrationToken()
+
+  useEffect(() => {
+    if (!canRate) return
+    getMyFeedback()
+      .then((feedback) => setRatings(Object.fromEntries(feedback.map((f) => [f.sessionId, f.rating]))))
+      .catch((err) => console.error('Failed to load your feedback:', err))
+  }, [canRate])
 
   useEffect(() => {
     const fetchData = async () => {
@@ -15,13 +98,15 @@ const SessionsSpeakers = () => {
           getSpeakers(),
         ])
 
-        // Map sessions with their speakers
-        const sessionsWithSpeakers: SessionWithSpeakers[] = sessionsData.map((session) => ({
-          ...session,
-          speakers: speakersData.filter((speaker) =>
-            session.speakerIds.includes(speaker.id || '')
-          ),
-        }))
+        // Signed-in admins get drafts too; keep the public agenda the same for them
+        const sessionsWithSpeakers: SessionWithSpeakers[] = sessionsData
+          .filter((session) => session.status !== 'draft')
+          .map((session) => ({
+            ...session,
+            speakers: speakersData.filter((speaker) =>
+              session.speakerIds.includes(speaker.id || '')
+            ),
+          }))
 
         setSessions(sessionsWithSpeakers)
         setLoading(false)
@@ -32,6 +117,12 @@ const SessionsSpeakers = () => {
     }
 
     fetchData()
+    // Any session or speaker change can move speakers between sessions, so reload both
+    const source = streamEvents()
+    for (const type of ['session.updated', 'session.deleted', 'speaker.updated', 'speaker.deleted', 'resync']) {
+      source.addEventListener(type, fetchData)
+    }
+    return () => source.close()
   }, [])
 
   if (loading) {
@@ -87,13 +178,7 @@ const SessionsSpeakers = () => {
                     <div className="space-y-2">
                       {session.speakers.map((speaker) => (
                         <div key={speaker.id} className="flex items-center gap-2">
-                          {speaker.imageUrl && (
-                            <img
-                              src={speaker.imageUrl}
-                              alt={speaker.name}
-                              className="w-10 h-10 rounded-full object-cover"
-                            />
-                          )}
+                          <SpeakerImage speaker={speaker} sizes="40px" className="w-10 h-10 rounded-full object-cover" />
                           <div>
                             <p className="font-medium text-gray-900">{speaker.name}</p>
                             {speaker.bio && (
@@ -105,6 +190,31 @@ const SessionsSpeakers = () => {
                     </div>
                   </div>
                 )}
+                {session.id && (
+                  <div className="border-t pt-4 mt-4">
+                    <button
+                      onClick={() => setOpenQA(openQA === session.id ? null : session.id!)}
+                      className="text-sm font-semibold text-blue-600 hover:text-blue-700 mb-2"
+                    >
+                      {openQA === session.id ? 'Hide polls & Q&A' : 'Live polls & Q&A'}
+                    </button>
+                    {openQA === session.id && (
+                      <div className="space-y-4">
+                        <SessionPolls sessionId={session.id} />
+                        <SessionQA sessionId={session.id} />
+                      </div>
+                    )}
+                  </div>
+                )}
+                {canRate && session.id && (
+                  <div className="border-t pt-4 mt-4">
+                    <SessionFeedbackForm
+                      sessionId={session.id}
+                      rated={ratings[session.id]}
+                      onRated={(rating) => setRatings({ ...ratings, [session.id!]: rating })}
+                    />
+                  </div>
+                )}
               </div>
             ))}
           </div>
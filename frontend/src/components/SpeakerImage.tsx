@@ -0,0 +1,30 @@
+import { mediaUrl } from '../api/endpoints'
+import { ImageVariant, Speaker } from '../types'
+
+const srcSet = (variants: ImageVariant[]) => variants.map((v) => `${mediaUrl(v.url)} ${v.width}w`).join(', ')
+
+// SpeakerImage lets the browser pick the smallest uploaded variant for the rendered
+// size, as WebP where supported. Pasted image URLs are shown as they are.
+const SpeakerImage = ({ speaker, sizes, className }: { speaker: Speaker; sizes: string; className?: string }) => {
+  if (!speaker.imageUrl) return null
+
+  const images = speaker.images || []
+  const webp = images.filter((v) => v.format === 'webp')
+  const fallback = images.filter((v) => v.format !== 'webp')
+
+  return (
+    <picture>
+      {webp.length > 0 && <source type="image/webp" srcSet={srcSet(webp)} sizes={sizes} />}
+      <img
+        src={mediaUrl(speaker.imageUrl)}
+        srcSet={fallback.length > 0 ? srcSet(fallback) : undefined}
+        sizes={fallback.length > 0 ? sizes : undefined}
+        alt={speaker.name}
+        loading="lazy"
+        className={className}
+      />
+    </picture>
+  )
+}
+
+export default SpeakerImage
@@ -2,34 +2,180 @@ import { useEffect, useState } from 'react'
 import { useNavigate } from 'react-router-dom'
 import {
   getAttendees,
+  exportAttendees,
+  importAttendees,
+  ImportReport,
+  downloadImportTokens,
   getSpeakers,
   getSessions,
   getDesignationBreakdown,
+  getRegistrationTimeSeries,
+  getDesignations,
+  createDesignation,
+  deleteDesignation,
+  applyDesignations,
+  getOrganizationBreakdown,
+  getOrganizationDomains,
+  setOrganizationDomain,
+  deleteOrganizationDomain,
+  getFeedbackSummaries,
+  getSessionFeedback,
+  exportSessionFeedback,
+  getAllQuestions,
+  moderateQuestion,
+  getAllPolls,
+  createPoll,
+  updatePollStatus,
+  deletePoll,
+  getAllAnnouncements,
+  createAnnouncement,
+  updateAnnouncement,
+  deleteAnnouncement,
+  emailAnnouncement,
+  getAnnouncementEmails,
+  getWebhooks,
+  getWebhookEvents,
+  createWebhook,
+  updateWebhook,
+  deleteWebhook,
+  getWebhookDeliveries,
+  redeliverWebhook,
+  getWorkshopInfo,
+  updateCFP,
+  getProposals,
+  getProposal,
+  reviewProposal,
+  acceptProposal,
+  rejectProposal,
   createSpeaker,
   updateSpeaker,
   deleteSpeaker,
+  uploadSpeakerImage,
+  deleteSpeakerImage,
+  inviteSpeaker,
+  getSpeakerChanges,
+  approveSpeakerChange,
+  rejectSpeakerChange,
+  getSpeakerPortalSettings,
+  updateSpeakerPortalSettings,
   createSession,
   updateSession,
   deleteSession,
 } from '../api/endpoints'
-import { Registration, Speaker, Session, DesignationBreakdown } from '../types'
-import { PieChart, Pie, Cell, ResponsiveContainer, Legend, Tooltip } from 'recharts'
+import {
+  Registration,
+  Speaker,
+  Session,
+  DesignationBreakdown,
+  Designation,
+  RegistrationTimeSeries,
+  OrganizationBreakdown,
+  OrganizationDomain,
+  FeedbackSummary,
+  SessionFeedbackReport,
+  Question,
+  QuestionStatus,
+  Poll,
+  PollStatus,
+  Announcement,
+  AnnouncementEmail,
+  AnnouncementSeverity,
+  Webhook,
+  WebhookDelivery,
+  CFP,
+  Proposal,
+  ProposalDetails,
+  ProposalStatus,
+  SpeakerChangeDetails,
+} from '../types'
+import PollResults from '../components/PollResults'
+import SpeakerImage from '../components/SpeakerImage'
+import {
+  PieChart,
+  Pie,
+  Cell,
+  ResponsiveContainer,
+  Legend,
+  Tooltip,
+  ComposedChart,
+  Bar,
+  Line,
+  XAxis,
+  YAxis,
+  CartesianGrid,
+} from 'recharts'
+
+const MODERATION_ACTIONS: { status: QuestionStatus; label: string }[] = [
+  { status: 'approved', label: 'Approve' },
+  { status: 'answered', label: 'Mark answered' },
+  { status: 'hidden', label: 'Hide' },
+]
+
+// datetime-local inputs work in local time without a zone
+const toLocalInput = (iso?: string) => {
+  if (!iso) return ''
+  const date = new Date(iso)
+  return new Date(date.getTime() - date.getTimezoneOffset() * 60000).toISOString().slice(0, 16)
+}
+
+const fromLocalInput = (value: string) => (value ? new Date(value).toISOString() : undefined)
+
+const EMPTY_REVIEW = { score: 3, comment: '' }
+const EMPTY_WEBHOOK = { url: '', description: '', events: [] as string[] }
+const EMPTY_ANNOUNCEMENT = { title: '', body: '', severity: 'info' as AnnouncementSeverity, publishAt: '', expiresAt: '' }
 
 const COLORS = ['#3b82f6', '#8b5cf6', '#ec4899', '#f59e0b', '#10b981', '#ef4444']
 
 const AdminDashboard = () => {
   const navigate = useNavigate()
-  const [activeTab, setActiveTab] = useState<'attendees' | 'speakers' | 'sessions' | 'analytics'>(
+  const [activeTab, setActiveTab] = useState<
+    'attendees' | 'speakers' | 'sessions' | 'proposals' | 'announcements' | 'webhooks' | 'analytics'
+  >(
     'attendees'
   )
   const [attendees, setAttendees] = useState<Registration[]>([])
   const [speakers, setSpeakers] = useState<Speaker[]>([])
   const [sessions, setSessions] = useState<Session[]>([])
   const [breakdown, setBreakdown] = useState<DesignationBreakdown[]>([])
+  const [seriesInterval, setSeriesInterval] = useState<RegistrationTimeSeries['interval']>('day')
+  const [timeSeries, setTimeSeries] = useState<RegistrationTimeSeries | null>(null)
+  const [taxonomy, setTaxonomy] = useState<Designation[]>([])
+  const [designationForm, setDesignationForm] = useState({ name: '', synonyms: '', patterns: '' })
+  const [organizations, setOrganizations] = useState<OrganizationBreakdown | null>(null)
+  const [organizationDomains, setOrganizationDomains] = useState<OrganizationDomain[]>([])
+  const [domainForm, setDomainForm] = useState({ domain: '', organization: '' })
+  const [feedbackSummaries, setFeedbackSummaries] = useState<Record<string, FeedbackSummary>>({})
+  const [feedbackReport, setFeedbackReport] = useState<SessionFeedbackReport | null>(null)
+  const [moderatedSession, setModeratedSession] = useState<string | null>(null)
+  const [moderationQueue, setModerationQueue] = useState<Question[]>([])
+  const [pollSession, setPollSession] = useState<string | null>(null)
+  const [sessionPolls, setSessionPolls] = useState<Poll[]>([])
+  const [pollForm, setPollForm] = useState({ question: '', options: '', multiple: false })
+  const [announcements, setAnnouncements] = useState<Announcement[]>([])
+  const [announcementForm, setAnnouncementForm] = useState(EMPTY_ANNOUNCEMENT)
+  const [editingAnnouncement, setEditingAnnouncement] = useState<string | null>(null)
+  const [emailJobs, setEmailJobs] = useState<Record<string, AnnouncementEmail>>({})
+  const [webhooks, setWebhooks] = useState<Webhook[]>([])
+  const [webhookEvents, setWebhookEvents] = useState<string[]>([])
+  const [webhookForm, setWebhookForm] = useState(EMPTY_WEBHOOK)
+  const [webhookSecret, setWebhookSecret] = useState<string | null>(null)
+  const [selectedWebhook, setSelectedWebhook] = useState<string | null>(null)
+  const [deliveries, setDeliveries] = useState<WebhookDelivery[]>([])
+  const [cfp, setCFP] = useState<CFP>({ open: false })
+  const [cfpClosesAt, setCFPClosesAt] = useState('')
+  const [proposals, setProposals] = useState<Proposal[]>([])
+  const [proposalFilter, setProposalFilter] = useState<ProposalStatus | ''>('submitted')
+  const [selectedProposal, setSelectedProposal] = useState<ProposalDetails | null>(null)
+  const [reviewForm, setReviewForm] = useState(EMPTY_REVIEW)
   const [loading, setLoading] = useState(true)
+  const [importFile, setImportFile] = useState<File | null>(null)
+  const [importReport, setImportReport] = useState<ImportReport | null>(null)
   const [showSpeakerModal, setShowSpeakerModal] = useState(false)
   const [showSessionModal, setShowSessionModal] = useState(false)
   const [editingSpeaker, setEditingSpeaker] = useState<Speaker | null>(null)
+  const [uploadingImage, setUploadingImage] = useState(false)
+  const [speakerChanges, setSpeakerChanges] = useState<SpeakerChangeDetails[]>([])
+  const [speakerEditsNeedApproval, setSpeakerEditsNeedApproval] = useState(false)
   const [editingSession, setEditingSession] = useState<Session | null>(null)
   const [speakerForm, setSpeakerForm] = useState<Omit<Speaker, 'id'>>({
     name: '',
@@ -44,9 +190,17 @@ const AdminDashboard = () => {
     time: '',
     duration: '',
     speakerIds: [],
+    status: undefined,
   })
 
   useEffect(() => {
+    // Single sign-on hands the token over in the URL fragment
+    const hashToken = new URLSearchParams(window.location.hash.slice(1)).get('token')
+    if (hashToken) {
+      localStorage.setItem('admin_token', hashToken)
+      window.history.replaceState(null, '', window.location.pathname)
+    }
+
     const token = localStorage.getItem('admin_token')
     if (!token) {
       navigate('/')
@@ -80,6 +234,492 @@ const AdminDashboard = () => {
     }
   }
 
+  useEffect(() => {
+    if (activeTab !== 'analytics') return
+    getRegistrationTimeSeries(seriesInterval)
+      .then(setTimeSeries)
+      .catch((err) => console.error('Failed to load registration time series:', err))
+  }, [activeTab, seriesInterval])
+
+  useEffect(() => {
+    if (activeTab !== 'analytics') return
+    getDesignations()
+      .then(setTaxonomy)
+      .catch((err) => console.error('Failed to load designation taxonomy:', err))
+  }, [activeTab])
+
+  useEffect(() => {
+    if (activeTab !== 'sessions') return
+    getFeedbackSummaries()
+      .then((summaries) => setFeedbackSummaries(Object.fromEntries(summaries.map((s) => [s.sessionId, s]))))
+      .catch((err) => console.error('Failed to load session feedback:', err))
+  }, [activeTab])
+
+  const toggleFeedbackReport = async (sessionId: string) => {
+    if (feedbackReport?.sessionId === sessionId) {
+      setFeedbackReport(null)
+      return
+    }
+    try {
+      setFeedbackReport(await getSessionFeedback(sessionId))
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to load feedback')
+    }
+  }
+
+  // Moderators see new questions by polling; the live stream only carries public ones
+  useEffect(() => {
+    if (!moderatedSession) return
+    const load = () =>
+      getAllQuestions(moderatedSession)
+        .then(setModerationQueue)
+        .catch((err) => console.error('Failed to load questions:', err))
+    load()
+    const timer = setInterval(load, 10000)
+    return () => clearInterval(timer)
+  }, [moderatedSession])
+
+  const handleModerate = async (question: Question, status: QuestionStatus) => {
+    try {
+      const updated = await moderateQuestion(question.sessionId, question.id, status)
+      setModerationQueue(moderationQueue.map((q) => (q.id === updated.id ? updated : q)))
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to update question')
+    }
+  }
+
+  useEffect(() => {
+    if (!pollSession) return
+    const load = () =>
+      getAllPolls(pollSession)
+        .then(setSessionPolls)
+        .catch((err) => console.error('Failed to load polls:', err))
+    load()
+    const timer = setInterval(load, 5000)
+    return () => clearInterval(timer)
+  }, [pollSession])
+
+  const handlePollSubmit = async (e: React.FormEvent) => {
+    e.preventDefault()
+    if (!pollSession) return
+    try {
+      const poll = await createPoll(pollSession, {
+        question: pollForm.question,
+        options: pollForm.options.split('\n').map((o) => o.trim()).filter(Boolean),
+        multiple: pollForm.multiple,
+      })
+      setSessionPolls([...sessionPolls, poll])
+      setPollForm({ question: '', options: '', multiple: false })
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to create poll')
+    }
+  }
+
+  const handlePollStatus = async (poll: Poll, status: PollStatus) => {
+    try {
+      const updated = await updatePollStatus(poll.sessionId, poll.id, status)
+      setSessionPolls(sessionPolls.map((p) => (p.id === updated.id ? updated : p)))
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to update poll')
+    }
+  }
+
+  const handleDeletePoll = async (poll: Poll) => {
+    if (!confirm('Delete this poll?')) return
+    try {
+      await deletePoll(poll.sessionId, poll.id)
+      setSessionPolls(sessionPolls.filter((p) => p.id !== poll.id))
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to delete poll')
+    }
+  }
+
+  const loadAnnouncements = async () => {
+    try {
+      const data = await getAllAnnouncements()
+      setAnnouncements(data)
+      const jobs = await Promise.all(data.map((a) => getAnnouncementEmails(a.id)))
+      setEmailJobs(Object.fromEntries(data.flatMap((a, i) => (jobs[i].length > 0 ? [[a.id, jobs[i][0]]] : []))))
+    } catch (err) {
+      console.error('Failed to load announcements:', err)
+    }
+  }
+
+  useEffect(() => {
+    if (activeTab !== 'announcements') return
+    loadAnnouncements()
+  }, [activeTab])
+
+  // Follow running email jobs until they finish
+  const runningJobs = Object.values(emailJobs).filter((job) => job.status === 'running')
+  useEffect(() => {
+    if (runningJobs.length === 0) return
+    const timer = setInterval(async () => {
+      for (const job of runningJobs) {
+        try {
+          const [latest] = await getAnnouncementEmails(job.announcementId)
+          if (latest) setEmailJobs((current) => ({ ...current, [job.announcementId]: latest }))
+        } catch (err) {
+          console.error('Failed to load email progress:', err)
+        }
+      }
+    }, 3000)
+    return () => clearInterval(timer)
+  }, [runningJobs.map((job) => job.id).join(',')])
+
+  const handleAnnouncementSubmit = async (e: React.FormEvent) => {
+    e.preventDefault()
+    const data = {
+      title: announcementForm.title,
+      body: announcementForm.body,
+      severity: announcementForm.severity,
+      publishAt: fromLocalInput(announcementForm.publishAt),
+      expiresAt: fromLocalInput(announcementForm.expiresAt),
+    }
+    try {
+      if (editingAnnouncement) {
+        await updateAnnouncement(editingAnnouncement, data)
+      } else {
+        await createAnnouncement(data)
+      }
+      setAnnouncementForm(EMPTY_ANNOUNCEMENT)
+      setEditingAnnouncement(null)
+      await loadAnnouncements()
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to save announcement')
+    }
+  }
+
+  const editAnnouncement = (announcement: Announcement) => {
+    setEditingAnnouncement(announcement.id)
+    setAnnouncementForm({
+      title: announcement.title,
+      body: announcement.body,
+      severity: announcement.severity,
+      publishAt: toLocalInput(announcement.publishAt),
+      expiresAt: toLocalInput(announcement.expiresAt),
+    })
+  }
+
+  const handleDeleteAnnouncement = async (id: string) => {
+    if (!confirm('Delete this announcement?')) return
+    try {
+      await deleteAnnouncement(id)
+      setAnnouncements(announcements.filter((a) => a.id !== id))
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to delete announcement')
+    }
+  }
+
+  const handleEmailAnnouncement = async (announcement: Announcement) => {
+    if (!confirm(`Email "${announcement.title}" to every registrant?`)) return
+    try {
+      const job = await emailAnnouncement(announcement.id)
+      setEmailJobs({ ...emailJobs, [announcement.id]: job })
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to start sending emails')
+    }
+  }
+
+  const loadWebhooks = async () => {
+    try {
+      const [hooks, events] = await Promise.all([getWebhooks(), getWebhookEvents()])
+      setWebhooks(hooks)
+      setWebhookEvents(events)
+    } catch (err) {
+      console.error('Failed to load webhooks:', err)
+    }
+  }
+
+  useEffect(() => {
+    if (activeTab !== 'webhooks') return
+    loadWebhooks()
+  }, [activeTab])
+
+  const loadDeliveries = async (id: string) => {
+    try {
+      setDeliveries(await getWebhookDeliveries(id))
+    } catch (err) {
+      console.error('Failed to load deliveries:', err)
+    }
+  }
+
+  useEffect(() => {
+    if (!selectedWebhook) return
+    loadDeliveries(selectedWebhook)
+    const interval = setInterval(() => loadDeliveries(selectedWebhook), 10000)
+    return () => clearInterval(interval)
+  }, [selectedWebhook])
+
+  const handleWebhookSubmit = async (e: React.FormEvent) => {
+    e.preventDefault()
+    try {
+      const created = await createWebhook(webhookForm)
+      setWebhookSecret(created.secret)
+      setWebhookForm(EMPTY_WEBHOOK)
+      await loadWebhooks()
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to create webhook')
+    }
+  }
+
+  const toggleWebhookEvent = (event: string) => {
+    const events = webhookForm.events.includes(event)
+      ? webhookForm.events.filter((e) => e !== event)
+      : [...webhookForm.events, event]
+    setWebhookForm({ ...webhookForm, events })
+  }
+
+  const handleToggleWebhook = async (hook: Webhook) => {
+    try {
+      const updated = await updateWebhook(hook.id, {
+        url: hook.url,
+        events: hook.events,
+        description: hook.description,
+        active: !hook.active,
+      })
+      setWebhooks(webhooks.map((w) => (w.id === hook.id ? updated : w)))
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to update webhook')
+    }
+  }
+
+  const handleDeleteWebhook = async (id: string) => {
+    if (!confirm('Delete this webhook? Pending retries will stop.')) return
+    try {
+      await deleteWebhook(id)
+      setWebhooks(webhooks.filter((w) => w.id !== id))
+      if (selectedWebhook === id) setSelectedWebhook(null)
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to delete webhook')
+    }
+  }
+
+  const handleRedeliver = async (delivery: WebhookDelivery) => {
+    try {
+      await redeliverWebhook(delivery.webhookId, delivery.id)
+      await loadDeliveries(delivery.webhookId)
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to redeliver')
+    }
+  }
+
+  const loadProposals = async () => {
+    try {
+      const [workshopData, proposalsData] = await Promise.all([
+        getWorkshopInfo(),
+        getProposals(proposalFilter || undefined),
+      ])
+      setCFP(workshopData.cfp)
+      setCFPClosesAt(toLocalInput(workshopData.cfp.closesAt))
+      setProposals(proposalsData)
+    } catch (err) {
+      console.error('Failed to load proposals:', err)
+    }
+  }
+
+  useEffect(() => {
+    if (activeTab !== 'proposals') return
+    loadProposals()
+  }, [activeTab, proposalFilter])
+
+  const handleCFPSubmit = async (open: boolean) => {
+    try {
+      setCFP(await updateCFP({ open, closesAt: fromLocalInput(cfpClosesAt) }))
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to update the call for papers')
+    }
+  }
+
+  const openProposal = async (id: string) => {
+    if (selectedProposal?.id === id) {
+      setSelectedProposal(null)
+      return
+    }
+    try {
+      setSelectedProposal(await getProposal(id))
+      setReviewForm(EMPTY_REVIEW)
+    } catch (err) {
+      console.error('Failed to load proposal:', err)
+    }
+  }
+
+  const handleReviewSubmit = async (e: React.FormEvent) => {
+    e.preventDefault()
+    if (!selectedProposal) return
+    try {
+      await reviewProposal(selectedProposal.id, reviewForm)
+      setSelectedProposal(await getProposal(selectedProposal.id))
+      await loadProposals()
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to save review')
+    }
+  }
+
+  const handleDecideProposal = async (proposal: Proposal, accept: boolean) => {
+    const prompt = accept
+      ? `Accept "${proposal.title}"? This adds ${proposal.name} as a speaker with a draft session.`
+      : `Reject "${proposal.title}"?`
+    if (!confirm(prompt)) return
+    try {
+      if (accept) {
+        await acceptProposal(proposal.id)
+        await loadData()
+      } else {
+        await rejectProposal(proposal.id)
+      }
+      setSelectedProposal(null)
+      await loadProposals()
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to update proposal')
+    }
+  }
+
+  const loadSpeakerChanges = async () => {
+    try {
+      const [changes, settings] = await Promise.all([getSpeakerChanges(), getSpeakerPortalSettings()])
+      setSpeakerChanges(changes)
+      setSpeakerEditsNeedApproval(settings.requireApproval)
+    } catch (err) {
+      console.error('Failed to load speaker changes:', err)
+    }
+  }
+
+  useEffect(() => {
+    if (activeTab === 'speakers') loadSpeakerChanges()
+  }, [activeTab])
+
+  const handleInviteSpeaker = async (speaker: Speaker) => {
+    const email = window.prompt(`Email address to send ${speaker.name}'s profile link to:`)
+    if (!email) return
+    try {
+      const invite = await inviteSpeaker(speaker.id!, email.trim())
+      if (invite.emailed) {
+        alert(`Sent ${speaker.name} a link to edit their profile. It works until ${new Date(invite.expiresAt).toLocaleDateString()}.`)
+      } else {
+        // Without email delivery the admin passes the link on themselves
+        window.prompt(`Email isn't configured. Send ${speaker.name} this link:`, invite.link)
+      }
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to invite speaker')
+    }
+  }
+
+  const handleSpeakerApprovalToggle = async (requireApproval: boolean) => {
+    try {
+      const settings = await updateSpeakerPortalSettings(requireApproval)
+      setSpeakerEditsNeedApproval(settings.requireApproval)
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to update speaker portal settings')
+    }
+  }
+
+  const handleDecideSpeakerChange = async (change: SpeakerChangeDetails, approve: boolean) => {
+    try {
+      if (approve) {
+        await approveSpeakerChange(change.speakerId)
+        await loadData()
+      } else {
+        if (!confirm(`Discard ${change.current.name}'s changes?`)) return
+        await rejectSpeakerChange(change.speakerId)
+      }
+      await loadSpeakerChanges()
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to update speaker changes')
+    }
+  }
+
+  const loadOrganizations = async () => {
+    try {
+      const [breakdownData, domainsData] = await Promise.all([getOrganizationBreakdown(), getOrganizationDomains()])
+      setOrganizations(breakdownData)
+      setOrganizationDomains(domainsData)
+    } catch (err) {
+      console.error('Failed to load organization analytics:', err)
+    }
+  }
+
+  useEffect(() => {
+    if (activeTab !== 'analytics') return
+    loadOrganizations()
+  }, [activeTab])
+
+  const handleDomainSubmit = async (e: React.FormEvent) => {
+    e.preventDefault()
+    try {
+      await setOrganizationDomain(domainForm.domain.trim(), domainForm.organization)
+      setDomainForm({ domain: '', organization: '' })
+      await loadOrganizations()
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to save domain mapping')
+    }
+  }
+
+  const handleDeleteDomain = async (domain: string) => {
+    try {
+      await deleteOrganizationDomain(domain)
+      await loadOrganizations()
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to delete domain mapping')
+    }
+  }
+
+  const handleDesignationSubmit = async (e: React.FormEvent) => {
+    e.preventDefault()
+    try {
+      await createDesignation({
+        name: designationForm.name,
+        synonyms: designationForm.synonyms.split(',').map((s) => s.trim()).filter(Boolean),
+        patterns: designationForm.patterns.split('\n').map((p) => p.trim()).filter(Boolean),
+      })
+      setDesignationForm({ name: '', synonyms: '', patterns: '' })
+      setTaxonomy(await getDesignations())
+      setBreakdown(await getDesignationBreakdown())
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to save designation')
+    }
+  }
+
+  const handleDeleteDesignation = async (id: string) => {
+    if (!confirm('Delete this designation?')) return
+    try {
+      await deleteDesignation(id)
+      setTaxonomy(await getDesignations())
+      setBreakdown(await getDesignationBreakdown())
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to delete designation')
+    }
+  }
+
+  const handleApplyDesignations = async () => {
+    try {
+      const result = await applyDesignations()
+      alert(`Updated ${result.updated} of ${result.total} registrations (${result.unmapped} unmapped)`)
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to apply designations')
+    }
+  }
+
+  const unmappedDesignations = breakdown.find((item) => item.unmapped)?.values || []
+
+  const formatBucket = (start: string) => {
+    const date = new Date(start)
+    return seriesInterval === 'hour'
+      ? date.toLocaleString(undefined, { month: 'short', day: 'numeric', hour: 'numeric' })
+      : date.toLocaleDateString(undefined, { month: 'short', day: 'numeric' })
+  }
+
+  const handleImport = async (dryRun: boolean) => {
+    if (!importFile) return
+    try {
+      const report = await importAttendees(importFile, dryRun)
+      setImportReport(report)
+      if (!dryRun) loadData()
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Import failed')
+    }
+  }
+
   const handleLogout = () => {
     localStorage.removeItem('admin_token')
     navigate('/')
@@ -101,6 +741,34 @@ const AdminDashboard = () => {
     }
   }
 
+  // Photos are stored right away, so the speaker must exist first
+  const handleSpeakerImage = async (file?: File) => {
+    if (!editingSpeaker || !file) return
+    setUploadingImage(true)
+    try {
+      const updated = await uploadSpeakerImage(editingSpeaker.id!, file)
+      setEditingSpeaker(updated)
+      setSpeakerForm({ ...speakerForm, imageUrl: updated.imageUrl || '' })
+      await loadData()
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to upload image')
+    } finally {
+      setUploadingImage(false)
+    }
+  }
+
+  const handleRemoveSpeakerImage = async () => {
+    if (!editingSpeaker) return
+    try {
+      const updated = await deleteSpeakerImage(editingSpeaker.id!)
+      setEditingSpeaker(updated)
+      setSpeakerForm({ ...speakerForm, imageUrl: '' })
+      await loadData()
+    } catch (err: any) {
+      alert(err.response?.data?.error || 'Failed to remove image')
+    }
+  }
+
   const handleSessionSubmit = async (e: React.FormEvent) => {
     e.preventDefault()
     try {
@@ -145,7 +813,7 @@ const AdminDashboard = () => {
   }
 
   const resetSessionForm = () => {
-    setSessionForm({ title: '', description: '', time: '', duration: '', speakerIds: [] })
+    setSessionForm({ title: '', description: '', time: '', duration: '', speakerIds: [], status: undefined })
     setEditingSession(null)
   }
 
@@ -174,6 +842,7 @@ const AdminDashboard = () => {
         time: session.time,
         duration: session.duration,
         speakerIds: session.speakerIds,
+        status: session.status,
       })
     } else {
       resetSessionForm()
@@ -207,7 +876,7 @@ const AdminDashboard = () => {
         {/* Tabs */}
         <div className="bg-white rounded-lg shadow-sm mb-6">
           <div className="flex border-b">
-            {(['attendees', 'speakers', 'sessions', 'analytics'] as const).map((tab) => (
+            {(['attendees', 'speakers', 'sessions', 'proposals', 'announcements', 'webhooks', 'analytics'] as const).map((tab) => (
               <button
                 key={tab}
                 onClick={() => setActiveTab(tab)}
@@ -226,7 +895,78 @@ const AdminDashboard = () => {
         {/* Attendees Tab */}
         {activeTab === 'attendees' && (
           <div className="bg-white rounded-lg shadow-sm p-6">
-            <h2 className="text-xl font-bold mb-4">Attendees ({attendees.length})</h2>
+            <div className="flex items-center justify-between mb-4">
+              <h2 className="text-xl font-bold">Attendees ({attendees.length})</h2>
+              <div className="flex gap-2">
+                {(['csv', 'xlsx'] as const).map((format) => (
+                  <button
+                    key={format}
+                    onClick={() => exportAttendees({ format }).catch(() => alert('Export failed'))}
+                    className="px-4 py-2 border border-gray-300 rounded-lg hover:bg-gray-50 text-sm"
+                  >
+                    Export {format.toUpperCase()}
+                  </button>
+                ))}
+              </div>
+            </div>
+            <div className="flex flex-wrap items-center gap-2 mb-4">
+              <input
+                type="file"
+                accept=".csv,text/csv"
+                onChange={(e) => {
+                  setImportFile(e.target.files?.[0] || null)
+                  setImportReport(null)
+                }}
+                className="text-sm"
+              />
+              <button
+                onClick={() => handleImport(true)}
+                disabled={!importFile}
+                className="px-4 py-2 border border-gray-300 rounded-lg hover:bg-gray-50 text-sm disabled:opacity-50"
+              >
+                Preview import
+              </button>
+              <button
+                onClick={() => handleImport(false)}
+                disabled={!importFile}
+                className="px-4 py-2 bg-blue-600 text-white rounded-lg hover:bg-blue-700 text-sm disabled:opacity-50"
+              >
+                Import
+              </button>
+            </div>
+            {importReport && (
+              <div className="mb-4 p-4 bg-gray-50 rounded-lg text-sm">
+                <p className="font-semibold mb-2">
+                  {importReport.dryRun ? 'Preview' : 'Import'}: {importReport.total} rows,{' '}
+                  {importReport.dryRun ? `${importReport.valid} valid` : `${importReport.created} created`},{' '}
+                  {importReport.duplicates} duplicates, {importReport.invalid} invalid
+                  {importReport.failed > 0 && `, ${importReport.failed} failed`}
+                </p>
+                {importReport.created > 0 && (
+                  <div className="flex flex-wrap items-center gap-2 mb-2">
+                    <span className="text-gray-600">
+                      Imported attendees need their tokens for attendee features; they are only shown now.
+                    </span>
+                    <button
+                      onClick={() => downloadImportTokens(importReport)}
+                      className="px-3 py-1 border border-gray-300 rounded-lg hover:bg-white"
+                    >
+                      Download tokens
+                    </button>
+                  </div>
+                )}
+                <ul className="space-y-1">
+                  {importReport.rows
+                    .filter((row) => row.errors?.length)
+                    .map((row) => (
+                      <li key={row.row} className="text-red-700">
+                        Row {row.row}
+                        {row.email && ` (${row.email})`}: {row.errors!.join(', ')}
+                      </li>
+                    ))}
+                </ul>
+              </div>
+            )}
             <div className="overflow-x-auto">
               <table className="w-full">
                 <thead>
@@ -268,6 +1008,63 @@ const AdminDashboard = () => {
                 Add Speaker
               </button>
             </div>
+            <label className="flex items-center gap-2 text-sm text-gray-700 mb-4">
+              <input
+                type="checkbox"
+                checked={speakerEditsNeedApproval}
+                onChange={(e) => handleSpeakerApprovalToggle(e.target.checked)}
+              />
+              Review profile edits speakers make through their invite link before publishing them
+            </label>
+            {speakerChanges.length > 0 && (
+              <div className="border border-yellow-200 bg-yellow-50 rounded-lg p-4 mb-6">
+                <h3 className="font-semibold mb-3">Pending Profile Changes ({speakerChanges.length})</h3>
+                <div className="space-y-4">
+                  {speakerChanges.map((change) => (
+                    <div key={change.speakerId} className="bg-white border rounded-lg p-4 text-sm">
+                      <div className="flex justify-between items-start mb-2">
+                        <div>
+                          <span className="font-semibold">{change.current.name}</span>
+                          <span className="text-gray-500 ml-2">
+                            submitted {new Date(change.submittedAt).toLocaleString()}
+                          </span>
+                        </div>
+                        <div className="flex gap-3">
+                          <button
+                            onClick={() => handleDecideSpeakerChange(change, true)}
+                            className="text-green-600 hover:text-green-700 font-medium"
+                          >
+                            Approve
+                          </button>
+                          <button
+                            onClick={() => handleDecideSpeakerChange(change, false)}
+                            className="text-red-600 hover:text-red-700 font-medium"
+                          >
+                            Reject
+                          </button>
+                        </div>
+                      </div>
+                      {(
+                        [
+                          ['Name', change.current.name, change.name],
+                          ['Bio', change.current.bio, change.bio],
+                          ['LinkedIn', change.current.linkedinUrl || '', change.linkedinUrl || ''],
+                          ['Twitter / X', change.current.twitterUrl || '', change.twitterUrl || ''],
+                        ] as const
+                      )
+                        .filter(([, before, after]) => before !== after)
+                        .map(([field, before, after]) => (
+                          <div key={field} className="grid grid-cols-1 md:grid-cols-[8rem_1fr_1fr] gap-2 py-1">
+                            <span className="text-gray-500">{field}</span>
+                            <span className="text-red-700 line-through whitespace-pre-line">{before || '—'}</span>
+                            <span className="text-green-700 whitespace-pre-line">{after || '—'}</span>
+                          </div>
+                        ))}
+                    </div>
+                  ))}
+                </div>
+              </div>
+            )}
             {speakers.length === 0 ? (
               <div className="text-center py-12">
                 <p className="text-gray-500 text-lg mb-4">No speakers added yet.</p>
@@ -277,13 +1074,7 @@ const AdminDashboard = () => {
               <div className="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 gap-4">
                 {speakers.map((speaker) => (
                   <div key={speaker.id} className="border rounded-lg p-4">
-                    {speaker.imageUrl && (
-                      <img
-                        src={speaker.imageUrl}
-                        alt={speaker.name}
-                        className="w-full h-48 object-cover rounded-lg mb-3"
-                      />
-                    )}
+                    <SpeakerImage speaker={speaker} sizes="400px" className="w-full h-48 object-cover rounded-lg mb-3" />
                     <h3 className="font-bold text-lg mb-2">{speaker.name}</h3>
                     <p className="text-gray-600 text-sm mb-3 line-clamp-3">{speaker.bio}</p>
                     <div className="flex gap-2">
@@ -293,6 +1084,12 @@ const AdminDashboard = () => {
                       >
                         Edit
                       </button>
+                      <button
+                        onClick={() => handleInviteSpeaker(speaker)}
+                        className="text-blue-600 hover:text-blue-700 text-sm font-medium"
+                      >
+                        Invite
+                      </button>
                       <button
                         onClick={() => handleDeleteSpeaker(speaker.id!)}
                         className="text-red-600 hover:text-red-700 text-sm font-medium"
@@ -312,12 +1109,20 @@ const AdminDashboard = () => {
           <div className="bg-white rounded-lg shadow-sm p-6">
             <div className="flex justify-between items-center mb-4">
               <h2 className="text-xl font-bold">Sessions ({sessions.length})</h2>
-              <button
-                onClick={() => openSessionModal()}
-                className="gradient-bg text-white px-4 py-2 rounded-lg font-semibold hover:opacity-90"
-              >
-                Add Session
-              </button>
+              <div className="flex gap-2">
+                <button
+                  onClick={() => exportSessionFeedback().catch(() => alert('Export failed'))}
+                  className="px-4 py-2 border border-gray-300 rounded-lg text-sm hover:bg-gray-50"
+                >
+                  Export Feedback
+                </button>
+                <button
+                  onClick={() => openSessionModal()}
+                  className="gradient-bg text-white px-4 py-2 rounded-lg font-semibold hover:opacity-90"
+                >
+                  Add Session
+                </button>
+              </div>
             </div>
             {sessions.length === 0 ? (
               <div className="text-center py-12">
@@ -334,12 +1139,163 @@ const AdminDashboard = () => {
                           <span className="text-sm font-semibold text-blue-600 bg-blue-50 px-2 py-1 rounded">
                             {session.time} • {session.duration}
                           </span>
+                          {session.status === 'draft' && (
+                            <span className="text-xs font-semibold text-gray-600 bg-gray-100 px-2 py-1 rounded">Draft</span>
+                          )}
                         </div>
                         <h3 className="font-bold text-lg mb-2">{session.title}</h3>
                         <p className="text-gray-600 mb-2">{session.description}</p>
                         <p className="text-sm text-gray-500">
                           Speakers: {session.speakerIds.length}
                         </p>
+                        {feedbackSummaries[session.id!]?.count > 0 && (
+                          <button
+                            onClick={() => toggleFeedbackReport(session.id!)}
+                            className="text-sm text-gray-600 hover:text-gray-800 mt-1"
+                          >
+                            ★ {feedbackSummaries[session.id!].average.toFixed(2)} from{' '}
+                            {feedbackSummaries[session.id!].count} ratings
+                          </button>
+                        )}
+                        <button
+                          onClick={() => setPollSession(pollSession === session.id ? null : session.id!)}
+                          className="block text-sm text-blue-600 hover:text-blue-700 mt-1"
+                        >
+                          {pollSession === session.id ? 'Close polls' : 'Polls'}
+                        </button>
+                        {pollSession === session.id && (
+                          <div className="mt-3 space-y-3">
+                            {sessionPolls.map((poll) => (
+                              <div key={poll.id} className="border rounded p-3 space-y-2">
+                                <div className="flex justify-between gap-4">
+                                  <p className="font-medium">
+                                    {poll.question} <span className="text-xs text-gray-500">({poll.status})</span>
+                                  </p>
+                                  <div className="flex gap-2 text-sm shrink-0">
+                                    {poll.status !== 'open' && (
+                                      <button
+                                        onClick={() => handlePollStatus(poll, 'open')}
+                                        className="text-blue-600 hover:text-blue-700 font-medium"
+                                      >
+                                        Open
+                                      </button>
+                                    )}
+                                    {poll.status === 'open' && (
+                                      <button
+                                        onClick={() => handlePollStatus(poll, 'closed')}
+                                        className="text-blue-600 hover:text-blue-700 font-medium"
+                                      >
+                                        Close
+                                      </button>
+                                    )}
+                                    <button
+                                      onClick={() => handleDeletePoll(poll)}
+                                      className="text-red-600 hover:text-red-700 font-medium"
+                                    >
+                                      Delete
+                                    </button>
+                                  </div>
+                                </div>
+                                <PollResults poll={poll} />
+                              </div>
+                            ))}
+                            <form onSubmit={handlePollSubmit} className="space-y-2">
+                              <input
+                                type="text"
+                                required
+                                placeholder="Poll question"
+                                value={pollForm.question}
+                                onChange={(e) => setPollForm({ ...pollForm, question: e.target.value })}
+                                className="w-full px-3 py-2 border rounded-lg text-sm"
+                              />
+                              <textarea
+                                required
+                                placeholder="Options, one per line"
+                                value={pollForm.options}
+                                onChange={(e) => setPollForm({ ...pollForm, options: e.target.value })}
+                                className="w-full px-3 py-2 border rounded-lg text-sm"
+                                rows={3}
+                              />
+                              <div className="flex justify-between items-center">
+                                <label className="text-sm flex items-center gap-1">
+                                  <input
+                                    type="checkbox"
+                                    checked={pollForm.multiple}
+                                    onChange={(e) => setPollForm({ ...pollForm, multiple: e.target.checked })}
+                                  />
+                                  Allow multiple choices
+                                </label>
+                                <button type="submit" className="text-sm font-semibold text-blue-600 hover:text-blue-700">
+                                  Add Poll
+                                </button>
+                              </div>
+                            </form>
+                          </div>
+                        )}
+                        <button
+                          onClick={() => setModeratedSession(moderatedSession === session.id ? null : session.id!)}
+                          className="block text-sm text-blue-600 hover:text-blue-700 mt-1"
+                        >
+                          {moderatedSession === session.id ? 'Close Q&A moderation' : 'Moderate Q&A'}
+                        </button>
+                        {moderatedSession === session.id && (
+                          <div className="mt-3 space-y-2">
+                            {moderationQueue.length === 0 && <p className="text-sm text-gray-500">No questions yet.</p>}
+                            {moderationQueue.map((question) => (
+                              <div key={question.id} className="flex justify-between gap-4 border rounded p-2 text-sm">
+                                <div>
+                                  <p>{question.text}</p>
+                                  <p className="text-gray-500">
+                                    {question.authorName || 'Anonymous'} • {question.votes} votes • {question.status}
+                                  </p>
+                                </div>
+                                <div className="flex gap-2 shrink-0">
+                                  {MODERATION_ACTIONS.filter((action) => action.status !== question.status).map(
+                                    (action) => (
+                                      <button
+                                        key={action.status}
+                                        onClick={() => handleModerate(question, action.status)}
+                                        className="text-blue-600 hover:text-blue-700 font-medium"
+                                      >
+                                        {action.label}
+                                      </button>
+                                    )
+                                  )}
+                                </div>
+                              </div>
+                            ))}
+                          </div>
+                        )}
+                        {feedbackReport?.sessionId === session.id && (
+                          <div className="mt-3 space-y-3">
+                            <div className="space-y-1">
+                              {[5, 4, 3, 2, 1].map((rating) => (
+                                <div key={rating} className="flex items-center gap-2 text-sm">
+                                  <span className="w-6">{rating}★</span>
+                                  <div className="flex-1 bg-gray-100 rounded h-2">
+                                    <div
+                                      className="bg-yellow-400 h-2 rounded"
+                                      style={{
+                                        width: `${(feedbackReport.histogram[rating - 1] / feedbackReport.count) * 100}%`,
+                                      }}
+                                    ></div>
+                                  </div>
+                                  <span className="w-8 text-right text-gray-600">
+                                    {feedbackReport.histogram[rating - 1]}
+                                  </span>
+                                </div>
+                              ))}
+                            </div>
+                            {feedbackReport.comments.map((comment) => (
+                              <div key={comment.id} className="text-sm border-l-2 border-gray-200 pl-3">
+                                <p className="text-gray-800">{comment.comment}</p>
+                                <p className="text-gray-500">
+                                  {comment.rating}★ • {new Date(comment.createdAt).toLocaleString()}
+                                </p>
+                              </div>
+                            ))}
+                          </div>
+                        )}
                       </div>
                       <div className="flex gap-2 ml-4">
                         <button
@@ -363,48 +1319,706 @@ const AdminDashboard = () => {
           </div>
         )}
 
+        {/* Announcements Tab */}
+        {activeTab === 'proposals' && (
+          <div className="space-y-6">
+            <div className="bg-white rounded-lg shadow-sm p-6">
+              <h2 className="text-xl font-bold mb-4">Call for Papers</h2>
+              <div className="flex flex-wrap items-end gap-4">
+                <div>
+                  <label className="block text-sm font-medium mb-1">Closes at (optional)</label>
+                  <input
+                    type="datetime-local"
+                    value={cfpClosesAt}
+                    onChange={(e) => setCFPClosesAt(e.target.value)}
+                    className="px-3 py-2 border rounded-lg"
+                  />
+                </div>
+                {cfp.open ? (
+                  <>
+                    <button
+                      onClick={() => handleCFPSubmit(true)}
+                      className="px-6 py-2 border rounded-lg font-semibold hover:bg-gray-50"
+                    >
+                      Save
+                    </button>
+                    <button
+                      onClick={() => handleCFPSubmit(false)}
+                      className="px-6 py-2 rounded-lg font-semibold text-white bg-red-600 hover:bg-red-700"
+                    >
+                      Close CFP
+                    </button>
+                  </>
+                ) : (
+                  <button
+                    onClick={() => handleCFPSubmit(true)}
+                    className="gradient-bg text-white px-6 py-2 rounded-lg font-semibold hover:opacity-90"
+                  >
+                    Open CFP
+                  </button>
+                )}
+                <span className={`text-sm font-medium ${cfp.open ? 'text-green-600' : 'text-gray-500'}`}>
+                  {cfp.open ? 'Accepting proposals' : 'Closed'}
+                </span>
+              </div>
+            </div>
+
+            <div className="bg-white rounded-lg shadow-sm p-6">
+              <div className="flex justify-between items-center mb-4">
+                <h2 className="text-xl font-bold">Proposals ({proposals.length})</h2>
+                <select
+                  value={proposalFilter}
+                  onChange={(e) => setProposalFilter(e.target.value as ProposalStatus | '')}
+                  className="px-3 py-2 border rounded-lg text-sm"
+                >
+                  <option value="submitted">Awaiting decision</option>
+                  <option value="accepted">Accepted</option>
+                  <option value="rejected">Rejected</option>
+                  <option value="">All</option>
+                </select>
+              </div>
+              {proposals.length === 0 ? (
+                <p className="text-gray-500">No proposals.</p>
+              ) : (
+                <div className="space-y-3">
+                  {proposals.map((proposal) => (
+                    <div key={proposal.id} className="border rounded-lg p-4">
+                      <div className="flex justify-between items-start gap-4">
+                        <div className="min-w-0">
+                          <h3 className="font-semibold">
+                            {proposal.title}
+                            {proposal.status !== 'submitted' && (
+                              <span className="ml-2 text-xs text-gray-400 capitalize">{proposal.status}</span>
+                            )}
+                          </h3>
+                          <p className="text-sm text-gray-600">
+                            {proposal.name} &lt;{proposal.email}&gt;
+                          </p>
+                          <p className="text-xs text-gray-400 mt-1">
+                            {proposal.averageScore !== undefined
+                              ? `★ ${proposal.averageScore.toFixed(2)} from ${proposal.reviewCount} reviews`
+                              : 'Not reviewed yet'}
+                          </p>
+                        </div>
+                        <div className="flex gap-3 text-sm font-medium shrink-0">
+                          <button onClick={() => openProposal(proposal.id)} className="text-blue-600 hover:text-blue-700">
+                            {selectedProposal?.id === proposal.id ? 'Hide' : 'Review'}
+                          </button>
+                          {proposal.status === 'submitted' && (
+                            <>
+                              <button
+                                onClick={() => handleDecideProposal(proposal, true)}
+                                className="text-green-600 hover:text-green-700"
+                              >
+                                Accept
+                              </button>
+                              <button
+                                onClick={() => handleDecideProposal(proposal, false)}
+                                className="text-red-600 hover:text-red-700"
+                              >
+                                Reject
+                              </button>
+                            </>
+                          )}
+                        </div>
+                      </div>
+
+                      {selectedProposal?.id === proposal.id && (
+                        <div className="mt-4 pt-4 border-t space-y-4">
+                          <p className="text-gray-700 whitespace-pre-line">{selectedProposal.abstract}</p>
+                          <div className="text-sm text-gray-600">
+                            <p className="font-medium">About the speaker</p>
+                            <p className="whitespace-pre-line">{selectedProposal.bio}</p>
+                            {selectedProposal.links?.map((link) => (
+                              <a
+                                key={link}
+                                href={link}
+                                target="_blank"
+                                rel="noopener noreferrer"
+                                className="block text-blue-600 hover:text-blue-700 break-all"
+                              >
+                                {link}
+                              </a>
+                            ))}
+                          </div>
+
+                          {selectedProposal.reviews.length > 0 && (
+                            <div className="space-y-2">
+                              {selectedProposal.reviews.map((review) => (
+                                <div key={review.id} className="text-sm bg-gray-50 rounded p-2">
+                                  <span className="font-semibold">★ {review.score}</span>
+                                  <span className="text-gray-400 ml-2">{review.reviewerId}</span>
+                                  {review.comment && <p className="text-gray-700 mt-1">{review.comment}</p>}
+                                </div>
+                              ))}
+                            </div>
+                          )}
+
+                          {selectedProposal.status === 'submitted' && (
+                            <form onSubmit={handleReviewSubmit} className="flex flex-wrap items-start gap-3">
+                              <select
+                                value={reviewForm.score}
+                                onChange={(e) => setReviewForm({ ...reviewForm, score: Number(e.target.value) })}
+                                className="px-3 py-2 border rounded-lg"
+                              >
+                                {[1, 2, 3, 4, 5].map((score) => (
+                                  <option key={score} value={score}>
+                                    {score} ★
+                                  </option>
+                                ))}
+                              </select>
+                              <textarea
+                                placeholder="Comment for other reviewers"
+                                rows={2}
+                                maxLength={2000}
+                                value={reviewForm.comment}
+                                onChange={(e) => setReviewForm({ ...reviewForm, comment: e.target.value })}
+                                className="flex-1 min-w-[16rem] px-3 py-2 border rounded-lg"
+                              />
+                              <button
+                                type="submit"
+                                className="gradient-bg text-white px-6 py-2 rounded-lg font-semibold hover:opacity-90"
+                              >
+                                Save Review
+                              </button>
+                            </form>
+                          )}
+                        </div>
+                      )}
+                    </div>
+                  ))}
+                </div>
+              )}
+            </div>
+          </div>
+        )}
+
+        {activeTab === 'announcements' && (
+          <div className="space-y-6">
+            <div className="bg-white rounded-lg shadow-sm p-6">
+              <h2 className="text-xl font-bold mb-4">{editingAnnouncement ? 'Edit Announcement' : 'New Announcement'}</h2>
+              <form onSubmit={handleAnnouncementSubmit} className="grid grid-cols-1 md:grid-cols-3 gap-3">
+                <input
+                  type="text"
+                  required
+                  maxLength={200}
+                  placeholder="Title, e.g. Keynote moved to Hall B"
+                  value={announcementForm.title}
+                  onChange={(e) => setAnnouncementForm({ ...announcementForm, title: e.target.value })}
+                  className="md:col-span-2 px-3 py-2 border rounded-lg"
+                />
+                <select
+                  value={announcementForm.severity}
+                  onChange={(e) =>
+                    setAnnouncementForm({ ...announcementForm, severity: e.target.value as AnnouncementSeverity })
+                  }
+                  className="px-3 py-2 border rounded-lg"
+                >
+                  <option value="info">Info</option>
+                  <option value="warning">Warning</option>
+                  <option value="critical">Critical</option>
+                </select>
+                <textarea
+                  required
+                  maxLength={5000}
+                  placeholder="Message"
+                  value={announcementForm.body}
+                  onChange={(e) => setAnnouncementForm({ ...announcementForm, body: e.target.value })}
+                  className="md:col-span-3 px-3 py-2 border rounded-lg"
+                  rows={3}
+                />
+                <label className="text-sm text-gray-600">
+                  Publish at (defaults to now)
+                  <input
+                    type="datetime-local"
+                    value={announcementForm.publishAt}
+                    onChange={(e) => setAnnouncementForm({ ...announcementForm, publishAt: e.target.value })}
+                    className="w-full px-3 py-2 border rounded-lg"
+                  />
+                </label>
+                <label className="text-sm text-gray-600">
+                  Expires at (optional)
+                  <input
+                    type="datetime-local"
+                    value={announcementForm.expiresAt}
+                    onChange={(e) => setAnnouncementForm({ ...announcementForm, expiresAt: e.target.value })}
+                    className="w-full px-3 py-2 border rounded-lg"
+                  />
+                </label>
+                <div className="flex items-end gap-2">
+                  <button
+                    type="submit"
+                    className="flex-1 gradient-bg text-white py-2 rounded-lg font-semibold hover:opacity-90"
+                  >
+                    {editingAnnouncement ? 'Save' : 'Publish'}
+                  </button>
+                  {editingAnnouncement && (
+                    <button
+                      type="button"
+                      onClick={() => {
+                        setEditingAnnouncement(null)
+                        setAnnouncementForm(EMPTY_ANNOUNCEMENT)
+                      }}
+                      className="px-4 py-2 border rounded-lg"
+                    >
+                      Cancel
+                    </button>
+                  )}
+                </div>
+              </form>
+            </div>
+
+            <div className="bg-white rounded-lg shadow-sm p-6">
+              <h2 className="text-xl font-bold mb-4">Announcements ({announcements.length})</h2>
+              {announcements.length === 0 ? (
+                <p className="text-gray-500">No announcements yet.</p>
+              ) : (
+                <div className="space-y-3">
+                  {announcements.map((announcement) => {
+                    const job = emailJobs[announcement.id]
+                    const expired = announcement.expiresAt && Date.parse(announcement.expiresAt) <= Date.now()
+                    const scheduled = Date.parse(announcement.publishAt) > Date.now()
+                    return (
+                      <div key={announcement.id} className="border rounded-lg p-4">
+                        <div className="flex justify-between items-start gap-4">
+                          <div>
+                            <h3 className="font-semibold">
+                              {announcement.title}
+                              <span className="ml-2 text-xs uppercase text-gray-500">{announcement.severity}</span>
+                              {scheduled && <span className="ml-2 text-xs text-blue-600">Scheduled</span>}
+                              {expired && <span className="ml-2 text-xs text-gray-400">Expired</span>}
+                            </h3>
+                            <p className="text-sm text-gray-600 whitespace-pre-line">{announcement.body}</p>
+                            <p className="text-xs text-gray-400 mt-1">
+                              {new Date(announcement.publishAt).toLocaleString()}
+                              {announcement.expiresAt && ` – ${new Date(announcement.expiresAt).toLocaleString()}`}
+                            </p>
+                          </div>
+                          <div className="flex gap-3 text-sm font-medium shrink-0">
+                            <button onClick={() => editAnnouncement(announcement)} className="text-blue-600 hover:text-blue-700">
+                              Edit
+                            </button>
+                            {!expired && (
+                              <button
+                                onClick={() => handleEmailAnnouncement(announcement)}
+                                disabled={job?.status === 'running'}
+                                className="text-blue-600 hover:text-blue-700 disabled:text-gray-400"
+                              >
+                                Email registrants
+                              </button>
+                            )}
+                            <button
+                              onClick={() => handleDeleteAnnouncement(announcement.id)}
+                              className="text-red-600 hover:text-red-700"
+                            >
+                              Delete
+                            </button>
+                          </div>
+                        </div>
+                        {job && (
+                          <div className="mt-3 text-sm">
+                            <div className="flex justify-between text-gray-600">
+                              <span>
+                                Email {job.status}: {job.sent} sent
+                                {job.failed > 0 && `, ${job.failed} failed`} of {job.total}
+                              </span>
+                              <span>{new Date(job.createdAt).toLocaleString()}</span>
+                            </div>
+                            <div className="h-2 bg-gray-200 rounded mt-1">
+                              <div
+                                className={`h-2 rounded ${job.status === 'failed' ? 'bg-red-500' : 'bg-blue-600'}`}
+                                style={{ width: `${job.total ? ((job.sent + job.failed) / job.total) * 100 : 100}%` }}
+                              />
+                            </div>
+                            {job.errors && job.errors.length > 0 && (
+                              <ul className="mt-1 text-xs text-red-600">
+                                {job.errors.map((error) => (
+                                  <li key={error}>{error}</li>
+                                ))}
+                              </ul>
+                            )}
+                          </div>
+                        )}
+                      </div>
+                    )
+                  })}
+                </div>
+              )}
+            </div>
+          </div>
+        )}
+
+        {/* Webhooks Tab */}
+        {activeTab === 'webhooks' && (
+          <div className="space-y-6">
+            <div className="bg-white rounded-lg shadow-sm p-6">
+              <h2 className="text-xl font-bold mb-4">New Webhook</h2>
+              {webhookSecret && (
+                <div className="mb-4 p-3 bg-yellow-50 border border-yellow-200 rounded-lg text-sm">
+                  <p className="font-medium">Signing secret — copy it now, it won't be shown again:</p>
+                  <code className="block mt-1 break-all">{webhookSecret}</code>
+                  <button onClick={() => setWebhookSecret(null)} className="mt-2 text-blue-600 hover:text-blue-700">
+                    Done
+                  </button>
+                </div>
+              )}
+              <form onSubmit={handleWebhookSubmit} className="space-y-3">
+                <div className="grid grid-cols-1 md:grid-cols-2 gap-3">
+                  <input
+                    type="url"
+                    required
+                    placeholder="https://example.com/hooks/workshop"
+                    value={webhookForm.url}
+                    onChange={(e) => setWebhookForm({ ...webhookForm, url: e.target.value })}
+                    className="px-3 py-2 border rounded-lg"
+                  />
+                  <input
+                    type="text"
+                    placeholder="Description"
+                    value={webhookForm.description}
+                    onChange={(e) => setWebhookForm({ ...webhookForm, description: e.target.value })}
+                    className="px-3 py-2 border rounded-lg"
+                  />
+                </div>
+                <div className="flex flex-wrap gap-4 text-sm">
+                  {webhookEvents.map((event) => (
+                    <label key={event} className="flex items-center gap-1">
+                      <input
+                        type="checkbox"
+                        checked={webhookForm.events.includes(event)}
+                        onChange={() => toggleWebhookEvent(event)}
+                      />
+                      {event}
+                    </label>
+                  ))}
+                </div>
+                <button
+                  type="submit"
+                  disabled={webhookForm.events.length === 0}
+                  className="gradient-bg text-white px-6 py-2 rounded-lg font-semibold hover:opacity-90 disabled:opacity-50"
+                >
+                  Add Webhook
+                </button>
+              </form>
+            </div>
+
+            <div className="bg-white rounded-lg shadow-sm p-6">
+              <h2 className="text-xl font-bold mb-4">Webhooks ({webhooks.length})</h2>
+              {webhooks.length === 0 ? (
+                <p className="text-gray-500">No webhooks yet.</p>
+              ) : (
+                <div className="space-y-3">
+                  {webhooks.map((hook) => (
+                    <div key={hook.id} className="border rounded-lg p-4">
+                      <div className="flex justify-between items-start gap-4">
+                        <div className="min-w-0">
+                          <h3 className="font-semibold break-all">
+                            {hook.url}
+                            {!hook.active && <span className="ml-2 text-xs text-gray-400">Paused</span>}
+                          </h3>
+                          {hook.description && <p className="text-sm text-gray-600">{hook.description}</p>}
+                          <p className="text-xs text-gray-400 mt-1">{hook.events.join(', ')}</p>
+                        </div>
+                        <div className="flex gap-3 text-sm font-medium shrink-0">
+                          <button
+                            onClick={() => setSelectedWebhook(selectedWebhook === hook.id ? null : hook.id)}
+                            className="text-blue-600 hover:text-blue-700"
+                          >
+                            {selectedWebhook === hook.id ? 'Hide deliveries' : 'Deliveries'}
+                          </button>
+                          <button onClick={() => handleToggleWebhook(hook)} className="text-blue-600 hover:text-blue-700">
+                            {hook.active ? 'Pause' : 'Resume'}
+                          </button>
+                          <button onClick={() => handleDeleteWebhook(hook.id)} className="text-red-600 hover:text-red-700">
+                            Delete
+                          </button>
+                        </div>
+                      </div>
+                      {selectedWebhook === hook.id && (
+                        <div className="mt-3">
+                          {deliveries.length === 0 ? (
+                            <p className="text-sm text-gray-500">No deliveries yet.</p>
+                          ) : (
+                            <table className="w-full text-sm">
+                              <thead>
+                                <tr className="text-left text-gray-500">
+                                  <th className="py-1">Event</th>
+                                  <th className="py-1">Status</th>
+                                  <th className="py-1">Attempts</th>
+                                  <th className="py-1">Last response</th>
+                                  <th className="py-1">Created</th>
+                                  <th />
+                                </tr>
+                              </thead>
+                              <tbody>
+                                {deliveries.map((delivery) => {
+                                  const last = delivery.attempts?.[delivery.attempts.length - 1]
+                                  return (
+                                    <tr key={delivery.id} className="border-t">
+                                      <td className="py-1">{delivery.event}</td>
+                                      <td
+                                        className={`py-1 ${
+                                          delivery.status === 'failed'
+                                            ? 'text-red-600'
+                                            : delivery.status === 'succeeded'
+                                              ? 'text-green-600'
+                                              : 'text-gray-600'
+                                        }`}
+                                        title={
+                                          delivery.status === 'pending'
+                                            ? `Next attempt ${new Date(delivery.nextAttemptAt).toLocaleString()}`
+                                            : undefined
+                                        }
+                                      >
+                                        {delivery.status}
+                                      </td>
+                                      <td className="py-1">{delivery.attempts?.length ?? 0}</td>
+                                      <td className="py-1 text-gray-600">
+                                        {last ? last.error || last.statusCode : '—'}
+                                      </td>
+                                      <td className="py-1 text-gray-600">{new Date(delivery.createdAt).toLocaleString()}</td>
+                                      <td className="py-1 text-right">
+                                        {delivery.status !== 'pending' && (
+                                          <button
+                                            onClick={() => handleRedeliver(delivery)}
+                                            className="text-blue-600 hover:text-blue-700"
+                                          >
+                                            Redeliver
+                                          </button>
+                                        )}
+                                      </td>
+                                    </tr>
+                                  )
+                                })}
+                              </tbody>
+                            </table>
+                          )}
+                        </div>
+                      )}
+                    </div>
+                  ))}
+                </div>
+              )}
+            </div>
+          </div>
+        )}
+
         {/* Analytics Tab */}
         {activeTab === 'analytics' && (
-          <div className="bg-white rounded-lg shadow-sm p-6">
-            <h2 className="text-xl font-bold mb-6">Designation Breakdown</h2>
-            {breakdown.length > 0 ? (
-              <div className="max-w-2xl mx-auto">
-                <ResponsiveContainer width="100%" height={400}>
-                  <PieChart>
-                    <Pie
-                      data={breakdown}
-                      cx="50%"
-                      cy="50%"
-                      labelLine={false}
-                      label={({ designation, count }) => `${designation}: ${count}`}
-                      outerRadius={120}
-                      fill="#8884d8"
-                      dataKey="count"
-                    >
-                      {breakdown.map((_, index) => (
-                        <Cell key={`cell-${index}`} fill={COLORS[index % COLORS.length]} />
-                      ))}
-                    </Pie>
-                    <Tooltip />
+          <div className="space-y-6">
+            <div className="bg-white rounded-lg shadow-sm p-6">
+              <div className="flex justify-between items-center mb-6">
+                <h2 className="text-xl font-bold">Registrations Over Time</h2>
+                <select
+                  value={seriesInterval}
+                  onChange={(e) => setSeriesInterval(e.target.value as RegistrationTimeSeries['interval'])}
+                  className="px-3 py-2 border rounded-lg text-sm"
+                >
+                  <option value="hour">Hourly</option>
+                  <option value="day">Daily</option>
+                  <option value="week">Weekly</option>
+                </select>
+              </div>
+              {timeSeries && timeSeries.buckets.length > 0 ? (
+                <ResponsiveContainer width="100%" height={320}>
+                  <ComposedChart data={timeSeries.buckets}>
+                    <CartesianGrid strokeDasharray="3 3" />
+                    <XAxis dataKey="start" tickFormatter={formatBucket} />
+                    <YAxis yAxisId="count" allowDecimals={false} />
+                    <YAxis yAxisId="cumulative" orientation="right" allowDecimals={false} />
+                    <Tooltip labelFormatter={formatBucket} />
                     <Legend />
-                  </PieChart>
+                    <Bar yAxisId="count" dataKey="count" name="New registrations" fill={COLORS[0]} />
+                    <Line
+                      yAxisId="cumulative"
+                      dataKey="cumulative"
+                      name="Total"
+                      stroke={COLORS[1]}
+                      dot={false}
+                    />
+                  </ComposedChart>
                 </ResponsiveContainer>
-                <div className="mt-6 space-y-2">
-                  {breakdown.map((item, index) => (
-                    <div key={item.designation} className="flex items-center gap-3">
-                      <div
-                        className="w-4 h-4 rounded"
-                        style={{ backgroundColor: COLORS[index % COLORS.length] }}
-                      ></div>
-                      <span className="font-medium">{item.designation}</span>
-                      <span className="text-gray-600">({item.count})</span>
-                    </div>
-                  ))}
+              ) : (
+                <p className="text-gray-500 text-center py-8">No data available</p>
+              )}
+            </div>
+            <div className="bg-white rounded-lg shadow-sm p-6">
+              <h2 className="text-xl font-bold mb-6">Designation Breakdown</h2>
+              {breakdown.length > 0 ? (
+                <div className="max-w-2xl mx-auto">
+                  <ResponsiveContainer width="100%" height={400}>
+                    <PieChart>
+                      <Pie
+                        data={breakdown}
+                        cx="50%"
+                        cy="50%"
+                        labelLine={false}
+                        label={({ designation, count }) => `${designation}: ${count}`}
+                        outerRadius={120}
+                        fill="#8884d8"
+                        dataKey="count"
+                      >
+                        {breakdown.map((_, index) => (
+                          <Cell key={`cell-${index}`} fill={COLORS[index % COLORS.length]} />
+                        ))}
+                      </Pie>
+                      <Tooltip />
+                      <Legend />
+                    </PieChart>
+                  </ResponsiveContainer>
+                  <div className="mt-6 space-y-2">
+                    {breakdown.map((item, index) => (
+                      <div key={item.designation} className="flex items-center gap-3">
+                        <div
+                          className="w-4 h-4 rounded"
+                          style={{ backgroundColor: COLORS[index % COLORS.length] }}
+                        ></div>
+                        <span className="font-medium">{item.designation}</span>
+                        <span className="text-gray-600">({item.count})</span>
+                      </div>
+                    ))}
+                  </div>
                 </div>
+              ) : (
+                <p className="text-gray-500 text-center py-8">No data available</p>
+              )}
+            </div>
+            <div className="bg-white rounded-lg shadow-sm p-6">
+              <h2 className="text-xl font-bold mb-6">Organizations</h2>
+              {organizations && organizations.total > 0 ? (
+                <>
+                  <p className="text-sm text-gray-600 mb-4">
+                    {organizations.freeMail.count} registered with a personal email
+                    {organizations.invalid > 0 && `, ${organizations.invalid} without a valid email domain`}
+                  </p>
+                  <div className="space-y-2 mb-6">
+                    {organizations.organizations.map((org) => (
+                      <div key={org.organization} className="flex justify-between items-center border rounded-lg p-3">
+                        <div>
+                          <p className="font-medium">{org.organization}</p>
+                          {org.mapped && <p className="text-sm text-gray-600">{org.domains.join(', ')}</p>}
+                        </div>
+                        <div className="flex items-center gap-3">
+                          <span className="text-gray-600">{org.count}</span>
+                          {!org.mapped && (
+                            <button
+                              onClick={() => setDomainForm({ domain: org.organization, organization: '' })}
+                              className="text-blue-600 hover:text-blue-700 text-sm font-medium"
+                            >
+                              Map
+                            </button>
+                          )}
+                        </div>
+                      </div>
+                    ))}
+                  </div>
+                </>
+              ) : (
+                <p className="text-gray-500 text-center py-8">No data available</p>
+              )}
+              <h3 className="font-semibold mb-3">Domain mappings</h3>
+              <div className="space-y-2 mb-4">
+                {organizationDomains.map((mapping) => (
+                  <div key={mapping.domain} className="flex justify-between items-center text-sm">
+                    <span>
+                      <span className="font-mono">{mapping.domain}</span> → {mapping.organization}
+                    </span>
+                    <button
+                      onClick={() => handleDeleteDomain(mapping.domain)}
+                      className="text-red-600 hover:text-red-700 font-medium"
+                    >
+                      Delete
+                    </button>
+                  </div>
+                ))}
               </div>
-            ) : (
-              <p className="text-gray-500 text-center py-8">No data available</p>
-            )}
+              <form onSubmit={handleDomainSubmit} className="grid grid-cols-1 md:grid-cols-3 gap-3">
+                <input
+                  type="text"
+                  required
+                  placeholder="Domain, e.g. example.com"
+                  value={domainForm.domain}
+                  onChange={(e) => setDomainForm({ ...domainForm, domain: e.target.value })}
+                  className="px-3 py-2 border rounded-lg"
+                />
+                <input
+                  type="text"
+                  required
+                  placeholder="Organization"
+                  value={domainForm.organization}
+                  onChange={(e) => setDomainForm({ ...domainForm, organization: e.target.value })}
+                  className="px-3 py-2 border rounded-lg"
+                />
+                <button type="submit" className="gradient-bg text-white py-2 rounded-lg font-semibold hover:opacity-90">
+                  Save Mapping
+                </button>
+              </form>
+            </div>
+            <div className="bg-white rounded-lg shadow-sm p-6">
+              <div className="flex justify-between items-center mb-6">
+                <h2 className="text-xl font-bold">Designation Taxonomy</h2>
+                <button
+                  onClick={handleApplyDesignations}
+                  className="px-4 py-2 border border-gray-300 rounded-lg text-sm hover:bg-gray-50"
+                >
+                  Apply to existing registrations
+                </button>
+              </div>
+              {unmappedDesignations.length > 0 && (
+                <p className="text-sm text-gray-600 mb-4">Unmapped: {unmappedDesignations.join(', ')}</p>
+              )}
+              <div className="space-y-2 mb-6">
+                {taxonomy.map((entry) => (
+                  <div key={entry.id} className="flex justify-between items-start border rounded-lg p-3">
+                    <div>
+                      <p className="font-medium">{entry.name}</p>
+                      {entry.synonyms.length > 0 && (
+                        <p className="text-sm text-gray-600">Synonyms: {entry.synonyms.join(', ')}</p>
+                      )}
+                      {entry.patterns.length > 0 && (
+                        <p className="text-sm text-gray-600 font-mono">{entry.patterns.join('  ')}</p>
+                      )}
+                    </div>
+                    <button
+                      onClick={() => handleDeleteDesignation(entry.id!)}
+                      className="text-red-600 hover:text-red-700 text-sm font-medium"
+                    >
+                      Delete
+                    </button>
+                  </div>
+                ))}
+              </div>
+              <form onSubmit={handleDesignationSubmit} className="grid grid-cols-1 md:grid-cols-3 gap-3">
+                <input
+                  type="text"
+                  required
+                  placeholder="Canonical name"
+                  value={designationForm.name}
+                  onChange={(e) => setDesignationForm({ ...designationForm, name: e.target.value })}
+                  className="px-3 py-2 border rounded-lg"
+                />
+                <input
+                  type="text"
+                  placeholder="Synonyms, comma-separated"
+                  value={designationForm.synonyms}
+                  onChange={(e) => setDesignationForm({ ...designationForm, synonyms: e.target.value })}
+                  className="px-3 py-2 border rounded-lg"
+                />
+                <textarea
+                  placeholder="Regex patterns, one per line"
+                  value={designationForm.patterns}
+                  onChange={(e) => setDesignationForm({ ...designationForm, patterns: e.target.value })}
+                  className="px-3 py-2 border rounded-lg font-mono text-sm"
+                  rows={1}
+                />
+                <button
+                  type="submit"
+                  className="md:col-span-3 gradient-bg text-white py-2 rounded-lg font-semibold hover:opacity-90"
+                >
+                  Add Designation
+                </button>
+              </form>
+            </div>
           </div>
         )}
       </div>
@@ -438,14 +2052,47 @@ const AdminDashboard = () => {
                 />
               </div>
               <div>
-                <label className="block text-sm font-medium mb-1">Image URL</label>
-                <input
-                  type="url"
-                  value={speakerForm.imageUrl}
-                  onChange={(e) => setSpeakerForm({ ...speakerForm, imageUrl: e.target.value })}
-                  className="w-full px-3 py-2 border rounded-lg"
-                />
+                <label className="block text-sm font-medium mb-1">Photo</label>
+                {editingSpeaker ? (
+                  <div className="flex items-center gap-3">
+                    <SpeakerImage speaker={editingSpeaker} sizes="64px" className="w-16 h-16 rounded-full object-cover" />
+                    <input
+                      type="file"
+                      accept="image/jpeg,image/png,image/gif,image/webp"
+                      disabled={uploadingImage}
+                      onChange={(e) => {
+                        handleSpeakerImage(e.target.files?.[0])
+                        e.target.value = ''
+                      }}
+                      className="text-sm"
+                    />
+                    {editingSpeaker.imageUrl && (
+                      <button
+                        type="button"
+                        onClick={handleRemoveSpeakerImage}
+                        className="text-red-600 hover:text-red-700 text-sm font-medium"
+                      >
+                        Remove
+                      </button>
+                    )}
+                  </div>
+                ) : (
+                  <p className="text-xs text-gray-500">Save the speaker first to upload a photo.</p>
+                )}
+                {uploadingImage && <p className="text-xs text-gray-500 mt-1">Uploading...</p>}
               </div>
+              {/* An uploaded photo replaces the pasted URL until it's removed */}
+              {!editingSpeaker?.images?.length && (
+                <div>
+                  <label className="block text-sm font-medium mb-1">Or image URL</label>
+                  <input
+                    type="url"
+                    value={speakerForm.imageUrl}
+                    onChange={(e) => setSpeakerForm({ ...speakerForm, imageUrl: e.target.value })}
+                    className="w-full px-3 py-2 border rounded-lg"
+                  />
+                </div>
+              )}
               <div>
                 <label className="block text-sm font-medium mb-1">LinkedIn URL</label>
                 <input
@@ -545,6 +2192,19 @@ const AdminDashboard = () => {
                   />
                 </div>
               </div>
+              <div>
+                <label className="block text-sm font-medium mb-1">Visibility</label>
+                <select
+                  value={sessionForm.status || ''}
+                  onChange={(e) =>
+                    setSessionForm({ ...sessionForm, status: e.target.value === 'draft' ? 'draft' : undefined })
+                  }
+                  className="w-full px-3 py-2 border rounded-lg"
+                >
+                  <option value="">Published</option>
+                  <option value="draft">Draft (hidden from the public agenda)</option>
+                </select>
+              </div>
               <div>
                 <label className="block text-sm font-medium mb-1">Speakers</label>
                 <select
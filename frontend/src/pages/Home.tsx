@@ -1,20 +1,41 @@
+import { useEffect, useState } from 'react'
 import Hero from '../components/Hero'
+import Announcements from '../components/Announcements'
 import SessionsSpeakers from '../components/SessionsSpeakers'
 import RegistrationForm from '../components/RegistrationForm'
+import CallForPapers from '../components/CallForPapers'
 import Location from '../components/Location'
 import Footer from '../components/Footer'
+import { getWorkshopInfo } from '../api/endpoints'
+import { WorkshopInfo } from '../types'
 
 const Home = () => {
+  const [workshop, setWorkshop] = useState<WorkshopInfo | null>(null)
+
+  useEffect(() => {
+    // The components fall back to their built-in details if this fails
+    getWorkshopInfo()
+      .then(setWorkshop)
+      .catch((err) => console.error('Failed to load workshop details:', err))
+  }, [])
+
+  useEffect(() => {
+    if (workshop?.title) {
+      document.title = workshop.title
+    }
+  }, [workshop?.title])
+
   return (
     <div className="min-h-screen">
-      <Hero />
+      <Announcements />
+      <Hero workshop={workshop} />
       <SessionsSpeakers />
-      <RegistrationForm />
-      <Location />
-      <Footer />
+      <RegistrationForm lifecycle={workshop?.lifecycle} />
+      <CallForPapers cfp={workshop?.cfp} />
+      <Location workshop={workshop} />
+      <Footer workshop={workshop} />
     </div>
   )
 }
 
 export default Home
-
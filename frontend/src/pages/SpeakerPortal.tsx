@@ -0,0 +1,219 @@
+import { useEffect, useState } from 'react'
+import { getSpeakerProfile, updateSpeakerProfile } from '../api/endpoints'
+import SpeakerImage from '../components/SpeakerImage'
+import { SpeakerProfile, SpeakerProfileInput } from '../types'
+
+const inputClass =
+  'w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-blue-500 focus:border-transparent transition-all'
+
+// The magic link carries the token in the fragment and, for workshops other than the
+// default, the workshop in the query. Both are kept so reloading the page still works.
+const takeMagicLink = () => {
+  const token = new URLSearchParams(window.location.hash.slice(1)).get('token')
+  if (!token) return
+  localStorage.setItem('speaker_token', token)
+  const workshop = new URLSearchParams(window.location.search).get('workshop')
+  if (workshop) {
+    localStorage.setItem('speaker_workshop', workshop)
+  } else {
+    localStorage.removeItem('speaker_workshop')
+  }
+  window.history.replaceState(null, '', window.location.pathname)
+}
+
+const SpeakerPortal = () => {
+  const [profile, setProfile] = useState<SpeakerProfile | null>(null)
+  const [form, setForm] = useState<SpeakerProfileInput>({ name: '', bio: '', linkedinUrl: '', twitterUrl: '' })
+  const [loading, setLoading] = useState(true)
+  const [saving, setSaving] = useState(false)
+  const [message, setMessage] = useState<string | null>(null)
+  const [error, setError] = useState<string | null>(null)
+
+  useEffect(() => {
+    takeMagicLink()
+    if (!localStorage.getItem('speaker_token')) {
+      setError('Open the link from your invitation email to edit your speaker profile.')
+      setLoading(false)
+      return
+    }
+    getSpeakerProfile()
+      .then((data) => {
+        setProfile(data)
+        // Pick up where the speaker left off if an edit is still waiting for review
+        const source = data.pendingChange || data.speaker
+        setForm({
+          name: source.name,
+          bio: source.bio,
+          linkedinUrl: source.linkedinUrl || '',
+          twitterUrl: source.twitterUrl || '',
+        })
+      })
+      .catch((err) => setError(err.response?.data?.error || 'Failed to load your speaker profile.'))
+      .finally(() => setLoading(false))
+  }, [])
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault()
+    if (!profile) return
+    setSaving(true)
+    setMessage(null)
+    setError(null)
+
+    try {
+      const result = await updateSpeakerProfile(form)
+      if (result.speaker) {
+        setProfile({ ...profile, speaker: result.speaker, pendingChange: undefined })
+        setMessage('Your profile has been updated.')
+      } else {
+        setProfile({ ...profile, pendingChange: result.pendingChange })
+        setMessage('Thanks! The organizers will review your changes before they go live.')
+      }
+    } catch (err: any) {
+      setError(err.response?.data?.error || 'Failed to save your profile. Please try again.')
+    } finally {
+      setSaving(false)
+    }
+  }
+
+  if (loading) {
+    return (
+      <div className="min-h-screen flex items-center justify-center">
+        <p className="text-gray-600">Loading your profile...</p>
+      </div>
+    )
+  }
+
+  return (
+    <div className="min-h-screen bg-gray-50 py-12 px-4">
+      <div className="max-w-3xl mx-auto space-y-6">
+        <h1 className="text-3xl md:text-4xl font-bold gradient-text">Speaker Profile</h1>
+
+        {error && <div className="bg-red-50 border border-red-200 text-red-700 px-4 py-3 rounded-lg">{error}</div>}
+        {message && (
+          <div className="bg-green-50 border border-green-200 text-green-800 px-4 py-3 rounded-lg">{message}</div>
+        )}
+
+        {profile && (
+          <>
+            <div className="bg-white rounded-lg shadow-sm p-6">
+              <div className="flex items-center gap-4 mb-6">
+                <SpeakerImage speaker={profile.speaker} sizes="64px" className="w-16 h-16 rounded-full object-cover" />
+                <div>
+                  <h2 className="text-xl font-semibold text-gray-900">{profile.speaker.name}</h2>
+                  <p className="text-sm text-gray-500">
+                    {profile.requiresApproval
+                      ? 'Changes are published once the organizers approve them.'
+                      : 'Changes are published as soon as you save them.'}
+                  </p>
+                </div>
+              </div>
+
+              {profile.pendingChange && (
+                <div className="bg-yellow-50 border border-yellow-200 text-yellow-800 px-4 py-3 rounded-lg mb-6 text-sm">
+                  Changes you submitted on {new Date(profile.pendingChange.submittedAt).toLocaleString()} are waiting
+                  for review. Saving again replaces them.
+                </div>
+              )}
+
+              <form onSubmit={handleSubmit} className="space-y-6">
+                <div>
+                  <label htmlFor="speaker-name" className="block text-sm font-medium text-gray-700 mb-2">
+                    Name *
+                  </label>
+                  <input
+                    type="text"
+                    id="speaker-name"
+                    required
+                    maxLength={200}
+                    value={form.name}
+                    onChange={(e) => setForm({ ...form, name: e.target.value })}
+                    className={inputClass}
+                  />
+                </div>
+
+                <div>
+                  <label htmlFor="speaker-bio" className="block text-sm font-medium text-gray-700 mb-2">
+                    Bio
+                  </label>
+                  <textarea
+                    id="speaker-bio"
+                    rows={5}
+                    maxLength={2000}
+                    value={form.bio}
+                    onChange={(e) => setForm({ ...form, bio: e.target.value })}
+                    className={inputClass}
+                  />
+                </div>
+
+                <div className="grid grid-cols-1 md:grid-cols-2 gap-4">
+                  <div>
+                    <label htmlFor="speaker-linkedin" className="block text-sm font-medium text-gray-700 mb-2">
+                      LinkedIn URL
+                    </label>
+                    <input
+                      type="url"
+                      id="speaker-linkedin"
+                      value={form.linkedinUrl}
+                      onChange={(e) => setForm({ ...form, linkedinUrl: e.target.value })}
+                      className={inputClass}
+                    />
+                  </div>
+                  <div>
+                    <label htmlFor="speaker-twitter" className="block text-sm font-medium text-gray-700 mb-2">
+                      Twitter / X URL
+                    </label>
+                    <input
+                      type="url"
+                      id="speaker-twitter"
+                      value={form.twitterUrl}
+                      onChange={(e) => setForm({ ...form, twitterUrl: e.target.value })}
+                      className={inputClass}
+                    />
+                  </div>
+                </div>
+
+                <p className="text-sm text-gray-500">To change your photo, contact the organizers.</p>
+
+                <button
+                  type="submit"
+                  disabled={saving}
+                  className="gradient-bg text-white px-6 py-3 rounded-lg font-semibold hover:opacity-90 transition-opacity disabled:opacity-50"
+                >
+                  {saving ? 'Saving...' : profile.requiresApproval ? 'Submit for Review' : 'Save Profile'}
+                </button>
+              </form>
+            </div>
+
+            <div className="bg-white rounded-lg shadow-sm p-6">
+              <h2 className="text-xl font-semibold text-gray-900 mb-4">Your Sessions</h2>
+              {profile.sessions.length === 0 ? (
+                <p className="text-gray-500">You aren't on the agenda yet.</p>
+              ) : (
+                <ul className="divide-y divide-gray-200">
+                  {profile.sessions.map((session) => (
+                    <li key={session.id} className="py-4">
+                      <div className="flex items-center gap-2">
+                        <h3 className="font-semibold text-gray-900">{session.title}</h3>
+                        {session.status === 'draft' && (
+                          <span className="text-xs bg-gray-100 text-gray-600 px-2 py-0.5 rounded">Draft</span>
+                        )}
+                      </div>
+                      {(session.time || session.duration) && (
+                        <p className="text-sm text-gray-500">
+                          {[session.time, session.duration].filter(Boolean).join(' · ')}
+                        </p>
+                      )}
+                      <p className="text-gray-600 mt-1">{session.description}</p>
+                    </li>
+                  ))}
+                </ul>
+              )}
+            </div>
+          </>
+        )}
+      </div>
+    </div>
+  )
+}
+
+export default SpeakerPortal
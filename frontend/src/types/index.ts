@@ -3,6 +3,7 @@ export interface Registration {
   name: string
   email: string
   designation: string
+  canonicalDesignation?: string
   createdAt?: string
 }
 
@@ -13,6 +14,16 @@ export interface Speaker {
   imageUrl?: string
   linkedinUrl?: string
   twitterUrl?: string
+  // Sizes and formats of an uploaded photo; imageUrl points at the large fallback
+  images?: ImageVariant[]
+}
+
+export interface ImageVariant {
+  size: 'large' | 'thumb'
+  format: 'jpeg' | 'png' | 'webp'
+  width: number
+  height: number
+  url: string
 }
 
 export interface Session {
@@ -22,14 +33,276 @@ export interface Session {
   time: string
   duration: string
   speakerIds: string[]
+  // Drafts are hidden from the public agenda
+  status?: 'draft'
 }
 
 export interface SessionWithSpeakers extends Session {
   speakers: Speaker[]
 }
 
+export interface SessionFeedback {
+  id?: string
+  sessionId: string
+  registrationId: string
+  rating: number
+  comment?: string
+  createdAt: string
+}
+
+export interface FeedbackSummary {
+  sessionId: string
+  sessionTitle: string
+  count: number
+  average: number
+  histogram: number[]
+}
+
+export interface SessionFeedbackReport extends FeedbackSummary {
+  comments: SessionFeedback[]
+}
+
+export type QuestionStatus = 'pending' | 'approved' | 'hidden' | 'answered'
+
+export interface Question {
+  id: string
+  sessionId: string
+  registrationId?: string
+  authorName?: string
+  text: string
+  status: QuestionStatus
+  votes: number
+  createdAt: string
+  answeredAt?: string
+}
+
+export type PollStatus = 'draft' | 'open' | 'closed'
+
+export interface Poll {
+  id: string
+  sessionId: string
+  question: string
+  options: { id: string; text: string }[]
+  multiple: boolean
+  status: PollStatus
+  votes: Record<string, number>
+  voters: number
+  createdAt: string
+}
+
+export type AnnouncementSeverity = 'info' | 'warning' | 'critical'
+
+export interface Announcement {
+  id: string
+  title: string
+  body: string
+  severity: AnnouncementSeverity
+  publishAt: string
+  expiresAt?: string
+  createdAt: string
+  updatedAt: string
+}
+
+export interface AnnouncementInput {
+  title: string
+  body: string
+  severity: AnnouncementSeverity
+  publishAt?: string
+  expiresAt?: string
+}
+
+export interface AnnouncementEmail {
+  id: string
+  announcementId: string
+  status: 'running' | 'completed' | 'failed'
+  total: number
+  sent: number
+  failed: number
+  errors?: string[]
+  requestedBy: string
+  createdAt: string
+  updatedAt: string
+  finishedAt?: string
+}
+
+export interface Webhook {
+  id: string
+  url: string
+  events: string[]
+  description?: string
+  active: boolean
+  createdBy: string
+  createdAt: string
+}
+
+export interface WebhookInput {
+  url: string
+  events: string[]
+  description?: string
+  active?: boolean
+}
+
+export interface WebhookDelivery {
+  id: string
+  webhookId: string
+  eventId: string
+  event: string
+  payload: string
+  status: 'pending' | 'succeeded' | 'failed'
+  attempts: { at: string; statusCode?: number; error?: string; response?: string; durationMs: number }[]
+  redeliveryOf?: string
+  createdAt: string
+  updatedAt: string
+  nextAttemptAt: string
+}
+
 export interface DesignationBreakdown {
   designation: string
   count: number
+  unmapped?: boolean
+  values?: string[]
+}
+
+export interface Designation {
+  id?: string
+  name: string
+  synonyms: string[]
+  patterns: string[]
+}
+
+export interface OrganizationDomain {
+  domain: string
+  organization: string
+}
+
+export interface OrganizationBreakdown {
+  total: number
+  invalid: number
+  freeMail: { count: number; domains: { domain: string; count: number }[] }
+  organizations: { organization: string; domains: string[]; count: number; mapped: boolean }[]
 }
 
+export interface RegistrationTimeSeries {
+  interval: 'hour' | 'day' | 'week'
+  timeZone: string
+  total: number
+  buckets: { start: string; count: number; cumulative: number }[]
+}
+
+export interface LoginResponse {
+  token?: string
+  mfaRequired?: boolean
+  mfaToken?: string
+}
+
+export interface WorkshopInfo {
+  slug: string
+  title: string
+  tagline?: string
+  description?: string
+  startsAt?: string
+  endsAt?: string
+  timeZone?: string
+  venue: {
+    name?: string
+    address?: string
+    mapUrl?: string
+    mapEmbedUrl?: string
+  }
+  branding: {
+    logoUrl?: string
+    primaryColor?: string
+    secondaryColor?: string
+  }
+  updatedAt?: string
+  lifecycle: WorkshopLifecycle
+  cfp: CFP
+}
+
+export interface CFP {
+  open: boolean
+  closesAt?: string
+}
+
+// The fields a speaker can edit through the speaker portal
+export interface SpeakerProfileInput {
+  name: string
+  bio: string
+  linkedinUrl?: string
+  twitterUrl?: string
+}
+
+export interface SpeakerChange extends SpeakerProfileInput {
+  speakerId: string
+  submittedAt: string
+}
+
+export interface SpeakerChangeDetails extends SpeakerChange {
+  current: Speaker
+}
+
+export interface SpeakerProfile {
+  speaker: Speaker
+  sessions: Session[]
+  pendingChange?: SpeakerChange
+  requiresApproval: boolean
+}
+
+export interface SpeakerInvite {
+  speakerId: string
+  email: string
+  invitedBy?: string
+  createdAt: string
+  expiresAt: string
+  emailed: boolean
+  // Only returned when the link couldn't be emailed
+  link?: string
+}
+
+export type ProposalStatus = 'submitted' | 'accepted' | 'rejected'
+
+export interface ProposalInput {
+  title: string
+  abstract: string
+  name: string
+  email: string
+  bio: string
+  links: string[]
+}
+
+export interface Proposal extends ProposalInput {
+  id: string
+  status: ProposalStatus
+  reviewCount: number
+  scoreTotal: number
+  averageScore?: number
+  speakerId?: string
+  sessionId?: string
+  decidedBy?: string
+  decidedAt?: string
+  createdAt: string
+}
+
+export interface ProposalReview {
+  id: string
+  proposalId: string
+  reviewerId: string
+  score: number
+  comment?: string
+  createdAt: string
+  updatedAt: string
+}
+
+export interface ProposalDetails extends Proposal {
+  reviews: ProposalReview[]
+}
+
+export type WorkshopState = 'draft' | 'open' | 'closed' | 'in-progress' | 'finished'
+
+export interface WorkshopLifecycle {
+  state: WorkshopState
+  registrationOpen: boolean
+  registrationOpensAt?: string
+  registrationClosesAt?: string
+  code?: string
+}